@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"maps"
 	"net"
 	"os"
 	"os/signal"
@@ -9,11 +12,19 @@ import (
 	"time"
 
 	"github.com/wonyus/backend-challenge/internal/application/services"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/config"
 	grpcHandlers "github.com/wonyus/backend-challenge/internal/infrastructure/grpc/handlers"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/grpc/interceptors"
 	pb "github.com/wonyus/backend-challenge/internal/infrastructure/grpc/proto"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/bolt"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/cache"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/memory"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/mongodb"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/sql"
 	"github.com/wonyus/backend-challenge/pkg/logger"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -25,38 +36,245 @@ func main() {
 	logger.Info("Starting gRPC server...")
 
 	// Load configuration
+	storage := flag.String("storage", "", "storage driver, overriding storage.driver (memory, bolt, mongodb, sqlite or postgres)")
+	flag.Parse()
 	cfg := config.Load()
-
-	// Connect to MongoDB
-	mongoClient, err := mongodb.NewConnection(cfg.MongoURI)
+	if *storage != "" {
+		cfg.Storage.Driver = *storage
+	}
+	emailPolicy, err := entities.NewEmailPolicy(cfg.EmailLocalPart, cfg.EmailIgnoreSubaddress)
 	if err != nil {
-		logger.Error("Failed to connect to MongoDB:", err)
+		logger.Error("Invalid email configuration:", err)
 		os.Exit(1)
 	}
-	defer func() {
-		if err := mongoClient.Disconnect(context.Background()); err != nil {
-			logger.Error("Failed to disconnect from MongoDB:", err)
+	userIDGenerator, err := entities.NewUserIDGenerator(cfg.UserIDFormat)
+	if err != nil {
+		logger.Error("Invalid user ID configuration:", err)
+		os.Exit(1)
+	}
+	entities.SetUserIDGenerator(userIDGenerator)
+
+	// Initialize repositories
+	var (
+		userRepo         repositories.UserRepository
+		userFeed         domainServices.UserChangeFeed
+		apiKeyRepo       repositories.APIKeyRepository
+		sessionRepo      repositories.SessionRepository
+		organizationRepo repositories.OrganizationRepository
+		teamRepo         repositories.TeamRepository
+		membershipRepo   repositories.MembershipRepository
+	)
+	switch cfg.Storage.Driver {
+	case memory.Driver:
+		logger.Info("Keeping data in memory; it is lost on restart")
+		userRepo = memory.NewUserRepository(emailPolicy)
+		apiKeyRepo = memory.NewAPIKeyRepository()
+		sessionRepo = memory.NewSessionRepository()
+		organizationRepo = memory.NewOrganizationRepository()
+		teamRepo = memory.NewTeamRepository()
+		membershipRepo = memory.NewMembershipRepository()
+	case bolt.Driver:
+		boltDB, err := bolt.Open(cfg.Storage.DSN)
+		if err != nil {
+			logger.Error("Failed to open the database:", err)
+			os.Exit(1)
 		}
-	}()
+		defer boltDB.Close()
+		logger.Info("Opened database", cfg.Storage.DSN)
 
-	db := mongoClient.Database(cfg.DatabaseName)
-	logger.Info("Connected to MongoDB successfully")
+		if cfg.Storage.BackupDir != "" {
+			interval := cfg.Storage.BackupInterval
+			if interval <= 0 {
+				interval = 24 * time.Hour
+			}
+			go func() {
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
 
-	// Initialize repositories
-	userRepo := mongodb.NewUserRepository(db)
+				for range ticker.C {
+					path, err := boltDB.Snapshot(cfg.Storage.BackupDir)
+					if err != nil {
+						logger.Error("Failed to snapshot the database:", err)
+					} else {
+						logger.Info("Wrote database snapshot", path)
+					}
+				}
+			}()
+		}
+
+		userRepo = bolt.NewUserRepository(boltDB, emailPolicy)
+		apiKeyRepo = bolt.NewAPIKeyRepository(boltDB)
+		sessionRepo = bolt.NewSessionRepository(boltDB)
+		organizationRepo = bolt.NewOrganizationRepository(boltDB)
+		teamRepo = bolt.NewTeamRepository(boltDB)
+		membershipRepo = bolt.NewMembershipRepository(boltDB)
+	case sql.DriverSQLite, sql.DriverPostgres:
+		sqlDB, err := sql.Open(cfg.Storage.Driver, cfg.Storage.DSN)
+		if err != nil {
+			logger.Error("Failed to connect to the database:", err)
+			os.Exit(1)
+		}
+		defer sqlDB.Close()
+		logger.Info("Connected to database", cfg.Storage.Driver)
+
+		// Apply pending schema migrations
+		if cfg.Storage.RunMigrations {
+			applied, err := sql.Migrate(context.Background(), sqlDB)
+			for _, migration := range applied {
+				logger.Info(fmt.Sprintf("Applied migration %d: %s", migration.Version, migration.Description))
+			}
+			if err != nil {
+				logger.Error("Failed to migrate the database:", err)
+				os.Exit(1)
+			}
+		}
+
+		userRepo = sql.NewUserRepository(sqlDB, emailPolicy)
+		apiKeyRepo = sql.NewAPIKeyRepository(sqlDB)
+		sessionRepo = sql.NewSessionRepository(sqlDB)
+		organizationRepo = sql.NewOrganizationRepository(sqlDB)
+		teamRepo = sql.NewTeamRepository(sqlDB)
+		membershipRepo = sql.NewMembershipRepository(sqlDB)
+	case "", "mongodb":
+		// Connect to MongoDB
+		mongoClient, err := mongodb.NewConnection(cfg.MongoURI, mongodb.ConnectionOptions{
+			AppName:                cfg.Mongo.AppName,
+			MinPoolSize:            cfg.Mongo.MinPoolSize,
+			MaxPoolSize:            cfg.Mongo.MaxPoolSize,
+			MaxConnIdleTime:        cfg.Mongo.MaxConnIdleTime,
+			ConnectTimeout:         cfg.Mongo.ConnectTimeout,
+			ServerSelectionTimeout: cfg.Mongo.ServerSelectionTimeout,
+			ReadPreference:         cfg.Mongo.ReadPreference,
+			ReadConcern:            cfg.Mongo.ReadConcern,
+			WriteConcern:           cfg.Mongo.WriteConcern,
+			Compressors:            cfg.Mongo.Compressors,
+		})
+		if err != nil {
+			logger.Error("Failed to connect to MongoDB:", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := mongoClient.Disconnect(context.Background()); err != nil {
+				logger.Error("Failed to disconnect from MongoDB:", err)
+			}
+		}()
+
+		db := mongoClient.Database(cfg.DatabaseName)
+		logger.Info("Connected to MongoDB successfully")
+
+		// Guard repository operations against a degraded MongoDB
+		guarded := mongodb.NewDatabase(db, mongodb.NewGuard(mongodb.GuardOptions{
+			OperationTimeout: cfg.Mongo.OperationTimeout,
+			MaxRetries:       cfg.Mongo.MaxRetries,
+			RetryBackoff:     cfg.Mongo.RetryBackoff,
+			BreakerThreshold: cfg.Mongo.BreakerThreshold,
+			BreakerCooldown:  cfg.Mongo.BreakerCooldown,
+			OnStateChange: func(from, to mongodb.BreakerState) {
+				logger.Info("MongoDB circuit breaker:", from, "->", to)
+			},
+		}))
+
+		// Apply pending schema migrations
+		if cfg.Storage.RunMigrations {
+			applied, err := mongodb.NewMigrator(db, mongodb.Migrations(emailPolicy)).Up(context.Background())
+			for _, migration := range applied {
+				logger.Info(fmt.Sprintf("Applied migration %d: %s", migration.Version, migration.Description))
+			}
+			if err != nil {
+				logger.Error("Failed to migrate the database:", err)
+				os.Exit(1)
+			}
+		}
+
+		userRepo = mongodb.NewUserRepository(guarded, emailPolicy)
+
+		// Deleted users are only reported with pre-images, which need
+		// MongoDB 6.0. Change streams need a replica set.
+		feed := mongodb.NewUserChangeFeed(guarded)
+		if err := feed.EnablePreImages(context.Background()); err != nil {
+			logger.Error("Failed to record pre-images of users, user deletions will not be streamed:", err)
+		}
+		userFeed = feed
+
+		apiKeyRepo = mongodb.NewAPIKeyRepository(guarded)
+		sessionRepo = mongodb.NewSessionRepository(guarded)
+		organizationRepo = mongodb.NewOrganizationRepository(guarded)
+		teamRepo = mongodb.NewTeamRepository(guarded)
+		membershipRepo = mongodb.NewMembershipRepository(guarded)
+	default:
+		logger.Error("Unknown storage driver:", cfg.Storage.Driver)
+		os.Exit(1)
+	}
+
+	// Stores without a change feed of their own stream the changes made
+	// through this instance
+	if userFeed == nil {
+		history := cfg.UserEvents.History
+		if history <= 0 {
+			history = 1000
+		}
+		feed := memory.NewUserChangeFeed(history)
+		userRepo = feed.UserRepository(userRepo)
+		userFeed = feed
+	}
+
+	// Cache users in front of the store
+	var userCache *cache.UserRepository
+	if cfg.UserCache.Enabled {
+		size, ttl := cfg.UserCache.Size, cfg.UserCache.TTL
+		if size <= 0 {
+			size = 10000
+		}
+		if ttl <= 0 {
+			ttl = 30 * time.Second
+		}
+		userCache = cache.NewUserRepository(userRepo, cache.NewLRU(size, ttl))
+		userRepo = userCache
+	}
 
 	// Initialize services
-	jwtService := auth.NewJWTService(cfg.JWTSecret, userRepo)
-	userService := services.NewUserService(userRepo, jwtService)
+	passwordHasher, err := auth.NewConfiguredPasswordHasher(cfg.PasswordHashAlgorithm, cfg.BcryptCost, auth.Argon2idParams{
+		Memory:      cfg.Argon2Memory,
+		Iterations:  cfg.Argon2Iterations,
+		Parallelism: cfg.Argon2Parallelism,
+	})
+	if err != nil {
+		logger.Error("Failed to configure password hashing:", err)
+		os.Exit(1)
+	}
+
+	tenants, err := auth.NewTenants(cfg.Tenants...)
+	if err != nil {
+		logger.Error("Failed to configure tenants:", err)
+		os.Exit(1)
+	}
+
+	jwtService := auth.NewJWTService(cfg.JWTSecret, userRepo, auth.WithSessions(sessionRepo))
+	userService := services.NewUserService(userRepo, passwordHasher)
+	userEventService := services.NewUserEventService(userFeed)
+	authService := services.NewAuthService(userRepo, jwtService, passwordHasher)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo)
+	organizationService := services.NewOrganizationService(organizationRepo, teamRepo, membershipRepo, userRepo)
 
 	// Initialize gRPC handlers
-	userGRPCHandler := grpcHandlers.NewUserGRPCHandler(userService)
+	userGRPCHandler := grpcHandlers.NewUserGRPCHandler(userService, organizationService, userEventService)
+	organizationGRPCHandler := grpcHandlers.NewOrganizationGRPCHandler(organizationService)
+
+	// Initialize interceptors
+	methodScopes := make(map[string][]string)
+	maps.Copy(methodScopes, grpcHandlers.UserServiceScopes)
+	maps.Copy(methodScopes, grpcHandlers.OrganizationServiceScopes)
+	authInterceptor := interceptors.NewAuthInterceptor(authService, apiKeyService, tenants, methodScopes)
 
 	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(authInterceptor.Unary()),
+		grpc.StreamInterceptor(authInterceptor.Stream()),
+	)
 
 	// Register services
 	pb.RegisterUserServiceServer(grpcServer, userGRPCHandler)
+	pb.RegisterOrganizationServiceServer(grpcServer, organizationGRPCHandler)
 
 	// Enable reflection for testing with tools like grpcurl
 	reflection.Register(grpcServer)
@@ -69,11 +287,16 @@ func main() {
 		for {
 			select {
 			case <-ticker.C:
-				count, err := userService.GetUserCount(context.Background())
-				if err != nil {
-					logger.Error("Failed to get user count:", err)
-				} else {
-					logger.Info("Current user count:", count)
+				for _, tenantID := range tenants.IDs() {
+					count, err := userService.GetUserCount(domainServices.WithTenant(context.Background(), tenantID))
+					if err != nil {
+						logger.Error("Failed to get user count:", err)
+					} else {
+						logger.Info("Current user count:", tenantID, count)
+					}
+				}
+				if userCache != nil {
+					logger.Info("User cache:", userCache.Stats())
 				}
 			}
 		}
@@ -102,8 +325,21 @@ func main() {
 
 	logger.Info("Shutting down gRPC server...")
 
-	// Graceful shutdown
-	grpcServer.GracefulStop()
+	// Graceful shutdown. Watches of the user change feed never finish on
+	// their own, so they are ended first, and calls still running after the
+	// deadline are cut off.
+	userEventService.Close()
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(30 * time.Second):
+		logger.Info("gRPC server forced to stop")
+		grpcServer.Stop()
+	}
 
 	logger.Info("gRPC server exited")
 }
@@ -2,20 +2,37 @@ package main
 
 import (
 	"context"
+	"crypto/rsa"
+	"flag"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/wonyus/backend-challenge/internal/application/ports"
 	"github.com/wonyus/backend-challenge/internal/application/services"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/config"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/crypto"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/federation"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/http/handlers"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/http/router"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/mailer"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/bolt"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/cache"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/memory"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/mongodb"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/sql"
 	"github.com/wonyus/backend-challenge/pkg/logger"
+	"github.com/wonyus/backend-challenge/pkg/webauthn"
 )
 
 func main() {
@@ -24,43 +41,367 @@ func main() {
 	logger.Info("Starting HTTP server...")
 
 	// Load configuration
+	storage := flag.String("storage", "", "storage driver, overriding storage.driver (memory, bolt, mongodb, sqlite or postgres)")
+	flag.Parse()
 	cfg := config.Load()
-
-	// Connect to MongoDB
-	mongoClient, err := mongodb.NewConnection(cfg.MongoURI)
+	if *storage != "" {
+		cfg.Storage.Driver = *storage
+	}
+	emailPolicy, err := entities.NewEmailPolicy(cfg.EmailLocalPart, cfg.EmailIgnoreSubaddress)
+	if err != nil {
+		logger.Error("Invalid email configuration:", err)
+		os.Exit(1)
+	}
+	userIDGenerator, err := entities.NewUserIDGenerator(cfg.UserIDFormat)
 	if err != nil {
-		logger.Error("Failed to connect to MongoDB:", err)
+		logger.Error("Invalid user ID configuration:", err)
 		os.Exit(1)
 	}
-	defer func() {
-		if err := mongoClient.Disconnect(context.Background()); err != nil {
-			logger.Error("Failed to disconnect from MongoDB:", err)
+	entities.SetUserIDGenerator(userIDGenerator)
+
+	// Initialize repositories
+	var (
+		userRepo              repositories.UserRepository
+		userFeed              domainServices.UserChangeFeed
+		apiKeyRepo            repositories.APIKeyRepository
+		oauthClientRepo       repositories.OAuthClientRepository
+		authorizationCodeRepo repositories.AuthorizationCodeRepository
+		federatedLoginRepo    repositories.FederatedLoginRepository
+		magicLinkRepo         repositories.MagicLinkRepository
+		passkeyRepo           repositories.PasskeyRepository
+		passkeyCeremonyRepo   repositories.PasskeyCeremonyRepository
+		sessionRepo           repositories.SessionRepository
+		auditRepo             repositories.AuditRepository
+		organizationRepo      repositories.OrganizationRepository
+		teamRepo              repositories.TeamRepository
+		membershipRepo        repositories.MembershipRepository
+		userInvitationRepo    repositories.UserInvitationRepository
+	)
+	switch cfg.Storage.Driver {
+	case memory.Driver:
+		logger.Info("Keeping data in memory; it is lost on restart")
+		userRepo = memory.NewUserRepository(emailPolicy)
+		apiKeyRepo = memory.NewAPIKeyRepository()
+		oauthClientRepo = memory.NewOAuthClientRepository()
+		authorizationCodeRepo = memory.NewAuthorizationCodeRepository()
+		federatedLoginRepo = memory.NewFederatedLoginRepository()
+		magicLinkRepo = memory.NewMagicLinkRepository()
+		passkeyRepo = memory.NewPasskeyRepository()
+		passkeyCeremonyRepo = memory.NewPasskeyCeremonyRepository()
+		sessionRepo = memory.NewSessionRepository()
+		auditRepo = memory.NewAuditRepository()
+		organizationRepo = memory.NewOrganizationRepository()
+		teamRepo = memory.NewTeamRepository()
+		membershipRepo = memory.NewMembershipRepository()
+		userInvitationRepo = memory.NewUserInvitationRepository(emailPolicy)
+	case bolt.Driver:
+		boltDB, err := bolt.Open(cfg.Storage.DSN)
+		if err != nil {
+			logger.Error("Failed to open the database:", err)
+			os.Exit(1)
 		}
-	}()
+		defer boltDB.Close()
+		logger.Info("Opened database", cfg.Storage.DSN)
 
-	db := mongoClient.Database(cfg.DatabaseName)
-	logger.Info("Connected to MongoDB successfully")
+		if cfg.Storage.BackupDir != "" {
+			interval := cfg.Storage.BackupInterval
+			if interval <= 0 {
+				interval = 24 * time.Hour
+			}
+			go func() {
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
 
-	// Initialize repositories
-	userRepo := mongodb.NewUserRepository(db)
+				for range ticker.C {
+					path, err := boltDB.Snapshot(cfg.Storage.BackupDir)
+					if err != nil {
+						logger.Error("Failed to snapshot the database:", err)
+					} else {
+						logger.Info("Wrote database snapshot", path)
+					}
+				}
+			}()
+		}
+
+		userRepo = bolt.NewUserRepository(boltDB, emailPolicy)
+		apiKeyRepo = bolt.NewAPIKeyRepository(boltDB)
+		oauthClientRepo = bolt.NewOAuthClientRepository(boltDB)
+		authorizationCodeRepo = bolt.NewAuthorizationCodeRepository(boltDB)
+		federatedLoginRepo = bolt.NewFederatedLoginRepository(boltDB)
+		magicLinkRepo = bolt.NewMagicLinkRepository(boltDB)
+		passkeyRepo = bolt.NewPasskeyRepository(boltDB)
+		passkeyCeremonyRepo = bolt.NewPasskeyCeremonyRepository(boltDB)
+		sessionRepo = bolt.NewSessionRepository(boltDB)
+		auditRepo = bolt.NewAuditRepository(boltDB)
+		organizationRepo = bolt.NewOrganizationRepository(boltDB)
+		teamRepo = bolt.NewTeamRepository(boltDB)
+		membershipRepo = bolt.NewMembershipRepository(boltDB)
+		userInvitationRepo = bolt.NewUserInvitationRepository(boltDB, emailPolicy)
+	case sql.DriverSQLite, sql.DriverPostgres:
+		sqlDB, err := sql.Open(cfg.Storage.Driver, cfg.Storage.DSN)
+		if err != nil {
+			logger.Error("Failed to connect to the database:", err)
+			os.Exit(1)
+		}
+		defer sqlDB.Close()
+		logger.Info("Connected to database", cfg.Storage.Driver)
+
+		// Apply pending schema migrations
+		if cfg.Storage.RunMigrations {
+			applied, err := sql.Migrate(context.Background(), sqlDB)
+			for _, migration := range applied {
+				logger.Info(fmt.Sprintf("Applied migration %d: %s", migration.Version, migration.Description))
+			}
+			if err != nil {
+				logger.Error("Failed to migrate the database:", err)
+				os.Exit(1)
+			}
+		}
+
+		userRepo = sql.NewUserRepository(sqlDB, emailPolicy)
+		apiKeyRepo = sql.NewAPIKeyRepository(sqlDB)
+		oauthClientRepo = sql.NewOAuthClientRepository(sqlDB)
+		authorizationCodeRepo = sql.NewAuthorizationCodeRepository(sqlDB)
+		federatedLoginRepo = sql.NewFederatedLoginRepository(sqlDB)
+		magicLinkRepo = sql.NewMagicLinkRepository(sqlDB)
+		passkeyRepo = sql.NewPasskeyRepository(sqlDB)
+		passkeyCeremonyRepo = sql.NewPasskeyCeremonyRepository(sqlDB)
+		sessionRepo = sql.NewSessionRepository(sqlDB)
+		auditRepo = sql.NewAuditRepository(sqlDB)
+		organizationRepo = sql.NewOrganizationRepository(sqlDB)
+		teamRepo = sql.NewTeamRepository(sqlDB)
+		membershipRepo = sql.NewMembershipRepository(sqlDB)
+		userInvitationRepo = sql.NewUserInvitationRepository(sqlDB, emailPolicy)
+	case "", "mongodb":
+		// Connect to MongoDB
+		mongoClient, err := mongodb.NewConnection(cfg.MongoURI, mongodb.ConnectionOptions{
+			AppName:                cfg.Mongo.AppName,
+			MinPoolSize:            cfg.Mongo.MinPoolSize,
+			MaxPoolSize:            cfg.Mongo.MaxPoolSize,
+			MaxConnIdleTime:        cfg.Mongo.MaxConnIdleTime,
+			ConnectTimeout:         cfg.Mongo.ConnectTimeout,
+			ServerSelectionTimeout: cfg.Mongo.ServerSelectionTimeout,
+			ReadPreference:         cfg.Mongo.ReadPreference,
+			ReadConcern:            cfg.Mongo.ReadConcern,
+			WriteConcern:           cfg.Mongo.WriteConcern,
+			Compressors:            cfg.Mongo.Compressors,
+		})
+		if err != nil {
+			logger.Error("Failed to connect to MongoDB:", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := mongoClient.Disconnect(context.Background()); err != nil {
+				logger.Error("Failed to disconnect from MongoDB:", err)
+			}
+		}()
+
+		db := mongoClient.Database(cfg.DatabaseName)
+		logger.Info("Connected to MongoDB successfully")
+
+		// Guard repository operations against a degraded MongoDB
+		guarded := mongodb.NewDatabase(db, mongodb.NewGuard(mongodb.GuardOptions{
+			OperationTimeout: cfg.Mongo.OperationTimeout,
+			MaxRetries:       cfg.Mongo.MaxRetries,
+			RetryBackoff:     cfg.Mongo.RetryBackoff,
+			BreakerThreshold: cfg.Mongo.BreakerThreshold,
+			BreakerCooldown:  cfg.Mongo.BreakerCooldown,
+			OnStateChange: func(from, to mongodb.BreakerState) {
+				logger.Info("MongoDB circuit breaker:", from, "->", to)
+			},
+		}))
+
+		// Apply pending schema migrations
+		if cfg.Storage.RunMigrations {
+			applied, err := mongodb.NewMigrator(db, mongodb.Migrations(emailPolicy)).Up(context.Background())
+			for _, migration := range applied {
+				logger.Info(fmt.Sprintf("Applied migration %d: %s", migration.Version, migration.Description))
+			}
+			if err != nil {
+				logger.Error("Failed to migrate the database:", err)
+				os.Exit(1)
+			}
+		}
+
+		userRepo = mongodb.NewUserRepository(guarded, emailPolicy)
+
+		// Deleted users are only reported with pre-images, which need
+		// MongoDB 6.0. Change streams need a replica set.
+		feed := mongodb.NewUserChangeFeed(guarded)
+		if err := feed.EnablePreImages(context.Background()); err != nil {
+			logger.Error("Failed to record pre-images of users, user deletions will not be streamed:", err)
+		}
+		userFeed = feed
+
+		apiKeyRepo = mongodb.NewAPIKeyRepository(guarded)
+		oauthClientRepo = mongodb.NewOAuthClientRepository(guarded)
+		authorizationCodeRepo = mongodb.NewAuthorizationCodeRepository(guarded)
+		federatedLoginRepo = mongodb.NewFederatedLoginRepository(guarded)
+		magicLinkRepo = mongodb.NewMagicLinkRepository(guarded)
+		passkeyRepo = mongodb.NewPasskeyRepository(guarded)
+		passkeyCeremonyRepo = mongodb.NewPasskeyCeremonyRepository(guarded)
+		sessionRepo = mongodb.NewSessionRepository(guarded)
+		auditRepo = mongodb.NewAuditRepository(guarded)
+		organizationRepo = mongodb.NewOrganizationRepository(guarded)
+		teamRepo = mongodb.NewTeamRepository(guarded)
+		membershipRepo = mongodb.NewMembershipRepository(guarded)
+		userInvitationRepo = mongodb.NewUserInvitationRepository(guarded, emailPolicy)
+	default:
+		logger.Error("Unknown storage driver:", cfg.Storage.Driver)
+		os.Exit(1)
+	}
+
+	// Stores without a change feed of their own stream the changes made
+	// through this instance
+	if userFeed == nil {
+		history := cfg.UserEvents.History
+		if history <= 0 {
+			history = 1000
+		}
+		feed := memory.NewUserChangeFeed(history)
+		userRepo = feed.UserRepository(userRepo)
+		userFeed = feed
+	}
+
+	// Cache users in front of the store
+	var userCache *cache.UserRepository
+	if cfg.UserCache.Enabled {
+		size, ttl := cfg.UserCache.Size, cfg.UserCache.TTL
+		if size <= 0 {
+			size = 10000
+		}
+		if ttl <= 0 {
+			ttl = 30 * time.Second
+		}
+		userCache = cache.NewUserRepository(userRepo, cache.NewLRU(size, ttl))
+		userRepo = userCache
+	}
+
+	// Initialize mailer
+	var mail ports.Mailer = mailer.NewLogMailer(logger)
+	if cfg.SMTPHost != "" {
+		mail = mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	}
 
 	// Initialize services
-	jwtService := auth.NewJWTService(cfg.JWTSecret, userRepo)
-	userService := services.NewUserService(userRepo, jwtService)
-	authService := services.NewAuthService(userRepo, jwtService)
+	var authOptions []services.AuthServiceOption
+	if cfg.EnumerationSafeRegistration {
+		authOptions = append(authOptions, services.WithEnumerationSafeRegistration(mail))
+	}
+
+	passwordHasher, err := auth.NewConfiguredPasswordHasher(cfg.PasswordHashAlgorithm, cfg.BcryptCost, auth.Argon2idParams{
+		Memory:      cfg.Argon2Memory,
+		Iterations:  cfg.Argon2Iterations,
+		Parallelism: cfg.Argon2Parallelism,
+	})
+	if err != nil {
+		logger.Error("Failed to configure password hashing:", err)
+		os.Exit(1)
+	}
+
+	secretEncrypter, err := crypto.NewAESEncrypter(cfg.MFAEncryptionKey)
+	if err != nil {
+		logger.Error("Failed to configure MFA encryption:", err)
+		os.Exit(1)
+	}
+
+	var signingKey *rsa.PrivateKey
+	if cfg.OIDCSigningKeyFile != "" {
+		signingKey, err = auth.LoadSigningKey(cfg.OIDCSigningKeyFile)
+	} else {
+		logger.Info("No OIDC signing key configured, generating a temporary one")
+		signingKey, err = auth.GenerateSigningKey()
+	}
+	if err != nil {
+		logger.Error("Failed to load OIDC signing key:", err)
+		os.Exit(1)
+	}
+
+	identityProviders := make([]domainServices.IdentityProvider, 0, len(cfg.FederatedProviders))
+	for _, provider := range cfg.FederatedProviders {
+		redirectURL := provider.RedirectURL
+		if redirectURL == "" {
+			redirectURL = strings.TrimSuffix(cfg.OIDCIssuer, "/") + "/api/auth/federated/" + provider.Name + "/callback"
+		}
+		identityProviders = append(identityProviders, federation.NewOIDCProvider(federation.ProviderConfig{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       provider.Scopes,
+		}, nil))
+	}
+
+	magicLinkURL := cfg.MagicLinkURL
+	if magicLinkURL == "" {
+		magicLinkURL = strings.TrimSuffix(cfg.OIDCIssuer, "/") + "/api/auth/magic-link/callback"
+	}
+
+	invitationURL := cfg.InvitationURL
+	if invitationURL == "" {
+		invitationURL = strings.TrimSuffix(cfg.OIDCIssuer, "/") + "/invitations/accept"
+	}
+
+	tenants, err := auth.NewTenants(cfg.Tenants...)
+	if err != nil {
+		logger.Error("Failed to configure tenants:", err)
+		os.Exit(1)
+	}
+
+	relyingParty, err := newRelyingParty(cfg)
+	if err != nil {
+		logger.Error("Failed to configure WebAuthn:", err)
+		os.Exit(1)
+	}
+
+	jwtService := auth.NewJWTService(cfg.JWTSecret, userRepo, auth.WithIDTokenSigning(cfg.OIDCIssuer, signingKey), auth.WithSessions(sessionRepo))
+	userService := services.NewUserService(userRepo, passwordHasher)
+	userEventService := services.NewUserEventService(userFeed)
+	authService := services.NewAuthService(userRepo, jwtService, passwordHasher, authOptions...)
+	mfaService := services.NewMFAService(userRepo, jwtService, secretEncrypter, cfg.MFAIssuer)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo)
+	oidcService := services.NewOIDCService(oauthClientRepo, authorizationCodeRepo, userRepo, jwtService, cfg.OIDCIssuer)
+	federationService := services.NewFederationService(federatedLoginRepo, userRepo, userService, jwtService, identityProviders...)
+	magicLinkService := services.NewMagicLinkService(magicLinkRepo, userRepo, jwtService, mail, magicLinkURL)
+	passkeyService := services.NewPasskeyService(passkeyRepo, passkeyCeremonyRepo, userRepo, jwtService, relyingParty)
+	sessionService := services.NewSessionService(sessionRepo, userRepo)
+	impersonationService := services.NewImpersonationService(userRepo, auditRepo, jwtService)
+	organizationService := services.NewOrganizationService(organizationRepo, teamRepo, membershipRepo, userRepo)
+	userInvitationService := services.NewUserInvitationService(userInvitationRepo, userRepo, passwordHasher, mail, invitationURL)
+	scimService := services.NewSCIMService(userService, userRepo, cfg.OIDCIssuer)
 
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, organizationService)
+	heartbeat := cfg.UserEvents.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	userEventHandler := handlers.NewUserEventHandler(userEventService, heartbeat)
 	authHandler := handlers.NewAuthHandler(authService)
+	mfaHandler := handlers.NewMFAHandler(mfaService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, authService, mfaService)
+	federationHandler := handlers.NewFederationHandler(federationService)
+	magicLinkHandler := handlers.NewMagicLinkHandler(magicLinkService)
+	passkeyHandler := handlers.NewPasskeyHandler(passkeyService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	impersonationHandler := handlers.NewImpersonationHandler(impersonationService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+	userInvitationHandler := handlers.NewUserInvitationHandler(userInvitationService)
+	scimHandler := handlers.NewSCIMHandler(scimService)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(authService)
+	authMiddleware := middleware.NewAuthMiddleware(authService, apiKeyService, middleware.WithServiceToken("scim", cfg.SCIMToken, entities.ScopeSCIM), middleware.WithImpersonationAudit(impersonationService))
+
+	// Initialize tenant middleware
+	tenantMiddleware := middleware.NewTenantMiddleware(tenants, cfg.TenantDomain)
 
 	// Initialize logging middleware
 	loggingMiddleware := middleware.NewLoggingMiddleware(logger)
 
 	// Initialize router
-	r := router.NewRouter(userHandler, authHandler, authMiddleware, loggingMiddleware)
+	r := router.NewRouter(userHandler, userEventHandler, authHandler, mfaHandler, apiKeyHandler, oidcHandler, federationHandler, magicLinkHandler, passkeyHandler, sessionHandler, impersonationHandler, organizationHandler, userInvitationHandler, scimHandler, tenantMiddleware, authMiddleware, loggingMiddleware)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -70,6 +411,9 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Shutdown waits for requests to finish, which watches of the user change
+	// feed never do on their own
+	server.RegisterOnShutdown(userEventService.Close)
 
 	// Start background goroutine for user count logging
 	go func() {
@@ -79,11 +423,16 @@ func main() {
 		for {
 			select {
 			case <-ticker.C:
-				count, err := userService.GetUserCount(context.Background())
-				if err != nil {
-					logger.Error("Failed to get user count:", err)
-				} else {
-					logger.Info("Current user count:", count)
+				for _, tenantID := range tenants.IDs() {
+					count, err := userService.GetUserCount(domainServices.WithTenant(context.Background(), tenantID))
+					if err != nil {
+						logger.Error("Failed to get user count:", err)
+					} else {
+						logger.Info("Current user count:", tenantID, count)
+					}
+				}
+				if userCache != nil {
+					logger.Info("User cache:", userCache.Stats())
 				}
 			}
 		}
@@ -117,3 +466,28 @@ func main() {
 
 	logger.Info("Server exited")
 }
+
+// newRelyingParty configures passkeys for the web app, which is served from
+// OIDCIssuer unless configured otherwise.
+func newRelyingParty(cfg *config.Config) (*webauthn.RelyingParty, error) {
+	issuer, err := url.Parse(cfg.OIDCIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	relyingParty := &webauthn.RelyingParty{
+		ID:      cfg.WebAuthnRPID,
+		Name:    cfg.WebAuthnRPName,
+		Origins: cfg.WebAuthnOrigins,
+	}
+	if relyingParty.ID == "" {
+		relyingParty.ID = issuer.Hostname()
+	}
+	if relyingParty.Name == "" {
+		relyingParty.Name = relyingParty.ID
+	}
+	if len(relyingParty.Origins) == 0 {
+		relyingParty.Origins = []string{issuer.Scheme + "://" + issuer.Host}
+	}
+	return relyingParty, nil
+}
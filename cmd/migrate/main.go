@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/config"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/mongodb"
+	"github.com/wonyus/backend-challenge/pkg/logger"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up        apply every pending migration
+  down [n]  undo the last n applied migrations (default 1)
+  status    list the migrations and whether they are applied
+`
+
+func main() {
+	// Initialize logger
+	logger := logger.New()
+
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load configuration
+	cfg := config.Load()
+	emailPolicy, err := entities.NewEmailPolicy(cfg.EmailLocalPart, cfg.EmailIgnoreSubaddress)
+	if err != nil {
+		logger.Error("Invalid email configuration:", err)
+		os.Exit(1)
+	}
+
+	// Connect to MongoDB
+	mongoClient, err := mongodb.NewConnection(cfg.MongoURI, mongodb.ConnectionOptions{
+		AppName:                cfg.Mongo.AppName,
+		MinPoolSize:            cfg.Mongo.MinPoolSize,
+		MaxPoolSize:            cfg.Mongo.MaxPoolSize,
+		MaxConnIdleTime:        cfg.Mongo.MaxConnIdleTime,
+		ConnectTimeout:         cfg.Mongo.ConnectTimeout,
+		ServerSelectionTimeout: cfg.Mongo.ServerSelectionTimeout,
+		ReadPreference:         cfg.Mongo.ReadPreference,
+		ReadConcern:            cfg.Mongo.ReadConcern,
+		WriteConcern:           cfg.Mongo.WriteConcern,
+		Compressors:            cfg.Mongo.Compressors,
+	})
+	if err != nil {
+		logger.Error("Failed to connect to MongoDB:", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := mongoClient.Disconnect(context.Background()); err != nil {
+			logger.Error("Failed to disconnect from MongoDB:", err)
+		}
+	}()
+
+	migrator := mongodb.NewMigrator(mongoClient.Database(cfg.DatabaseName), mongodb.Migrations(emailPolicy))
+	ctx := context.Background()
+
+	switch flag.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			logger.Info(fmt.Sprintf("Applied migration %d: %s", migration.Version, migration.Description))
+		}
+		if err != nil {
+			logger.Error("Failed to migrate:", err)
+			os.Exit(1)
+		}
+		if len(applied) == 0 {
+			logger.Info("The database is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				logger.Error("The number of migrations to undo must be a positive number")
+				os.Exit(2)
+			}
+		}
+		undone, err := migrator.Down(ctx, steps)
+		for _, migration := range undone {
+			logger.Info(fmt.Sprintf("Undid migration %d: %s", migration.Version, migration.Description))
+		}
+		if err != nil {
+			logger.Error("Failed to migrate:", err)
+			os.Exit(1)
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Error("Failed to read the migrations:", err)
+			os.Exit(1)
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02T15:04:05Z")
+			}
+			fmt.Printf("%4d  %-30s %s\n", status.Version, status.Description, applied)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
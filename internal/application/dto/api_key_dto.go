@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+// Request DTOs
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=2"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Response DTOs
+type APIKeyResponse struct {
+	ID         entities.ID `json:"id"`
+	Name       string      `json:"name"`
+	Prefix     string      `json:"prefix"`
+	Scopes     []string    `json:"scopes,omitempty"`
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// CreateAPIKeyResponse is the only response that ever contains the plaintext
+// key; it cannot be retrieved again later.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type APIKeysListResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+	Total   int              `json:"total"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// ProvisionUserRequest creates a user without a password. Provider and
+// Subject link the user to an account at an external identity provider.
+type ProvisionUserRequest struct {
+	Name     string
+	Email    string
+	Provider string
+	Subject  string
+}
+
+// FederatedLoginStart is where to send the user to sign in at the identity
+// provider. State must be bound to the user's browser until the callback.
+type FederatedLoginStart struct {
+	AuthorizationURL string
+	State            string
+}
+
+type IdentityProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
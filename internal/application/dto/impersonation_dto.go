@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// Response DTOs
+
+// ImpersonationResponse carries a short-lived token for acting as User. Calls
+// made with it are audited and cannot change User's credentials.
+type ImpersonationResponse struct {
+	Token     string        `json:"token"`
+	Scope     string        `json:"scope,omitempty"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	User      *UserResponse `json:"user"`
+	Actor     *UserResponse `json:"actor"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MagicLinkResponse struct {
+	Message string `json:"message"`
+}
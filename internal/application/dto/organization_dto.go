@@ -0,0 +1,99 @@
+package dto
+
+import (
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"time"
+)
+
+// Request DTOs
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2"`
+}
+
+// InviteMemberRequest invites a user of the tenant by email. Role is owner,
+// admin or member.
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required"`
+}
+
+// MemberRoleRequest sets the role of an organization member (owner, admin
+// or member) or of a team member (maintainer or member).
+type MemberRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+type CreateTeamRequest struct {
+	Name string `json:"name" validate:"required,min=2"`
+}
+
+type UpdateTeamRequest struct {
+	Name string `json:"name" validate:"required,min=2"`
+}
+
+// Response DTOs
+
+// OrganizationResponse carries the caller's Role, which is empty for
+// administrators who are not members.
+type OrganizationResponse struct {
+	ID        entities.ID `json:"id"`
+	Name      string      `json:"name"`
+	Role      string      `json:"role,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type OrganizationsListResponse struct {
+	Organizations []OrganizationResponse `json:"organizations"`
+	Total         int                    `json:"total"`
+}
+
+type TeamResponse struct {
+	ID             entities.ID `json:"id"`
+	OrganizationID entities.ID `json:"organization_id"`
+	Name           string      `json:"name"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+type TeamsListResponse struct {
+	Teams []TeamResponse `json:"teams"`
+	Total int            `json:"total"`
+}
+
+// MemberResponse is a user in an organization or team.
+type MemberResponse struct {
+	UserID    entities.UserID `json:"user_id"`
+	Name      string          `json:"name"`
+	Email     string          `json:"email"`
+	Role      string          `json:"role"`
+	Status    string          `json:"status"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type MembersListResponse struct {
+	Members []MemberResponse `json:"members"`
+	Total   int              `json:"total"`
+}
+
+// MembershipResponse is an organization or team a user belongs to, or has
+// been invited to.
+type MembershipResponse struct {
+	OrganizationID   entities.ID      `json:"organization_id"`
+	OrganizationName string           `json:"organization_name"`
+	TeamID           *entities.ID     `json:"team_id,omitempty"`
+	TeamName         string           `json:"team_name,omitempty"`
+	Role             string           `json:"role"`
+	Status           string           `json:"status"`
+	InvitedBy        *entities.UserID `json:"invited_by,omitempty"`
+	CreatedAt        time.Time        `json:"created_at"`
+}
+
+type MembershipsListResponse struct {
+	Memberships []MembershipResponse `json:"memberships"`
+	Total       int                  `json:"total"`
+}
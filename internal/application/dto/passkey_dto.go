@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wonyus/backend-challenge/pkg/webauthn"
+)
+
+// Request DTOs
+
+// RegisterPasskeyRequest completes a registration with the credential
+// returned by navigator.credentials.create.
+type RegisterPasskeyRequest struct {
+	Name       string                        `json:"name" validate:"required,min=2"`
+	Credential webauthn.RegistrationResponse `json:"credential"`
+}
+
+// PasskeyLoginRequest completes a login with the credential returned by
+// navigator.credentials.get.
+type PasskeyLoginRequest struct {
+	Credential webauthn.AssertionResponse `json:"credential"`
+}
+
+type RenamePasskeyRequest struct {
+	Name string `json:"name" validate:"required,min=2"`
+}
+
+// Response DTOs
+
+// PasskeyRegistrationOptions is passed to navigator.credentials.create.
+type PasskeyRegistrationOptions struct {
+	PublicKey *webauthn.CreationOptions `json:"publicKey"`
+}
+
+// PasskeyLoginOptions is passed to navigator.credentials.get.
+type PasskeyLoginOptions struct {
+	PublicKey *webauthn.RequestOptions `json:"publicKey"`
+}
+
+type PasskeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports,omitempty"`
+	Synced     bool       `json:"synced"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type PasskeysListResponse struct {
+	Passkeys []PasskeyResponse `json:"passkeys"`
+	Total    int               `json:"total"`
+}
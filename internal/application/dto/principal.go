@@ -0,0 +1,14 @@
+package dto
+
+// Principal is an authenticated caller: the user behind the credential and
+// the scopes that credential grants, which may be fewer than the user's own.
+// SessionID is set when the credential is a session's access token. Actor is
+// set when an administrator is impersonating User. TenantID is the tenant of
+// User, and empty for callers without a user account.
+type Principal struct {
+	User      *UserResponse
+	TenantID  string
+	Scopes    []string
+	SessionID string
+	Actor     *UserResponse
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// Response DTOs
+
+// SessionResponse is a signed in device. Current marks the session of the
+// token making the request.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	AuthMethod string    `json:"auth_method"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type SessionsListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+	Total    int               `json:"total"`
+}
@@ -1,9 +1,8 @@
 package dto
 
 import (
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
 	"time"
-
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Request DTOs
@@ -18,27 +17,38 @@ type UpdateUserRequest struct {
 	Email string `json:"email,omitempty" validate:"omitempty,email"`
 }
 
+// LoginRequest may ask for a down-scoped token with a space-delimited Scope.
+// Without it the token carries every scope the user is granted.
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	Scope    string `json:"scope,omitempty"`
 }
 
 // Response DTOs
+
+// UserResponse includes Memberships only when they are asked for.
 type UserResponse struct {
-	ID        primitive.ObjectID `json:"id"`
-	Name      string             `json:"name"`
-	Email     string             `json:"email"`
-	CreatedAt time.Time          `json:"created_at"`
+	ID          entities.UserID      `json:"id"`
+	Name        string               `json:"name"`
+	Email       string               `json:"email"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Memberships []MembershipResponse `json:"memberships,omitempty"`
 }
 
 type RegisterResponse struct {
-	ID      primitive.ObjectID `json:"id"`
-	Message string             `json:"message"`
+	ID      *entities.UserID `json:"id,omitempty"`
+	Message string           `json:"message"`
 }
 
+// LoginResponse carries either an access token and the user, or, when the
+// account has MFA enabled, an MFA challenge token to pass to the verify step.
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token       string        `json:"token,omitempty"`
+	Scope       string        `json:"scope,omitempty"`
+	User        *UserResponse `json:"user,omitempty"`
+	MFARequired bool          `json:"mfa_required,omitempty"`
+	MFAToken    string        `json:"mfa_token,omitempty"`
 }
 
 type UsersListResponse struct {
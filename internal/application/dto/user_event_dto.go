@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+// WatchUsersRequest selects the user events to stream. Types are "created",
+// "updated" and "deleted", all of them when empty. ResumeAfter is the ID of
+// the last event the watcher got; Consumer names a watcher whose position is
+// remembered, so it resumes where it left off when ResumeAfter is empty.
+type WatchUsersRequest struct {
+	Types       []string
+	ResumeAfter string
+	Consumer    string
+}
+
+// UserEvent reports a change to a user. User is missing from deletions.
+type UserEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserID     entities.UserID `json:"user_id"`
+	User       *UserResponse   `json:"user,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"time"
+)
+
+// Request DTOs
+
+// CreateUserInvitationRequest invites Email to create an account that is
+// granted Scopes, which must be held by the inviter. Without Scopes the
+// account gets the default user scopes.
+type CreateUserInvitationRequest struct {
+	Email  string   `json:"email" validate:"required,email"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// AcceptUserInvitationRequest creates the invited account with the token from
+// the invitation email.
+type AcceptUserInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"required,min=2"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// Response DTOs
+
+// UserInvitationResponse never carries the token, which is only mailed to the
+// invitee.
+type UserInvitationResponse struct {
+	ID         entities.ID      `json:"id"`
+	Email      string           `json:"email"`
+	Scopes     []string         `json:"scopes,omitempty"`
+	Status     string           `json:"status"`
+	InvitedBy  entities.UserID  `json:"invited_by"`
+	UserID     *entities.UserID `json:"user_id,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	ExpiresAt  time.Time        `json:"expires_at"`
+	AcceptedAt *time.Time       `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time       `json:"revoked_at,omitempty"`
+}
+
+type UserInvitationsListResponse struct {
+	Invitations []UserInvitationResponse `json:"invitations"`
+	Total       int                      `json:"total"`
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, caller *dto.Principal, userID entities.UserID, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, userID entities.UserID) (*dto.APIKeysListResponse, error)
+	RevokeAPIKey(ctx context.Context, userID entities.UserID, keyID entities.ID) error
+	ValidateAPIKey(ctx context.Context, key string) (*dto.Principal, error)
+}
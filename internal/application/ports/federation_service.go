@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type FederationService interface {
+	Providers() *dto.IdentityProvidersResponse
+	StartLogin(ctx context.Context, provider string) (*dto.FederatedLoginStart, error)
+	CompleteLogin(ctx context.Context, provider, state, code string) (*dto.LoginResponse, error)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type ImpersonationService interface {
+	// Impersonate lets the administrator actorID act as userID.
+	Impersonate(ctx context.Context, actorID, userID entities.UserID) (*dto.ImpersonationResponse, error)
+	// RecordRequest adds a request made under impersonation to the audit
+	// trail. It is called before the request is handled.
+	RecordRequest(ctx context.Context, actorID, userID entities.UserID, method, path string) error
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type MagicLinkService interface {
+	// RequestLink mails a sign in link to the email if it belongs to an
+	// active user. The response is the same either way.
+	RequestLink(ctx context.Context, req *dto.MagicLinkRequest) (*dto.MagicLinkResponse, error)
+	// Login exchanges the token of a mailed link for a login. The email must
+	// be the one the link was sent to.
+	Login(ctx context.Context, token, email string) (*dto.LoginResponse, error)
+}
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type MFAService interface {
+	Enroll(ctx context.Context, userID entities.UserID) (*dto.MFAEnrollResponse, error)
+	Confirm(ctx context.Context, userID entities.UserID, req *dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID entities.UserID, req *dto.MFACodeRequest) error
+	Verify(ctx context.Context, req *dto.MFAVerifyRequest) (*dto.LoginResponse, error)
+}
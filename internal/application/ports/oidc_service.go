@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type OIDCService interface {
+	RegisterClient(ctx context.Context, req *dto.CreateOAuthClientRequest) (*dto.OAuthClientResponse, error)
+	// ValidateAuthorizeRequest checks an authorization request before the
+	// user is asked to sign in.
+	ValidateAuthorizeRequest(ctx context.Context, req *dto.AuthorizeRequest) error
+	// Authorize issues an authorization code to the user holding accessToken.
+	Authorize(ctx context.Context, accessToken string, req *dto.AuthorizeRequest) (*dto.AuthorizeResponse, error)
+	Token(ctx context.Context, req *dto.TokenRequest) (*dto.TokenResponse, error)
+	UserInfo(ctx context.Context, principal *dto.Principal) (*dto.UserInfoResponse, error)
+	Discovery() *dto.OpenIDConfiguration
+	JWKS() *dto.JSONWebKeySet
+}
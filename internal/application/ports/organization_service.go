@@ -0,0 +1,49 @@
+package ports
+
+import (
+	"context"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+// OrganizationService manages organizations, their teams and members on
+// behalf of caller. Members can read an organization; its owners and admins,
+// and administrators, can change it. Organizations the caller cannot see are
+// reported as not found.
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, caller *dto.Principal, req *dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error)
+	// ListOrganizations lists the organizations the user is an active member of.
+	ListOrganizations(ctx context.Context, userID entities.UserID) (*dto.OrganizationsListResponse, error)
+	GetOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.OrganizationResponse, error)
+	UpdateOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.UpdateOrganizationRequest) (*dto.OrganizationResponse, error)
+	DeleteOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID) error
+
+	ListMembers(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.MembersListResponse, error)
+	// InviteMember invites a user, who becomes a member once they accept.
+	InviteMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.InviteMemberRequest) (*dto.MemberResponse, error)
+	UpdateMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, userID entities.UserID, req *dto.MemberRoleRequest) (*dto.MemberResponse, error)
+	// RemoveMember removes a member, or withdraws an invitation, along with
+	// the user's team memberships. Members can remove themselves.
+	RemoveMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, userID entities.UserID) error
+
+	ListInvitations(ctx context.Context, userID entities.UserID) (*dto.MembershipsListResponse, error)
+	AcceptInvitation(ctx context.Context, userID entities.UserID, organizationID entities.ID) (*dto.MembershipResponse, error)
+	DeclineInvitation(ctx context.Context, userID entities.UserID, organizationID entities.ID) error
+
+	CreateTeam(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.CreateTeamRequest) (*dto.TeamResponse, error)
+	ListTeams(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.TeamsListResponse, error)
+	GetTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) (*dto.TeamResponse, error)
+	UpdateTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, req *dto.UpdateTeamRequest) (*dto.TeamResponse, error)
+	DeleteTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) error
+
+	ListTeamMembers(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) (*dto.MembersListResponse, error)
+	// SetTeamMember adds an active organization member to the team, or
+	// changes their role in it. Team maintainers can manage their team.
+	SetTeamMember(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, userID entities.UserID, req *dto.MemberRoleRequest) (*dto.MemberResponse, error)
+	RemoveTeamMember(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, userID entities.UserID) error
+
+	// GetUserMemberships lists the organizations and teams of a user,
+	// including pending invitations.
+	GetUserMemberships(ctx context.Context, userID entities.UserID) ([]dto.MembershipResponse, error)
+}
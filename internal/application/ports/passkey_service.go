@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type PasskeyService interface {
+	BeginRegistration(ctx context.Context, userID entities.UserID) (*dto.PasskeyRegistrationOptions, error)
+	FinishRegistration(ctx context.Context, userID entities.UserID, req *dto.RegisterPasskeyRequest) (*dto.PasskeyResponse, error)
+	BeginLogin(ctx context.Context) (*dto.PasskeyLoginOptions, error)
+	FinishLogin(ctx context.Context, req *dto.PasskeyLoginRequest) (*dto.LoginResponse, error)
+	ListPasskeys(ctx context.Context, userID entities.UserID) (*dto.PasskeysListResponse, error)
+	RenamePasskey(ctx context.Context, userID entities.UserID, passkeyID string, req *dto.RenamePasskeyRequest) (*dto.PasskeyResponse, error)
+	DeletePasskey(ctx context.Context, userID entities.UserID, passkeyID string) error
+}
@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type SCIMService interface {
+	CreateUser(ctx context.Context, req *dto.SCIMUser) (*dto.SCIMUser, error)
+	GetUser(ctx context.Context, id string) (*dto.SCIMUser, error)
+	ListUsers(ctx context.Context, query *dto.SCIMListQuery) (*dto.SCIMListResponse, error)
+	ReplaceUser(ctx context.Context, id string, req *dto.SCIMUser) (*dto.SCIMUser, error)
+	PatchUser(ctx context.Context, id string, req *dto.SCIMPatchRequest) (*dto.SCIMUser, error)
+	DeleteUser(ctx context.Context, id string) error
+
+	ServiceProviderConfig() *dto.SCIMServiceProviderConfig
+	ResourceTypes() *dto.SCIMListResponse
+	ResourceType(id string) (*dto.SCIMResourceType, error)
+	Schemas() *dto.SCIMListResponse
+	Schema(id string) (*dto.SCIMSchema, error)
+}
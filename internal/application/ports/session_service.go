@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type SessionService interface {
+	// ListSessions returns the active sessions of a user, marking
+	// currentSessionID as the current one.
+	ListSessions(ctx context.Context, userID entities.UserID, currentSessionID string) (*dto.SessionsListResponse, error)
+	// RevokeSession signs a device out. Its token stops working immediately.
+	RevokeSession(ctx context.Context, userID entities.UserID, sessionID string) error
+}
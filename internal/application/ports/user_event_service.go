@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type UserEventService interface {
+	// WatchUsers sends the changes to the users of the tenant to send until
+	// ctx ends, when it returns nil, or send fails.
+	WatchUsers(ctx context.Context, req *dto.WatchUsersRequest, send func(*dto.UserEvent) error) error
+	// Close ends the watches under way, and those started later, as if their
+	// ctx ended. Servers call it as they shut down, since watches never
+	// finish on their own.
+	Close()
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+)
+
+type UserInvitationService interface {
+	// CreateInvitation mails an invitation link to the email on behalf of the
+	// inviter. The email must not belong to a user or have an invitation
+	// pending.
+	CreateInvitation(ctx context.Context, inviterID entities.UserID, req *dto.CreateUserInvitationRequest) (*dto.UserInvitationResponse, error)
+	ListInvitations(ctx context.Context) (*dto.UserInvitationsListResponse, error)
+	// RevokeInvitation stops a pending invitation from being accepted.
+	RevokeInvitation(ctx context.Context, id entities.ID) error
+	// AcceptInvitation creates the invited account, in the tenant of the
+	// invitation, with the given name and password.
+	AcceptInvitation(ctx context.Context, req *dto.AcceptUserInvitationRequest) (*dto.UserResponse, error)
+}
@@ -2,16 +2,19 @@ package ports
 
 import (
 	"context"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
 
 	"github.com/wonyus/backend-challenge/internal/application/dto"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UserService interface {
 	CreateUser(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error)
-	GetUserByID(ctx context.Context, id primitive.ObjectID) (*dto.UserResponse, error)
+	// ProvisionUser creates a user without a password, optionally linked to
+	// an account at an external identity provider.
+	ProvisionUser(ctx context.Context, req *dto.ProvisionUserRequest) (*dto.UserResponse, error)
+	GetUserByID(ctx context.Context, id entities.UserID) (*dto.UserResponse, error)
 	GetAllUsers(ctx context.Context) (*dto.UsersListResponse, error)
-	UpdateUser(ctx context.Context, id primitive.ObjectID, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
-	DeleteUser(ctx context.Context, id primitive.ObjectID) error
+	UpdateUser(ctx context.Context, id entities.UserID, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
+	DeleteUser(ctx context.Context, id entities.UserID) error
 	GetUserCount(ctx context.Context) (int64, error)
 }
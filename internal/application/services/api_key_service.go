@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+const (
+	// Keys look like bck_<prefix>_<secret>. The prefix is stored in the clear
+	// to find the key; only a hash of the whole key is kept.
+	apiKeyMarker       = "bck_"
+	apiKeyPrefixBytes  = 6
+	apiKeySecretBytes  = 32
+	apiKeyMaxGenerated = 3
+
+	// lastUsedInterval limits how often LastUsedAt is written, so that a busy
+	// client does not turn every request into a database write.
+	lastUsedInterval = time.Minute
+)
+
+type apiKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+	now        func() time.Time
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository) ports.APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		now:        time.Now,
+	}
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, caller *dto.Principal, userID entities.UserID, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys without scopes act with the scopes of the caller, all of the
+	// user's unless it signed in with a restricted credential
+	scopes, err := delegableScopes(user, caller, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return nil, domainErrors.ErrInvalidExpiry
+	}
+
+	// Prefixes are random, but retry a few times in the unlikely case of a
+	// collision with an existing key
+	for attempt := 0; ; attempt++ {
+		prefix, key, err := generateAPIKey()
+		if err != nil {
+			return nil, err
+		}
+
+		apiKey, err := entities.NewAPIKey(userID, req.Name, prefix, hashAPIKey(key), scopes, req.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.apiKeyRepo.Create(ctx, apiKey)
+		if errors.Is(err, domainErrors.ErrAPIKeyExists) && attempt < apiKeyMaxGenerated-1 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &dto.CreateAPIKeyResponse{
+			APIKeyResponse: *newAPIKeyResponse(apiKey),
+			Key:            key,
+		}, nil
+	}
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, userID entities.UserID) (*dto.APIKeysListResponse, error) {
+	keys, err := s.apiKeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = *newAPIKeyResponse(key)
+	}
+
+	return &dto.APIKeysListResponse{
+		APIKeys: responses,
+		Total:   len(responses),
+	}, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, userID entities.UserID, keyID entities.ID) error {
+	key, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+
+	// Keys of other users are reported as missing rather than forbidden
+	if key.UserID != userID {
+		return domainErrors.ErrAPIKeyNotFound
+	}
+
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	key.Revoke()
+	return s.apiKeyRepo.Update(ctx, key)
+}
+
+func (s *apiKeyService) ValidateAPIKey(ctx context.Context, key string) (*dto.Principal, error) {
+	prefix, ok := parseAPIKey(key)
+	if !ok {
+		return nil, domainErrors.ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domainErrors.ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.Hash), []byte(hashAPIKey(key))) != 1 {
+		return nil, domainErrors.ErrInvalidAPIKey
+	}
+
+	now := s.now()
+	if !apiKey.IsActive(now) {
+		return nil, domainErrors.ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetByID(ctx, apiKey.UserID)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
+	}
+	if err != nil || !user.IsActive() {
+		return nil, domainErrors.ErrInvalidAPIKey
+	}
+
+	// Recording usage is best effort and never fails the request
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedInterval {
+		apiKey.MarkUsed(now)
+		_ = s.apiKeyRepo.MarkUsed(ctx, apiKey.ID, now)
+	}
+
+	return newPrincipal(user, effectiveScopes(user, apiKey.Scopes)), nil
+}
+
+func newAPIKeyResponse(key *entities.APIKey) *dto.APIKeyResponse {
+	return &dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     apiKeyMarker + key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// generateAPIKey returns the lookup prefix and the full key to hand out.
+func generateAPIKey() (string, string, error) {
+	prefix := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	encodedPrefix := hex.EncodeToString(prefix)
+	return encodedPrefix, apiKeyMarker + encodedPrefix + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// parseAPIKey extracts the lookup prefix. The secret part may itself contain
+// underscores, so only the first separator after the prefix counts.
+func parseAPIKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, apiKeyMarker)
+	if !ok {
+		return "", false
+	}
+
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != hex.EncodedLen(apiKeyPrefixBytes) || secret == "" {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+// hashAPIKey uses a plain SHA-256: keys carry 256 bits of randomness, so a
+// slow password hash would only add latency to every authenticated request.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
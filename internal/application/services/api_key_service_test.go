@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_APIKey_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyRepo := mock_repositories.NewMockAPIKeyRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	service := NewAPIKeyService(mockAPIKeyRepo, mockUserRepo)
+	ctx := context.Background()
+	user := &entities.User{ID: entities.NewUserID(), Email: "test@example.com"}
+	caller := &dto.Principal{User: toUserResponse(user), Scopes: user.GrantedScopes()}
+
+	t.Run("Create success", func(t *testing.T) {
+		var stored *entities.APIKey
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockAPIKeyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key *entities.APIKey) error {
+			stored = key
+			return nil
+		}).Times(1)
+
+		response, err := service.CreateAPIKey(ctx, caller, user.ID, &dto.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"users:read", " users:read"}})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(response.Key, response.Prefix+"_"))
+		assert.Equal(t, []string{"users:read"}, response.Scopes)
+		assert.Equal(t, user.ID, stored.UserID)
+		assert.Equal(t, hashAPIKey(response.Key), stored.Hash)
+		assert.NotContains(t, stored.Hash, stored.Prefix)
+	})
+
+	t.Run("Retry on prefix collision", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		gomock.InOrder(
+			mockAPIKeyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domainErrors.ErrAPIKeyExists),
+			mockAPIKeyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil),
+		)
+
+		response, err := service.CreateAPIKey(ctx, caller, user.ID, &dto.CreateAPIKeyRequest{Name: "ci"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Key)
+		assert.Nil(t, response.Scopes)
+	})
+
+	t.Run("Expiry in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		response, err := service.CreateAPIKey(ctx, caller, user.ID, &dto.CreateAPIKeyRequest{Name: "ci", ExpiresAt: &expiresAt})
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidExpiry)
+		assert.Nil(t, response)
+	})
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		response, err := service.CreateAPIKey(ctx, caller, user.ID, &dto.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"users:everything"}})
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidScope)
+		assert.Nil(t, response)
+	})
+
+	t.Run("Scope not granted to the user", func(t *testing.T) {
+		readOnlyUser := &entities.User{ID: user.ID, Scopes: []string{entities.ScopeUsersRead}}
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(readOnlyUser, nil).Times(1)
+
+		response, err := service.CreateAPIKey(ctx, caller, user.ID, &dto.CreateAPIKeyRequest{Name: "ci", Scopes: []string{entities.ScopeUsersDelete}})
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidScope)
+		assert.Nil(t, response)
+	})
+
+	t.Run("Scope not held by the caller", func(t *testing.T) {
+		readOnlyCaller := &dto.Principal{User: caller.User, Scopes: []string{entities.ScopeUsersRead}}
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		response, err := service.CreateAPIKey(ctx, readOnlyCaller, user.ID, &dto.CreateAPIKeyRequest{Name: "ci", Scopes: []string{entities.ScopeUsersWrite}})
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidScope)
+		assert.Nil(t, response)
+	})
+
+	t.Run("Restricted caller without scopes", func(t *testing.T) {
+		readOnlyCaller := &dto.Principal{User: caller.User, Scopes: []string{entities.ScopeUsersRead}}
+		var stored *entities.APIKey
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockAPIKeyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key *entities.APIKey) error {
+			stored = key
+			return nil
+		}).Times(1)
+
+		// The key is as restricted as the caller, not unrestricted
+		response, err := service.CreateAPIKey(ctx, readOnlyCaller, user.ID, &dto.CreateAPIKeyRequest{Name: "ci"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{entities.ScopeUsersRead}, response.Scopes)
+		assert.Equal(t, []string{entities.ScopeUsersRead}, stored.Scopes)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(nil, domainErrors.ErrUserNotFound).Times(1)
+
+		response, err := service.CreateAPIKey(ctx, caller, user.ID, &dto.CreateAPIKeyRequest{Name: "ci"})
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+		assert.Nil(t, response)
+	})
+}
+
+func TestService_APIKey_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyRepo := mock_repositories.NewMockAPIKeyRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	service := NewAPIKeyService(mockAPIKeyRepo, mockUserRepo)
+	ctx := context.Background()
+	userID := entities.NewUserID()
+
+	t.Run("Revoke success", func(t *testing.T) {
+		key := &entities.APIKey{ID: entities.NewID(), UserID: userID}
+		mockAPIKeyRepo.EXPECT().GetByID(gomock.Any(), key.ID).Return(key, nil).Times(1)
+		mockAPIKeyRepo.EXPECT().Update(gomock.Any(), key).Return(nil).Times(1)
+
+		err := service.RevokeAPIKey(ctx, userID, key.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, key.RevokedAt)
+	})
+
+	t.Run("Key of another user", func(t *testing.T) {
+		key := &entities.APIKey{ID: entities.NewID(), UserID: entities.NewUserID()}
+		mockAPIKeyRepo.EXPECT().GetByID(gomock.Any(), key.ID).Return(key, nil).Times(1)
+
+		err := service.RevokeAPIKey(ctx, userID, key.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrAPIKeyNotFound)
+		assert.Nil(t, key.RevokedAt)
+	})
+}
+
+func TestService_APIKey_Validate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyRepo := mock_repositories.NewMockAPIKeyRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	service := NewAPIKeyService(mockAPIKeyRepo, mockUserRepo).(*apiKeyService)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	user := &entities.User{ID: entities.NewUserID(), Name: "Service Account", Email: "svc@example.com"}
+
+	newKey := func(t *testing.T) (*entities.APIKey, string) {
+		prefix, key, err := generateAPIKey()
+		assert.NoError(t, err)
+		return &entities.APIKey{ID: entities.NewID(), UserID: user.ID, Prefix: prefix, Hash: hashAPIKey(key)}, key
+	}
+
+	t.Run("Validate success records usage", func(t *testing.T) {
+		apiKey, key := newKey(t)
+		mockAPIKeyRepo.EXPECT().GetByPrefix(gomock.Any(), apiKey.Prefix).Return(apiKey, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockAPIKeyRepo.EXPECT().MarkUsed(gomock.Any(), apiKey.ID, now).Return(nil).Times(1)
+
+		response, err := service.ValidateAPIKey(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, response.User.ID)
+		assert.Equal(t, entities.DefaultUserScopes, response.Scopes)
+		assert.Equal(t, now, *apiKey.LastUsedAt)
+	})
+
+	t.Run("Key scopes limit the principal", func(t *testing.T) {
+		apiKey, key := newKey(t)
+		apiKey.Scopes = []string{entities.ScopeUsersRead, entities.ScopeAdmin}
+		mockAPIKeyRepo.EXPECT().GetByPrefix(gomock.Any(), apiKey.Prefix).Return(apiKey, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockAPIKeyRepo.EXPECT().MarkUsed(gomock.Any(), apiKey.ID, now).Return(nil).Times(1)
+
+		response, err := service.ValidateAPIKey(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{entities.ScopeUsersRead}, response.Scopes)
+	})
+
+	t.Run("Recent usage is not rewritten", func(t *testing.T) {
+		apiKey, key := newKey(t)
+		apiKey.MarkUsed(now.Add(-lastUsedInterval / 2))
+		mockAPIKeyRepo.EXPECT().GetByPrefix(gomock.Any(), apiKey.Prefix).Return(apiKey, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		_, err := service.ValidateAPIKey(ctx, key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		apiKey, key := newKey(t)
+		mockAPIKeyRepo.EXPECT().GetByPrefix(gomock.Any(), apiKey.Prefix).Return(apiKey, nil).Times(1)
+
+		response, err := service.ValidateAPIKey(ctx, key+"x")
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidAPIKey)
+		assert.Nil(t, response)
+	})
+
+	t.Run("Revoked key", func(t *testing.T) {
+		apiKey, key := newKey(t)
+		apiKey.Revoke()
+		mockAPIKeyRepo.EXPECT().GetByPrefix(gomock.Any(), apiKey.Prefix).Return(apiKey, nil).Times(1)
+
+		_, err := service.ValidateAPIKey(ctx, key)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidAPIKey)
+	})
+
+	t.Run("Expired key", func(t *testing.T) {
+		apiKey, key := newKey(t)
+		expiresAt := now.Add(-time.Second)
+		apiKey.ExpiresAt = &expiresAt
+		mockAPIKeyRepo.EXPECT().GetByPrefix(gomock.Any(), apiKey.Prefix).Return(apiKey, nil).Times(1)
+
+		_, err := service.ValidateAPIKey(ctx, key)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidAPIKey)
+	})
+
+	t.Run("Malformed key", func(t *testing.T) {
+		for _, key := range []string{"", "secret", "bck_short_secret", "bck_0123456789ab", "bck_0123456789ab_"} {
+			_, err := service.ValidateAPIKey(ctx, key)
+			assert.ErrorIs(t, err, domainErrors.ErrInvalidAPIKey, key)
+		}
+	})
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"strings"
+	"sync"
 
 	"github.com/wonyus/backend-challenge/internal/application/dto"
 	"github.com/wonyus/backend-challenge/internal/application/ports"
@@ -12,19 +14,58 @@ import (
 	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
 )
 
+const (
+	registrationSuccessMessage  = "User registered successfully"
+	registrationAcceptedMessage = "Registration received, please check your email to continue"
+
+	// dummyPassword is hashed once and compared against when a login email is
+	// unknown, so that the request costs the same as a wrong password.
+	dummyPassword = "timing-safe-dummy-password"
+)
+
 type authService struct {
-	userRepo    repositories.UserRepository
-	authService domainServices.AuthService
+	userRepo       repositories.UserRepository
+	authService    domainServices.AuthService
+	passwordHasher domainServices.PasswordHasher
+
+	// enumerationSafe hides whether an email is registered: Register always
+	// answers the same way and the existing owner is notified by mail.
+	enumerationSafe bool
+	mailer          ports.Mailer
+
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
-func NewAuthService(userRepo repositories.UserRepository, service domainServices.AuthService) ports.AuthService {
-	return &authService{
-		userRepo:    userRepo,
-		authService: service,
+type AuthServiceOption func(*authService)
+
+// WithEnumerationSafeRegistration makes Register respond identically for new
+// and already registered emails. The owner of an existing account is told
+// about the attempt through the mailer instead.
+func WithEnumerationSafeRegistration(mailer ports.Mailer) AuthServiceOption {
+	return func(s *authService) {
+		s.enumerationSafe = true
+		s.mailer = mailer
+	}
+}
+
+func NewAuthService(userRepo repositories.UserRepository, service domainServices.AuthService, passwordHasher domainServices.PasswordHasher, opts ...AuthServiceOption) ports.AuthService {
+	s := &authService{
+		userRepo:       userRepo,
+		authService:    service,
+		passwordHasher: passwordHasher,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
+
 func (s *authService) Register(ctx context.Context, req *dto.CreateUserRequest) (*dto.RegisterResponse, error) {
-	fmt.Println(req)
+	if s.enumerationSafe {
+		return s.registerEnumerationSafe(ctx, req)
+	}
+
 	// Check if user already exists
 	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
@@ -32,7 +73,7 @@ func (s *authService) Register(ctx context.Context, req *dto.CreateUserRequest)
 	}
 
 	// Hash password
-	hashedPassword, err := s.authService.HashPassword(req.Password)
+	hashedPassword, err := s.passwordHasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -49,50 +90,170 @@ func (s *authService) Register(ctx context.Context, req *dto.CreateUserRequest)
 	}
 
 	return &dto.RegisterResponse{
-		ID:      user.ID,
-		Message: "User registered successfully",
+		ID:      &user.ID,
+		Message: registrationSuccessMessage,
+	}, nil
+}
+
+// registerEnumerationSafe does the same amount of work whether or not the
+// email is taken: the password is always hashed and exactly one email is sent.
+func (s *authService) registerEnumerationSafe(ctx context.Context, req *dto.CreateUserRequest) (*dto.RegisterResponse, error) {
+	hashedPassword, err := s.passwordHasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := entities.NewUser(req.Name, req.Email, hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
+	if existingUser == nil {
+		err = s.userRepo.Create(ctx, user)
+	}
+
+	switch {
+	case existingUser != nil, errors.Is(err, domainErrors.ErrUserAlreadyExists):
+		err = s.mailer.Send(ctx, req.Email,
+			"Someone tried to register with your email",
+			"Somebody tried to create an account using this email address, which is already registered. "+
+				"If this was you, you can sign in with your existing password. Otherwise you can ignore this message.")
+	case err == nil:
+		err = s.mailer.Send(ctx, req.Email,
+			"Welcome",
+			"Your account has been created. You can now sign in with your email and password.")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RegisterResponse{
+		Message: registrationAcceptedMessage,
 	}, nil
 }
 
 func (s *authService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
+	}
 	if err != nil {
+		// Burn the same hashing work as a real comparison so unknown emails
+		// cannot be told apart from wrong passwords by response time
+		_ = s.passwordHasher.Compare(s.getDummyHash(), req.Password)
+		return nil, domainErrors.ErrInvalidCredentials
+	}
+
+	// Federated accounts have no password to compare against
+	if !user.HasPassword() {
+		_ = s.passwordHasher.Compare(s.getDummyHash(), req.Password)
 		return nil, domainErrors.ErrInvalidCredentials
 	}
 
 	// Compare password
-	if err := s.authService.ComparePassword(user.Password, req.Password); err != nil {
+	if err := s.passwordHasher.Compare(user.Password, req.Password); err != nil {
 		return nil, domainErrors.ErrInvalidCredentials
 	}
 
-	// Generate token
-	token, err := s.authService.GenerateToken(ctx, user)
+	// Upgrade hashes made with an old algorithm or work factor while the
+	// plaintext is at hand. This is best effort and never fails the login.
+	if s.passwordHasher.NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+
+	// A down-scoped token may only ask for scopes the user already holds
+	scopes, err := grantableScopes(user.GrantedScopes(), strings.Fields(req.Scope))
 	if err != nil {
 		return nil, err
 	}
+	if scopes == nil {
+		scopes = user.GrantedScopes()
+	}
 
-	return &dto.LoginResponse{
-		Token: token,
-		User: dto.UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-		},
-	}, nil
+	return issueLogin(ctx, s.authService, user, scopes, entities.AuthMethodPassword)
 }
 
-func (s *authService) ValidateToken(ctx context.Context, token string) (*dto.UserResponse, error) {
-	user, err := s.authService.ValidateToken(ctx, token)
+func (s *authService) ValidateToken(ctx context.Context, token string) (*dto.Principal, error) {
+	verified, err := s.authService.ValidateToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
 
+	principal := newPrincipal(verified.User, effectiveScopes(verified.User, verified.Scopes))
+	principal.SessionID = verified.SessionID
+	if verified.Actor != nil {
+		principal.Actor = toUserResponse(verified.Actor)
+	}
+	return principal, nil
+}
+
+func newPrincipal(user *entities.User, scopes []string) *dto.Principal {
+	return &dto.Principal{
+		User:     toUserResponse(user),
+		TenantID: user.TenantID,
+		Scopes:   scopes,
+	}
+}
+
+func toUserResponse(user *entities.User) *dto.UserResponse {
 	return &dto.UserResponse{
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt,
-	}, nil
+	}
+}
+
+// issueLogin finishes a successful first-factor login with authMethod.
+// Accounts with MFA only get a challenge token to complete the login.
+func issueLogin(ctx context.Context, authService domainServices.AuthService, user *entities.User, scopes []string, authMethod string) (*dto.LoginResponse, error) {
+	if user.MFA.Enabled {
+		mfaToken, err := authService.GenerateMFAToken(ctx, user, scopes, authMethod)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	token, err := authService.GenerateToken(ctx, user, scopes, authMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLoginResponse(token, user, scopes), nil
+}
+
+func newLoginResponse(token string, user *entities.User, scopes []string) *dto.LoginResponse {
+	return &dto.LoginResponse{
+		Token: token,
+		Scope: strings.Join(scopes, " "),
+		User:  toUserResponse(user),
+	}
+}
+
+func (s *authService) rehashPassword(ctx context.Context, user *entities.User, password string) {
+	hashedPassword, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return
+	}
+
+	previous := user.Password
+	user.UpdatePassword(hashedPassword)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		user.Password = previous
+	}
+}
+
+// getDummyHash lazily hashes dummyPassword with the current algorithm, so the
+// comparison cost matches the configured work factor.
+func (s *authService) getDummyHash() string {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.passwordHasher.Hash(dummyPassword)
+	})
+	return s.dummyHash
 }
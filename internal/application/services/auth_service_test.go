@@ -4,16 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/wonyus/backend-challenge/internal/application/dto"
 	"github.com/wonyus/backend-challenge/internal/domain/entities"
 	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
 	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
 	"go.uber.org/mock/gomock"
 )
 
@@ -23,11 +25,12 @@ func TestService_Auth_Register(t *testing.T) {
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
 	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	AuthService := NewAuthService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	AuthService := NewAuthService(mockUserRepo, jwtService, passwordHasher)
 
 	var (
 		ctx = context.Background()
-		id  = primitive.NewObjectID()
+		id  = entities.NewUserID()
 
 		now = time.Now()
 	)
@@ -78,7 +81,7 @@ func TestService_Auth_Register(t *testing.T) {
 		mockRequest.Name = ""
 		response, err := AuthService.Register(ctx, mockRequest)
 		assert.Nil(t, response)
-		assert.Equal(t, "name, email, and password are required", err.Error())
+		assert.Equal(t, "name and email are required", err.Error())
 	})
 
 	t.Run("Register user password hashing error", func(t *testing.T) {
@@ -91,17 +94,93 @@ func TestService_Auth_Register(t *testing.T) {
 
 }
 
+func TestService_Auth_Register_EnumerationSafe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	mockMailer := mock_ports.NewMockMailer(ctrl)
+	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	AuthService := NewAuthService(mockUserRepo, jwtService, passwordHasher, WithEnumerationSafeRegistration(mockMailer))
+
+	var (
+		ctx = context.Background()
+		now = time.Now()
+	)
+
+	mockRequest := &dto.CreateUserRequest{
+		Name:     "Test User",
+		Email:    "test@example.com",
+		Password: "password",
+	}
+
+	mockUserEntity := &entities.User{
+		ID:        entities.NewUserID(),
+		Name:      "Test User",
+		Email:     "test@example.com",
+		Password:  "hashed_password",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	t.Run("New email creates user and sends welcome email", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockUserRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockMailer.EXPECT().Send(gomock.Any(), mockRequest.Email, "Welcome", gomock.Any()).Return(nil).Times(1)
+		response, err := AuthService.Register(ctx, mockRequest)
+		assert.NoError(t, err)
+		assert.Nil(t, response.ID)
+		assert.Equal(t, registrationAcceptedMessage, response.Message)
+	})
+
+	t.Run("Existing email notifies owner with the same response", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(mockUserEntity, nil).Times(1)
+		mockMailer.EXPECT().Send(gomock.Any(), mockRequest.Email, "Someone tried to register with your email", gomock.Any()).Return(nil).Times(1)
+		response, err := AuthService.Register(ctx, mockRequest)
+		assert.NoError(t, err)
+		assert.Nil(t, response.ID)
+		assert.Equal(t, registrationAcceptedMessage, response.Message)
+	})
+
+	t.Run("Concurrent duplicate insert notifies owner", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockUserRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domainErrors.ErrUserAlreadyExists).Times(1)
+		mockMailer.EXPECT().Send(gomock.Any(), mockRequest.Email, "Someone tried to register with your email", gomock.Any()).Return(nil).Times(1)
+		response, err := AuthService.Register(ctx, mockRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, registrationAcceptedMessage, response.Message)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockUserRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("failed to create user")).Times(1)
+		response, err := AuthService.Register(ctx, mockRequest)
+		assert.Nil(t, response)
+		assert.Equal(t, "failed to create user", err.Error())
+	})
+
+	t.Run("Mailer error", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(mockUserEntity, nil).Times(1)
+		mockMailer.EXPECT().Send(gomock.Any(), mockRequest.Email, gomock.Any(), gomock.Any()).Return(errors.New("smtp unavailable")).Times(1)
+		response, err := AuthService.Register(ctx, mockRequest)
+		assert.Nil(t, response)
+		assert.Equal(t, "smtp unavailable", err.Error())
+	})
+}
+
 func TestService_Auth_Login(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
 	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	AuthService := NewAuthService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(6))
+	AuthService := NewAuthService(mockUserRepo, jwtService, passwordHasher)
 
 	var (
 		ctx          = context.Background()
-		id           = primitive.NewObjectID()
+		id           = entities.NewUserID()
 		password     = "password"
 		passwordHash = "$2a$06$R.ga34oljt5UqXmSgNR6ze4QpEbq8u9i0Fui/eG2WpZs/nCgjbT1e"
 		now          = time.Now()
@@ -129,6 +208,41 @@ func TestService_Auth_Login(t *testing.T) {
 		fmt.Println(response)
 	})
 
+	t.Run("Login With MFA Returns Challenge", func(t *testing.T) {
+		mfaUser := *mockUserEntity
+		mfaUser.MFA = entities.MFA{Enabled: true, Secret: "encrypted"}
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(&mfaUser, nil).Times(1)
+		response, err := AuthService.Login(ctx, mockRequest)
+		assert.NoError(t, err)
+		assert.True(t, response.MFARequired)
+		assert.NotEmpty(t, response.MFAToken)
+		assert.Empty(t, response.Token)
+		assert.Nil(t, response.User)
+
+		// The challenge token must not work as an access token
+		_, err = AuthService.ValidateToken(ctx, response.MFAToken)
+		assert.Equal(t, domainErrors.ErrInvalidToken, err)
+	})
+
+	t.Run("Login With Down-Scoped Token", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(mockUserEntity, nil).Times(1)
+		response, err := AuthService.Login(ctx, &dto.LoginRequest{Email: mockRequest.Email, Password: password, Scope: "users:read"})
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ScopeUsersRead, response.Scope)
+
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), mockUserEntity.ID).Return(mockUserEntity, nil).Times(1)
+		principal, err := AuthService.ValidateToken(ctx, response.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{entities.ScopeUsersRead}, principal.Scopes)
+	})
+
+	t.Run("Login With Scope Beyond Grants", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(mockUserEntity, nil).Times(1)
+		response, err := AuthService.Login(ctx, &dto.LoginRequest{Email: mockRequest.Email, Password: password, Scope: "users:read admin:*"})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidScope)
+	})
+
 	t.Run("Login User Not Found", func(t *testing.T) {
 		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(nil, errors.New("user not found")).Times(1)
 		response, err := AuthService.Login(ctx, mockRequest)
@@ -139,7 +253,7 @@ func TestService_Auth_Login(t *testing.T) {
 	t.Run("Login Token Generation Error", func(t *testing.T) {
 		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(mockUserEntity, nil).Times(1)
 		jwtService = auth.NewJWTService("", mockUserRepo) // Empty secret to trigger error
-		AuthService = NewAuthService(mockUserRepo, jwtService)
+		AuthService = NewAuthService(mockUserRepo, jwtService, passwordHasher)
 		response, err := AuthService.Login(ctx, mockRequest)
 		assert.Nil(t, response)
 		assert.Equal(t, domainErrors.ErrInvalidTokenSecret.Error(), err.Error())
@@ -155,17 +269,84 @@ func TestService_Auth_Login(t *testing.T) {
 
 }
 
+func TestService_Auth_Login_Rehash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
+	passwordHasher := auth.NewPasswordHasher(
+		auth.NewArgon2idHasher(auth.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}),
+		auth.NewBcryptHasher(6),
+	)
+	AuthService := NewAuthService(mockUserRepo, jwtService, passwordHasher)
+
+	var (
+		ctx          = context.Background()
+		password     = "password"
+		passwordHash = "$2a$06$R.ga34oljt5UqXmSgNR6ze4QpEbq8u9i0Fui/eG2WpZs/nCgjbT1e"
+	)
+
+	mockRequest := &dto.LoginRequest{
+		Email:    "test@example.com",
+		Password: password,
+	}
+
+	newUser := func() *entities.User {
+		return &entities.User{
+			ID:       entities.NewUserID(),
+			Name:     "Test User",
+			Email:    mockRequest.Email,
+			Password: passwordHash,
+		}
+	}
+
+	t.Run("Login upgrades legacy bcrypt hash", func(t *testing.T) {
+		user := newUser()
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).DoAndReturn(func(ctx context.Context, updated *entities.User) error {
+			assert.True(t, strings.HasPrefix(updated.Password, "$argon2id$"))
+			assert.NoError(t, passwordHasher.Compare(updated.Password, password))
+			return nil
+		}).Times(1)
+		response, err := AuthService.Login(ctx, mockRequest)
+		assert.NoError(t, err)
+		assert.NotNil(t, response)
+		assert.False(t, passwordHasher.NeedsRehash(user.Password))
+	})
+
+	t.Run("Login succeeds when saving the upgraded hash fails", func(t *testing.T) {
+		user := newUser()
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(errors.New("failed to update user")).Times(1)
+		response, err := AuthService.Login(ctx, mockRequest)
+		assert.NoError(t, err)
+		assert.NotNil(t, response)
+		assert.Equal(t, passwordHash, user.Password)
+	})
+
+	t.Run("Login does not rehash current hash", func(t *testing.T) {
+		user := newUser()
+		user.Password, _ = passwordHasher.Hash(password)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(user, nil).Times(1)
+		response, err := AuthService.Login(ctx, mockRequest)
+		assert.NoError(t, err)
+		assert.NotNil(t, response)
+	})
+}
+
 func TestService_Auth_ValidateToken(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
 	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	AuthService := NewAuthService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	AuthService := NewAuthService(mockUserRepo, jwtService, passwordHasher)
 
 	var (
 		ctx = context.Background()
-		id  = primitive.NewObjectID()
+		id  = entities.NewUserID()
 		// password     = "password"
 		passwordHash = "$2a$06$R.ga34oljt5UqXmSgNR6ze4QpEbq8u9i0Fui/eG2WpZs/nCgjbT1e"
 		now          = time.Now()
@@ -181,13 +362,51 @@ func TestService_Auth_ValidateToken(t *testing.T) {
 
 	t.Run("Validate Token", func(t *testing.T) {
 		mockUserRepo.EXPECT().GetByID(gomock.Any(), mockUserEntity.ID).Return(mockUserEntity, nil).Times(1)
-		token, err := jwtService.GenerateToken(ctx, mockUserEntity)
+		token, err := jwtService.GenerateToken(ctx, mockUserEntity, entities.DefaultUserScopes, entities.AuthMethodPassword)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
 
 		response, err := AuthService.ValidateToken(ctx, token)
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
+		assert.Equal(t, entities.DefaultUserScopes, response.Scopes)
+	})
+
+	t.Run("Validate Token Without Scope Claim", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), mockUserEntity.ID).Return(mockUserEntity, nil).Times(1)
+		// Tokens issued before scopes were recorded carry every scope
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": mockUserEntity.ID.String(),
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("cfg.JWTSecret"))
+		assert.NoError(t, err)
+
+		response, err := AuthService.ValidateToken(ctx, token)
+		assert.NoError(t, err)
+		assert.Equal(t, mockUserEntity.GrantedScopes(), response.Scopes)
+	})
+
+	t.Run("Validate Token Without Scopes", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), mockUserEntity.ID).Return(mockUserEntity, nil).Times(1)
+		token, err := jwtService.GenerateToken(ctx, mockUserEntity, []string{}, entities.AuthMethodPassword)
+		assert.NoError(t, err)
+
+		response, err := AuthService.ValidateToken(ctx, token)
+		assert.NoError(t, err)
+		assert.Empty(t, response.Scopes)
+	})
+
+	t.Run("Validate Token Drops Scopes No Longer Granted", func(t *testing.T) {
+		admin := *mockUserEntity
+		admin.Scopes = []string{entities.ScopeAdmin}
+		token, err := jwtService.GenerateToken(ctx, &admin, []string{entities.ScopeAdmin}, entities.AuthMethodPassword)
+		assert.NoError(t, err)
+
+		// The admin scope was taken away after the token was issued
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), mockUserEntity.ID).Return(mockUserEntity, nil).Times(1)
+		response, err := AuthService.ValidateToken(ctx, token)
+		assert.NoError(t, err)
+		assert.Empty(t, response.Scopes)
 	})
 
 	t.Run("Validate Token Invalid Token", func(t *testing.T) {
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/memory"
+)
+
+// The time of a login or registration is mostly hashing work, so these tests
+// check that every path does the same of it, which holds on any machine. The
+// timing tests then measure the paths, to catch differences outside the
+// hasher; being at the mercy of the machine's load, they are skipped with
+// -short.
+
+// timingSamples is the number of measurements taken per path. Samples of the
+// paths are interleaved so that drift in machine load affects them alike.
+const timingSamples = 15
+
+// maxMedianRatio is how much slower one path's median may be than another's
+// before the difference is considered observable.
+const maxMedianRatio = 1.5
+
+func median(samples []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+func assertIndistinguishable(t *testing.T, a, b []time.Duration) {
+	t.Helper()
+
+	medianA, medianB := median(a), median(b)
+	slow, fast := max(medianA, medianB), min(medianA, medianB)
+	ratio := float64(slow) / float64(fast)
+	t.Logf("median %v vs %v (ratio %.2f)", medianA, medianB, ratio)
+	assert.Less(t, ratio, maxMedianRatio, "response times differ enough to reveal which path was taken")
+}
+
+func timed(fn func()) time.Duration {
+	start := time.Now()
+	fn()
+	return time.Since(start)
+}
+
+// recordingHasher records the hashing work asked of it.
+type recordingHasher struct {
+	domainServices.PasswordHasher
+	hashes   int
+	compared []string
+}
+
+func (h *recordingHasher) Hash(password string) (string, error) {
+	h.hashes++
+	return h.PasswordHasher.Hash(password)
+}
+
+func (h *recordingHasher) Compare(hashedPassword, password string) error {
+	h.compared = append(h.compared, hashedPassword)
+	return h.PasswordHasher.Compare(hashedPassword, password)
+}
+
+func (h *recordingHasher) reset() {
+	h.hashes = 0
+	h.compared = nil
+}
+
+// countingMailer counts the emails it is asked to send.
+type countingMailer struct {
+	sent int
+}
+
+func (m *countingMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.sent++
+	return nil
+}
+
+func TestService_Auth_Login_SameWorkForEveryFailure(t *testing.T) {
+	ctx := domainServices.WithTenant(context.Background(), entities.DefaultTenant)
+	userRepo := memory.NewUserRepository(entities.EmailPolicy{})
+	hasher := &recordingHasher{PasswordHasher: auth.NewPasswordHasher(auth.NewBcryptHasher(4))}
+	service := NewAuthService(userRepo, auth.NewJWTService("test-secret", userRepo), hasher)
+
+	_, err := service.Register(ctx, &dto.CreateUserRequest{Name: "Known User", Email: "known@example.com", Password: "correct-password"})
+	assert.NoError(t, err)
+	federated, err := entities.NewPasswordlessUser("Federated User", "federated@example.com")
+	assert.NoError(t, err)
+	assert.NoError(t, userRepo.Create(ctx, federated))
+
+	for _, email := range []string{"known@example.com", "unknown@example.com", "federated@example.com"} {
+		t.Run(email, func(t *testing.T) {
+			hasher.reset()
+			_, err := service.Login(ctx, &dto.LoginRequest{Email: email, Password: "wrong-password"})
+			assert.Error(t, err)
+
+			// Exactly one comparison, against a hash of the current algorithm
+			// and work factor, so it costs what comparing a real one does
+			if assert.Len(t, hasher.compared, 1) {
+				assert.NotEmpty(t, hasher.compared[0])
+				assert.False(t, hasher.NeedsRehash(hasher.compared[0]))
+			}
+		})
+	}
+}
+
+func TestService_Auth_Register_EnumerationSafe_SameWork(t *testing.T) {
+	ctx := domainServices.WithTenant(context.Background(), entities.DefaultTenant)
+	userRepo := memory.NewUserRepository(entities.EmailPolicy{})
+	hasher := &recordingHasher{PasswordHasher: auth.NewPasswordHasher(auth.NewBcryptHasher(4))}
+	mailer := &countingMailer{}
+	service := NewAuthService(userRepo, auth.NewJWTService("test-secret", userRepo), hasher, WithEnumerationSafeRegistration(mailer))
+
+	existing := &dto.CreateUserRequest{Name: "Known User", Email: "known@example.com", Password: "password"}
+	_, err := service.Register(ctx, existing)
+	assert.NoError(t, err)
+
+	for name, req := range map[string]*dto.CreateUserRequest{
+		"Taken email": existing,
+		"Fresh email": {Name: "New User", Email: "new@example.com", Password: "password"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hasher.reset()
+			mailer.sent = 0
+
+			response, err := service.Register(ctx, req)
+			assert.NoError(t, err)
+			assert.Equal(t, registrationAcceptedMessage, response.Message)
+			assert.Equal(t, 1, hasher.hashes)
+			assert.Empty(t, hasher.compared)
+			assert.Equal(t, 1, mailer.sent)
+		})
+	}
+}
+
+func TestService_Auth_Login_Timing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping timing test in short mode")
+	}
+
+	ctx := domainServices.WithTenant(context.Background(), entities.DefaultTenant)
+	userRepo := memory.NewUserRepository(entities.EmailPolicy{})
+	service := NewAuthService(userRepo, auth.NewJWTService("test-secret", userRepo), auth.NewPasswordHasher(auth.NewBcryptHasher(0)))
+
+	_, err := service.Register(ctx, &dto.CreateUserRequest{Name: "Known User", Email: "known@example.com", Password: "correct-password"})
+	assert.NoError(t, err)
+	federated, err := entities.NewPasswordlessUser("Federated User", "federated@example.com")
+	assert.NoError(t, err)
+	assert.NoError(t, userRepo.Create(ctx, federated))
+
+	// Warm up the dummy hash so its one-off cost is not measured
+	_, _ = service.Login(ctx, &dto.LoginRequest{Email: "warmup@example.com", Password: "x"})
+
+	login := func(email string) time.Duration {
+		return timed(func() {
+			_, err := service.Login(ctx, &dto.LoginRequest{Email: email, Password: "wrong-password"})
+			assert.Error(t, err)
+		})
+	}
+	var known, unknown, passwordless []time.Duration
+	for i := 0; i < timingSamples; i++ {
+		known = append(known, login("known@example.com"))
+		unknown = append(unknown, login(fmt.Sprintf("unknown%d@example.com", i)))
+		passwordless = append(passwordless, login("federated@example.com"))
+	}
+
+	t.Run("Unknown email", func(t *testing.T) {
+		assertIndistinguishable(t, known, unknown)
+	})
+	t.Run("Federated user", func(t *testing.T) {
+		assertIndistinguishable(t, known, passwordless)
+	})
+}
+
+func TestService_Auth_Register_EnumerationSafe_Timing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping timing test in short mode")
+	}
+
+	ctx := domainServices.WithTenant(context.Background(), entities.DefaultTenant)
+	userRepo := memory.NewUserRepository(entities.EmailPolicy{})
+	service := NewAuthService(userRepo, auth.NewJWTService("test-secret", userRepo), auth.NewPasswordHasher(auth.NewBcryptHasher(0)),
+		WithEnumerationSafeRegistration(&countingMailer{}))
+
+	existing := &dto.CreateUserRequest{Name: "Known User", Email: "known@example.com", Password: "password"}
+	_, err := service.Register(ctx, existing)
+	assert.NoError(t, err)
+
+	register := func(req *dto.CreateUserRequest) time.Duration {
+		return timed(func() {
+			response, err := service.Register(ctx, req)
+			assert.NoError(t, err)
+			assert.Equal(t, registrationAcceptedMessage, response.Message)
+		})
+	}
+	var taken, fresh []time.Duration
+	for i := 0; i < timingSamples; i++ {
+		taken = append(taken, register(existing))
+		fresh = append(fresh, register(&dto.CreateUserRequest{Name: "New User", Email: fmt.Sprintf("new%d@example.com", i), Password: "password"}))
+	}
+
+	assertIndistinguishable(t, taken, fresh)
+}
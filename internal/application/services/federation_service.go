@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+// federatedLoginTTL is how long a user has to sign in at the identity provider.
+const federatedLoginTTL = 10 * time.Minute
+
+type federationService struct {
+	loginRepo   repositories.FederatedLoginRepository
+	userRepo    repositories.UserRepository
+	userService ports.UserService
+	authService domainServices.AuthService
+	providers   map[string]domainServices.IdentityProvider
+	now         func() time.Time
+}
+
+func NewFederationService(loginRepo repositories.FederatedLoginRepository, userRepo repositories.UserRepository, userService ports.UserService, authService domainServices.AuthService, providers ...domainServices.IdentityProvider) ports.FederationService {
+	s := &federationService{
+		loginRepo:   loginRepo,
+		userRepo:    userRepo,
+		userService: userService,
+		authService: authService,
+		providers:   make(map[string]domainServices.IdentityProvider, len(providers)),
+		now:         time.Now,
+	}
+	for _, provider := range providers {
+		s.providers[provider.Name()] = provider
+	}
+	return s
+}
+
+func (s *federationService) Providers() *dto.IdentityProvidersResponse {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &dto.IdentityProvidersResponse{Providers: names}
+}
+
+func (s *federationService) StartLogin(ctx context.Context, providerName string) (*dto.FederatedLoginStart, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, domainErrors.ErrIdentityProviderNotFound
+	}
+
+	state, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	login := &entities.FederatedLogin{
+		StateHash:    hashOAuthSecret(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    s.now().Add(federatedLoginTTL),
+	}
+	if err := s.loginRepo.Create(ctx, login); err != nil {
+		return nil, err
+	}
+
+	return &dto.FederatedLoginStart{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+	}, nil
+}
+
+func (s *federationService) CompleteLogin(ctx context.Context, providerName, state, code string) (*dto.LoginResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, domainErrors.ErrIdentityProviderNotFound
+	}
+
+	// The state is consumed before anything else so it cannot be replayed
+	login, err := s.loginRepo.Consume(ctx, hashOAuthSecret(state))
+	if err != nil {
+		return nil, err
+	}
+	if login.Provider != providerName || login.IsExpired(s.now()) {
+		return nil, domainErrors.ErrInvalidFederatedState
+	}
+
+	claims, err := provider.Exchange(ctx, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(ctx, providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return issueLogin(ctx, s.authService, user, user.GrantedScopes(), entities.AuthMethodFederated)
+}
+
+// resolveUser finds the user linked to the external account. An unlinked
+// account is linked to the user with the same email, or a new user is
+// provisioned, but only when the provider has verified the email.
+func (s *federationService) resolveUser(ctx context.Context, providerName string, claims *domainServices.ExternalClaims) (*entities.User, error) {
+	user, err := s.userRepo.GetByIdentity(ctx, providerName, claims.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, domainErrors.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, domainErrors.ErrEmailNotVerified
+	}
+
+	user, err = s.userRepo.GetByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		user.LinkIdentity(entities.ExternalIdentity{
+			Provider: providerName,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		})
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	case !errors.Is(err, domainErrors.ErrUserNotFound):
+		return nil, err
+	}
+
+	name := claims.Name
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	created, err := s.userService.ProvisionUser(ctx, &dto.ProvisionUserRequest{
+		Name:     name,
+		Email:    claims.Email,
+		Provider: providerName,
+		Subject:  claims.Subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, created.ID)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
+	mock_services "github.com/wonyus/backend-challenge/mock/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Federation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLoginRepo := mock_repositories.NewMockFederatedLoginRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	mockUserService := mock_ports.NewMockUserService(ctrl)
+	mockProvider := mock_services.NewMockIdentityProvider(ctrl)
+	mockProvider.EXPECT().Name().Return("corp").AnyTimes()
+	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
+	service := NewFederationService(mockLoginRepo, mockUserRepo, mockUserService, jwtService, mockProvider)
+	ctx := context.Background()
+
+	pending := func() *entities.FederatedLogin {
+		return &entities.FederatedLogin{
+			StateHash:    hashOAuthSecret("state"),
+			Provider:     "corp",
+			Nonce:        "nonce",
+			CodeVerifier: "verifier",
+			ExpiresAt:    time.Now().Add(time.Minute),
+		}
+	}
+	claims := &domainServices.ExternalClaims{Subject: "corp-1", Email: "test@example.com", EmailVerified: true, Name: "Test User"}
+
+	t.Run("StartLogin stores state, nonce and verifier", func(t *testing.T) {
+		var stored *entities.FederatedLogin
+		mockProvider.EXPECT().AuthCodeURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+				return "https://idp.example.com/authorize?" + url.Values{"state": {state}, "nonce": {nonce}, "code_challenge": {codeChallenge}}.Encode(), nil
+			}).Times(1)
+		mockLoginRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, login *entities.FederatedLogin) error {
+			stored = login
+			return nil
+		}).Times(1)
+
+		start, err := service.StartLogin(ctx, "corp")
+		assert.NoError(t, err)
+		authorizationURL, _ := url.Parse(start.AuthorizationURL)
+		assert.Equal(t, start.State, authorizationURL.Query().Get("state"))
+		assert.Equal(t, hashOAuthSecret(start.State), stored.StateHash)
+		assert.Equal(t, stored.Nonce, authorizationURL.Query().Get("nonce"))
+		assert.Equal(t, testCodeChallenge(stored.CodeVerifier), authorizationURL.Query().Get("code_challenge"))
+		assert.Equal(t, "corp", stored.Provider)
+	})
+
+	t.Run("StartLogin with unknown provider", func(t *testing.T) {
+		start, err := service.StartLogin(ctx, "other")
+		assert.Nil(t, start)
+		assert.ErrorIs(t, err, domainErrors.ErrIdentityProviderNotFound)
+	})
+
+	t.Run("Linked identity signs in", func(t *testing.T) {
+		user := &entities.User{ID: entities.NewUserID(), Name: "Test User", Email: "old@example.com"}
+		mockLoginRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("state")).Return(pending(), nil).Times(1)
+		mockProvider.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(claims, nil).Times(1)
+		mockUserRepo.EXPECT().GetByIdentity(gomock.Any(), "corp", "corp-1").Return(user, nil).Times(1)
+
+		response, err := service.CompleteLogin(ctx, "corp", "state", "code")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.Equal(t, user.ID, response.User.ID)
+	})
+
+	t.Run("Links existing user by verified email", func(t *testing.T) {
+		user := &entities.User{ID: entities.NewUserID(), Name: "Test User", Email: "test@example.com", Password: "hash"}
+		mockLoginRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).Return(pending(), nil).Times(1)
+		mockProvider.EXPECT().Exchange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(claims, nil).Times(1)
+		mockUserRepo.EXPECT().GetByIdentity(gomock.Any(), "corp", "corp-1").Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+
+		response, err := service.CompleteLogin(ctx, "corp", "state", "code")
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, response.User.ID)
+		assert.True(t, user.HasIdentity("corp", "corp-1"))
+	})
+
+	t.Run("Provisions a new user through the user service", func(t *testing.T) {
+		user := &entities.User{ID: entities.NewUserID(), Name: "Test User", Email: "test@example.com"}
+		mockLoginRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).Return(pending(), nil).Times(1)
+		mockProvider.EXPECT().Exchange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(claims, nil).Times(1)
+		mockUserRepo.EXPECT().GetByIdentity(gomock.Any(), "corp", "corp-1").Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockUserService.EXPECT().ProvisionUser(gomock.Any(), &dto.ProvisionUserRequest{
+			Name:     "Test User",
+			Email:    "test@example.com",
+			Provider: "corp",
+			Subject:  "corp-1",
+		}).Return(&dto.UserResponse{ID: user.ID}, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		response, err := service.CompleteLogin(ctx, "corp", "state", "code")
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, response.User.ID)
+	})
+
+	t.Run("Unverified email is neither linked nor provisioned", func(t *testing.T) {
+		unverified := *claims
+		unverified.EmailVerified = false
+		mockLoginRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).Return(pending(), nil).Times(1)
+		mockProvider.EXPECT().Exchange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&unverified, nil).Times(1)
+		mockUserRepo.EXPECT().GetByIdentity(gomock.Any(), "corp", "corp-1").Return(nil, domainErrors.ErrUserNotFound).Times(1)
+
+		response, err := service.CompleteLogin(ctx, "corp", "state", "code")
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrEmailNotVerified)
+	})
+
+	t.Run("MFA users get a challenge", func(t *testing.T) {
+		user := &entities.User{ID: entities.NewUserID(), Email: "test@example.com", MFA: entities.MFA{Enabled: true}}
+		mockLoginRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).Return(pending(), nil).Times(1)
+		mockProvider.EXPECT().Exchange(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(claims, nil).Times(1)
+		mockUserRepo.EXPECT().GetByIdentity(gomock.Any(), "corp", "corp-1").Return(user, nil).Times(1)
+
+		response, err := service.CompleteLogin(ctx, "corp", "state", "code")
+		assert.NoError(t, err)
+		assert.True(t, response.MFARequired)
+		assert.Empty(t, response.Token)
+	})
+
+	t.Run("Unknown state", func(t *testing.T) {
+		mockLoginRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).Return(nil, domainErrors.ErrInvalidFederatedState).Times(1)
+
+		response, err := service.CompleteLogin(ctx, "corp", "state", "code")
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidFederatedState)
+	})
+
+	t.Run("State of another provider", func(t *testing.T) {
+		login := pending()
+		login.Provider = "other"
+		mockLoginRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).Return(login, nil).Times(1)
+
+		response, err := service.CompleteLogin(ctx, "corp", "state", "code")
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidFederatedState)
+	})
+
+	t.Run("Expired state", func(t *testing.T) {
+		login := pending()
+		login.ExpiresAt = time.Now().Add(-time.Second)
+		mockLoginRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).Return(login, nil).Times(1)
+
+		response, err := service.CompleteLogin(ctx, "corp", "state", "code")
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidFederatedState)
+	})
+
+	t.Run("Providers", func(t *testing.T) {
+		assert.Equal(t, []string{"corp"}, service.Providers().Providers)
+	})
+}
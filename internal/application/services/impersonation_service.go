@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+type impersonationService struct {
+	userRepo    repositories.UserRepository
+	auditRepo   repositories.AuditRepository
+	authService domainServices.AuthService
+	now         func() time.Time
+}
+
+func NewImpersonationService(userRepo repositories.UserRepository, auditRepo repositories.AuditRepository, authService domainServices.AuthService) ports.ImpersonationService {
+	return &impersonationService{
+		userRepo:    userRepo,
+		auditRepo:   auditRepo,
+		authService: authService,
+		now:         time.Now,
+	}
+}
+
+func (s *impersonationService) Impersonate(ctx context.Context, actorID, userID entities.UserID) (*dto.ImpersonationResponse, error) {
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domainErrors.ErrForbidden
+	}
+	if !entities.HasScope(actor.GrantedScopes(), entities.ScopeAdmin) {
+		return nil, domainErrors.ErrForbidden
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Acting as another administrator would hand out admin rights under a
+	// borrowed name
+	if user.ID == actor.ID || entities.HasScope(user.GrantedScopes(), entities.ScopeAdmin) {
+		return nil, domainErrors.ErrCannotImpersonate
+	}
+
+	scopes := user.GrantedScopes()
+	token, err := s.authService.GenerateImpersonationToken(ctx, actor, user, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token is not handed out unless the audit trail has it
+	event := s.newEvent(ctx, entities.AuditImpersonationStarted, actor.ID, user.ID)
+	if err := s.auditRepo.Create(ctx, event); err != nil {
+		return nil, err
+	}
+
+	return &dto.ImpersonationResponse{
+		Token:     token,
+		Scope:     strings.Join(scopes, " "),
+		ExpiresAt: event.CreatedAt.Add(domainServices.ImpersonationTokenTTL),
+		User:      toUserResponse(user),
+		Actor:     toUserResponse(actor),
+	}, nil
+}
+
+func (s *impersonationService) RecordRequest(ctx context.Context, actorID, userID entities.UserID, method, path string) error {
+	event := s.newEvent(ctx, entities.AuditImpersonatedRequest, actorID, userID)
+	event.Method = method
+	event.Path = path
+	return s.auditRepo.Create(ctx, event)
+}
+
+func (s *impersonationService) newEvent(ctx context.Context, action string, actorID, userID entities.UserID) *entities.AuditEvent {
+	client := domainServices.ClientFromContext(ctx)
+	event := entities.NewAuditEvent(action, actorID, userID)
+	event.IPAddress = client.IPAddress
+	event.UserAgent = client.UserAgent
+	event.CreatedAt = s.now()
+	return event
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Impersonation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	mockAuditRepo := mock_repositories.NewMockAuditRepository(ctrl)
+	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
+	service := NewImpersonationService(mockUserRepo, mockAuditRepo, jwtService)
+	ctx := domainServices.WithClient(context.Background(), domainServices.Client{IPAddress: "10.0.0.1", UserAgent: "Support console"})
+
+	admin, _ := entities.NewUser("Admin", "admin@example.com", "hashed")
+	admin.Scopes = []string{entities.ScopeAdmin}
+	user, _ := entities.NewUser("Test User", "test@example.com", "hashed")
+
+	t.Run("Issues a token that carries the actor", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), admin.ID).Return(admin, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockAuditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *entities.AuditEvent) error {
+			assert.Equal(t, entities.AuditImpersonationStarted, event.Action)
+			assert.Equal(t, admin.ID, event.ActorID)
+			assert.Equal(t, user.ID, event.SubjectID)
+			assert.Equal(t, "10.0.0.1", event.IPAddress)
+			assert.Equal(t, "Support console", event.UserAgent)
+			return nil
+		}).Times(1)
+
+		response, err := service.Impersonate(ctx, admin.ID, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, response.User.ID)
+		assert.Equal(t, admin.ID, response.Actor.ID)
+		assert.NotContains(t, response.Scope, entities.ScopeAdmin)
+
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), admin.ID).Return(admin, nil).Times(1)
+		verified, err := jwtService.ValidateToken(ctx, response.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, verified.User.ID)
+		assert.Equal(t, admin.ID, verified.Actor.ID)
+		assert.Equal(t, entities.AuthMethodImpersonation, verified.AuthMethod)
+		assert.Equal(t, entities.DefaultUserScopes, verified.Scopes)
+
+		// The token stops working once the actor is no longer an admin
+		demoted := *admin
+		demoted.Scopes = nil
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), admin.ID).Return(&demoted, nil).Times(1)
+		_, err = jwtService.ValidateToken(ctx, response.Token)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidToken)
+	})
+
+	t.Run("Only admins may impersonate", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		_, err := service.Impersonate(ctx, user.ID, admin.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrForbidden)
+	})
+
+	t.Run("Refuses to impersonate admins", func(t *testing.T) {
+		other, _ := entities.NewUser("Other Admin", "other@example.com", "hashed")
+		other.Scopes = []string{entities.ScopeAdmin}
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), admin.ID).Return(admin, nil).Times(3)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), other.ID).Return(other, nil).Times(1)
+
+		_, err := service.Impersonate(ctx, admin.ID, other.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrCannotImpersonate)
+
+		_, err = service.Impersonate(ctx, admin.ID, admin.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrCannotImpersonate)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		unknownID := entities.NewUserID()
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), admin.ID).Return(admin, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), unknownID).Return(nil, domainErrors.ErrUserNotFound).Times(1)
+
+		_, err := service.Impersonate(ctx, admin.ID, unknownID)
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+	})
+
+	t.Run("No token without an audit record", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), admin.ID).Return(admin, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockAuditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(assert.AnError).Times(1)
+
+		response, err := service.Impersonate(ctx, admin.ID, user.ID)
+		assert.Error(t, err)
+		assert.Nil(t, response)
+	})
+
+	t.Run("Records requests", func(t *testing.T) {
+		mockAuditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event *entities.AuditEvent) error {
+			assert.Equal(t, entities.AuditImpersonatedRequest, event.Action)
+			assert.Equal(t, "GET", event.Method)
+			assert.Equal(t, "/api/users", event.Path)
+			assert.Equal(t, "10.0.0.1", event.IPAddress)
+			return nil
+		}).Times(1)
+
+		err := service.RecordRequest(ctx, admin.ID, user.ID, "GET", "/api/users")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+const (
+	// magicLinkTTL is how long a mailed sign in link can be used.
+	magicLinkTTL = 15 * time.Minute
+	// At most magicLinkRateLimit links are mailed to an email per
+	// magicLinkTTL, which also bounds how many links are valid at once.
+	magicLinkRateLimit = 3
+
+	magicLinkSentMessage = "If the email is registered, a sign in link has been sent"
+)
+
+type magicLinkService struct {
+	linkRepo    repositories.MagicLinkRepository
+	userRepo    repositories.UserRepository
+	authService domainServices.AuthService
+	mailer      ports.Mailer
+	callbackURL string
+	now         func() time.Time
+}
+
+// NewMagicLinkService mails links to callbackURL, which must route to the
+// magic link callback.
+func NewMagicLinkService(linkRepo repositories.MagicLinkRepository, userRepo repositories.UserRepository, authService domainServices.AuthService, mailer ports.Mailer, callbackURL string) ports.MagicLinkService {
+	return &magicLinkService{
+		linkRepo:    linkRepo,
+		userRepo:    userRepo,
+		authService: authService,
+		mailer:      mailer,
+		callbackURL: callbackURL,
+		now:         time.Now,
+	}
+}
+
+func (s *magicLinkService) RequestLink(ctx context.Context, req *dto.MagicLinkRequest) (*dto.MagicLinkResponse, error) {
+	now := s.now()
+	// Links are counted and bound under one case of the email, so changing
+	// its case neither escapes the rate limit nor breaks the link
+	email := strings.ToLower(entities.NormalizeEmail(req.Email))
+
+	// Requests count whether or not the email is registered, so being rate
+	// limited does not reveal it either
+	count, err := s.linkRepo.CountSince(ctx, email, now.Add(-magicLinkTTL))
+	if err != nil {
+		return nil, err
+	}
+	if count >= magicLinkRateLimit {
+		return nil, domainErrors.ErrTooManyMagicLinks
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, domainErrors.ErrUserNotFound) {
+		return nil, err
+	}
+
+	token, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	// The callback may resolve to another tenant, so the link remembers its own
+	tenantID, _ := domainServices.TenantFromContext(ctx)
+	link := &entities.MagicLink{
+		TokenHash: hashOAuthSecret(token),
+		TenantID:  tenantID,
+		Email:     email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(magicLinkTTL),
+	}
+	active := user != nil && user.IsActive()
+	if active {
+		link.UserID = user.ID
+	}
+	if err := s.linkRepo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	if active {
+		err = s.mailer.Send(ctx, req.Email,
+			"Your sign in link",
+			"Use this link to sign in. It expires in 15 minutes and can be used once:\n\n"+s.linkURL(token, email)+
+				"\n\nIf you did not ask to sign in, you can ignore this message.")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.MagicLinkResponse{
+		Message: magicLinkSentMessage,
+	}, nil
+}
+
+func (s *magicLinkService) Login(ctx context.Context, token, email string) (*dto.LoginResponse, error) {
+	// The link is consumed before anything else so it cannot be replayed
+	link, err := s.linkRepo.Consume(ctx, hashOAuthSecret(token))
+	if err != nil {
+		return nil, err
+	}
+	if link.UserID.IsZero() || link.IsExpired(s.now()) ||
+		subtle.ConstantTimeCompare([]byte(link.Email), []byte(strings.ToLower(entities.NormalizeEmail(email)))) != 1 {
+		return nil, domainErrors.ErrInvalidMagicLink
+	}
+
+	// The link signs in to the tenant it was requested in, whichever one the
+	// callback names
+	ctx = domainServices.WithTenant(ctx, link.TenantID)
+
+	// The link only signs in the account that still owns the email
+	user, err := s.userRepo.GetByID(ctx, link.UserID)
+	if errors.Is(err, domainErrors.ErrUserNotFound) {
+		return nil, domainErrors.ErrInvalidMagicLink
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, link.Email) || !user.IsActive() {
+		return nil, domainErrors.ErrInvalidMagicLink
+	}
+
+	return issueLogin(ctx, s.authService, user, user.GrantedScopes(), entities.AuthMethodMagicLink)
+}
+
+func (s *magicLinkService) linkURL(token, email string) string {
+	query := url.Values{}
+	query.Set("token", token)
+	query.Set("email", email)
+	return s.callbackURL + "?" + query.Encode()
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_MagicLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLinkRepo := mock_repositories.NewMockMagicLinkRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	mockMailer := mock_ports.NewMockMailer(ctrl)
+	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
+	service := NewMagicLinkService(mockLinkRepo, mockUserRepo, jwtService, mockMailer, "https://app.example.com/magic")
+	ctx := context.Background()
+
+	user, _ := entities.NewUser("Test User", "test@example.com", "")
+	request := &dto.MagicLinkRequest{Email: "test@example.com"}
+	link := func(email string, userID entities.UserID) *entities.MagicLink {
+		return &entities.MagicLink{
+			TokenHash: hashOAuthSecret("token"),
+			Email:     email,
+			UserID:    userID,
+			CreatedAt: time.Now(),
+			ExpiresAt: time.Now().Add(time.Minute),
+		}
+	}
+
+	t.Run("RequestLink mails a link bound to the email", func(t *testing.T) {
+		var stored *entities.MagicLink
+		var body string
+		mockLinkRepo.EXPECT().CountSince(gomock.Any(), "test@example.com", gomock.Any()).Return(int64(0), nil).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil).Times(1)
+		mockLinkRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, link *entities.MagicLink) error {
+			stored = link
+			return nil
+		}).Times(1)
+		mockMailer.EXPECT().Send(gomock.Any(), "test@example.com", gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, to, subject, text string) error {
+			body = text
+			return nil
+		}).Times(1)
+
+		response, err := service.RequestLink(ctx, request)
+		assert.NoError(t, err)
+		assert.Equal(t, magicLinkSentMessage, response.Message)
+
+		linkURL, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(body))
+		assert.NoError(t, err)
+		assert.Equal(t, "/magic", linkURL.Path)
+		assert.Equal(t, "test@example.com", linkURL.Query().Get("email"))
+		assert.Equal(t, hashOAuthSecret(linkURL.Query().Get("token")), stored.TokenHash)
+		assert.Equal(t, user.ID, stored.UserID)
+		assert.WithinDuration(t, time.Now().Add(magicLinkTTL), stored.ExpiresAt, time.Second)
+	})
+
+	t.Run("RequestLink for an unknown email sends nothing", func(t *testing.T) {
+		var stored *entities.MagicLink
+		mockLinkRepo.EXPECT().CountSince(gomock.Any(), "unknown@example.com", gomock.Any()).Return(int64(0), nil).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "unknown@example.com").Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockLinkRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, link *entities.MagicLink) error {
+			stored = link
+			return nil
+		}).Times(1)
+
+		response, err := service.RequestLink(ctx, &dto.MagicLinkRequest{Email: "unknown@example.com"})
+		assert.NoError(t, err)
+		assert.Equal(t, magicLinkSentMessage, response.Message)
+		assert.True(t, stored.UserID.IsZero())
+	})
+
+	t.Run("RequestLink is rate limited", func(t *testing.T) {
+		mockLinkRepo.EXPECT().CountSince(gomock.Any(), "test@example.com", gomock.Any()).Return(int64(magicLinkRateLimit), nil).Times(1)
+
+		response, err := service.RequestLink(ctx, request)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrTooManyMagicLinks)
+	})
+
+	t.Run("Login signs in a passwordless user", func(t *testing.T) {
+		mockLinkRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("token")).Return(link("test@example.com", user.ID), nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		response, err := service.Login(ctx, "token", "test@example.com")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.Equal(t, user.ID, response.User.ID)
+	})
+
+	t.Run("Login signs in to the tenant the link was requested in", func(t *testing.T) {
+		var stored *entities.MagicLink
+		mockLinkRepo.EXPECT().CountSince(gomock.Any(), "test@example.com", gomock.Any()).Return(int64(0), nil).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "test@example.com").Return(user, nil).Times(1)
+		mockLinkRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, link *entities.MagicLink) error {
+			stored = link
+			return nil
+		}).Times(1)
+		mockMailer.EXPECT().Send(gomock.Any(), "test@example.com", gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		_, err := service.RequestLink(domainServices.WithTenant(ctx, "acme"), request)
+		assert.NoError(t, err)
+		assert.Equal(t, "acme", stored.TenantID)
+
+		// The callback resolves to the default tenant
+		mockLinkRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("token")).Return(stored, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).DoAndReturn(func(ctx context.Context, id entities.UserID) (*entities.User, error) {
+			tenantID, _ := domainServices.TenantFromContext(ctx)
+			assert.Equal(t, "acme", tenantID)
+			return user, nil
+		}).Times(1)
+
+		response, err := service.Login(domainServices.WithTenant(ctx, entities.DefaultTenant), "token", "test@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, response.User.ID)
+	})
+
+	t.Run("Login requires an MFA challenge", func(t *testing.T) {
+		mfaUser, _ := entities.NewUser("Test User", "test@example.com", "")
+		mfaUser.MFA.Enabled = true
+		mockLinkRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("token")).Return(link("test@example.com", mfaUser.ID), nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), mfaUser.ID).Return(mfaUser, nil).Times(1)
+
+		response, err := service.Login(ctx, "token", "test@example.com")
+		assert.NoError(t, err)
+		assert.True(t, response.MFARequired)
+		assert.Empty(t, response.Token)
+	})
+
+	t.Run("Login rejects invalid links", func(t *testing.T) {
+		expired := link("test@example.com", user.ID)
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+		changed, _ := entities.NewUser("Test User", "changed@example.com", "")
+		changed.ID = user.ID
+
+		tests := []struct {
+			name  string
+			link  *entities.MagicLink
+			email string
+			user  *entities.User
+		}{
+			{"expired", expired, "test@example.com", nil},
+			{"other email", link("test@example.com", user.ID), "other@example.com", nil},
+			{"unknown email", link("unknown@example.com", ""), "unknown@example.com", nil},
+			{"email changed since", link("test@example.com", user.ID), "test@example.com", changed},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockLinkRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("token")).Return(tt.link, nil).Times(1)
+				if tt.user != nil {
+					mockUserRepo.EXPECT().GetByID(gomock.Any(), tt.user.ID).Return(tt.user, nil).Times(1)
+				}
+
+				response, err := service.Login(ctx, "token", tt.email)
+				assert.Nil(t, response)
+				assert.ErrorIs(t, err, domainErrors.ErrInvalidMagicLink)
+			})
+		}
+	})
+
+	t.Run("Login with a used link", func(t *testing.T) {
+		mockLinkRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("token")).Return(nil, domainErrors.ErrInvalidMagicLink).Times(1)
+
+		response, err := service.Login(ctx, "token", "test@example.com")
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidMagicLink)
+	})
+}
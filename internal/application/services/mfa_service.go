@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/pkg/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+
+	// totpSkew accepts codes from one step before and after the current one
+	// to tolerate clock drift between server and device.
+	totpSkew = 1
+
+	maxMFAAttempts = 5
+	mfaLockout     = 15 * time.Minute
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type mfaService struct {
+	userRepo    repositories.UserRepository
+	authService domainServices.AuthService
+	encrypter   domainServices.SecretEncrypter
+	issuer      string
+	now         func() time.Time
+}
+
+func NewMFAService(userRepo repositories.UserRepository, authService domainServices.AuthService, encrypter domainServices.SecretEncrypter, issuer string) ports.MFAService {
+	return &mfaService{
+		userRepo:    userRepo,
+		authService: authService,
+		encrypter:   encrypter,
+		issuer:      issuer,
+		now:         time.Now,
+	}
+}
+
+func (s *mfaService) Enroll(ctx context.Context, userID entities.UserID) (*dto.MFAEnrollResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFA.Enabled {
+		return nil, domainErrors.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSecret, err := s.encrypter.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Enrollment stays pending until Confirm proves the device has the secret
+	user.EnrollMFA(encryptedSecret)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *mfaService) Confirm(ctx context.Context, userID entities.UserID, req *dto.MFACodeRequest) (*dto.MFARecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFA.Enabled {
+		return nil, domainErrors.ErrMFAAlreadyEnabled
+	}
+
+	if user.MFA.Secret == "" {
+		return nil, domainErrors.ErrMFANotEnrolled
+	}
+
+	step, ok := s.validateCode(user, req.Code)
+	if !ok {
+		return nil, domainErrors.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.EnableMFA(step, hashes)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFARecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID entities.UserID, req *dto.MFACodeRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFA.Enabled {
+		return domainErrors.ErrMFANotEnabled
+	}
+
+	// Either a current code or an unused recovery code proves possession
+	if _, ok := s.validateCode(user, req.Code); !ok && !user.UseRecoveryCode(hashRecoveryCode(req.Code)) {
+		return domainErrors.ErrInvalidMFACode
+	}
+
+	user.DisableMFA()
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *mfaService) Verify(ctx context.Context, req *dto.MFAVerifyRequest) (*dto.LoginResponse, error) {
+	verified, err := s.authService.ValidateMFAToken(ctx, req.MFAToken)
+	if err != nil {
+		return nil, domainErrors.ErrInvalidToken
+	}
+	user := verified.User
+
+	if !user.MFA.Enabled {
+		return nil, domainErrors.ErrMFANotEnabled
+	}
+
+	if s.now().Before(user.MFA.LockedUntil) {
+		return nil, domainErrors.ErrMFATooManyAttempts
+	}
+
+	var ok bool
+	switch {
+	case req.RecoveryCode != "":
+		ok = user.UseRecoveryCode(hashRecoveryCode(req.RecoveryCode))
+	case req.Code != "":
+		var step int64
+		if step, ok = s.validateCode(user, req.Code); ok {
+			user.MFA.LastUsedStep = step
+		}
+	}
+
+	if !ok {
+		user.MFA.FailedAttempts++
+		if user.MFA.FailedAttempts >= maxMFAAttempts {
+			user.MFA.FailedAttempts = 0
+			user.MFA.LockedUntil = s.now().Add(mfaLockout)
+		}
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+		return nil, domainErrors.ErrInvalidMFACode
+	}
+
+	// Persist the consumed step or recovery code before issuing a token so
+	// that neither can be replayed
+	user.MFA.FailedAttempts = 0
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Keep the scopes chosen at login, minus any the user lost since
+	scopes := effectiveScopes(user, verified.Scopes)
+	authMethod := verified.AuthMethod
+	if authMethod == "" {
+		authMethod = entities.AuthMethodPassword
+	}
+	token, err := s.authService.GenerateToken(ctx, user, scopes, authMethod+entities.AuthMethodMFASuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLoginResponse(token, user, scopes), nil
+}
+
+// validateCode checks a TOTP code against the user's secret and rejects codes
+// from a step that was already used.
+func (s *mfaService) validateCode(user *entities.User, code string) (int64, bool) {
+	secret, err := s.encrypter.Decrypt(user.MFA.Secret)
+	if err != nil {
+		return 0, false
+	}
+
+	step, ok := totp.Validate(secret, code, s.now(), totpSkew)
+	if !ok || step <= user.MFA.LastUsedStep {
+		return 0, false
+	}
+	return step, true
+}
+
+// generateRecoveryCodes returns the plaintext codes to show the user once and
+// the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// Recovery codes carry enough entropy that a fast hash is sufficient.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/crypto"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	"github.com/wonyus/backend-challenge/pkg/totp"
+	"go.uber.org/mock/gomock"
+)
+
+const testEncryptionKey = "Q100ExqbW2d+rjyzaB8gBlZhyqExXaZCfkzFiHQD07w="
+
+func newTestMFAService(t *testing.T, userRepo *mock_repositories.MockUserRepository, now time.Time) *mfaService {
+	encrypter, err := crypto.NewAESEncrypter(testEncryptionKey)
+	assert.NoError(t, err)
+
+	service := NewMFAService(userRepo, auth.NewJWTService("cfg.JWTSecret", userRepo), encrypter, "Backend Challenge").(*mfaService)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+// enrolledUser returns a user with MFA enabled and the plaintext TOTP secret.
+func enrolledUser(t *testing.T, service *mfaService) (*entities.User, string) {
+	secret, err := totp.GenerateSecret()
+	assert.NoError(t, err)
+	encryptedSecret, err := service.encrypter.Encrypt(secret)
+	assert.NoError(t, err)
+
+	user := &entities.User{
+		ID:    entities.NewUserID(),
+		Name:  "Test User",
+		Email: "test@example.com",
+	}
+	user.EnrollMFA(encryptedSecret)
+	user.EnableMFA(0, []string{hashRecoveryCode("abcd-efgh")})
+	return user, secret
+}
+
+func TestService_MFA_Enroll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	service := newTestMFAService(t, mockUserRepo, time.Now())
+	ctx := context.Background()
+
+	t.Run("Enroll success", func(t *testing.T) {
+		user := &entities.User{ID: entities.NewUserID(), Email: "test@example.com"}
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+
+		response, err := service.Enroll(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Contains(t, response.OTPAuthURI, "otpauth://totp/Backend%20Challenge:test@example.com?")
+		assert.False(t, user.MFA.Enabled)
+		assert.NotEmpty(t, user.MFA.Secret)
+		assert.NotContains(t, user.MFA.Secret, response.Secret, "secret must be stored encrypted")
+
+		decrypted, err := service.encrypter.Decrypt(user.MFA.Secret)
+		assert.NoError(t, err)
+		assert.Equal(t, response.Secret, decrypted)
+	})
+
+	t.Run("Enroll already enabled", func(t *testing.T) {
+		user, _ := enrolledUser(t, service)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		response, err := service.Enroll(ctx, user.ID)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrMFAAlreadyEnabled)
+	})
+
+	t.Run("Enroll user not found", func(t *testing.T) {
+		id := entities.NewUserID()
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), id).Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		response, err := service.Enroll(ctx, id)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+	})
+}
+
+func TestService_MFA_Confirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	now := time.Now()
+	service := newTestMFAService(t, mockUserRepo, now)
+	ctx := context.Background()
+
+	pendingUser := func() (*entities.User, string) {
+		user, secret := enrolledUser(t, service)
+		user.MFA.Enabled = false
+		user.MFA.RecoveryCodes = nil
+		return user, secret
+	}
+
+	t.Run("Confirm success", func(t *testing.T) {
+		user, secret := pendingUser()
+		code, _ := totp.GenerateCode(secret, totp.Step(now))
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+
+		response, err := service.Confirm(ctx, user.ID, &dto.MFACodeRequest{Code: code})
+		assert.NoError(t, err)
+		assert.Len(t, response.RecoveryCodes, recoveryCodeCount)
+		assert.True(t, user.MFA.Enabled)
+		assert.Equal(t, totp.Step(now), user.MFA.LastUsedStep)
+		assert.NotContains(t, user.MFA.RecoveryCodes, response.RecoveryCodes[0], "recovery codes must be stored hashed")
+	})
+
+	t.Run("Confirm invalid code", func(t *testing.T) {
+		user, _ := pendingUser()
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		response, err := service.Confirm(ctx, user.ID, &dto.MFACodeRequest{Code: "000000"})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidMFACode)
+		assert.False(t, user.MFA.Enabled)
+	})
+
+	t.Run("Confirm without enrollment", func(t *testing.T) {
+		user := &entities.User{ID: entities.NewUserID()}
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		response, err := service.Confirm(ctx, user.ID, &dto.MFACodeRequest{Code: "123456"})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrMFANotEnrolled)
+	})
+}
+
+func TestService_MFA_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	now := time.Now()
+	service := newTestMFAService(t, mockUserRepo, now)
+	ctx := context.Background()
+
+	challenge := func(user *entities.User) string {
+		token, err := service.authService.GenerateMFAToken(ctx, user, []string{entities.ScopeUsersRead}, entities.AuthMethodPassword)
+		assert.NoError(t, err)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		return token
+	}
+
+	t.Run("Verify with TOTP code", func(t *testing.T) {
+		user, secret := enrolledUser(t, service)
+		code, _ := totp.GenerateCode(secret, totp.Step(now))
+		mfaToken := challenge(user)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+
+		response, err := service.Verify(ctx, &dto.MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.Equal(t, user.Email, response.User.Email)
+		assert.Equal(t, entities.ScopeUsersRead, response.Scope)
+
+		t.Run("Replay of the same code is rejected", func(t *testing.T) {
+			mfaToken := challenge(user)
+			mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+			response, err := service.Verify(ctx, &dto.MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+			assert.Nil(t, response)
+			assert.ErrorIs(t, err, domainErrors.ErrInvalidMFACode)
+		})
+	})
+
+	t.Run("Verify with recovery code only once", func(t *testing.T) {
+		user, _ := enrolledUser(t, service)
+		mfaToken := challenge(user)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+
+		response, err := service.Verify(ctx, &dto.MFAVerifyRequest{MFAToken: mfaToken, RecoveryCode: "ABCD-EFGH"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		assert.Empty(t, user.MFA.RecoveryCodes)
+
+		mfaToken = challenge(user)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+		response, err = service.Verify(ctx, &dto.MFAVerifyRequest{MFAToken: mfaToken, RecoveryCode: "abcd-efgh"})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidMFACode)
+	})
+
+	t.Run("Verify locks out after repeated failures", func(t *testing.T) {
+		user, secret := enrolledUser(t, service)
+		for i := 0; i < maxMFAAttempts; i++ {
+			mfaToken := challenge(user)
+			mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+			_, err := service.Verify(ctx, &dto.MFAVerifyRequest{MFAToken: mfaToken, Code: "000000"})
+			assert.ErrorIs(t, err, domainErrors.ErrInvalidMFACode)
+		}
+
+		code, _ := totp.GenerateCode(secret, totp.Step(now))
+		mfaToken := challenge(user)
+		response, err := service.Verify(ctx, &dto.MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrMFATooManyAttempts)
+	})
+
+	t.Run("Verify rejects access tokens", func(t *testing.T) {
+		user, _ := enrolledUser(t, service)
+		accessToken, err := service.authService.GenerateToken(ctx, user, nil, entities.AuthMethodPassword)
+		assert.NoError(t, err)
+		response, err := service.Verify(ctx, &dto.MFAVerifyRequest{MFAToken: accessToken, Code: "000000"})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidToken)
+	})
+
+	t.Run("Verify persists before issuing a token", func(t *testing.T) {
+		user, secret := enrolledUser(t, service)
+		code, _ := totp.GenerateCode(secret, totp.Step(now))
+		mfaToken := challenge(user)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(errors.New("failed to update user")).Times(1)
+		response, err := service.Verify(ctx, &dto.MFAVerifyRequest{MFAToken: mfaToken, Code: code})
+		assert.Nil(t, response)
+		assert.Equal(t, "failed to update user", err.Error())
+	})
+}
+
+func TestService_MFA_Disable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	now := time.Now()
+	service := newTestMFAService(t, mockUserRepo, now)
+	ctx := context.Background()
+
+	t.Run("Disable with TOTP code", func(t *testing.T) {
+		user, secret := enrolledUser(t, service)
+		code, _ := totp.GenerateCode(secret, totp.Step(now))
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+		assert.NoError(t, service.Disable(ctx, user.ID, &dto.MFACodeRequest{Code: code}))
+		assert.False(t, user.MFA.Enabled)
+		assert.Empty(t, user.MFA.Secret)
+	})
+
+	t.Run("Disable with recovery code", func(t *testing.T) {
+		user, _ := enrolledUser(t, service)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+		assert.NoError(t, service.Disable(ctx, user.ID, &dto.MFACodeRequest{Code: "abcd-efgh"}))
+		assert.False(t, user.MFA.Enabled)
+	})
+
+	t.Run("Disable with invalid code", func(t *testing.T) {
+		user, _ := enrolledUser(t, service)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		assert.ErrorIs(t, service.Disable(ctx, user.ID, &dto.MFACodeRequest{Code: "000000"}), domainErrors.ErrInvalidMFACode)
+		assert.True(t, user.MFA.Enabled)
+	})
+
+	t.Run("Disable when not enabled", func(t *testing.T) {
+		user := &entities.User{ID: entities.NewUserID()}
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		assert.ErrorIs(t, service.Disable(ctx, user.ID, &dto.MFACodeRequest{Code: "000000"}), domainErrors.ErrMFANotEnabled)
+	})
+}
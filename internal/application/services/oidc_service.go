@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+const (
+	authorizationCodeTTL   = time.Minute
+	authorizationCodeBytes = 32
+	oauthClientIDBytes     = 16
+	oauthClientSecretBytes = 32
+
+	responseTypeCode           = "code"
+	grantTypeAuthorizationCode = "authorization_code"
+	codeChallengeMethodS256    = "S256"
+
+	// RFC 7636 bounds for code verifiers; a S256 challenge is always 43
+	// characters long.
+	minCodeVerifierLength = 43
+	maxCodeVerifierLength = 128
+	codeChallengeLength   = 43
+)
+
+type oidcService struct {
+	clientRepo  repositories.OAuthClientRepository
+	codeRepo    repositories.AuthorizationCodeRepository
+	userRepo    repositories.UserRepository
+	authService domainServices.AuthService
+	issuer      string
+	now         func() time.Time
+}
+
+func NewOIDCService(clientRepo repositories.OAuthClientRepository, codeRepo repositories.AuthorizationCodeRepository, userRepo repositories.UserRepository, authService domainServices.AuthService, issuer string) ports.OIDCService {
+	return &oidcService{
+		clientRepo:  clientRepo,
+		codeRepo:    codeRepo,
+		userRepo:    userRepo,
+		authService: authService,
+		issuer:      strings.TrimSuffix(issuer, "/"),
+		now:         time.Now,
+	}
+}
+
+func (s *oidcService) RegisterClient(ctx context.Context, req *dto.CreateOAuthClientRequest) (*dto.OAuthClientResponse, error) {
+	for _, redirectURI := range req.RedirectURIs {
+		if !isValidRedirectURI(redirectURI) {
+			return nil, domainErrors.ErrInvalidRedirectURI
+		}
+	}
+
+	for _, scope := range req.Scopes {
+		if !entities.IsKnownScope(scope) {
+			return nil, domainErrors.ErrInvalidScope
+		}
+	}
+
+	clientID, err := randomToken(oauthClientIDBytes, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret, secretHash string
+	if !req.Public {
+		secret, err = randomToken(oauthClientSecretBytes, base64.RawURLEncoding.EncodeToString)
+		if err != nil {
+			return nil, err
+		}
+		secretHash = hashOAuthSecret(secret)
+	}
+
+	client, err := entities.NewOAuthClient(clientID, secretHash, req.Name, req.RedirectURIs, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, err
+	}
+
+	return &dto.OAuthClientResponse{
+		ClientID:     client.ClientID,
+		ClientSecret: secret,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		CreatedAt:    client.CreatedAt,
+	}, nil
+}
+
+func (s *oidcService) ValidateAuthorizeRequest(ctx context.Context, req *dto.AuthorizeRequest) error {
+	_, _, err := s.checkAuthorizeRequest(ctx, req)
+	return err
+}
+
+func (s *oidcService) Authorize(ctx context.Context, accessToken string, req *dto.AuthorizeRequest) (*dto.AuthorizeResponse, error) {
+	client, requested, err := s.checkAuthorizeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	verified, err := s.authService.ValidateToken(ctx, accessToken)
+	if err != nil {
+		return nil, domainErrors.ErrInvalidToken
+	}
+
+	// An impersonation token must not turn into a client's token, which
+	// would outlive it and escape the audit trail
+	if verified.Actor != nil {
+		return nil, domainErrors.ErrInvalidToken
+	}
+
+	// The client gets at most what the presented token holds
+	user := verified.User
+	scopes := entities.RestrictScopes(effectiveScopes(user, verified.Scopes), requested)
+
+	code, err := randomToken(authorizationCodeBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if err := s.codeRepo.Create(ctx, &entities.AuthorizationCode{
+		CodeHash:      hashOAuthSecret(code),
+		TenantID:      verified.TenantID,
+		ClientID:      client.ClientID,
+		UserID:        user.ID,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      now,
+		ExpiresAt:     now.Add(authorizationCodeTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthorizeResponse{
+		RedirectURI: req.RedirectURI,
+		Code:        code,
+		State:       req.State,
+	}, nil
+}
+
+func (s *oidcService) Token(ctx context.Context, req *dto.TokenRequest) (*dto.TokenResponse, error) {
+	if req.GrantType != grantTypeAuthorizationCode {
+		return nil, domainErrors.ErrUnsupportedGrantType
+	}
+
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, domainErrors.ErrInvalidOAuthRequest
+	}
+
+	// The code is gone after this call whether or not the request succeeds
+	code, err := s.codeRepo.Consume(ctx, hashOAuthSecret(req.Code))
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domainErrors.ErrInvalidGrant
+	}
+
+	if code.IsExpired(s.now()) || code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI ||
+		!verifyCodeChallenge(code.CodeChallenge, req.CodeVerifier) {
+		return nil, domainErrors.ErrInvalidGrant
+	}
+
+	// The user is in the tenant the code was issued in
+	user, err := s.userRepo.GetByID(domainServices.WithTenant(ctx, code.TenantID), code.UserID)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domainErrors.ErrInvalidGrant
+	}
+
+	scopes := effectiveScopes(user, code.Scopes)
+	accessToken, err := s.authService.GenerateToken(ctx, user, scopes, entities.AuthMethodOAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(domainServices.AccessTokenTTL.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	if entities.HasScope(scopes, entities.ScopeOpenID) {
+		response.IDToken, err = s.authService.GenerateIDToken(ctx, &domainServices.IDToken{
+			User:     user,
+			Audience: client.ClientID,
+			Nonce:    code.Nonce,
+			Scopes:   scopes,
+			AuthTime: code.AuthTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+func (s *oidcService) UserInfo(ctx context.Context, principal *dto.Principal) (*dto.UserInfoResponse, error) {
+	if !entities.HasScope(principal.Scopes, entities.ScopeOpenID) {
+		return nil, domainErrors.ErrInsufficientScope
+	}
+
+	response := &dto.UserInfoResponse{
+		Subject: principal.User.ID.String(),
+	}
+	if entities.HasScope(principal.Scopes, entities.ScopeProfile) {
+		response.Name = principal.User.Name
+	}
+	if entities.HasScope(principal.Scopes, entities.ScopeEmail) {
+		response.Email = principal.User.Email
+	}
+
+	return response, nil
+}
+
+func (s *oidcService) Discovery() *dto.OpenIDConfiguration {
+	return &dto.OpenIDConfiguration{
+		Issuer:                            s.issuer,
+		AuthorizationEndpoint:             s.issuer + "/oauth/authorize",
+		TokenEndpoint:                     s.issuer + "/oauth/token",
+		UserInfoEndpoint:                  s.issuer + "/userinfo",
+		JWKSURI:                           s.issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   entities.KnownScopes(),
+		ResponseTypesSupported:            []string{responseTypeCode},
+		GrantTypesSupported:               []string{grantTypeAuthorizationCode},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"RS256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{codeChallengeMethodS256},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "email"},
+	}
+}
+
+func (s *oidcService) JWKS() *dto.JSONWebKeySet {
+	keys := []dto.JSONWebKey{}
+	for _, publicKey := range s.authService.PublicKeys() {
+		rsaKey, ok := publicKey.Key.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		keys = append(keys, dto.JSONWebKey{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: publicKey.Algorithm,
+			KeyID:     publicKey.KeyID,
+			N:         base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		})
+	}
+
+	return &dto.JSONWebKeySet{Keys: keys}
+}
+
+// checkAuthorizeRequest validates the client and redirect URI first: errors
+// about them must not be sent to the redirect URI.
+func (s *oidcService) checkAuthorizeRequest(ctx context.Context, req *dto.AuthorizeRequest) (*entities.OAuthClient, []string, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, req.ClientID)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, domainErrors.ErrInvalidClient
+	}
+
+	if !client.HasRedirectURI(req.RedirectURI) {
+		return nil, nil, domainErrors.ErrInvalidRedirectURI
+	}
+
+	if req.ResponseType != responseTypeCode {
+		return nil, nil, domainErrors.ErrUnsupportedResponseType
+	}
+
+	// PKCE is required for every client, as OAuth 2.1 recommends
+	if req.CodeChallengeMethod != codeChallengeMethodS256 || len(req.CodeChallenge) != codeChallengeLength {
+		return nil, nil, domainErrors.ErrInvalidOAuthRequest
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		return nil, nil, domainErrors.ErrInvalidOAuthRequest
+	}
+	for _, scope := range scopes {
+		if !entities.IsKnownScope(scope) || !client.AllowsScope(scope) {
+			return nil, nil, domainErrors.ErrInvalidScope
+		}
+	}
+
+	return client, scopes, nil
+}
+
+func (s *oidcService) authenticateClient(ctx context.Context, clientID, clientSecret string) (*entities.OAuthClient, error) {
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, domainErrors.ErrInvalidClient
+	}
+
+	if client.IsConfidential() &&
+		subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(hashOAuthSecret(clientSecret))) != 1 {
+		return nil, domainErrors.ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if len(verifier) < minCodeVerifierLength || len(verifier) > maxCodeVerifierLength {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func isValidRedirectURI(redirectURI string) bool {
+	u, err := url.Parse(redirectURI)
+	return err == nil && u.IsAbs() && u.Host != "" && u.Fragment == ""
+}
+
+func randomToken(size int, encode func([]byte) string) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return encode(buf), nil
+}
+
+// hashOAuthSecret hashes client secrets and authorization codes, which are
+// random enough that a fast hash is sufficient.
+func hashOAuthSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
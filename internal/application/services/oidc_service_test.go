@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testIssuer       = "https://id.example.com"
+	testRedirectURI  = "https://app.example.com/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mJ92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func testCodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestService_OIDC_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClientRepo := mock_repositories.NewMockOAuthClientRepository(ctrl)
+	mockCodeRepo := mock_repositories.NewMockAuthorizationCodeRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
+	service := NewOIDCService(mockClientRepo, mockCodeRepo, mockUserRepo, jwtService, testIssuer)
+	ctx := context.Background()
+
+	client := &entities.OAuthClient{ClientID: "client", Name: "App", RedirectURIs: []string{testRedirectURI}}
+	user := &entities.User{ID: entities.NewUserID(), Email: "test@example.com"}
+	newRequest := func() *dto.AuthorizeRequest {
+		return &dto.AuthorizeRequest{
+			ResponseType:        "code",
+			ClientID:            client.ClientID,
+			RedirectURI:         testRedirectURI,
+			Scope:               "openid users:read users:delete",
+			State:               "xyz",
+			CodeChallenge:       testCodeChallenge(testCodeVerifier),
+			CodeChallengeMethod: "S256",
+		}
+	}
+
+	t.Run("Authorize narrows scopes to the presented token", func(t *testing.T) {
+		accessToken, err := jwtService.GenerateToken(ctx, user, []string{entities.ScopeUsersRead}, entities.AuthMethodOAuth)
+		assert.NoError(t, err)
+
+		var stored *entities.AuthorizationCode
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockCodeRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, code *entities.AuthorizationCode) error {
+			stored = code
+			return nil
+		}).Times(1)
+
+		response, err := service.Authorize(ctx, accessToken, newRequest())
+		assert.NoError(t, err)
+		assert.Equal(t, "xyz", response.State)
+		assert.Equal(t, hashOAuthSecret(response.Code), stored.CodeHash)
+		assert.Equal(t, []string{entities.ScopeOpenID, entities.ScopeUsersRead}, stored.Scopes)
+		assert.Equal(t, entities.DefaultTenant, stored.TenantID)
+	})
+
+	t.Run("Unknown client", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(nil, domainErrors.ErrOAuthClientNotFound).Times(1)
+		err := service.ValidateAuthorizeRequest(ctx, newRequest())
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidClient)
+	})
+
+	t.Run("Unregistered redirect URI", func(t *testing.T) {
+		req := newRequest()
+		req.RedirectURI = testRedirectURI + "/other"
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+		err := service.ValidateAuthorizeRequest(ctx, req)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidRedirectURI)
+	})
+
+	t.Run("Missing PKCE", func(t *testing.T) {
+		req := newRequest()
+		req.CodeChallengeMethod = "plain"
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+		err := service.ValidateAuthorizeRequest(ctx, req)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidOAuthRequest)
+	})
+
+	t.Run("Scope not allowed for client", func(t *testing.T) {
+		limited := *client
+		limited.Scopes = []string{entities.ScopeUsersRead}
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(&limited, nil).Times(1)
+		err := service.ValidateAuthorizeRequest(ctx, newRequest())
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidScope)
+	})
+}
+
+func TestService_OIDC_Token(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	signingKey, err := auth.GenerateSigningKey()
+	assert.NoError(t, err)
+
+	mockClientRepo := mock_repositories.NewMockOAuthClientRepository(ctrl)
+	mockCodeRepo := mock_repositories.NewMockAuthorizationCodeRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo, auth.WithIDTokenSigning(testIssuer, signingKey))
+	service := NewOIDCService(mockClientRepo, mockCodeRepo, mockUserRepo, jwtService, testIssuer)
+	ctx := context.Background()
+
+	client := &entities.OAuthClient{ClientID: "client", SecretHash: hashOAuthSecret("secret"), RedirectURIs: []string{testRedirectURI}}
+	user := &entities.User{ID: entities.NewUserID(), Name: "Test User", Email: "test@example.com"}
+	newCode := func() *entities.AuthorizationCode {
+		return &entities.AuthorizationCode{
+			CodeHash:      hashOAuthSecret("code"),
+			ClientID:      client.ClientID,
+			UserID:        user.ID,
+			RedirectURI:   testRedirectURI,
+			Scopes:        []string{entities.ScopeOpenID, entities.ScopeEmail, entities.ScopeUsersRead},
+			Nonce:         "n-0S6_WzA2Mj",
+			CodeChallenge: testCodeChallenge(testCodeVerifier),
+			AuthTime:      time.Now(),
+			ExpiresAt:     time.Now().Add(time.Minute),
+		}
+	}
+	newRequest := func() *dto.TokenRequest {
+		return &dto.TokenRequest{
+			GrantType:    "authorization_code",
+			Code:         "code",
+			RedirectURI:  testRedirectURI,
+			ClientID:     client.ClientID,
+			ClientSecret: "secret",
+			CodeVerifier: testCodeVerifier,
+		}
+	}
+
+	t.Run("Token success", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+		mockCodeRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("code")).Return(newCode(), nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		response, err := service.Token(ctx, newRequest())
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer", response.TokenType)
+		assert.Equal(t, "openid email users:read", response.Scope)
+
+		claims := &auth.IDTokenClaims{}
+		_, err = jwt.ParseWithClaims(response.IDToken, claims, func(token *jwt.Token) (interface{}, error) {
+			return &signingKey.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(testIssuer), jwt.WithAudience(client.ClientID))
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID.String(), claims.Subject)
+		assert.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+		assert.Equal(t, user.Email, claims.Email)
+		assert.Empty(t, claims.Name)
+	})
+
+	t.Run("Wrong client secret", func(t *testing.T) {
+		req := newRequest()
+		req.ClientSecret = "wrong"
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+
+		response, err := service.Token(ctx, req)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidClient)
+	})
+
+	t.Run("Wrong code verifier", func(t *testing.T) {
+		req := newRequest()
+		req.CodeVerifier = strings.Repeat("a", 43)
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+		mockCodeRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("code")).Return(newCode(), nil).Times(1)
+
+		response, err := service.Token(ctx, req)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidGrant)
+	})
+
+	t.Run("Redirect URI mismatch", func(t *testing.T) {
+		req := newRequest()
+		req.RedirectURI = "https://evil.example.com/callback"
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+		mockCodeRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("code")).Return(newCode(), nil).Times(1)
+
+		_, err := service.Token(ctx, req)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidGrant)
+	})
+
+	t.Run("Expired code", func(t *testing.T) {
+		code := newCode()
+		code.ExpiresAt = time.Now().Add(-time.Second)
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+		mockCodeRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("code")).Return(code, nil).Times(1)
+
+		_, err := service.Token(ctx, newRequest())
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidGrant)
+	})
+
+	t.Run("Code already used", func(t *testing.T) {
+		mockClientRepo.EXPECT().GetByClientID(gomock.Any(), client.ClientID).Return(client, nil).Times(1)
+		mockCodeRepo.EXPECT().Consume(gomock.Any(), hashOAuthSecret("code")).Return(nil, domainErrors.ErrAuthorizationCodeNotFound).Times(1)
+
+		_, err := service.Token(ctx, newRequest())
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidGrant)
+	})
+
+	t.Run("Unsupported grant type", func(t *testing.T) {
+		req := newRequest()
+		req.GrantType = "password"
+
+		_, err := service.Token(ctx, req)
+		assert.ErrorIs(t, err, domainErrors.ErrUnsupportedGrantType)
+	})
+}
+
+func TestService_OIDC_RegisterClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClientRepo := mock_repositories.NewMockOAuthClientRepository(ctrl)
+	service := NewOIDCService(mockClientRepo, nil, nil, nil, testIssuer)
+	ctx := context.Background()
+
+	t.Run("Confidential client gets a secret", func(t *testing.T) {
+		var stored *entities.OAuthClient
+		mockClientRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, client *entities.OAuthClient) error {
+			stored = client
+			return nil
+		}).Times(1)
+
+		response, err := service.RegisterClient(ctx, &dto.CreateOAuthClientRequest{Name: "App", RedirectURIs: []string{testRedirectURI}})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, response.ClientSecret)
+		assert.Equal(t, hashOAuthSecret(response.ClientSecret), stored.SecretHash)
+	})
+
+	t.Run("Public client", func(t *testing.T) {
+		mockClientRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		response, err := service.RegisterClient(ctx, &dto.CreateOAuthClientRequest{Name: "SPA", RedirectURIs: []string{testRedirectURI}, Public: true})
+		assert.NoError(t, err)
+		assert.Empty(t, response.ClientSecret)
+	})
+
+	t.Run("Relative redirect URI", func(t *testing.T) {
+		response, err := service.RegisterClient(ctx, &dto.CreateOAuthClientRequest{Name: "App", RedirectURIs: []string{"/callback"}})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidRedirectURI)
+	})
+}
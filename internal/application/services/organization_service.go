@@ -0,0 +1,684 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type organizationService struct {
+	organizationRepo repositories.OrganizationRepository
+	teamRepo         repositories.TeamRepository
+	membershipRepo   repositories.MembershipRepository
+	userRepo         repositories.UserRepository
+}
+
+func NewOrganizationService(organizationRepo repositories.OrganizationRepository, teamRepo repositories.TeamRepository, membershipRepo repositories.MembershipRepository, userRepo repositories.UserRepository) ports.OrganizationService {
+	return &organizationService{
+		organizationRepo: organizationRepo,
+		teamRepo:         teamRepo,
+		membershipRepo:   membershipRepo,
+		userRepo:         userRepo,
+	}
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, caller *dto.Principal, req *dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	if caller.User == nil {
+		return nil, domainErrors.ErrForbidden
+	}
+
+	organization, err := entities.NewOrganization(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.organizationRepo.Create(ctx, organization); err != nil {
+		return nil, err
+	}
+
+	// The creator owns the organization
+	owner := entities.NewMembership(organization.ID, "", caller.User.ID, entities.OrganizationRoleOwner)
+	if err := s.membershipRepo.Create(ctx, owner); err != nil {
+		return nil, err
+	}
+
+	return toOrganizationResponse(organization, owner), nil
+}
+
+func (s *organizationService) ListOrganizations(ctx context.Context, userID entities.UserID) (*dto.OrganizationsListResponse, error) {
+	memberships, err := s.membershipRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OrganizationResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.IsTeamMembership() || !membership.IsActive() {
+			continue
+		}
+		organization, err := s.organizationRepo.GetByID(ctx, membership.OrganizationID)
+		if errors.Is(err, domainErrors.ErrOrganizationNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *toOrganizationResponse(organization, membership))
+	}
+
+	return &dto.OrganizationsListResponse{
+		Organizations: responses,
+		Total:         len(responses),
+	}, nil
+}
+
+func (s *organizationService) GetOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.OrganizationResponse, error) {
+	organization, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toOrganizationResponse(organization, membership), nil
+}
+
+func (s *organizationService) UpdateOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.UpdateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	organization, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(caller, membership) {
+		return nil, domainErrors.ErrForbidden
+	}
+
+	organization.UpdateName(req.Name)
+	if err := s.organizationRepo.Update(ctx, organization); err != nil {
+		return nil, err
+	}
+
+	return toOrganizationResponse(organization, membership), nil
+}
+
+func (s *organizationService) DeleteOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID) error {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return err
+	}
+	if !isOwner(caller, membership) {
+		return domainErrors.ErrForbidden
+	}
+
+	if err := s.membershipRepo.DeleteByOrganizationID(ctx, organizationID); err != nil {
+		return err
+	}
+	if err := s.teamRepo.DeleteByOrganizationID(ctx, organizationID); err != nil {
+		return err
+	}
+	return s.organizationRepo.Delete(ctx, organizationID)
+}
+
+func (s *organizationService) ListMembers(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.MembersListResponse, error) {
+	if _, _, err := s.access(ctx, caller, organizationID); err != nil {
+		return nil, err
+	}
+
+	return s.listMembers(ctx, organizationID, "")
+}
+
+func (s *organizationService) InviteMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.InviteMemberRequest) (*dto.MemberResponse, error) {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(caller, membership) {
+		return nil, domainErrors.ErrForbidden
+	}
+	if !entities.IsValidOrganizationRole(req.Role) {
+		return nil, domainErrors.ErrInvalidRole
+	}
+	if req.Role == entities.OrganizationRoleOwner && !isOwner(caller, membership) {
+		return nil, domainErrors.ErrForbidden
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitedBy entities.UserID
+	if caller.User != nil {
+		invitedBy = caller.User.ID
+	}
+	invitation := entities.NewInvitation(organizationID, user.ID, invitedBy, req.Role)
+	if err := s.membershipRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	return toMemberResponse(user, invitation), nil
+}
+
+func (s *organizationService) UpdateMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, userID entities.UserID, req *dto.MemberRoleRequest) (*dto.MemberResponse, error) {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(caller, membership) {
+		return nil, domainErrors.ErrForbidden
+	}
+	if !entities.IsValidOrganizationRole(req.Role) {
+		return nil, domainErrors.ErrInvalidRole
+	}
+
+	member, err := s.membershipRepo.Get(ctx, organizationID, "", userID)
+	if err != nil {
+		return nil, err
+	}
+	// Only owners make or unmake owners
+	if (req.Role == entities.OrganizationRoleOwner || member.Role == entities.OrganizationRoleOwner) && !isOwner(caller, membership) {
+		return nil, domainErrors.ErrForbidden
+	}
+	if member.Role == entities.OrganizationRoleOwner && req.Role != entities.OrganizationRoleOwner {
+		if err := s.ensureOtherOwner(ctx, member); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	member.ChangeRole(req.Role)
+	if err := s.membershipRepo.Update(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return toMemberResponse(user, member), nil
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, userID entities.UserID) error {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return err
+	}
+	leaving := membership != nil && membership.UserID == userID
+	if !leaving && !canManage(caller, membership) {
+		return domainErrors.ErrForbidden
+	}
+
+	member, err := s.membershipRepo.Get(ctx, organizationID, "", userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == entities.OrganizationRoleOwner {
+		if !leaving && !isOwner(caller, membership) {
+			return domainErrors.ErrForbidden
+		}
+		if err := s.ensureOtherOwner(ctx, member); err != nil {
+			return err
+		}
+	}
+
+	// Team memberships require the organization membership
+	memberships, err := s.membershipRepo.GetByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	for _, teamMembership := range memberships {
+		if teamMembership.IsTeamMembership() && teamMembership.UserID == userID {
+			if err := s.membershipRepo.Delete(ctx, teamMembership.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.membershipRepo.Delete(ctx, member.ID)
+}
+
+func (s *organizationService) ListInvitations(ctx context.Context, userID entities.UserID) (*dto.MembershipsListResponse, error) {
+	memberships, err := s.GetUserMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]dto.MembershipResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.Status == entities.MembershipInvited {
+			invitations = append(invitations, membership)
+		}
+	}
+
+	return &dto.MembershipsListResponse{
+		Memberships: invitations,
+		Total:       len(invitations),
+	}, nil
+}
+
+func (s *organizationService) AcceptInvitation(ctx context.Context, userID entities.UserID, organizationID entities.ID) (*dto.MembershipResponse, error) {
+	organization, invitation, err := s.getInvitation(ctx, userID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.Accept()
+	if err := s.membershipRepo.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	return toMembershipResponse(organization, nil, invitation), nil
+}
+
+func (s *organizationService) DeclineInvitation(ctx context.Context, userID entities.UserID, organizationID entities.ID) error {
+	_, invitation, err := s.getInvitation(ctx, userID, organizationID)
+	if err != nil {
+		return err
+	}
+
+	return s.membershipRepo.Delete(ctx, invitation.ID)
+}
+
+// getInvitation returns the user's pending invitation to the organization.
+func (s *organizationService) getInvitation(ctx context.Context, userID entities.UserID, organizationID entities.ID) (*entities.Organization, *entities.Membership, error) {
+	organization, err := s.organizationRepo.GetByID(ctx, organizationID)
+	if errors.Is(err, domainErrors.ErrOrganizationNotFound) {
+		return nil, nil, domainErrors.ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invitation, err := s.membershipRepo.Get(ctx, organizationID, "", userID)
+	if errors.Is(err, domainErrors.ErrMembershipNotFound) {
+		return nil, nil, domainErrors.ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if invitation.IsActive() {
+		return nil, nil, domainErrors.ErrInvitationNotFound
+	}
+
+	return organization, invitation, nil
+}
+
+func (s *organizationService) CreateTeam(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.CreateTeamRequest) (*dto.TeamResponse, error) {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(caller, membership) {
+		return nil, domainErrors.ErrForbidden
+	}
+
+	team, err := entities.NewTeam(organizationID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.teamRepo.Create(ctx, team); err != nil {
+		return nil, err
+	}
+
+	return toTeamResponse(team), nil
+}
+
+func (s *organizationService) ListTeams(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.TeamsListResponse, error) {
+	if _, _, err := s.access(ctx, caller, organizationID); err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamRepo.GetByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.TeamResponse, 0, len(teams))
+	for _, team := range teams {
+		responses = append(responses, *toTeamResponse(team))
+	}
+
+	return &dto.TeamsListResponse{
+		Teams: responses,
+		Total: len(responses),
+	}, nil
+}
+
+func (s *organizationService) GetTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) (*dto.TeamResponse, error) {
+	if _, _, err := s.access(ctx, caller, organizationID); err != nil {
+		return nil, err
+	}
+
+	team, err := s.getTeam(ctx, organizationID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTeamResponse(team), nil
+}
+
+func (s *organizationService) UpdateTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, req *dto.UpdateTeamRequest) (*dto.TeamResponse, error) {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	team, err := s.getTeam(ctx, organizationID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.canManageTeam(ctx, caller, membership, team) {
+		return nil, domainErrors.ErrForbidden
+	}
+
+	team.UpdateName(req.Name)
+	if err := s.teamRepo.Update(ctx, team); err != nil {
+		return nil, err
+	}
+
+	return toTeamResponse(team), nil
+}
+
+func (s *organizationService) DeleteTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) error {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return err
+	}
+	if !canManage(caller, membership) {
+		return domainErrors.ErrForbidden
+	}
+	if _, err := s.getTeam(ctx, organizationID, teamID); err != nil {
+		return err
+	}
+
+	if err := s.membershipRepo.DeleteByTeamID(ctx, teamID); err != nil {
+		return err
+	}
+	return s.teamRepo.Delete(ctx, teamID)
+}
+
+func (s *organizationService) ListTeamMembers(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) (*dto.MembersListResponse, error) {
+	if _, _, err := s.access(ctx, caller, organizationID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getTeam(ctx, organizationID, teamID); err != nil {
+		return nil, err
+	}
+
+	return s.listMembers(ctx, organizationID, teamID)
+}
+
+func (s *organizationService) SetTeamMember(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, userID entities.UserID, req *dto.MemberRoleRequest) (*dto.MemberResponse, error) {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	team, err := s.getTeam(ctx, organizationID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.canManageTeam(ctx, caller, membership, team) {
+		return nil, domainErrors.ErrForbidden
+	}
+	if !entities.IsValidTeamRole(req.Role) {
+		return nil, domainErrors.ErrInvalidRole
+	}
+
+	// Only active organization members join teams
+	member, err := s.membershipRepo.Get(ctx, organizationID, "", userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.IsActive() {
+		return nil, domainErrors.ErrMembershipNotFound
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamMembership, err := s.membershipRepo.Get(ctx, organizationID, teamID, userID)
+	switch {
+	case errors.Is(err, domainErrors.ErrMembershipNotFound):
+		teamMembership = entities.NewMembership(organizationID, teamID, userID, req.Role)
+		err = s.membershipRepo.Create(ctx, teamMembership)
+	case err == nil:
+		teamMembership.ChangeRole(req.Role)
+		err = s.membershipRepo.Update(ctx, teamMembership)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toMemberResponse(user, teamMembership), nil
+}
+
+func (s *organizationService) RemoveTeamMember(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, userID entities.UserID) error {
+	_, membership, err := s.access(ctx, caller, organizationID)
+	if err != nil {
+		return err
+	}
+	team, err := s.getTeam(ctx, organizationID, teamID)
+	if err != nil {
+		return err
+	}
+	leaving := membership != nil && membership.UserID == userID
+	if !leaving && !s.canManageTeam(ctx, caller, membership, team) {
+		return domainErrors.ErrForbidden
+	}
+
+	teamMembership, err := s.membershipRepo.Get(ctx, organizationID, teamID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.membershipRepo.Delete(ctx, teamMembership.ID)
+}
+
+func (s *organizationService) GetUserMemberships(ctx context.Context, userID entities.UserID) ([]dto.MembershipResponse, error) {
+	memberships, err := s.membershipRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations := make(map[entities.ID]*entities.Organization)
+	responses := make([]dto.MembershipResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		organization, ok := organizations[membership.OrganizationID]
+		if !ok {
+			organization, err = s.organizationRepo.GetByID(ctx, membership.OrganizationID)
+			if errors.Is(err, domainErrors.ErrOrganizationNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			organizations[organization.ID] = organization
+		}
+
+		var team *entities.Team
+		if membership.IsTeamMembership() {
+			team, err = s.teamRepo.GetByID(ctx, membership.TeamID)
+			if errors.Is(err, domainErrors.ErrTeamNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		responses = append(responses, *toMembershipResponse(organization, team, membership))
+	}
+
+	return responses, nil
+}
+
+// access returns the organization and the caller's active membership of it.
+// Administrators may have no membership. The organization is not found for
+// anybody else, so that its existence is not revealed.
+func (s *organizationService) access(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*entities.Organization, *entities.Membership, error) {
+	organization, err := s.organizationRepo.GetByID(ctx, organizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if caller.User == nil {
+		if isAdministrator(caller) {
+			return organization, nil, nil
+		}
+		return nil, nil, domainErrors.ErrOrganizationNotFound
+	}
+
+	membership, err := s.membershipRepo.Get(ctx, organizationID, "", caller.User.ID)
+	if err != nil && !errors.Is(err, domainErrors.ErrMembershipNotFound) {
+		return nil, nil, err
+	}
+	if membership != nil && !membership.IsActive() {
+		membership = nil
+	}
+	if membership == nil && !isAdministrator(caller) {
+		return nil, nil, domainErrors.ErrOrganizationNotFound
+	}
+
+	return organization, membership, nil
+}
+
+// getTeam hides teams of other organizations as not found.
+func (s *organizationService) getTeam(ctx context.Context, organizationID, teamID entities.ID) (*entities.Team, error) {
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if team.OrganizationID != organizationID {
+		return nil, domainErrors.ErrTeamNotFound
+	}
+	return team, nil
+}
+
+// ensureOtherOwner fails when owner is the only active owner left.
+func (s *organizationService) ensureOtherOwner(ctx context.Context, owner *entities.Membership) error {
+	memberships, err := s.membershipRepo.GetByOrganizationID(ctx, owner.OrganizationID)
+	if err != nil {
+		return err
+	}
+	for _, membership := range memberships {
+		if membership.ID != owner.ID && !membership.IsTeamMembership() && membership.IsActive() && membership.Role == entities.OrganizationRoleOwner {
+			return nil
+		}
+	}
+	return domainErrors.ErrLastOwner
+}
+
+// listMembers lists the members of the organization, or of the team unless
+// teamID is zero.
+func (s *organizationService) listMembers(ctx context.Context, organizationID, teamID entities.ID) (*dto.MembersListResponse, error) {
+	memberships, err := s.membershipRepo.GetByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.MemberResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.TeamID != teamID {
+			continue
+		}
+		user, err := s.userRepo.GetByID(ctx, membership.UserID)
+		if errors.Is(err, domainErrors.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *toMemberResponse(user, membership))
+	}
+
+	return &dto.MembersListResponse{
+		Members: responses,
+		Total:   len(responses),
+	}, nil
+}
+
+// canManageTeam allows organization managers and the team's maintainers.
+func (s *organizationService) canManageTeam(ctx context.Context, caller *dto.Principal, membership *entities.Membership, team *entities.Team) bool {
+	if canManage(caller, membership) {
+		return true
+	}
+	if membership == nil {
+		return false
+	}
+
+	teamMembership, err := s.membershipRepo.Get(ctx, team.OrganizationID, team.ID, membership.UserID)
+	return err == nil && teamMembership.Role == entities.TeamRoleMaintainer
+}
+
+func isAdministrator(caller *dto.Principal) bool {
+	return entities.HasScope(caller.Scopes, entities.ScopeAdmin)
+}
+
+// canManage allows the organization's owners and admins, and administrators.
+func canManage(caller *dto.Principal, membership *entities.Membership) bool {
+	if isAdministrator(caller) {
+		return true
+	}
+	return membership != nil && (membership.Role == entities.OrganizationRoleOwner || membership.Role == entities.OrganizationRoleAdmin)
+}
+
+// isOwner allows the organization's owners, and administrators.
+func isOwner(caller *dto.Principal, membership *entities.Membership) bool {
+	if isAdministrator(caller) {
+		return true
+	}
+	return membership != nil && membership.Role == entities.OrganizationRoleOwner
+}
+
+func toOrganizationResponse(organization *entities.Organization, membership *entities.Membership) *dto.OrganizationResponse {
+	response := &dto.OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		CreatedAt: organization.CreatedAt,
+		UpdatedAt: organization.UpdatedAt,
+	}
+	if membership != nil {
+		response.Role = membership.Role
+	}
+	return response
+}
+
+func toTeamResponse(team *entities.Team) *dto.TeamResponse {
+	return &dto.TeamResponse{
+		ID:             team.ID,
+		OrganizationID: team.OrganizationID,
+		Name:           team.Name,
+		CreatedAt:      team.CreatedAt,
+		UpdatedAt:      team.UpdatedAt,
+	}
+}
+
+func toMemberResponse(user *entities.User, membership *entities.Membership) *dto.MemberResponse {
+	return &dto.MemberResponse{
+		UserID:    user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      membership.Role,
+		Status:    membership.Status,
+		CreatedAt: membership.CreatedAt,
+	}
+}
+
+func toMembershipResponse(organization *entities.Organization, team *entities.Team, membership *entities.Membership) *dto.MembershipResponse {
+	response := &dto.MembershipResponse{
+		OrganizationID:   organization.ID,
+		OrganizationName: organization.Name,
+		Role:             membership.Role,
+		Status:           membership.Status,
+		CreatedAt:        membership.CreatedAt,
+	}
+	if team != nil {
+		response.TeamID = &team.ID
+		response.TeamName = team.Name
+	}
+	if !membership.InvitedBy.IsZero() {
+		invitedBy := membership.InvitedBy
+		response.InvitedBy = &invitedBy
+	}
+	return response
+}
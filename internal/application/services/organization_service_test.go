@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Organization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrganizationRepo := mock_repositories.NewMockOrganizationRepository(ctrl)
+	mockTeamRepo := mock_repositories.NewMockTeamRepository(ctrl)
+	mockMembershipRepo := mock_repositories.NewMockMembershipRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	service := NewOrganizationService(mockOrganizationRepo, mockTeamRepo, mockMembershipRepo, mockUserRepo)
+	ctx := context.Background()
+	var none entities.ID
+
+	owner, _ := entities.NewUser("Owner", "owner@example.com", "hashed")
+	member, _ := entities.NewUser("Member", "member@example.com", "hashed")
+	outsider, _ := entities.NewUser("Outsider", "outsider@example.com", "hashed")
+	principal := func(user *entities.User, scopes ...string) *dto.Principal {
+		return &dto.Principal{User: toUserResponse(user), Scopes: append([]string{entities.ScopeUsersRead, entities.ScopeUsersWrite}, scopes...)}
+	}
+
+	organization, _ := entities.NewOrganization("Acme")
+	ownership := entities.NewMembership(organization.ID, none, owner.ID, entities.OrganizationRoleOwner)
+	membership := entities.NewMembership(organization.ID, none, member.ID, entities.OrganizationRoleMember)
+
+	t.Run("Makes the creator the owner", func(t *testing.T) {
+		mockOrganizationRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockMembershipRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, created *entities.Membership) error {
+			assert.Equal(t, owner.ID, created.UserID)
+			assert.Equal(t, entities.OrganizationRoleOwner, created.Role)
+			assert.True(t, created.IsActive())
+			assert.False(t, created.IsTeamMembership())
+			return nil
+		}).Times(1)
+
+		response, err := service.CreateOrganization(ctx, principal(owner), &dto.CreateOrganizationRequest{Name: "Globex"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Globex", response.Name)
+		assert.Equal(t, entities.OrganizationRoleOwner, response.Role)
+	})
+
+	t.Run("Hides organizations from non-members", func(t *testing.T) {
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, outsider.ID).Return(nil, domainErrors.ErrMembershipNotFound).Times(1)
+
+		_, err := service.GetOrganization(ctx, principal(outsider), organization.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrOrganizationNotFound)
+	})
+
+	t.Run("Lets administrators in without a membership", func(t *testing.T) {
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, outsider.ID).Return(nil, domainErrors.ErrMembershipNotFound).Times(1)
+
+		response, err := service.GetOrganization(ctx, principal(outsider, entities.ScopeAdmin), organization.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, response.Role)
+	})
+
+	t.Run("Does not let members change the organization", func(t *testing.T) {
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, member.ID).Return(membership, nil).Times(1)
+
+		_, err := service.UpdateOrganization(ctx, principal(member), organization.ID, &dto.UpdateOrganizationRequest{Name: "Renamed"})
+		assert.ErrorIs(t, err, domainErrors.ErrForbidden)
+	})
+
+	t.Run("Invites a user of the tenant", func(t *testing.T) {
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, owner.ID).Return(ownership, nil).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), outsider.Email).Return(outsider, nil).Times(1)
+		mockMembershipRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, invitation *entities.Membership) error {
+			assert.Equal(t, outsider.ID, invitation.UserID)
+			assert.Equal(t, owner.ID, invitation.InvitedBy)
+			assert.False(t, invitation.IsActive())
+			return nil
+		}).Times(1)
+
+		response, err := service.InviteMember(ctx, principal(owner), organization.ID, &dto.InviteMemberRequest{Email: outsider.Email, Role: entities.OrganizationRoleAdmin})
+		assert.NoError(t, err)
+		assert.Equal(t, entities.MembershipInvited, response.Status)
+	})
+
+	t.Run("Rejects unknown roles", func(t *testing.T) {
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, owner.ID).Return(ownership, nil).Times(1)
+
+		_, err := service.InviteMember(ctx, principal(owner), organization.ID, &dto.InviteMemberRequest{Email: outsider.Email, Role: "superuser"})
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidRole)
+	})
+
+	t.Run("Only owners make owners", func(t *testing.T) {
+		admin := entities.NewMembership(organization.ID, none, member.ID, entities.OrganizationRoleAdmin)
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, member.ID).Return(admin, nil).Times(1)
+
+		_, err := service.InviteMember(ctx, principal(member), organization.ID, &dto.InviteMemberRequest{Email: outsider.Email, Role: entities.OrganizationRoleOwner})
+		assert.ErrorIs(t, err, domainErrors.ErrForbidden)
+	})
+
+	t.Run("Keeps the last owner", func(t *testing.T) {
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, owner.ID).Return(ownership, nil).Times(2)
+		mockMembershipRepo.EXPECT().GetByOrganizationID(gomock.Any(), organization.ID).Return([]*entities.Membership{ownership, membership}, nil).Times(1)
+
+		err := service.RemoveMember(ctx, principal(owner), organization.ID, owner.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrLastOwner)
+	})
+
+	t.Run("Removes a member with their teams", func(t *testing.T) {
+		teamMembership := entities.NewMembership(organization.ID, entities.NewID(), member.ID, entities.TeamRoleMember)
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, owner.ID).Return(ownership, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, member.ID).Return(membership, nil).Times(1)
+		mockMembershipRepo.EXPECT().GetByOrganizationID(gomock.Any(), organization.ID).Return([]*entities.Membership{ownership, membership, teamMembership}, nil).Times(1)
+		mockMembershipRepo.EXPECT().Delete(gomock.Any(), teamMembership.ID).Return(nil).Times(1)
+		mockMembershipRepo.EXPECT().Delete(gomock.Any(), membership.ID).Return(nil).Times(1)
+
+		err := service.RemoveMember(ctx, principal(owner), organization.ID, member.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Accepts an invitation", func(t *testing.T) {
+		invitation := entities.NewInvitation(organization.ID, outsider.ID, owner.ID, entities.OrganizationRoleMember)
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, outsider.ID).Return(invitation, nil).Times(1)
+		mockMembershipRepo.EXPECT().Update(gomock.Any(), invitation).Return(nil).Times(1)
+
+		response, err := service.AcceptInvitation(ctx, outsider.ID, organization.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entities.MembershipActive, response.Status)
+		assert.Equal(t, "Acme", response.OrganizationName)
+	})
+
+	t.Run("Does not accept an active membership as an invitation", func(t *testing.T) {
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, member.ID).Return(membership, nil).Times(1)
+
+		_, err := service.AcceptInvitation(ctx, member.ID, organization.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrInvitationNotFound)
+	})
+
+	t.Run("Adds only active members to teams", func(t *testing.T) {
+		team, _ := entities.NewTeam(organization.ID, "Platform")
+		invitation := entities.NewInvitation(organization.ID, outsider.ID, owner.ID, entities.OrganizationRoleMember)
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, owner.ID).Return(ownership, nil).Times(1)
+		mockTeamRepo.EXPECT().GetByID(gomock.Any(), team.ID).Return(team, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, outsider.ID).Return(invitation, nil).Times(1)
+
+		_, err := service.SetTeamMember(ctx, principal(owner), organization.ID, team.ID, outsider.ID, &dto.MemberRoleRequest{Role: entities.TeamRoleMember})
+		assert.ErrorIs(t, err, domainErrors.ErrMembershipNotFound)
+	})
+
+	t.Run("Hides teams of other organizations", func(t *testing.T) {
+		team, _ := entities.NewTeam(entities.NewID(), "Elsewhere")
+		mockOrganizationRepo.EXPECT().GetByID(gomock.Any(), organization.ID).Return(organization, nil).Times(1)
+		mockMembershipRepo.EXPECT().Get(gomock.Any(), organization.ID, none, owner.ID).Return(ownership, nil).Times(1)
+		mockTeamRepo.EXPECT().GetByID(gomock.Any(), team.ID).Return(team, nil).Times(1)
+
+		_, err := service.GetTeam(ctx, principal(owner), organization.ID, team.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrTeamNotFound)
+	})
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/pkg/webauthn"
+)
+
+type passkeyService struct {
+	passkeyRepo  repositories.PasskeyRepository
+	ceremonyRepo repositories.PasskeyCeremonyRepository
+	userRepo     repositories.UserRepository
+	authService  domainServices.AuthService
+	relyingParty *webauthn.RelyingParty
+	now          func() time.Time
+}
+
+func NewPasskeyService(passkeyRepo repositories.PasskeyRepository, ceremonyRepo repositories.PasskeyCeremonyRepository, userRepo repositories.UserRepository, authService domainServices.AuthService, relyingParty *webauthn.RelyingParty) ports.PasskeyService {
+	return &passkeyService{
+		passkeyRepo:  passkeyRepo,
+		ceremonyRepo: ceremonyRepo,
+		userRepo:     userRepo,
+		authService:  authService,
+		relyingParty: relyingParty,
+		now:          time.Now,
+	}
+}
+
+func (s *passkeyService) BeginRegistration(ctx context.Context, userID entities.UserID) (*dto.PasskeyRegistrationOptions, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authenticators that already hold one of the user's passkeys decline
+	passkeys, err := s.passkeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		id, err := base64.RawURLEncoding.DecodeString(passkey.ID)
+		if err != nil {
+			return nil, err
+		}
+		exclude = append(exclude, webauthn.CredentialDescriptor{Type: "public-key", ID: id, Transports: passkey.Transports})
+	}
+
+	challenge, err := s.startCeremony(ctx, entities.PasskeyRegistration, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The user handle is the user ID, which carries no personal data
+	userEntity := webauthn.UserEntity{ID: userHandle(user.ID), Name: user.Email, DisplayName: user.Name}
+	return &dto.PasskeyRegistrationOptions{
+		PublicKey: s.relyingParty.CreationOptions(challenge, userEntity, exclude),
+	}, nil
+}
+
+func (s *passkeyService) FinishRegistration(ctx context.Context, userID entities.UserID, req *dto.RegisterPasskeyRequest) (*dto.PasskeyResponse, error) {
+	ceremony, challenge, err := s.finishCeremony(ctx, entities.PasskeyRegistration, req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.UserID != userID {
+		return nil, domainErrors.ErrInvalidPasskeyChallenge
+	}
+
+	credential, err := s.relyingParty.VerifyRegistration(&req.Credential, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainErrors.ErrInvalidPasskey, err)
+	}
+
+	passkey, err := entities.NewPasskey(userID, req.Name, base64.RawURLEncoding.EncodeToString(credential.ID), credential.PublicKey, credential.SignCount)
+	if err != nil {
+		return nil, err
+	}
+	passkey.AAGUID = credential.AAGUID
+	passkey.Transports = credential.Transports
+	passkey.BackupEligible = credential.BackupEligible
+	passkey.BackupState = credential.BackupState
+
+	if err := s.passkeyRepo.Create(ctx, passkey); err != nil {
+		return nil, err
+	}
+
+	return toPasskeyResponse(passkey), nil
+}
+
+// BeginLogin starts a login with any passkey of the relying party, so the
+// user does not have to enter an email first.
+func (s *passkeyService) BeginLogin(ctx context.Context) (*dto.PasskeyLoginOptions, error) {
+	challenge, err := s.startCeremony(ctx, entities.PasskeyLogin, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PasskeyLoginOptions{
+		PublicKey: s.relyingParty.RequestOptions(challenge, nil),
+	}, nil
+}
+
+// FinishLogin verifies the assertion and issues a token. User verification is
+// required, so a passkey counts as multi-factor and no MFA challenge follows.
+func (s *passkeyService) FinishLogin(ctx context.Context, req *dto.PasskeyLoginRequest) (*dto.LoginResponse, error) {
+	_, challenge, err := s.finishCeremony(ctx, entities.PasskeyLogin, req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	passkey, err := s.passkeyRepo.GetByID(ctx, base64.RawURLEncoding.EncodeToString(req.Credential.RawID))
+	if errors.Is(err, domainErrors.ErrPasskeyNotFound) {
+		return nil, domainErrors.ErrPasskeyVerificationFailed
+	}
+	if err != nil {
+		return nil, err
+	}
+	if handle := req.Credential.Response.UserHandle; len(handle) != 0 && !bytes.Equal(handle, userHandle(passkey.UserID)) {
+		return nil, domainErrors.ErrPasskeyVerificationFailed
+	}
+
+	authenticatorData, err := s.relyingParty.VerifyAssertion(&req.Credential, challenge, passkey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainErrors.ErrPasskeyVerificationFailed, err)
+	}
+	if !passkey.RecordUse(authenticatorData.SignCount, authenticatorData.BackupState, s.now()) {
+		return nil, domainErrors.ErrPasskeyCloned
+	}
+	if err := s.passkeyRepo.Update(ctx, passkey); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, passkey.UserID)
+	if errors.Is(err, domainErrors.ErrUserNotFound) {
+		return nil, domainErrors.ErrPasskeyVerificationFailed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := user.GrantedScopes()
+	token, err := s.authService.GenerateToken(ctx, user, scopes, entities.AuthMethodPasskey)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLoginResponse(token, user, scopes), nil
+}
+
+func (s *passkeyService) ListPasskeys(ctx context.Context, userID entities.UserID) (*dto.PasskeysListResponse, error) {
+	passkeys, err := s.passkeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PasskeyResponse, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		responses = append(responses, *toPasskeyResponse(passkey))
+	}
+
+	return &dto.PasskeysListResponse{
+		Passkeys: responses,
+		Total:    len(responses),
+	}, nil
+}
+
+func (s *passkeyService) RenamePasskey(ctx context.Context, userID entities.UserID, passkeyID string, req *dto.RenamePasskeyRequest) (*dto.PasskeyResponse, error) {
+	passkey, err := s.getOwnPasskey(ctx, userID, passkeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	passkey.Rename(req.Name)
+	if err := s.passkeyRepo.Update(ctx, passkey); err != nil {
+		return nil, err
+	}
+
+	return toPasskeyResponse(passkey), nil
+}
+
+func (s *passkeyService) DeletePasskey(ctx context.Context, userID entities.UserID, passkeyID string) error {
+	if _, err := s.getOwnPasskey(ctx, userID, passkeyID); err != nil {
+		return err
+	}
+
+	return s.passkeyRepo.Delete(ctx, passkeyID)
+}
+
+// getOwnPasskey hides passkeys of other users as not found.
+func (s *passkeyService) getOwnPasskey(ctx context.Context, userID entities.UserID, passkeyID string) (*entities.Passkey, error) {
+	passkey, err := s.passkeyRepo.GetByID(ctx, passkeyID)
+	if err != nil {
+		return nil, err
+	}
+	if passkey.UserID != userID {
+		return nil, domainErrors.ErrPasskeyNotFound
+	}
+	return passkey, nil
+}
+
+// startCeremony stores a new challenge under its hash.
+func (s *passkeyService) startCeremony(ctx context.Context, ceremonyType string, userID entities.UserID) ([]byte, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+
+	ceremony := &entities.PasskeyCeremony{
+		ChallengeHash: hashOAuthSecret(base64.RawURLEncoding.EncodeToString(challenge)),
+		Type:          ceremonyType,
+		UserID:        userID,
+		ExpiresAt:     s.now().Add(webauthn.Timeout),
+	}
+	if err := s.ceremonyRepo.Create(ctx, ceremony); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// finishCeremony consumes the ceremony whose challenge the client signed, so
+// that a challenge cannot be answered twice.
+func (s *passkeyService) finishCeremony(ctx context.Context, ceremonyType string, clientDataJSON []byte) (*entities.PasskeyCeremony, []byte, error) {
+	clientData, err := webauthn.ParseClientData(clientDataJSON)
+	if err != nil {
+		return nil, nil, domainErrors.ErrInvalidPasskeyChallenge
+	}
+	challenge, err := clientData.DecodedChallenge()
+	if err != nil {
+		return nil, nil, domainErrors.ErrInvalidPasskeyChallenge
+	}
+
+	ceremony, err := s.ceremonyRepo.Consume(ctx, hashOAuthSecret(clientData.Challenge))
+	if err != nil {
+		return nil, nil, err
+	}
+	if ceremony.Type != ceremonyType || ceremony.IsExpired(s.now()) {
+		return nil, nil, domainErrors.ErrInvalidPasskeyChallenge
+	}
+
+	return ceremony, challenge, nil
+}
+
+func toPasskeyResponse(passkey *entities.Passkey) *dto.PasskeyResponse {
+	return &dto.PasskeyResponse{
+		ID:         passkey.ID,
+		Name:       passkey.Name,
+		Transports: passkey.Transports,
+		Synced:     passkey.BackupState,
+		LastUsedAt: passkey.LastUsedAt,
+		CreatedAt:  passkey.CreatedAt,
+	}
+}
+
+// userHandle returns the WebAuthn user handle of a user: the 12 bytes of an
+// ObjectID, as passkeys registered before user IDs were configurable have,
+// or the text of any other ID.
+func userHandle(id entities.UserID) []byte {
+	if len(id) == 24 {
+		if b, err := hex.DecodeString(id.String()); err == nil {
+			return b
+		}
+	}
+	return []byte(id)
+}
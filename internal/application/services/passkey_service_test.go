@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	"github.com/wonyus/backend-challenge/pkg/webauthn"
+	"github.com/wonyus/backend-challenge/pkg/webauthn/softauthn"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Passkey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPasskeyRepo := mock_repositories.NewMockPasskeyRepository(ctrl)
+	mockCeremonyRepo := mock_repositories.NewMockPasskeyCeremonyRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
+	relyingParty := &webauthn.RelyingParty{ID: "example.com", Name: "Example", Origins: []string{"https://example.com"}}
+	service := NewPasskeyService(mockPasskeyRepo, mockCeremonyRepo, mockUserRepo, jwtService, relyingParty)
+	ctx := context.Background()
+
+	user, _ := entities.NewUser("Test User", "test@example.com", "")
+	user.MFA.Enabled = true
+	authenticator := softauthn.New("https://example.com")
+
+	// expectCeremony stores the next ceremony and hands it back on Consume
+	expectCeremony := func() {
+		var stored *entities.PasskeyCeremony
+		mockCeremonyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, ceremony *entities.PasskeyCeremony) error {
+			stored = ceremony
+			return nil
+		}).Times(1)
+		mockCeremonyRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, challengeHash string) (*entities.PasskeyCeremony, error) {
+			if stored == nil || stored.ChallengeHash != challengeHash {
+				return nil, domainErrors.ErrInvalidPasskeyChallenge
+			}
+			return stored, nil
+		}).Times(1)
+	}
+	beginLogin := func(t *testing.T, authenticator *softauthn.Authenticator) *dto.PasskeyLoginRequest {
+		expectCeremony()
+		options, err := service.BeginLogin(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, options.PublicKey.AllowCredentials)
+		assert.Equal(t, "required", options.PublicKey.UserVerification)
+
+		assertion, err := authenticator.Get(options.PublicKey)
+		assert.NoError(t, err)
+		return &dto.PasskeyLoginRequest{Credential: *assertion}
+	}
+
+	var passkey *entities.Passkey
+
+	t.Run("Registers a passkey", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockPasskeyRepo.EXPECT().GetByUserID(gomock.Any(), user.ID).Return(nil, nil).Times(1)
+		expectCeremony()
+		mockPasskeyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, created *entities.Passkey) error {
+			passkey = created
+			return nil
+		}).Times(1)
+
+		options, err := service.BeginRegistration(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, userHandle(user.ID), []byte(options.PublicKey.User.ID))
+		assert.Equal(t, "none", options.PublicKey.Attestation)
+
+		credential, err := authenticator.Create(options.PublicKey)
+		assert.NoError(t, err)
+		response, err := service.FinishRegistration(ctx, user.ID, &dto.RegisterPasskeyRequest{Name: "Laptop", Credential: *credential})
+		assert.NoError(t, err)
+		assert.Equal(t, credential.ID, response.ID)
+		assert.Equal(t, "Laptop", passkey.Name)
+		assert.Equal(t, user.ID, passkey.UserID)
+	})
+
+	t.Run("Registration excludes existing passkeys", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockPasskeyRepo.EXPECT().GetByUserID(gomock.Any(), user.ID).Return([]*entities.Passkey{passkey}, nil).Times(1)
+		mockCeremonyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		options, err := service.BeginRegistration(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, options.PublicKey.ExcludeCredentials, 1)
+
+		_, err = authenticator.Create(options.PublicKey)
+		assert.ErrorIs(t, err, softauthn.ErrCredentialExcluded)
+	})
+
+	t.Run("Registration challenge belongs to the user", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockPasskeyRepo.EXPECT().GetByUserID(gomock.Any(), user.ID).Return(nil, nil).Times(1)
+		expectCeremony()
+
+		options, err := service.BeginRegistration(ctx, user.ID)
+		assert.NoError(t, err)
+		credential, err := softauthn.New("https://example.com").Create(options.PublicKey)
+		assert.NoError(t, err)
+
+		response, err := service.FinishRegistration(ctx, entities.NewUserID(), &dto.RegisterPasskeyRequest{Name: "Laptop", Credential: *credential})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidPasskeyChallenge)
+	})
+
+	t.Run("Registration rejects a login challenge", func(t *testing.T) {
+		expectCeremony()
+		options, err := service.BeginLogin(ctx)
+		assert.NoError(t, err)
+
+		credential, err := softauthn.New("https://example.com").Create(&webauthn.CreationOptions{
+			Challenge:    options.PublicKey.Challenge,
+			RelyingParty: webauthn.RelyingPartyEntity{ID: "example.com"},
+			User:         webauthn.UserEntity{ID: userHandle(user.ID)},
+			Parameters:   []webauthn.CredentialParameter{{Type: "public-key", Algorithm: webauthn.AlgES256}},
+		})
+		assert.NoError(t, err)
+
+		response, err := service.FinishRegistration(ctx, user.ID, &dto.RegisterPasskeyRequest{Name: "Laptop", Credential: *credential})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidPasskeyChallenge)
+	})
+
+	t.Run("Login issues a token without an MFA challenge", func(t *testing.T) {
+		req := beginLogin(t, authenticator)
+		mockPasskeyRepo.EXPECT().GetByID(gomock.Any(), passkey.ID).Return(passkey, nil).Times(1)
+		mockPasskeyRepo.EXPECT().Update(gomock.Any(), passkey).Return(nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+		response, err := service.FinishLogin(ctx, req)
+		assert.NoError(t, err)
+		assert.False(t, response.MFARequired)
+		assert.NotEmpty(t, response.Token)
+		assert.Equal(t, uint32(1), passkey.SignCount)
+		assert.NotNil(t, passkey.LastUsedAt)
+	})
+
+	t.Run("Login rejects a cloned authenticator", func(t *testing.T) {
+		clone := authenticator.Clone()
+		req := beginLogin(t, authenticator)
+		mockPasskeyRepo.EXPECT().GetByID(gomock.Any(), passkey.ID).Return(passkey, nil).Times(2)
+		mockPasskeyRepo.EXPECT().Update(gomock.Any(), passkey).Return(nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		_, err := service.FinishLogin(ctx, req)
+		assert.NoError(t, err)
+
+		req = beginLogin(t, clone)
+		response, err := service.FinishLogin(ctx, req)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrPasskeyCloned)
+	})
+
+	t.Run("Login answers a challenge once", func(t *testing.T) {
+		req := beginLogin(t, authenticator)
+		mockPasskeyRepo.EXPECT().GetByID(gomock.Any(), passkey.ID).Return(passkey, nil).Times(1)
+		mockPasskeyRepo.EXPECT().Update(gomock.Any(), passkey).Return(nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		_, err := service.FinishLogin(ctx, req)
+		assert.NoError(t, err)
+
+		mockCeremonyRepo.EXPECT().Consume(gomock.Any(), gomock.Any()).Return(nil, domainErrors.ErrInvalidPasskeyChallenge).Times(1)
+		response, err := service.FinishLogin(ctx, req)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidPasskeyChallenge)
+	})
+
+	t.Run("Login with an unknown passkey", func(t *testing.T) {
+		stranger := softauthn.New("https://example.com")
+		_, err := stranger.Create(&webauthn.CreationOptions{
+			Challenge:    []byte("challenge"),
+			RelyingParty: webauthn.RelyingPartyEntity{ID: "example.com"},
+			User:         webauthn.UserEntity{ID: []byte("someone")},
+			Parameters:   []webauthn.CredentialParameter{{Type: "public-key", Algorithm: webauthn.AlgES256}},
+		})
+		assert.NoError(t, err)
+
+		req := beginLogin(t, stranger)
+		mockPasskeyRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, domainErrors.ErrPasskeyNotFound).Times(1)
+
+		response, err := service.FinishLogin(ctx, req)
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrPasskeyVerificationFailed)
+	})
+
+	t.Run("Other users' passkeys are not found", func(t *testing.T) {
+		other := entities.NewUserID()
+		mockPasskeyRepo.EXPECT().GetByID(gomock.Any(), passkey.ID).Return(passkey, nil).Times(2)
+
+		response, err := service.RenamePasskey(ctx, other, passkey.ID, &dto.RenamePasskeyRequest{Name: "Mine"})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrPasskeyNotFound)
+		assert.ErrorIs(t, service.DeletePasskey(ctx, other, passkey.ID), domainErrors.ErrPasskeyNotFound)
+	})
+
+	t.Run("Renames and deletes a passkey", func(t *testing.T) {
+		mockPasskeyRepo.EXPECT().GetByID(gomock.Any(), passkey.ID).Return(passkey, nil).Times(2)
+		mockPasskeyRepo.EXPECT().Update(gomock.Any(), passkey).Return(nil).Times(1)
+		mockPasskeyRepo.EXPECT().Delete(gomock.Any(), passkey.ID).Return(nil).Times(1)
+
+		response, err := service.RenamePasskey(ctx, user.ID, passkey.ID, &dto.RenamePasskeyRequest{Name: "Phone"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Phone", response.Name)
+		assert.NoError(t, service.DeletePasskey(ctx, user.ID, passkey.ID))
+	})
+}
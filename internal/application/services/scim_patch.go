@@ -0,0 +1,143 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/pkg/scim"
+)
+
+// applySCIMPatch applies one PATCH operation to a user representation.
+// Users have a single name, so displayName and the name attributes all set it.
+// emails mirror userName and changes to them are ignored.
+func applySCIMPatch(user *dto.SCIMUser, operation dto.SCIMPatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	switch op {
+	case "add", "replace":
+		if operation.Path != "" {
+			return setSCIMAttribute(user, operation.Path, operation.Value)
+		}
+
+		// Without a path the value is an object of attributes
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+			return fmt.Errorf("%w: value must be an object when there is no path", domainErrors.ErrInvalidSCIMValue)
+		}
+		for path, value := range attributes {
+			if err := setSCIMAttribute(user, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "remove":
+		if operation.Path == "" {
+			return fmt.Errorf("%w: remove requires a path", domainErrors.ErrInvalidSCIMPath)
+		}
+		return removeSCIMAttribute(user, operation.Path)
+	default:
+		return fmt.Errorf("%w: unknown operation %q", domainErrors.ErrInvalidSCIMValue, operation.Op)
+	}
+}
+
+func setSCIMAttribute(user *dto.SCIMUser, path string, value json.RawMessage) error {
+	var err error
+	switch attribute := strings.ToLower(scim.AttributePath(path)); {
+	case attribute == "username":
+		user.UserName, err = scimString(value)
+	case attribute == "displayname":
+		user.DisplayName, err = scimString(value)
+		user.Name = nil
+	case attribute == "name":
+		var name dto.SCIMName
+		if err = json.Unmarshal(value, &name); err == nil {
+			user.Name = &name
+		}
+	case attribute == "name.formatted":
+		user.Name = scimNameOf(user)
+		user.Name.Formatted, err = scimString(value)
+	case attribute == "name.givenname":
+		user.Name = scimNameOf(user)
+		user.Name.GivenName, err = scimString(value)
+		user.Name.Formatted = ""
+	case attribute == "name.familyname":
+		user.Name = scimNameOf(user)
+		user.Name.FamilyName, err = scimString(value)
+		user.Name.Formatted = ""
+	case attribute == "externalid":
+		user.ExternalID, err = scimString(value)
+	case attribute == "active":
+		var active bool
+		if active, err = scimBool(value); err == nil {
+			user.Active = &active
+		}
+	case attribute == "emails" || strings.HasPrefix(attribute, "emails[") || strings.HasPrefix(attribute, "emails."):
+		return nil
+	case attribute == "id" || attribute == "meta" || strings.HasPrefix(attribute, "meta.") || attribute == "schemas":
+		return fmt.Errorf("%w: %s", domainErrors.ErrSCIMReadOnly, path)
+	case attribute == "password":
+		return fmt.Errorf("%w: password changes are not supported", domainErrors.ErrSCIMReadOnly)
+	default:
+		return fmt.Errorf("%w: %s", domainErrors.ErrInvalidSCIMPath, path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s", domainErrors.ErrInvalidSCIMValue, path)
+	}
+	return nil
+}
+
+func removeSCIMAttribute(user *dto.SCIMUser, path string) error {
+	switch attribute := strings.ToLower(scim.AttributePath(path)); {
+	case attribute == "externalid":
+		user.ExternalID = ""
+	case attribute == "displayname":
+		user.DisplayName = ""
+	case attribute == "active":
+		user.Active = nil
+	case attribute == "emails" || strings.HasPrefix(attribute, "emails[") || strings.HasPrefix(attribute, "emails."):
+	case attribute == "username" || attribute == "name" || strings.HasPrefix(attribute, "name."):
+		return fmt.Errorf("%w: %s is required", domainErrors.ErrInvalidSCIMValue, path)
+	case attribute == "id" || attribute == "meta" || strings.HasPrefix(attribute, "meta.") || attribute == "schemas":
+		return fmt.Errorf("%w: %s", domainErrors.ErrSCIMReadOnly, path)
+	default:
+		return fmt.Errorf("%w: %s", domainErrors.ErrInvalidSCIMPath, path)
+	}
+	return nil
+}
+
+func scimNameOf(user *dto.SCIMUser) *dto.SCIMName {
+	if user.Name == nil {
+		return &dto.SCIMName{}
+	}
+	return user.Name
+}
+
+func scimString(value json.RawMessage) (string, error) {
+	var s string
+	err := json.Unmarshal(value, &s)
+	return s, err
+}
+
+// scimBool accepts JSON booleans and the strings "True" and "False", which
+// some provisioning clients send.
+func scimBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	s, err := scimString(value)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(s) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", s)
+}
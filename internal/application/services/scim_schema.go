@@ -0,0 +1,111 @@
+package services
+
+import (
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+// scimUserAttributes describes the User attributes this service supports.
+var scimUserAttributes = []dto.SCIMSchemaAttribute{
+	{Name: "userName", Type: "string", Required: true, Mutability: "readWrite", Returned: "default", Uniqueness: "server",
+		Description: "The user's email address, used to sign in."},
+	{Name: "name", Type: "complex", Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+		Description: "The user's name. Users have a single name, formatted from the sub-attributes when not given.",
+		SubAttributes: []dto.SCIMSchemaAttribute{
+			{Name: "formatted", Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+			{Name: "givenName", Type: "string", Mutability: "writeOnly", Returned: "never", Uniqueness: "none"},
+			{Name: "familyName", Type: "string", Mutability: "writeOnly", Returned: "never", Uniqueness: "none"},
+		}},
+	{Name: "displayName", Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+		Description: "Same as name.formatted."},
+	{Name: "emails", Type: "complex", MultiValued: true, Mutability: "readOnly", Returned: "default", Uniqueness: "none",
+		Description: "Mirrors userName.",
+		SubAttributes: []dto.SCIMSchemaAttribute{
+			{Name: "value", Type: "string", Mutability: "readOnly", Returned: "default", Uniqueness: "none"},
+			{Name: "type", Type: "string", Mutability: "readOnly", Returned: "default", Uniqueness: "none"},
+			{Name: "primary", Type: "boolean", Mutability: "readOnly", Returned: "default", Uniqueness: "none"},
+		}},
+	{Name: "active", Type: "boolean", Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+		Description: "Inactive users cannot sign in, and their tokens and API keys stop working."},
+	{Name: "password", Type: "string", Mutability: "writeOnly", Returned: "never", Uniqueness: "none",
+		Description: "Initial password. Users created without one sign in through an identity provider."},
+}
+
+func (s *scimService) ServiceProviderConfig() *dto.SCIMServiceProviderConfig {
+	return &dto.SCIMServiceProviderConfig{
+		Schemas:        []string{dto.SCIMServiceProviderConfigSchema},
+		Patch:          dto.SCIMSupported{Supported: true},
+		Bulk:           dto.SCIMBulkSupported{Supported: false},
+		Filter:         dto.SCIMFilterSupported{Supported: true, MaxResults: scimMaxResults},
+		ChangePassword: dto.SCIMSupported{Supported: false},
+		Sort:           dto.SCIMSupported{Supported: false},
+		ETag:           dto.SCIMSupported{Supported: false},
+		AuthenticationSchemes: []dto.SCIMAuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "The dedicated SCIM token, or an access token or API key with the scim:provision scope",
+			Primary:     true,
+		}},
+		Meta: &dto.SCIMMeta{
+			ResourceType: "ServiceProviderConfig",
+			Location:     s.baseURL + "/ServiceProviderConfig",
+		},
+	}
+}
+
+func (s *scimService) ResourceTypes() *dto.SCIMListResponse {
+	resourceType, _ := s.ResourceType("User")
+	return &dto.SCIMListResponse{
+		Schemas:      []string{dto.SCIMListResponseSchema},
+		TotalResults: 1,
+		StartIndex:   1,
+		ItemsPerPage: 1,
+		Resources:    []*dto.SCIMResourceType{resourceType},
+	}
+}
+
+func (s *scimService) ResourceType(id string) (*dto.SCIMResourceType, error) {
+	if id != "User" {
+		return nil, domainErrors.ErrSCIMResourceNotFound
+	}
+	return &dto.SCIMResourceType{
+		Schemas:     []string{dto.SCIMResourceTypeSchema},
+		ID:          "User",
+		Name:        "User",
+		Endpoint:    "/Users",
+		Description: "User Account",
+		Schema:      dto.SCIMUserSchema,
+		Meta: &dto.SCIMMeta{
+			ResourceType: "ResourceType",
+			Location:     s.baseURL + "/ResourceTypes/User",
+		},
+	}, nil
+}
+
+func (s *scimService) Schemas() *dto.SCIMListResponse {
+	schema, _ := s.Schema(dto.SCIMUserSchema)
+	return &dto.SCIMListResponse{
+		Schemas:      []string{dto.SCIMListResponseSchema},
+		TotalResults: 1,
+		StartIndex:   1,
+		ItemsPerPage: 1,
+		Resources:    []*dto.SCIMSchema{schema},
+	}
+}
+
+func (s *scimService) Schema(id string) (*dto.SCIMSchema, error) {
+	if id != dto.SCIMUserSchema {
+		return nil, domainErrors.ErrSCIMResourceNotFound
+	}
+	return &dto.SCIMSchema{
+		Schemas:     []string{dto.SCIMSchemaSchema},
+		ID:          dto.SCIMUserSchema,
+		Name:        "User",
+		Description: "User Account",
+		Attributes:  scimUserAttributes,
+		Meta: &dto.SCIMMeta{
+			ResourceType: "Schema",
+			Location:     s.baseURL + "/Schemas/" + dto.SCIMUserSchema,
+		},
+	}, nil
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/pkg/scim"
+	"github.com/wonyus/backend-challenge/pkg/validator"
+)
+
+// scimMaxResults caps the page size of list requests.
+const scimMaxResults = 100
+
+// scimService maps SCIM users onto entities.User. Account lifecycle goes
+// through the user service; the SCIM-only attributes externalId and active
+// are stored on the entity directly.
+type scimService struct {
+	userService ports.UserService
+	userRepo    repositories.UserRepository
+	validator   *validator.Validator
+	baseURL     string
+}
+
+// NewSCIMService creates the SCIM service. baseURL is the public URL of this
+// service and is used for resource locations.
+func NewSCIMService(userService ports.UserService, userRepo repositories.UserRepository, baseURL string) ports.SCIMService {
+	return &scimService{
+		userService: userService,
+		userRepo:    userRepo,
+		validator:   validator.New(),
+		baseURL:     strings.TrimSuffix(baseURL, "/") + "/scim/v2",
+	}
+}
+
+func (s *scimService) CreateUser(ctx context.Context, req *dto.SCIMUser) (*dto.SCIMUser, error) {
+	name, err := s.validateUser(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *dto.UserResponse
+	if req.Password != "" {
+		createReq := &dto.CreateUserRequest{Name: name, Email: req.UserName, Password: req.Password}
+		if err := s.validator.Validate(createReq); err != nil {
+			return nil, fmt.Errorf("%w: %v", domainErrors.ErrInvalidSCIMValue, err)
+		}
+		created, err = s.userService.CreateUser(ctx, createReq)
+	} else {
+		created, err = s.userService.ProvisionUser(ctx, &dto.ProvisionUserRequest{Name: name, Email: req.UserName})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, created.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.updateSCIMAttributes(ctx, user, req); err != nil {
+		return nil, err
+	}
+
+	return s.toSCIMUser(user), nil
+}
+
+func (s *scimService) GetUser(ctx context.Context, id string) (*dto.SCIMUser, error) {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toSCIMUser(user), nil
+}
+
+func (s *scimService) ListUsers(ctx context.Context, query *dto.SCIMListQuery) (*dto.SCIMListResponse, error) {
+	startIndex := query.StartIndex
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := scimMaxResults
+	if query.Count != nil && *query.Count < count {
+		count = max(*query.Count, 0)
+	}
+	resources := make([]*dto.SCIMUser, 0, count)
+	response := &dto.SCIMListResponse{
+		Schemas:    []string{dto.SCIMListResponseSchema},
+		StartIndex: startIndex,
+		Resources:  resources,
+	}
+
+	var userQuery repositories.UserQuery
+	if query.Filter != "" {
+		filter, err := scim.ParseFilter(query.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domainErrors.ErrInvalidSCIMFilter, err)
+		}
+		var matchesAny bool
+		if userQuery, matchesAny, err = scimUserQuery(filter); err != nil {
+			return nil, err
+		}
+		if !matchesAny {
+			return response, nil
+		}
+	}
+
+	// The zero query orders users oldest first, so pages are stable. A Limit
+	// of 0 would return every user, so a count of 0 still reads one.
+	userQuery.Offset = startIndex - 1
+	userQuery.Limit = max(count, 1)
+	users, total, err := s.userRepo.Find(ctx, userQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users[:min(count, len(users))] {
+		resources = append(resources, s.toSCIMUser(user))
+	}
+	response.TotalResults = int(total)
+	response.ItemsPerPage = len(resources)
+	response.Resources = resources
+	return response, nil
+}
+
+func (s *scimService) ReplaceUser(ctx context.Context, id string, req *dto.SCIMUser) (*dto.SCIMUser, error) {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.replaceUser(ctx, user, req)
+}
+
+func (s *scimService) PatchUser(ctx context.Context, id string, req *dto.SCIMPatchRequest) (*dto.SCIMUser, error) {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations", domainErrors.ErrInvalidSCIMValue)
+	}
+
+	// Operations apply to the current representation, which then replaces
+	// the user as a whole
+	patched := s.toSCIMUser(user)
+	for _, operation := range req.Operations {
+		if err := applySCIMPatch(patched, operation); err != nil {
+			return nil, err
+		}
+	}
+	return s.replaceUser(ctx, user, patched)
+}
+
+func (s *scimService) DeleteUser(ctx context.Context, id string) error {
+	userID, err := entities.ParseUserID(id)
+	if err != nil {
+		return domainErrors.ErrUserNotFound
+	}
+	return s.userService.DeleteUser(ctx, userID)
+}
+
+func (s *scimService) replaceUser(ctx context.Context, user *entities.User, req *dto.SCIMUser) (*dto.SCIMUser, error) {
+	name, err := s.validateUser(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Password != "" {
+		return nil, fmt.Errorf("%w: password changes are not supported", domainErrors.ErrSCIMReadOnly)
+	}
+
+	if _, err := s.userService.UpdateUser(ctx, user.ID, &dto.UpdateUserRequest{Name: name, Email: req.UserName}); err != nil {
+		return nil, err
+	}
+
+	user, err = s.userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.updateSCIMAttributes(ctx, user, req); err != nil {
+		return nil, err
+	}
+
+	return s.toSCIMUser(user), nil
+}
+
+// updateSCIMAttributes stores externalId and active, which the user service
+// does not manage. A missing active means active.
+func (s *scimService) updateSCIMAttributes(ctx context.Context, user *entities.User, req *dto.SCIMUser) error {
+	active := req.Active == nil || *req.Active
+	if user.ExternalID == req.ExternalID && user.IsActive() == active {
+		return nil
+	}
+
+	user.UpdateExternalID(req.ExternalID)
+	user.SetActive(active)
+	return s.userRepo.Update(ctx, user)
+}
+
+// validateUser checks the attributes the user service needs and returns the
+// name to store.
+func (s *scimService) validateUser(req *dto.SCIMUser) (string, error) {
+	name := scimUserName(req)
+	if err := s.validator.Validate(&dto.UpdateUserRequest{Name: name, Email: req.UserName}); err != nil || req.UserName == "" {
+		return "", fmt.Errorf("%w: userName must be an email address and name at least 2 characters", domainErrors.ErrInvalidSCIMValue)
+	}
+	return name, nil
+}
+
+func (s *scimService) getUser(ctx context.Context, id string) (*entities.User, error) {
+	userID, err := entities.ParseUserID(id)
+	if err != nil {
+		return nil, domainErrors.ErrUserNotFound
+	}
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+func (s *scimService) toSCIMUser(user *entities.User) *dto.SCIMUser {
+	active := user.IsActive()
+	created, lastModified := user.CreatedAt.UTC(), user.UpdatedAt.UTC()
+	return &dto.SCIMUser{
+		Schemas:     []string{dto.SCIMUserSchema},
+		ID:          user.ID.String(),
+		ExternalID:  user.ExternalID,
+		UserName:    user.Email,
+		Name:        &dto.SCIMName{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []dto.SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &dto.SCIMMeta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &lastModified,
+			Location:     s.baseURL + "/Users/" + user.ID.String(),
+		},
+	}
+}
+
+// scimUserName picks the single name a user has from the SCIM name
+// attributes, falling back to the local part of the email.
+func scimUserName(req *dto.SCIMUser) string {
+	if req.Name != nil {
+		if req.Name.Formatted != "" {
+			return req.Name.Formatted
+		}
+		if name := strings.TrimSpace(req.Name.GivenName + " " + req.Name.FamilyName); name != "" {
+			return name
+		}
+	}
+	if req.DisplayName != "" {
+		return req.DisplayName
+	}
+	name, _, _ := strings.Cut(req.UserName, "@")
+	return name
+}
+
+// scimUserQuery translates a filter into the query selecting the users it
+// matches, so the repository filters rather than this service. Only "eq" on
+// userName, emails or externalId, joined by "and", is supported; other
+// filters fail with ErrInvalidSCIMFilter. matchesAny is false when the filter
+// compares an attribute with two different values, or with "".
+func scimUserQuery(filter scim.Filter) (query repositories.UserQuery, matchesAny bool, err error) {
+	comparisons, ok := scim.Comparisons(filter)
+	if !ok {
+		return query, false, fmt.Errorf("%w: only eq comparisons joined by and are supported", domainErrors.ErrInvalidSCIMFilter)
+	}
+
+	for _, comparison := range comparisons {
+		if comparison.Operator != "eq" {
+			return query, false, fmt.Errorf("%w: unsupported operator %q", domainErrors.ErrInvalidSCIMFilter, comparison.Operator)
+		}
+
+		// No user has an empty email, and an empty externalId is no externalId
+		if comparison.Value == "" {
+			return query, false, nil
+		}
+
+		switch strings.ToLower(comparison.Path) {
+		case "username", "emails", "emails.value":
+			// userName is case-insensitive, as emails are under the email policy
+			if query.Email != "" && !strings.EqualFold(query.Email, comparison.Value) {
+				return query, false, nil
+			}
+			query.Email = comparison.Value
+		case "externalid":
+			if query.ExternalID != "" && query.ExternalID != comparison.Value {
+				return query, false, nil
+			}
+			query.ExternalID = comparison.Value
+		default:
+			return query, false, fmt.Errorf("%w: unsupported attribute %q", domainErrors.ErrInvalidSCIMFilter, comparison.Path)
+		}
+	}
+	return query, true, nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_SCIM(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mock_ports.NewMockUserService(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	service := NewSCIMService(mockUserService, mockUserRepo, "https://id.example.com/")
+	ctx := context.Background()
+
+	newUser := func() *entities.User {
+		return &entities.User{ID: entities.NewUserID(), Name: "Barbara Jensen", Email: "bjensen@example.com", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	}
+	patch := func(operations ...dto.SCIMPatchOperation) *dto.SCIMPatchRequest {
+		return &dto.SCIMPatchRequest{Schemas: []string{dto.SCIMPatchOpSchema}, Operations: operations}
+	}
+
+	t.Run("Create without password provisions a passwordless user", func(t *testing.T) {
+		user := newUser()
+		mockUserService.EXPECT().ProvisionUser(gomock.Any(), &dto.ProvisionUserRequest{Name: "Barbara Jensen", Email: "bjensen@example.com"}).
+			Return(&dto.UserResponse{ID: user.ID}, nil).Times(1)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+
+		response, err := service.CreateUser(ctx, &dto.SCIMUser{
+			UserName:   "bjensen@example.com",
+			ExternalID: "E-1",
+			Name:       &dto.SCIMName{GivenName: "Barbara", FamilyName: "Jensen"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "E-1", response.ExternalID)
+		assert.Equal(t, "https://id.example.com/scim/v2/Users/"+user.ID.String(), response.Meta.Location)
+		assert.True(t, *response.Active)
+	})
+
+	t.Run("Create requires an email userName", func(t *testing.T) {
+		response, err := service.CreateUser(ctx, &dto.SCIMUser{UserName: "bjensen"})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidSCIMValue)
+	})
+
+	t.Run("Create with a short password", func(t *testing.T) {
+		response, err := service.CreateUser(ctx, &dto.SCIMUser{UserName: "bjensen@example.com", Password: "short"})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidSCIMValue)
+	})
+
+	t.Run("Patch name parts replaces the name", func(t *testing.T) {
+		user := newUser()
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(2)
+		mockUserService.EXPECT().UpdateUser(gomock.Any(), user.ID, &dto.UpdateUserRequest{Name: "Babs Jensen", Email: "bjensen@example.com"}).
+			Return(&dto.UserResponse{ID: user.ID}, nil).Times(1)
+
+		_, err := service.PatchUser(ctx, user.ID.String(), patch(
+			dto.SCIMPatchOperation{Op: "replace", Path: "name.givenName", Value: json.RawMessage(`"Babs"`)},
+			dto.SCIMPatchOperation{Op: "replace", Path: "name.familyName", Value: json.RawMessage(`"Jensen"`)},
+		))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Patch active deactivates", func(t *testing.T) {
+		user := newUser()
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(2)
+		mockUserService.EXPECT().UpdateUser(gomock.Any(), user.ID, gomock.Any()).Return(&dto.UserResponse{ID: user.ID}, nil).Times(1)
+		mockUserRepo.EXPECT().Update(gomock.Any(), user).Return(nil).Times(1)
+
+		response, err := service.PatchUser(ctx, user.ID.String(), patch(
+			dto.SCIMPatchOperation{Op: "replace", Path: "urn:ietf:params:scim:schemas:core:2.0:User:active", Value: json.RawMessage(`false`)},
+		))
+		assert.NoError(t, err)
+		assert.False(t, *response.Active)
+		assert.False(t, user.IsActive())
+	})
+
+	t.Run("Patch errors", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			operation dto.SCIMPatchOperation
+			err       error
+		}{
+			{"remove required userName", dto.SCIMPatchOperation{Op: "remove", Path: "userName"}, domainErrors.ErrInvalidSCIMValue},
+			{"unknown attribute", dto.SCIMPatchOperation{Op: "add", Path: "nickName", Value: json.RawMessage(`"Babs"`)}, domainErrors.ErrInvalidSCIMPath},
+			{"read-only attribute", dto.SCIMPatchOperation{Op: "replace", Path: "id", Value: json.RawMessage(`"x"`)}, domainErrors.ErrSCIMReadOnly},
+			{"wrong value type", dto.SCIMPatchOperation{Op: "replace", Path: "active", Value: json.RawMessage(`"maybe"`)}, domainErrors.ErrInvalidSCIMValue},
+			{"unknown operation", dto.SCIMPatchOperation{Op: "move", Path: "active"}, domainErrors.ErrInvalidSCIMValue},
+			{"remove without path", dto.SCIMPatchOperation{Op: "remove"}, domainErrors.ErrInvalidSCIMPath},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				user := newUser()
+				mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+
+				response, err := service.PatchUser(ctx, user.ID.String(), patch(tt.operation))
+				assert.Nil(t, response)
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+	})
+
+	t.Run("List with invalid filter", func(t *testing.T) {
+		response, err := service.ListUsers(ctx, &dto.SCIMListQuery{Filter: `userName xx "a"`})
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidSCIMFilter)
+	})
+
+	t.Run("List with zero count only counts", func(t *testing.T) {
+		count := 0
+		mockUserRepo.EXPECT().Find(gomock.Any(), repositories.UserQuery{Limit: 1}).Return([]*entities.User{newUser()}, int64(2), nil).Times(1)
+
+		response, err := service.ListUsers(ctx, &dto.SCIMListQuery{Count: &count})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, response.TotalResults)
+		assert.Equal(t, 0, response.ItemsPerPage)
+		assert.Empty(t, response.Resources)
+	})
+
+	t.Run("List translates the filter and page into a query", func(t *testing.T) {
+		count := 10
+		user := newUser()
+		mockUserRepo.EXPECT().Find(gomock.Any(), repositories.UserQuery{Email: "BJensen@example.com", ExternalID: "E-1", Offset: 4, Limit: 10}).
+			Return([]*entities.User{user}, int64(5), nil).Times(1)
+
+		response, err := service.ListUsers(ctx, &dto.SCIMListQuery{
+			Filter:     `userName eq "BJensen@example.com" and urn:ietf:params:scim:schemas:core:2.0:User:externalId eq "E-1"`,
+			StartIndex: 5,
+			Count:      &count,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, response.TotalResults)
+		assert.Equal(t, 5, response.StartIndex)
+		assert.Equal(t, 1, response.ItemsPerPage)
+		assert.Equal(t, user.ID.String(), response.Resources.([]*dto.SCIMUser)[0].ID)
+	})
+
+	t.Run("List with contradicting filter matches nothing", func(t *testing.T) {
+		response, err := service.ListUsers(ctx, &dto.SCIMListQuery{Filter: `userName eq "a@example.com" and emails.value eq "b@example.com"`})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, response.TotalResults)
+		assert.Empty(t, response.Resources)
+	})
+
+	t.Run("List with unsupported filter", func(t *testing.T) {
+		for _, filter := range []string{
+			`userName eq "a@example.com" or userName eq "b@example.com"`,
+			`userName sw "a"`,
+			`displayName eq "Barbara Jensen"`,
+			`externalId pr`,
+		} {
+			response, err := service.ListUsers(ctx, &dto.SCIMListQuery{Filter: filter})
+			assert.Nil(t, response, filter)
+			assert.ErrorIs(t, err, domainErrors.ErrInvalidSCIMFilter, filter)
+		}
+	})
+
+	t.Run("Unknown id", func(t *testing.T) {
+		response, err := service.GetUser(ctx, "not-an-id")
+		assert.Nil(t, response)
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+		assert.ErrorIs(t, service.DeleteUser(ctx, "not-an-id"), domainErrors.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+// grantableScopes checks that every requested scope is known and covered by
+// granted, dropping duplicates. An empty request yields nil.
+func grantableScopes(granted, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(requested))
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if !entities.IsKnownScope(scope) || !entities.CanGrant(granted, scope) {
+			return nil, domainErrors.ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+// delegableScopes checks the scopes requested for a credential created by
+// caller, who may only pass on the scopes it holds. An empty request yields
+// the scopes of the caller, or nil, standing for all of the user's, when the
+// caller holds them all; a restricted caller cannot create an unrestricted
+// credential.
+func delegableScopes(user *entities.User, caller *dto.Principal, requested []string) ([]string, error) {
+	held := entities.RestrictScopes(user.GrantedScopes(), caller.Scopes)
+	if len(requested) > 0 {
+		return grantableScopes(held, requested)
+	}
+
+	all := user.GrantedScopes()
+	if len(entities.RestrictScopes(held, all)) == len(all) {
+		return nil, nil
+	}
+	if len(held) == 0 {
+		return nil, domainErrors.ErrInvalidScope
+	}
+	return held, nil
+}
+
+// effectiveScopes narrows the scopes carried by a token or API key to what
+// the user is granted now, so taking a scope away from a user also applies
+// to credentials issued earlier. Nil scopes, of API keys created without any
+// and of tokens issued before scopes were recorded, stand for all granted
+// ones; an empty list stands for none.
+func effectiveScopes(user *entities.User, scopes []string) []string {
+	granted := user.GrantedScopes()
+	if scopes == nil {
+		return granted
+	}
+	return entities.RestrictScopes(granted, scopes)
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type sessionService struct {
+	sessionRepo repositories.SessionRepository
+	userRepo    repositories.UserRepository
+	now         func() time.Time
+}
+
+func NewSessionService(sessionRepo repositories.SessionRepository, userRepo repositories.UserRepository) ports.SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		now:         time.Now,
+	}
+}
+
+func (s *sessionService) ListSessions(ctx context.Context, userID entities.UserID, currentSessionID string) (*dto.SessionsListResponse, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	responses := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.IsActive(now) {
+			continue
+		}
+		response := toSessionResponse(session)
+		response.Current = session.ID.String() == currentSessionID
+		responses = append(responses, *response)
+	}
+
+	// Most recently used devices first
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].LastSeenAt.After(responses[j].LastSeenAt)
+	})
+
+	return &dto.SessionsListResponse{
+		Sessions: responses,
+		Total:    len(responses),
+	}, nil
+}
+
+func (s *sessionService) RevokeSession(ctx context.Context, userID entities.UserID, sessionID string) error {
+	session, err := s.getOwnSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if !session.IsActive(now) {
+		return domainErrors.ErrSessionNotFound
+	}
+
+	session.Revoke(now)
+	return s.sessionRepo.Update(ctx, session)
+}
+
+// getOwnSession hides sessions of other users as not found. Sessions are not
+// scoped by tenant, so the user is looked up in the caller's tenant first.
+func (s *sessionService) getOwnSession(ctx context.Context, userID entities.UserID, sessionID string) (*entities.Session, error) {
+	id, err := entities.ParseID(sessionID)
+	if err != nil {
+		return nil, domainErrors.ErrSessionNotFound
+	}
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, domainErrors.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func toSessionResponse(session *entities.Session) *dto.SessionResponse {
+	return &dto.SessionResponse{
+		ID:         session.ID.String(),
+		AuthMethod: session.AuthMethod,
+		IPAddress:  session.IPAddress,
+		UserAgent:  session.UserAgent,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Session(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessionRepo := mock_repositories.NewMockSessionRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	service := NewSessionService(mockSessionRepo, mockUserRepo)
+	ctx := context.Background()
+
+	user, _ := entities.NewUser("Test User", "test@example.com", "hashed")
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	laptop := entities.NewSession(user.ID, entities.AuthMethodPassword, "10.0.0.1", "Laptop", expiresAt)
+	laptop.LastSeenAt = now.Add(-time.Hour)
+	phone := entities.NewSession(user.ID, entities.AuthMethodPasskey, "10.0.0.2", "Phone", expiresAt)
+	expired := entities.NewSession(user.ID, entities.AuthMethodPassword, "10.0.0.3", "Old", now.Add(-time.Minute))
+	revoked := entities.NewSession(user.ID, entities.AuthMethodPassword, "10.0.0.4", "Gone", expiresAt)
+	revoked.Revoke(now)
+
+	t.Run("Lists active sessions, most recently used first", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockSessionRepo.EXPECT().GetByUserID(gomock.Any(), user.ID).Return([]*entities.Session{laptop, expired, phone, revoked}, nil).Times(1)
+
+		response, err := service.ListSessions(ctx, user.ID, laptop.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, response.Total)
+		assert.Equal(t, phone.ID.String(), response.Sessions[0].ID)
+		assert.Equal(t, entities.AuthMethodPasskey, response.Sessions[0].AuthMethod)
+		assert.False(t, response.Sessions[0].Current)
+		assert.Equal(t, laptop.ID.String(), response.Sessions[1].ID)
+		assert.True(t, response.Sessions[1].Current)
+	})
+
+	t.Run("Lists sessions of an unknown user", func(t *testing.T) {
+		unknownID := entities.NewUserID()
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), unknownID).Return(nil, domainErrors.ErrUserNotFound).Times(1)
+
+		_, err := service.ListSessions(ctx, unknownID, "")
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+	})
+
+	t.Run("Revokes a session", func(t *testing.T) {
+		session := entities.NewSession(user.ID, entities.AuthMethodPassword, "10.0.0.1", "Laptop", expiresAt)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockSessionRepo.EXPECT().GetByID(gomock.Any(), session.ID).Return(session, nil).Times(1)
+		mockSessionRepo.EXPECT().Update(gomock.Any(), session).Return(nil).Times(1)
+
+		err := service.RevokeSession(ctx, user.ID, session.ID.String())
+		assert.NoError(t, err)
+		assert.NotNil(t, session.RevokedAt)
+		assert.False(t, session.IsActive(time.Now()))
+	})
+
+	t.Run("Hides sessions of other users", func(t *testing.T) {
+		other := entities.NewSession(entities.NewUserID(), entities.AuthMethodPassword, "10.0.0.9", "Other", expiresAt)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockSessionRepo.EXPECT().GetByID(gomock.Any(), other.ID).Return(other, nil).Times(1)
+
+		err := service.RevokeSession(ctx, user.ID, other.ID.String())
+		assert.ErrorIs(t, err, domainErrors.ErrSessionNotFound)
+		assert.Nil(t, other.RevokedAt)
+	})
+
+	t.Run("Does not revoke a session twice", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, nil).Times(1)
+		mockSessionRepo.EXPECT().GetByID(gomock.Any(), revoked.ID).Return(revoked, nil).Times(1)
+
+		err := service.RevokeSession(ctx, user.ID, revoked.ID.String())
+		assert.ErrorIs(t, err, domainErrors.ErrSessionNotFound)
+	})
+
+	t.Run("Does not revoke sessions of users outside the tenant", func(t *testing.T) {
+		unknownID := entities.NewUserID()
+		outsider := entities.NewSession(unknownID, entities.AuthMethodPassword, "10.0.0.9", "Other", expiresAt)
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), unknownID).Return(nil, domainErrors.ErrUserNotFound).Times(1)
+
+		err := service.RevokeSession(ctx, unknownID, outsider.ID.String())
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+		assert.Nil(t, outsider.RevokedAt)
+	})
+
+	t.Run("Rejects a malformed session ID", func(t *testing.T) {
+		err := service.RevokeSession(ctx, user.ID, "not-an-id")
+		assert.ErrorIs(t, err, domainErrors.ErrSessionNotFound)
+	})
+}
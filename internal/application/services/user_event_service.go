@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+type userEventService struct {
+	feed domainServices.UserChangeFeed
+	// closed ends when Close is called, and every watch with it
+	closed context.Context
+	close  context.CancelFunc
+}
+
+func NewUserEventService(feed domainServices.UserChangeFeed) ports.UserEventService {
+	closed, cancel := context.WithCancel(context.Background())
+	return &userEventService{feed: feed, closed: closed, close: cancel}
+}
+
+func (s *userEventService) WatchUsers(ctx context.Context, req *dto.WatchUsersRequest, send func(*dto.UserEvent) error) error {
+	watch := domainServices.UserWatch{
+		After:    req.ResumeAfter,
+		Consumer: req.Consumer,
+	}
+	for _, name := range req.Types {
+		eventType := entities.UserEventType(name)
+		if !eventType.IsValid() {
+			return domainErrors.ErrInvalidUserEventType
+		}
+		watch.Types = append(watch.Types, eventType)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(s.closed, cancel)()
+
+	return s.feed.Watch(ctx, watch, func(event *entities.UserEvent) error {
+		response := &dto.UserEvent{
+			ID:         event.ID,
+			Type:       string(event.Type),
+			UserID:     event.UserID,
+			OccurredAt: event.OccurredAt,
+		}
+		if event.User != nil {
+			response.User = toUserResponse(event.User)
+		}
+		return send(response)
+	})
+}
+
+func (s *userEventService) Close() {
+	s.close()
+}
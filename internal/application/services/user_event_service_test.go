@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/memory"
+)
+
+func TestService_WatchUsers(t *testing.T) {
+	feed := memory.NewUserChangeFeed(10)
+	service := NewUserEventService(feed)
+	ctx := domainServices.WithTenant(context.Background(), "acme")
+	errDone := errors.New("done")
+
+	user, _ := entities.NewUser("Test User", "test@example.com", "hashed")
+	feed.Publish(&entities.UserEvent{Type: entities.UserCreated, TenantID: "acme", UserID: user.ID, User: user})
+	feed.Publish(&entities.UserEvent{Type: entities.UserDeleted, TenantID: "acme", UserID: user.ID})
+
+	t.Run("Maps the events of the selected types", func(t *testing.T) {
+		var events []*dto.UserEvent
+		err := service.WatchUsers(ctx, &dto.WatchUsersRequest{ResumeAfter: "0", Types: []string{"created"}}, func(event *dto.UserEvent) error {
+			events = append(events, event)
+			return errDone
+		})
+		assert.ErrorIs(t, err, errDone)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "1", events[0].ID)
+			assert.Equal(t, "created", events[0].Type)
+			assert.Equal(t, user.ID, events[0].UserID)
+			assert.Equal(t, "test@example.com", events[0].User.Email)
+		}
+	})
+
+	t.Run("Leaves the user out of deletions", func(t *testing.T) {
+		var events []*dto.UserEvent
+		err := service.WatchUsers(ctx, &dto.WatchUsersRequest{ResumeAfter: "1"}, func(event *dto.UserEvent) error {
+			events = append(events, event)
+			return errDone
+		})
+		assert.ErrorIs(t, err, errDone)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "deleted", events[0].Type)
+			assert.Nil(t, events[0].User)
+		}
+	})
+
+	t.Run("Rejects unknown event types", func(t *testing.T) {
+		err := service.WatchUsers(ctx, &dto.WatchUsersRequest{Types: []string{"renamed"}}, func(*dto.UserEvent) error { return nil })
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidUserEventType)
+	})
+}
+
+func TestService_WatchUsers_Close(t *testing.T) {
+	service := NewUserEventService(memory.NewUserChangeFeed(10))
+	ctx := domainServices.WithTenant(context.Background(), "acme")
+
+	watching := make(chan error, 1)
+	go func() {
+		watching <- service.WatchUsers(ctx, &dto.WatchUsersRequest{}, func(*dto.UserEvent) error { return nil })
+	}()
+
+	service.Close()
+	select {
+	case err := <-watching:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watch outlived Close")
+	}
+
+	// Watches started after Close end at once
+	err := service.WatchUsers(ctx, &dto.WatchUsersRequest{}, func(*dto.UserEvent) error { return nil })
+	assert.NoError(t, err)
+}
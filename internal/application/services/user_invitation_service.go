@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+// userInvitationTTL is how long a mailed invitation can be accepted.
+const userInvitationTTL = 7 * 24 * time.Hour
+
+type userInvitationService struct {
+	invitationRepo repositories.UserInvitationRepository
+	userRepo       repositories.UserRepository
+	passwordHasher domainServices.PasswordHasher
+	mailer         ports.Mailer
+	acceptURL      string
+	now            func() time.Time
+}
+
+// NewUserInvitationService mails links to acceptURL, which must lead to a page
+// that posts the token, a name and a password to the accept route.
+func NewUserInvitationService(invitationRepo repositories.UserInvitationRepository, userRepo repositories.UserRepository, passwordHasher domainServices.PasswordHasher, mailer ports.Mailer, acceptURL string) ports.UserInvitationService {
+	return &userInvitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		passwordHasher: passwordHasher,
+		mailer:         mailer,
+		acceptURL:      acceptURL,
+		now:            time.Now,
+	}
+}
+
+func (s *userInvitationService) CreateInvitation(ctx context.Context, inviterID entities.UserID, req *dto.CreateUserInvitationRequest) (*dto.UserInvitationResponse, error) {
+	inviter, err := s.userRepo.GetByID(ctx, inviterID)
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := grantableScopes(inviter.GrantedScopes(), req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if user already exists
+	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
+	if existingUser != nil {
+		return nil, domainErrors.ErrUserAlreadyExists
+	}
+
+	invitations, err := s.invitationRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	for _, invitation := range invitations {
+		if invitation.IsPending(now) {
+			return nil, domainErrors.ErrUserInvitationExists
+		}
+	}
+
+	token, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := entities.NewUserInvitation(req.Email, hashOAuthSecret(token), scopes, inviter.ID, now.Add(userInvitationTTL))
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	err = s.mailer.Send(ctx, req.Email,
+		"You are invited",
+		inviter.Name+" invited you to create an account. Use this link to choose your name and password. It expires in 7 days and can be used once:\n\n"+s.inviteURL(token)+
+			"\n\nIf you were not expecting this invitation, you can ignore this message.")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toInvitationResponse(invitation), nil
+}
+
+func (s *userInvitationService) ListInvitations(ctx context.Context) (*dto.UserInvitationsListResponse, error) {
+	invitations, err := s.invitationRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.UserInvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, *s.toInvitationResponse(invitation))
+	}
+
+	return &dto.UserInvitationsListResponse{
+		Invitations: responses,
+		Total:       len(responses),
+	}, nil
+}
+
+func (s *userInvitationService) RevokeInvitation(ctx context.Context, id entities.ID) error {
+	invitation, err := s.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !invitation.IsPending(s.now()) {
+		return domainErrors.ErrInvalidUserInvitation
+	}
+
+	invitation.Revoke()
+	return s.invitationRepo.Update(ctx, invitation)
+}
+
+func (s *userInvitationService) AcceptInvitation(ctx context.Context, req *dto.AcceptUserInvitationRequest) (*dto.UserResponse, error) {
+	invitation, err := s.invitationRepo.GetByTokenHash(ctx, hashOAuthSecret(req.Token))
+	if errors.Is(err, domainErrors.ErrUserInvitationNotFound) {
+		return nil, domainErrors.ErrInvalidUserInvitation
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !invitation.IsPending(s.now()) {
+		return nil, domainErrors.ErrInvalidUserInvitation
+	}
+
+	// The account belongs to the tenant the invitation was sent from,
+	// whichever one the request names
+	ctx = domainServices.WithTenant(ctx, invitation.TenantID)
+
+	// Someone may have signed up with the email in the meantime. The unique
+	// email index also stops the same invitation from being accepted twice.
+	existingUser, _ := s.userRepo.GetByEmail(ctx, invitation.Email)
+	if existingUser != nil {
+		return nil, domainErrors.ErrUserAlreadyExists
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := entities.NewUser(req.Name, invitation.Email, hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+	user.Scopes = invitation.Scopes
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	invitation.Accept(user.ID)
+	if err := s.invitationRepo.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	return toUserResponse(user), nil
+}
+
+func (s *userInvitationService) inviteURL(token string) string {
+	query := url.Values{}
+	query.Set("token", token)
+	return s.acceptURL + "?" + query.Encode()
+}
+
+func (s *userInvitationService) toInvitationResponse(invitation *entities.UserInvitation) *dto.UserInvitationResponse {
+	response := &dto.UserInvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Scopes:    invitation.Scopes,
+		Status:    invitation.Status(s.now()),
+		InvitedBy: invitation.InvitedBy,
+		CreatedAt: invitation.CreatedAt,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+	if !invitation.UserID.IsZero() {
+		response.UserID = &invitation.UserID
+	}
+	if !invitation.AcceptedAt.IsZero() {
+		response.AcceptedAt = &invitation.AcceptedAt
+	}
+	if !invitation.RevokedAt.IsZero() {
+		response.RevokedAt = &invitation.RevokedAt
+	}
+	return response
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_UserInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInvitationRepo := mock_repositories.NewMockUserInvitationRepository(ctrl)
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	mockMailer := mock_ports.NewMockMailer(ctrl)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	service := NewUserInvitationService(mockInvitationRepo, mockUserRepo, passwordHasher, mockMailer, "https://app.example.com/invitations/accept")
+	ctx := context.Background()
+
+	admin, _ := entities.NewUser("Admin", "admin@example.com", "hashed")
+	admin.Scopes = []string{entities.ScopeAdmin}
+	member, _ := entities.NewUser("Member", "member@example.com", "hashed")
+	request := &dto.CreateUserInvitationRequest{Email: "new@example.com", Scopes: []string{entities.ScopeUsersRead}}
+	pending := func() *entities.UserInvitation {
+		invitation := entities.NewUserInvitation("new@example.com", hashOAuthSecret("token"), []string{entities.ScopeUsersRead}, admin.ID, time.Now().Add(time.Hour))
+		invitation.TenantID = "acme"
+		return invitation
+	}
+
+	t.Run("CreateInvitation mails a link with the token", func(t *testing.T) {
+		var stored *entities.UserInvitation
+		var body string
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), admin.ID).Return(admin, nil).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockInvitationRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return(nil, nil).Times(1)
+		mockInvitationRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, invitation *entities.UserInvitation) error {
+			stored = invitation
+			return nil
+		}).Times(1)
+		mockMailer.EXPECT().Send(gomock.Any(), "new@example.com", gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, to, subject, text string) error {
+			body = text
+			return nil
+		}).Times(1)
+
+		response, err := service.CreateInvitation(ctx, admin.ID, request)
+		assert.NoError(t, err)
+		assert.Equal(t, entities.UserInvitationPending, response.Status)
+		assert.Equal(t, []string{entities.ScopeUsersRead}, response.Scopes)
+
+		linkURL, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(body))
+		assert.NoError(t, err)
+		assert.Equal(t, "/invitations/accept", linkURL.Path)
+		assert.Equal(t, hashOAuthSecret(linkURL.Query().Get("token")), stored.TokenHash)
+		assert.Equal(t, admin.ID, stored.InvitedBy)
+		assert.WithinDuration(t, time.Now().Add(userInvitationTTL), stored.ExpiresAt, time.Second)
+	})
+
+	t.Run("CreateInvitation only grants scopes the inviter holds", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), member.ID).Return(member, nil).Times(1)
+
+		_, err := service.CreateInvitation(ctx, member.ID, &dto.CreateUserInvitationRequest{Email: "new@example.com", Scopes: []string{entities.ScopeAdmin}})
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidScope)
+	})
+
+	t.Run("CreateInvitation rejects a second pending invitation", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByID(gomock.Any(), admin.ID).Return(admin, nil).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockInvitationRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return([]*entities.UserInvitation{pending()}, nil).Times(1)
+
+		_, err := service.CreateInvitation(ctx, admin.ID, request)
+		assert.ErrorIs(t, err, domainErrors.ErrUserInvitationExists)
+	})
+
+	t.Run("AcceptInvitation creates the account in the invitation's tenant", func(t *testing.T) {
+		invitation := pending()
+		mockInvitationRepo.EXPECT().GetByTokenHash(gomock.Any(), hashOAuthSecret("token")).Return(invitation, nil).Times(1)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "new@example.com").Return(nil, domainErrors.ErrUserNotFound).Times(1)
+		mockUserRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *entities.User) error {
+			tenantID, _ := domainServices.TenantFromContext(ctx)
+			assert.Equal(t, "acme", tenantID)
+			assert.Equal(t, []string{entities.ScopeUsersRead}, user.Scopes)
+			assert.NoError(t, passwordHasher.Compare(user.Password, "new-password"))
+			return nil
+		}).Times(1)
+		mockInvitationRepo.EXPECT().Update(gomock.Any(), invitation).Return(nil).Times(1)
+
+		response, err := service.AcceptInvitation(ctx, &dto.AcceptUserInvitationRequest{Token: "token", Name: "New User", Password: "new-password"})
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", response.Email)
+		assert.Equal(t, response.ID, invitation.UserID)
+		assert.Equal(t, entities.UserInvitationAccepted, invitation.Status(time.Now()))
+	})
+
+	t.Run("AcceptInvitation rejects expired and revoked invitations", func(t *testing.T) {
+		expired := pending()
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		revoked := pending()
+		revoked.Revoke()
+
+		for _, invitation := range []*entities.UserInvitation{expired, revoked} {
+			mockInvitationRepo.EXPECT().GetByTokenHash(gomock.Any(), hashOAuthSecret("token")).Return(invitation, nil).Times(1)
+
+			_, err := service.AcceptInvitation(ctx, &dto.AcceptUserInvitationRequest{Token: "token", Name: "New User", Password: "new-password"})
+			assert.ErrorIs(t, err, domainErrors.ErrInvalidUserInvitation)
+		}
+	})
+
+	t.Run("AcceptInvitation rejects unknown tokens", func(t *testing.T) {
+		mockInvitationRepo.EXPECT().GetByTokenHash(gomock.Any(), hashOAuthSecret("forged")).Return(nil, domainErrors.ErrUserInvitationNotFound).Times(1)
+
+		_, err := service.AcceptInvitation(ctx, &dto.AcceptUserInvitationRequest{Token: "forged", Name: "New User", Password: "new-password"})
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidUserInvitation)
+	})
+}
@@ -9,18 +9,17 @@ import (
 	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
 	"github.com/wonyus/backend-challenge/internal/domain/repositories"
 	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type userService struct {
-	userRepo    repositories.UserRepository
-	authService domainServices.AuthService
+	userRepo       repositories.UserRepository
+	passwordHasher domainServices.PasswordHasher
 }
 
-func NewUserService(userRepo repositories.UserRepository, authService domainServices.AuthService) ports.UserService {
+func NewUserService(userRepo repositories.UserRepository, passwordHasher domainServices.PasswordHasher) ports.UserService {
 	return &userService{
-		userRepo:    userRepo,
-		authService: authService,
+		userRepo:       userRepo,
+		passwordHasher: passwordHasher,
 	}
 }
 
@@ -32,7 +31,7 @@ func (s *userService) CreateUser(ctx context.Context, req *dto.CreateUserRequest
 	}
 
 	// Hash password
-	hashedPassword, err := s.authService.HashPassword(req.Password)
+	hashedPassword, err := s.passwordHasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +55,41 @@ func (s *userService) CreateUser(ctx context.Context, req *dto.CreateUserRequest
 	}, nil
 }
 
-func (s *userService) GetUserByID(ctx context.Context, id primitive.ObjectID) (*dto.UserResponse, error) {
+func (s *userService) ProvisionUser(ctx context.Context, req *dto.ProvisionUserRequest) (*dto.UserResponse, error) {
+	// Check if user already exists
+	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
+	if existingUser != nil {
+		return nil, domainErrors.ErrUserAlreadyExists
+	}
+
+	// Create user entity, linked to the external account if there is one
+	var identities []entities.ExternalIdentity
+	if req.Provider != "" {
+		identities = append(identities, entities.ExternalIdentity{
+			Provider: req.Provider,
+			Subject:  req.Subject,
+			Email:    req.Email,
+		})
+	}
+	user, err := entities.NewPasswordlessUser(req.Name, req.Email, identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save to repository
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &dto.UserResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+	}, nil
+}
+
+func (s *userService) GetUserByID(ctx context.Context, id entities.UserID) (*dto.UserResponse, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -89,7 +122,7 @@ func (s *userService) GetAllUsers(ctx context.Context) (*dto.UsersListResponse,
 	}, nil
 }
 
-func (s *userService) UpdateUser(ctx context.Context, id primitive.ObjectID, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+func (s *userService) UpdateUser(ctx context.Context, id entities.UserID, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -122,7 +155,7 @@ func (s *userService) UpdateUser(ctx context.Context, id primitive.ObjectID, req
 	}, nil
 }
 
-func (s *userService) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+func (s *userService) DeleteUser(ctx context.Context, id entities.UserID) error {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
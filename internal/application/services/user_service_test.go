@@ -11,7 +11,6 @@ import (
 	"github.com/wonyus/backend-challenge/internal/domain/entities"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
 	mock_repositories "github.com/wonyus/backend-challenge/mock/mongodb"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/mock/gomock"
 )
 
@@ -20,12 +19,12 @@ func TestService_User_CreateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
-	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	userService := NewUserService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	userService := NewUserService(mockUserRepo, passwordHasher)
 
 	var (
 		ctx = context.Background()
-		id  = primitive.NewObjectID()
+		id  = entities.NewUserID()
 		now = time.Now()
 	)
 
@@ -81,7 +80,7 @@ func TestService_User_CreateUser(t *testing.T) {
 		mockRequest.Name = ""
 		response, err := userService.CreateUser(ctx, mockRequest)
 		assert.Nil(t, response)
-		assert.Equal(t, "name, email, and password are required", err.Error())
+		assert.Equal(t, "name and email are required", err.Error())
 	})
 	t.Run("password hashing error", func(t *testing.T) {
 		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(nil, nil).Times(1)
@@ -92,17 +91,55 @@ func TestService_User_CreateUser(t *testing.T) {
 	})
 }
 
+func TestService_User_ProvisionUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	userService := NewUserService(mockUserRepo, passwordHasher)
+	ctx := context.Background()
+
+	mockRequest := &dto.ProvisionUserRequest{
+		Name:     "Test User",
+		Email:    "test@example.com",
+		Provider: "corp",
+		Subject:  "corp-1",
+	}
+
+	t.Run("Provision user success", func(t *testing.T) {
+		var created *entities.User
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(nil, nil).Times(1)
+		mockUserRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *entities.User) error {
+			created = user
+			return nil
+		}).Times(1)
+		response, err := userService.ProvisionUser(ctx, mockRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, created.ID, response.ID)
+		assert.False(t, created.HasPassword())
+		assert.True(t, created.HasIdentity("corp", "corp-1"))
+	})
+
+	t.Run("User already exists", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), mockRequest.Email).Return(&entities.User{}, nil).Times(1)
+		response, err := userService.ProvisionUser(ctx, mockRequest)
+		assert.Nil(t, response)
+		assert.Equal(t, "user already exists", err.Error())
+	})
+}
+
 func TestService_User_GetUserByID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
-	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	userService := NewUserService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	userService := NewUserService(mockUserRepo, passwordHasher)
 
 	var (
 		ctx = context.Background()
-		id  = primitive.NewObjectID()
+		id  = entities.NewUserID()
 		now = time.Now()
 	)
 
@@ -136,8 +173,8 @@ func TestService_User_GetAllUsers(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
-	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	userService := NewUserService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	userService := NewUserService(mockUserRepo, passwordHasher)
 
 	var (
 		ctx = context.Background()
@@ -146,7 +183,7 @@ func TestService_User_GetAllUsers(t *testing.T) {
 
 	mockUsers := []*entities.User{
 		{
-			ID:        primitive.NewObjectID(),
+			ID:        entities.NewUserID(),
 			Name:      "Test User 1",
 			Email:     "test1@example.com",
 			Password:  "hashed_password",
@@ -154,7 +191,7 @@ func TestService_User_GetAllUsers(t *testing.T) {
 			UpdatedAt: now,
 		},
 		{
-			ID:        primitive.NewObjectID(),
+			ID:        entities.NewUserID(),
 			Name:      "Test User 2",
 			Email:     "test2@example.com",
 			Password:  "hashed_password",
@@ -183,13 +220,13 @@ func TestService_User_UpdateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
-	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	userService := NewUserService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	userService := NewUserService(mockUserRepo, passwordHasher)
 
 	var (
 		ctx   = context.Background()
-		id    = primitive.NewObjectID()
-		idNew = primitive.NewObjectID()
+		id    = entities.NewUserID()
+		idNew = entities.NewUserID()
 		now   = time.Now()
 	)
 
@@ -257,12 +294,12 @@ func TestUserService_DeleteUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
-	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	userService := NewUserService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	userService := NewUserService(mockUserRepo, passwordHasher)
 
 	var (
 		ctx = context.Background()
-		id  = primitive.NewObjectID()
+		id  = entities.NewUserID()
 		now = time.Now()
 	)
 
@@ -303,8 +340,8 @@ func TestUserService_GetUserCount(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserRepo := mock_repositories.NewMockUserRepository(ctrl)
-	jwtService := auth.NewJWTService("cfg.JWTSecret", mockUserRepo)
-	userService := NewUserService(mockUserRepo, jwtService)
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(0))
+	userService := NewUserService(mockUserRepo, passwordHasher)
 
 	var (
 		ctx = context.Background()
@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// APIKey is a long-lived credential for machine-to-machine access. Only a hash
+// of the key is stored; Prefix is the non-secret part used for lookup.
+type APIKey struct {
+	ID         ID         `bson:"_id,omitempty" json:"id"`
+	UserID     UserID     `bson:"user_id" json:"user_id"`
+	Name       string     `bson:"name" json:"name"`
+	Prefix     string     `bson:"prefix" json:"prefix"`
+	Hash       string     `bson:"hash" json:"-"`
+	Scopes     []string   `bson:"scopes,omitempty" json:"scopes,omitempty"`
+	ExpiresAt  *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+}
+
+func NewAPIKey(userID UserID, name, prefix, hash string, scopes []string, expiresAt *time.Time) (*APIKey, error) {
+	if name == "" || prefix == "" || hash == "" {
+		return nil, errors.New("name, prefix, and hash are required")
+	}
+
+	return &APIKey{
+		ID:        NewID(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsActive reports whether the key is neither revoked nor expired at t.
+func (k *APIKey) IsActive(t time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || t.Before(*k.ExpiresAt)
+}
+
+func (k *APIKey) Revoke() {
+	now := time.Now()
+	k.RevokedAt = &now
+}
+
+func (k *APIKey) MarkUsed(t time.Time) {
+	k.LastUsedAt = &t
+}
+
+// Clone returns a copy of the key sharing nothing mutable with it.
+func (k *APIKey) Clone() *APIKey {
+	clone := *k
+	clone.Scopes = slices.Clone(k.Scopes)
+	return &clone
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+)
+
+// Audit actions
+const (
+	AuditImpersonationStarted = "impersonation.started"
+	AuditImpersonatedRequest  = "impersonation.request"
+)
+
+// AuditEvent records something an administrator did on behalf of a user.
+// Events are only ever appended.
+type AuditEvent struct {
+	ID        ID        `bson:"_id"`
+	Action    string    `bson:"action"`
+	ActorID   UserID    `bson:"actor_id"`
+	SubjectID UserID    `bson:"subject_id"`
+	Method    string    `bson:"method,omitempty"`
+	Path      string    `bson:"path,omitempty"`
+	IPAddress string    `bson:"ip_address,omitempty"`
+	UserAgent string    `bson:"user_agent,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func NewAuditEvent(action string, actorID, subjectID UserID) *AuditEvent {
+	return &AuditEvent{
+		ID:        NewID(),
+		Action:    action,
+		ActorID:   actorID,
+		SubjectID: subjectID,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,71 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ways to treat the local part, before the @, of an email when telling
+// accounts apart.
+const (
+	// EmailLocalPartCaseInsensitive makes Bob@example.com and
+	// bob@example.com the same account. It is the default.
+	EmailLocalPartCaseInsensitive = "caseInsensitive"
+	// EmailLocalPartCaseSensitive keeps them apart, as RFC 5321 allows.
+	EmailLocalPartCaseSensitive = "caseSensitive"
+)
+
+// NormalizeEmail trims the email and lowercases its domain, which is never
+// case sensitive. It is the form emails are stored and shown in.
+func NormalizeEmail(email string) string {
+	email = strings.TrimSpace(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	return email[:at] + strings.ToLower(email[at:])
+}
+
+// EmailPolicy decides which emails belong to the same account. The zero
+// value compares local parts case-insensitively and keeps subaddresses.
+type EmailPolicy struct {
+	CaseSensitiveLocalPart bool
+	// IgnoreSubaddress drops a +tag from the local part, so that
+	// bob+news@example.com is bob@example.com.
+	IgnoreSubaddress bool
+}
+
+// NewEmailPolicy returns the policy for one of the EmailLocalPart modes. An
+// empty mode is the default.
+func NewEmailPolicy(localPart string, ignoreSubaddress bool) (EmailPolicy, error) {
+	policy := EmailPolicy{IgnoreSubaddress: ignoreSubaddress}
+	switch localPart {
+	case "", EmailLocalPartCaseInsensitive:
+	case EmailLocalPartCaseSensitive:
+		policy.CaseSensitiveLocalPart = true
+	default:
+		return EmailPolicy{}, fmt.Errorf("unknown email local part handling %q", localPart)
+	}
+	return policy, nil
+}
+
+// Key returns the form of the email that is unique per account: emails with
+// the same key belong to the same account.
+func (p EmailPolicy) Key(email string) string {
+	email = NormalizeEmail(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return strings.ToLower(email)
+	}
+
+	local := email[:at]
+	if p.IgnoreSubaddress {
+		if plus := strings.Index(local, "+"); plus > 0 {
+			local = local[:plus]
+		}
+	}
+	if !p.CaseSensitiveLocalPart {
+		local = strings.ToLower(local)
+	}
+	return local + email[at:]
+}
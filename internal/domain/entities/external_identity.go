@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// ExternalIdentity links a user to an account at an external identity
+// provider. Provider and Subject together identify the account; the subject
+// is stable where the email at the provider may change.
+type ExternalIdentity struct {
+	Provider string    `bson:"provider"`
+	Subject  string    `bson:"subject"`
+	Email    string    `bson:"email,omitempty"`
+	LinkedAt time.Time `bson:"linked_at"`
+}
+
+// FederatedLogin is the pending state of a login at an external identity
+// provider, kept from the redirect until the provider calls back. It is
+// stored under a hash of the state parameter and can be used once.
+type FederatedLogin struct {
+	StateHash    string    `bson:"_id"`
+	Provider     string    `bson:"provider"`
+	Nonce        string    `bson:"nonce"`
+	CodeVerifier string    `bson:"code_verifier"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+}
+
+func (l *FederatedLogin) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
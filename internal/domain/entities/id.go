@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ID identifies the entities other than users: organizations, teams,
+// memberships, sessions, API keys, OAuth clients, invitations and audit
+// events. It holds a MongoDB ObjectID in hex, the form all of them have
+// always had. Adapters store it in whatever form suits them; the zero ID is
+// none.
+type ID string
+
+// NewID returns a new, unique ID.
+func NewID() ID {
+	return ID(NewObjectIDUserID())
+}
+
+// ParseID parses an ID and returns it in canonical form.
+func ParseID(s string) (ID, error) {
+	if len(s) != 24 || !isHex(s) {
+		return "", fmt.Errorf("invalid id %q", s)
+	}
+	return ID(strings.ToLower(s)), nil
+}
+
+func (id ID) String() string {
+	return string(id)
+}
+
+func (id ID) IsZero() bool {
+	return id == ""
+}
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+)
+
+// MagicLink is a single-use sign in link mailed to Email. It is stored under a
+// hash of its token. Requests for unknown emails are recorded as well, without
+// a user, so that rate limiting treats every email alike.
+type MagicLink struct {
+	TokenHash string `bson:"_id"`
+	// TenantID is the tenant the link was requested in, and signs in to
+	TenantID  string    `bson:"tenant_id"`
+	Email     string    `bson:"email"`
+	UserID    UserID    `bson:"user_id,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func (l *MagicLink) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// OAuthClient is an application allowed to sign users in through the OpenID
+// Connect provider. Public clients (no SecretHash) rely on PKCE alone.
+type OAuthClient struct {
+	ID           ID        `bson:"_id,omitempty" json:"id"`
+	ClientID     string    `bson:"client_id" json:"client_id"`
+	SecretHash   string    `bson:"secret_hash,omitempty" json:"-"`
+	Name         string    `bson:"name" json:"name"`
+	RedirectURIs []string  `bson:"redirect_uris" json:"redirect_uris"`
+	Scopes       []string  `bson:"scopes,omitempty" json:"scopes,omitempty"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at"`
+}
+
+func NewOAuthClient(clientID, secretHash, name string, redirectURIs, scopes []string) (*OAuthClient, error) {
+	if clientID == "" || name == "" || len(redirectURIs) == 0 {
+		return nil, errors.New("client id, name, and redirect uris are required")
+	}
+
+	return &OAuthClient{
+		ID:           NewID(),
+		ClientID:     clientID,
+		SecretHash:   secretHash,
+		Name:         name,
+		RedirectURIs: redirectURIs,
+		Scopes:       scopes,
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+func (c *OAuthClient) IsConfidential() bool {
+	return c.SecretHash != ""
+}
+
+// HasRedirectURI requires an exact match, as OAuth 2.1 does.
+func (c *OAuthClient) HasRedirectURI(redirectURI string) bool {
+	for _, uri := range c.RedirectURIs {
+		if uri == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsScope reports whether the client may request scope. Clients without
+// a scope list may request any scope.
+func (c *OAuthClient) AllowsScope(scope string) bool {
+	return len(c.Scopes) == 0 || CanGrant(c.Scopes, scope)
+}
+
+// AuthorizationCode is the single-use grant handed to a client after the
+// user signs in. Only a hash of the code is stored.
+type AuthorizationCode struct {
+	CodeHash string `bson:"_id"`
+	// TenantID is the tenant of the user, which the token request need not
+	// name
+	TenantID      string    `bson:"tenant_id"`
+	ClientID      string    `bson:"client_id"`
+	UserID        UserID    `bson:"user_id"`
+	RedirectURI   string    `bson:"redirect_uri"`
+	Scopes        []string  `bson:"scopes"`
+	Nonce         string    `bson:"nonce,omitempty"`
+	CodeChallenge string    `bson:"code_challenge"`
+	AuthTime      time.Time `bson:"auth_time"`
+	ExpiresAt     time.Time `bson:"expires_at"`
+}
+
+func (c *AuthorizationCode) IsExpired(t time.Time) bool {
+	return !t.Before(c.ExpiresAt)
+}
@@ -0,0 +1,156 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// Organization groups users of a tenant. The repository sets TenantID.
+type Organization struct {
+	ID        ID        `bson:"_id,omitempty"`
+	TenantID  string    `bson:"tenant_id"`
+	Name      string    `bson:"name"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+func NewOrganization(name string) (*Organization, error) {
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	now := time.Now()
+	return &Organization{
+		ID:        NewID(),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (o *Organization) UpdateName(name string) {
+	o.Name = name
+	o.UpdatedAt = time.Now()
+}
+
+// Team is a group of members within an organization.
+type Team struct {
+	ID             ID        `bson:"_id,omitempty"`
+	OrganizationID ID        `bson:"organization_id"`
+	Name           string    `bson:"name"`
+	CreatedAt      time.Time `bson:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+func NewTeam(organizationID ID, name string) (*Team, error) {
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	now := time.Now()
+	return &Team{
+		ID:             NewID(),
+		OrganizationID: organizationID,
+		Name:           name,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+func (t *Team) UpdateName(name string) {
+	t.Name = name
+	t.UpdatedAt = time.Now()
+}
+
+// Organization roles. Owners and admins manage the organization, its members
+// and its teams; only owners can delete it or make other owners.
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Team roles. Maintainers manage the members of their team.
+const (
+	TeamRoleMaintainer = "maintainer"
+	TeamRoleMember     = "member"
+)
+
+func IsValidOrganizationRole(role string) bool {
+	switch role {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
+
+func IsValidTeamRole(role string) bool {
+	switch role {
+	case TeamRoleMaintainer, TeamRoleMember:
+		return true
+	}
+	return false
+}
+
+// Membership statuses. Invited members have no access until they accept.
+const (
+	MembershipInvited = "invited"
+	MembershipActive  = "active"
+)
+
+// Membership puts a user in an organization or, when TeamID is set, in one
+// of its teams, with a role of its own.
+type Membership struct {
+	ID             ID        `bson:"_id,omitempty"`
+	OrganizationID ID        `bson:"organization_id"`
+	TeamID         ID        `bson:"team_id,omitempty"`
+	UserID         UserID    `bson:"user_id"`
+	Role           string    `bson:"role"`
+	Status         string    `bson:"status"`
+	InvitedBy      UserID    `bson:"invited_by,omitempty"`
+	CreatedAt      time.Time `bson:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+// NewMembership returns an active membership of the organization, or of the
+// team unless teamID is zero.
+func NewMembership(organizationID, teamID ID, userID UserID, role string) *Membership {
+	now := time.Now()
+	return &Membership{
+		ID:             NewID(),
+		OrganizationID: organizationID,
+		TeamID:         teamID,
+		UserID:         userID,
+		Role:           role,
+		Status:         MembershipActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// NewInvitation returns an organization membership the user still has to
+// accept.
+func NewInvitation(organizationID ID, userID, invitedBy UserID, role string) *Membership {
+	membership := NewMembership(organizationID, "", userID, role)
+	membership.Status = MembershipInvited
+	membership.InvitedBy = invitedBy
+	return membership
+}
+
+func (m *Membership) IsTeamMembership() bool {
+	return !m.TeamID.IsZero()
+}
+
+func (m *Membership) IsActive() bool {
+	return m.Status == MembershipActive
+}
+
+func (m *Membership) Accept() {
+	m.Status = MembershipActive
+	m.UpdatedAt = time.Now()
+}
+
+func (m *Membership) ChangeRole(role string) {
+	m.Role = role
+	m.UpdatedAt = time.Now()
+}
@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+// Passkey is a WebAuthn credential registered by a user. ID is the base64url
+// encoded credential ID chosen by the authenticator and PublicKey is the COSE
+// encoded key that verifies its signatures.
+type Passkey struct {
+	ID         string   `bson:"_id"`
+	UserID     UserID   `bson:"user_id"`
+	Name       string   `bson:"name"`
+	PublicKey  []byte   `bson:"public_key"`
+	SignCount  uint32   `bson:"sign_count"`
+	AAGUID     []byte   `bson:"aaguid,omitempty"`
+	Transports []string `bson:"transports,omitempty"`
+	// BackupEligible passkeys may be synced between devices by the provider.
+	BackupEligible bool       `bson:"backup_eligible"`
+	BackupState    bool       `bson:"backup_state"`
+	LastUsedAt     *time.Time `bson:"last_used_at,omitempty"`
+	CreatedAt      time.Time  `bson:"created_at"`
+}
+
+func NewPasskey(userID UserID, name, id string, publicKey []byte, signCount uint32) (*Passkey, error) {
+	if name == "" || id == "" || len(publicKey) == 0 {
+		return nil, errors.New("name, id, and public key are required")
+	}
+
+	return &Passkey{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		PublicKey: publicKey,
+		SignCount: signCount,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// RecordUse stores the sign counter of a login and reports whether it is
+// plausible. Authenticators that keep a counter increase it on every use, so
+// a counter that does not grow means the key has likely been cloned.
+// Authenticators without a counter always report zero.
+func (p *Passkey) RecordUse(signCount uint32, backupState bool, t time.Time) bool {
+	if (signCount != 0 || p.SignCount != 0) && signCount <= p.SignCount {
+		return false
+	}
+
+	p.SignCount = signCount
+	p.BackupState = backupState
+	p.LastUsedAt = &t
+	return true
+}
+
+func (p *Passkey) Rename(name string) {
+	p.Name = name
+}
+
+// PasskeyCeremony is a pending WebAuthn registration or login, kept from the
+// options until the browser responds. It is stored under a hash of its
+// challenge and can be used once. Login ceremonies have no user.
+type PasskeyCeremony struct {
+	ChallengeHash string    `bson:"_id"`
+	Type          string    `bson:"type"`
+	UserID        UserID    `bson:"user_id,omitempty"`
+	ExpiresAt     time.Time `bson:"expires_at"`
+}
+
+const (
+	PasskeyRegistration = "registration"
+	PasskeyLogin        = "login"
+)
+
+func (c *PasskeyCeremony) IsExpired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
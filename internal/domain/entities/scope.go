@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"sort"
+	"strings"
+)
+
+// OAuth2-style scopes granted to users and carried by tokens and API keys.
+const (
+	ScopeUsersRead   = "users:read"
+	ScopeUsersWrite  = "users:write"
+	ScopeUsersDelete = "users:delete"
+
+	// ScopeSCIM allows provisioning users through the SCIM endpoints.
+	ScopeSCIM = "scim:provision"
+
+	// ScopeAdmin grants every other scope.
+	ScopeAdmin = "admin:*"
+
+	// OpenID Connect scopes only describe the user's own identity, so every
+	// user holds them.
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// DefaultUserScopes are granted to accounts that have no explicit scopes,
+// which includes every account created before scopes existed.
+var DefaultUserScopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeUsersDelete}
+
+var knownScopes = map[string]bool{
+	ScopeUsersRead:   true,
+	ScopeUsersWrite:  true,
+	ScopeUsersDelete: true,
+	ScopeSCIM:        true,
+	ScopeAdmin:       true,
+	ScopeOpenID:      true,
+	ScopeProfile:     true,
+	ScopeEmail:       true,
+}
+
+var identityScopes = map[string]bool{
+	ScopeOpenID:  true,
+	ScopeProfile: true,
+	ScopeEmail:   true,
+}
+
+func IsKnownScope(scope string) bool {
+	return knownScopes[scope]
+}
+
+// KnownScopes lists every scope in a stable order.
+func KnownScopes() []string {
+	scopes := make([]string, 0, len(knownScopes))
+	for scope := range knownScopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// HasScope reports whether the granted scopes cover required. A "<ns>:*"
+// scope covers every scope in that namespace and ScopeAdmin covers all.
+func HasScope(granted []string, required string) bool {
+	namespace, _, _ := strings.Cut(required, ":")
+	for _, scope := range granted {
+		if scope == required || scope == ScopeAdmin || scope == namespace+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CanGrant reports whether scope may be issued to a holder of granted.
+// Unlike HasScope it allows the identity scopes to everyone.
+func CanGrant(granted []string, scope string) bool {
+	return identityScopes[scope] || HasScope(granted, scope)
+}
+
+// RestrictScopes returns the requested scopes that can be issued to a
+// holder of granted.
+func RestrictScopes(granted, requested []string) []string {
+	restricted := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		if CanGrant(granted, scope) {
+			restricted = append(restricted, scope)
+		}
+	}
+	return restricted
+}
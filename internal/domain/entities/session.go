@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"time"
+)
+
+// Authentication methods recorded on sessions. A login completed with a
+// second factor is recorded as the first method followed by AuthMethodMFASuffix.
+const (
+	AuthMethodPassword  = "password"
+	AuthMethodFederated = "federated"
+	AuthMethodMagicLink = "magic_link"
+	AuthMethodPasskey   = "passkey"
+	AuthMethodOAuth     = "oauth"
+	// AuthMethodImpersonation marks sessions of administrators acting as the user
+	AuthMethodImpersonation = "impersonation"
+
+	AuthMethodMFASuffix = "+mfa"
+)
+
+// Session is a sign in on a device. Every access token belongs to one through
+// its jti claim, so revoking the session revokes the token.
+type Session struct {
+	ID         ID         `bson:"_id"`
+	UserID     UserID     `bson:"user_id"`
+	AuthMethod string     `bson:"auth_method"`
+	IPAddress  string     `bson:"ip_address,omitempty"`
+	UserAgent  string     `bson:"user_agent,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at"`
+	LastSeenAt time.Time  `bson:"last_seen_at"`
+	ExpiresAt  time.Time  `bson:"expires_at"`
+	RevokedAt  *time.Time `bson:"revoked_at,omitempty"`
+}
+
+func NewSession(userID UserID, authMethod, ipAddress, userAgent string, expiresAt time.Time) *Session {
+	now := time.Now()
+	return &Session{
+		ID:         NewID(),
+		UserID:     userID,
+		AuthMethod: authMethod,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+}
+
+// IsActive reports whether the session is neither revoked nor expired at t.
+func (s *Session) IsActive(t time.Time) bool {
+	return s.RevokedAt == nil && t.Before(s.ExpiresAt)
+}
+
+func (s *Session) Touch(t time.Time) {
+	s.LastSeenAt = t
+}
+
+func (s *Session) Revoke(t time.Time) {
+	s.RevokedAt = &t
+}
@@ -0,0 +1,17 @@
+package entities
+
+import "regexp"
+
+// DefaultTenant holds every user of a single-tenant deployment and users of
+// tokens issued before tenants existed.
+const DefaultTenant = "default"
+
+// tenantIDPattern keeps tenant IDs usable as a DNS label, so that tenants can
+// be addressed by subdomain.
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// IsValidTenantID reports whether id is well formed, not whether the tenant
+// exists.
+func IsValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
@@ -2,42 +2,186 @@ package entities
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"time"
-
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name      string             `bson:"name" json:"name"`
-	Email     string             `bson:"email" json:"email"`
-	Password  string             `bson:"password" json:"-"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	ID UserID `bson:"_id,omitempty" json:"id"`
+	// TenantID is set by the repository from the tenant the user is created in
+	TenantID string `bson:"tenant_id" json:"-"`
+	Name     string `bson:"name" json:"name"`
+	Email    string `bson:"email" json:"email"`
+	// NormalizedEmail is the key of Email under the email policy, set by the
+	// repository. Emails are unique per tenant by their key.
+	NormalizedEmail string    `bson:"email_normalized" json:"-"`
+	Password        string    `bson:"password" json:"-"`
+	CreatedAt       time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time `bson:"updated_at" json:"updated_at"`
+	Scopes          []string  `bson:"scopes,omitempty" json:"-"`
+
+	// Identities are the linked accounts at external identity providers.
+	// Users provisioned through one of them have no local password.
+	Identities []ExternalIdentity `bson:"identities,omitempty" json:"-"`
+
+	// ExternalID is the user's identifier in the provisioning system (SCIM).
+	ExternalID string `bson:"external_id,omitempty" json:"-"`
+	// Disabled users cannot sign in, and their tokens and API keys stop working.
+	Disabled bool `bson:"disabled,omitempty" json:"-"`
+
+	MFA MFA `bson:"mfa" json:"-"`
+}
+
+// MFA holds a user's TOTP second factor. Secret is encrypted at rest and is
+// set from enrollment on; Enabled only becomes true once a code is confirmed.
+type MFA struct {
+	Enabled        bool      `bson:"enabled"`
+	Secret         string    `bson:"secret,omitempty"`
+	LastUsedStep   int64     `bson:"last_used_step,omitempty"`
+	RecoveryCodes  []string  `bson:"recovery_codes,omitempty"`
+	FailedAttempts int       `bson:"failed_attempts,omitempty"`
+	LockedUntil    time.Time `bson:"locked_until,omitempty"`
 }
 
+// NewUser creates a user. An empty hashedPassword creates a user without a
+// local password, who signs in through a magic link or an external identity
+// provider instead.
 func NewUser(name, email, hashedPassword string) (*User, error) {
-	if name == "" || email == "" || hashedPassword == "" {
-		return nil, errors.New("name, email, and password are required")
+	if name == "" || strings.TrimSpace(email) == "" {
+		return nil, errors.New("name and email are required")
 	}
 
 	now := time.Now()
 	return &User{
-		ID:        primitive.NewObjectID(),
+		ID:        NewUserID(),
 		Name:      name,
-		Email:     email,
+		Email:     NormalizeEmail(email),
 		Password:  hashedPassword,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
 }
 
+// NewPasswordlessUser creates a user without a local password, such as one
+// provisioned through SCIM or signed up through an external identity provider.
+func NewPasswordlessUser(name, email string, identities ...ExternalIdentity) (*User, error) {
+	user, err := NewUser(name, email, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, identity := range identities {
+		if identity.Provider == "" || identity.Subject == "" {
+			return nil, errors.New("identity provider and subject are required")
+		}
+		identities[i].LinkedAt = user.CreatedAt
+	}
+	user.Identities = identities
+	return user, nil
+}
+
+// Clone returns a deep copy of the user, which shares nothing that can be
+// changed with it.
+func (u *User) Clone() *User {
+	clone := *u
+	clone.Scopes = slices.Clone(u.Scopes)
+	clone.Identities = slices.Clone(u.Identities)
+	clone.MFA.RecoveryCodes = slices.Clone(u.MFA.RecoveryCodes)
+	return &clone
+}
+
+// HasPassword reports whether the user can sign in with a local password.
+func (u *User) HasPassword() bool {
+	return u.Password != ""
+}
+
+// HasIdentity reports whether the account at the provider is linked.
+func (u *User) HasIdentity(provider, subject string) bool {
+	for _, identity := range u.Identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkIdentity links an account at an external identity provider.
+func (u *User) LinkIdentity(identity ExternalIdentity) {
+	if u.HasIdentity(identity.Provider, identity.Subject) {
+		return
+	}
+	identity.LinkedAt = time.Now()
+	u.Identities = append(u.Identities, identity)
+	u.UpdatedAt = identity.LinkedAt
+}
+
+// GrantedScopes returns the scopes the user may hold in tokens and API keys.
+func (u *User) GrantedScopes() []string {
+	if len(u.Scopes) == 0 {
+		return DefaultUserScopes
+	}
+	return u.Scopes
+}
+
 func (u *User) UpdateName(name string) {
 	u.Name = name
 	u.UpdatedAt = time.Now()
 }
 
 func (u *User) UpdateEmail(email string) {
-	u.Email = email
+	u.Email = NormalizeEmail(email)
 	u.UpdatedAt = time.Now()
 }
+
+func (u *User) IsActive() bool {
+	return !u.Disabled
+}
+
+func (u *User) SetActive(active bool) {
+	u.Disabled = !active
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) UpdateExternalID(externalID string) {
+	u.ExternalID = externalID
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) UpdatePassword(hashedPassword string) {
+	u.Password = hashedPassword
+	u.UpdatedAt = time.Now()
+}
+
+// EnrollMFA stores a new, not yet confirmed, encrypted TOTP secret.
+func (u *User) EnrollMFA(encryptedSecret string) {
+	u.MFA = MFA{Secret: encryptedSecret}
+	u.UpdatedAt = time.Now()
+}
+
+// EnableMFA activates the enrolled secret with the given hashed recovery codes.
+func (u *User) EnableMFA(step int64, recoveryCodeHashes []string) {
+	u.MFA.Enabled = true
+	u.MFA.LastUsedStep = step
+	u.MFA.RecoveryCodes = recoveryCodeHashes
+	u.MFA.FailedAttempts = 0
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) DisableMFA() {
+	u.MFA = MFA{}
+	u.UpdatedAt = time.Now()
+}
+
+// UseRecoveryCode removes the hashed recovery code and reports whether it was
+// present. Each code can be used once.
+func (u *User) UseRecoveryCode(codeHash string) bool {
+	for i, stored := range u.MFA.RecoveryCodes {
+		if stored == codeHash {
+			u.MFA.RecoveryCodes = append(u.MFA.RecoveryCodes[:i:i], u.MFA.RecoveryCodes[i+1:]...)
+			u.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// UserEventType is the kind of change a UserEvent reports.
+type UserEventType string
+
+const (
+	UserCreated UserEventType = "created"
+	UserUpdated UserEventType = "updated"
+	UserDeleted UserEventType = "deleted"
+)
+
+// UserEventTypes are all the types of user events.
+var UserEventTypes = []UserEventType{UserCreated, UserUpdated, UserDeleted}
+
+func (t UserEventType) IsValid() bool {
+	switch t {
+	case UserCreated, UserUpdated, UserDeleted:
+		return true
+	}
+	return false
+}
+
+// UserEvent reports a change to a user of a tenant.
+type UserEvent struct {
+	// ID is the position of the event in the change feed, opaque to
+	// consumers, which watch from it again to resume after the event.
+	ID       string
+	Type     UserEventType
+	TenantID string
+	UserID   UserID
+	// User is the user after the change, nil when it was deleted.
+	User       *User
+	OccurredAt time.Time
+}
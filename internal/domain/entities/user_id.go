@@ -0,0 +1,167 @@
+package entities
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// UserID identifies a user. It holds the canonical text form of one of the
+// supported formats: a MongoDB ObjectID in hex, which every user created
+// before IDs were configurable has, a UUID, or a ULID. Adapters store it in
+// whatever form suits them; the zero UserID is no user.
+type UserID string
+
+// Formats new user IDs are generated in.
+const (
+	// UserIDObjectID generates 24 hex digit MongoDB ObjectIDs. It is the
+	// default.
+	UserIDObjectID = "objectId"
+	// UserIDUUIDv7 generates time ordered UUIDs (RFC 9562).
+	UserIDUUIDv7 = "uuidv7"
+	// UserIDULID generates ULIDs.
+	UserIDULID = "ulid"
+)
+
+// crockford is the alphabet ULIDs are written in.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ParseUserID parses a user ID in any of the supported formats, whatever
+// generates new ones, and returns it in canonical form.
+func ParseUserID(s string) (UserID, error) {
+	switch len(s) {
+	case 24:
+		if isHex(s) {
+			return UserID(strings.ToLower(s)), nil
+		}
+	case 36:
+		if s[8] == '-' && s[13] == '-' && s[18] == '-' && s[23] == '-' &&
+			isHex(s[:8]+s[9:13]+s[14:18]+s[19:23]+s[24:]) {
+			return UserID(strings.ToLower(s)), nil
+		}
+	case 26:
+		upper := strings.ToUpper(s)
+		// The first digit only holds 3 of the 130 bits
+		if upper[0] <= '7' && strings.Trim(upper, crockford) == "" {
+			return UserID(upper), nil
+		}
+	}
+	return "", fmt.Errorf("invalid user id %q", s)
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func (id UserID) String() string {
+	return string(id)
+}
+
+func (id UserID) IsZero() bool {
+	return id == ""
+}
+
+// UserIDGenerator returns a new, unique user ID.
+type UserIDGenerator func() UserID
+
+var userIDGenerator atomic.Pointer[UserIDGenerator]
+
+// NewUserIDGenerator returns the generator for one of the UserID formats. An
+// empty format is the default.
+func NewUserIDGenerator(format string) (UserIDGenerator, error) {
+	switch format {
+	case "", UserIDObjectID:
+		return NewObjectIDUserID, nil
+	case UserIDUUIDv7:
+		return NewUUIDv7UserID, nil
+	case UserIDULID:
+		return NewULIDUserID, nil
+	default:
+		return nil, fmt.Errorf("unknown user id format %q", format)
+	}
+}
+
+// SetUserIDGenerator makes NewUser give users IDs from generate. It is meant
+// to be called once at startup; users already created keep their IDs.
+func SetUserIDGenerator(generate UserIDGenerator) {
+	userIDGenerator.Store(&generate)
+}
+
+// NewUserID returns a new user ID from the configured generator.
+func NewUserID() UserID {
+	if generate := userIDGenerator.Load(); generate != nil {
+		return (*generate)()
+	}
+	return NewObjectIDUserID()
+}
+
+var (
+	objectIDProcess = randomBytes(5)
+	objectIDCounter atomic.Uint32
+)
+
+func init() {
+	objectIDCounter.Store(binary.BigEndian.Uint32(randomBytes(4)))
+}
+
+// NewObjectIDUserID returns a MongoDB ObjectID: the time in seconds, a random
+// value per process and a counter.
+func NewObjectIDUserID() UserID {
+	var id [12]byte
+	binary.BigEndian.PutUint32(id[0:4], uint32(time.Now().Unix()))
+	copy(id[4:9], objectIDProcess)
+	counter := objectIDCounter.Add(1)
+	id[9], id[10], id[11] = byte(counter>>16), byte(counter>>8), byte(counter)
+	return UserID(hex.EncodeToString(id[:]))
+}
+
+// NewUUIDv7UserID returns a version 7 UUID: the time in milliseconds followed
+// by random bits.
+func NewUUIDv7UserID() UserID {
+	var id [16]byte
+	putMillis(id[:6])
+	copy(id[6:], randomBytes(10))
+	id[6] = id[6]&0x0f | 0x70 // version 7
+	id[8] = id[8]&0x3f | 0x80 // RFC 9562 variant
+
+	s := hex.EncodeToString(id[:])
+	return UserID(s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:])
+}
+
+// NewULIDUserID returns a ULID: the time in milliseconds followed by random
+// bits, in Crockford's base 32.
+func NewULIDUserID() UserID {
+	var id [16]byte
+	putMillis(id[:6])
+	copy(id[6:], randomBytes(10))
+
+	// 26 digits of 5 bits hold the 128 bits, with two leading zero bits
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	var s [26]byte
+	for i := 25; i >= 0; i-- {
+		s[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return UserID(s[:])
+}
+
+// putMillis writes the time in milliseconds as a 48 bit big endian number.
+func putMillis(b []byte) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(time.Now().UnixMilli()))
+	copy(b, buf[2:])
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("read random bytes: %v", err))
+	}
+	return b
+}
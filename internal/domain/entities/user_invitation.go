@@ -0,0 +1,81 @@
+package entities
+
+import (
+	"time"
+)
+
+// Statuses of a user invitation. Expired is never stored: it is derived from
+// ExpiresAt.
+const (
+	UserInvitationPending  = "pending"
+	UserInvitationAccepted = "accepted"
+	UserInvitationRevoked  = "revoked"
+	UserInvitationExpired  = "expired"
+)
+
+// UserInvitation invites Email to create an account. The token mailed to the
+// invitee is stored as a hash only. Scopes are granted to the account once the
+// invitation is accepted; none stands for the default user scopes.
+type UserInvitation struct {
+	ID ID `bson:"_id"`
+	// TenantID is set by the repository from the tenant the invitation is
+	// created in, and the account is created in the same tenant
+	TenantID string `bson:"tenant_id"`
+	Email    string `bson:"email"`
+	// NormalizedEmail is the key of Email under the email policy, set by the
+	// repository, so that invitations are found as users are.
+	NormalizedEmail string    `bson:"email_normalized"`
+	TokenHash       string    `bson:"token_hash"`
+	Scopes          []string  `bson:"scopes,omitempty"`
+	InvitedBy       UserID    `bson:"invited_by"`
+	CreatedAt       time.Time `bson:"created_at"`
+	ExpiresAt       time.Time `bson:"expires_at"`
+	AcceptedAt      time.Time `bson:"accepted_at,omitempty"`
+	UserID          UserID    `bson:"user_id,omitempty"`
+	RevokedAt       time.Time `bson:"revoked_at,omitempty"`
+}
+
+func NewUserInvitation(email, tokenHash string, scopes []string, invitedBy UserID, expiresAt time.Time) *UserInvitation {
+	return &UserInvitation{
+		ID:        NewID(),
+		Email:     NormalizeEmail(email),
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		InvitedBy: invitedBy,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+}
+
+func (i *UserInvitation) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// Status returns the state of the invitation at the given time.
+func (i *UserInvitation) Status(now time.Time) string {
+	switch {
+	case !i.AcceptedAt.IsZero():
+		return UserInvitationAccepted
+	case !i.RevokedAt.IsZero():
+		return UserInvitationRevoked
+	case i.IsExpired(now):
+		return UserInvitationExpired
+	default:
+		return UserInvitationPending
+	}
+}
+
+// IsPending reports whether the invitation can still be accepted.
+func (i *UserInvitation) IsPending(now time.Time) bool {
+	return i.Status(now) == UserInvitationPending
+}
+
+// Accept records the account created for the invitation.
+func (i *UserInvitation) Accept(userID UserID) {
+	i.AcceptedAt = time.Now()
+	i.UserID = userID
+}
+
+func (i *UserInvitation) Revoke() {
+	i.RevokedAt = time.Now()
+}
@@ -7,18 +7,105 @@ var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUserDisabled       = errors.New("user is disabled")
 	ErrInvalidUserData    = errors.New("invalid user data")
 
 	// Auth errors
 	ErrInvalidToken = errors.New("invalid token")
 	ErrTokenExpired = errors.New("token expired")
 	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+
+	// Scope errors
+	ErrInvalidScope      = errors.New("invalid scope")
+	ErrInsufficientScope = errors.New("insufficient scope")
 
 	// Validation errors
 	ErrInvalidEmail     = errors.New("invalid email format")
 	ErrPasswordTooShort = errors.New("password too short")
 	ErrRequiredField    = errors.New("required field missing")
 
+	// API key errors
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyExists   = errors.New("api key already exists")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrInvalidExpiry  = errors.New("expiry must be in the future")
+
+	// OAuth errors
+	ErrOAuthClientNotFound       = errors.New("oauth client not found")
+	ErrOAuthClientExists         = errors.New("oauth client already exists")
+	ErrInvalidClient             = errors.New("invalid client")
+	ErrInvalidRedirectURI        = errors.New("invalid redirect uri")
+	ErrInvalidGrant              = errors.New("invalid grant")
+	ErrInvalidOAuthRequest       = errors.New("invalid oauth request")
+	ErrUnsupportedGrantType      = errors.New("unsupported grant type")
+	ErrUnsupportedResponseType   = errors.New("unsupported response type")
+	ErrAuthorizationCodeNotFound = errors.New("authorization code not found")
+
+	// Federated login errors
+	ErrIdentityProviderNotFound = errors.New("identity provider not found")
+	ErrInvalidFederatedState    = errors.New("invalid or expired login state")
+	ErrFederatedLoginFailed     = errors.New("federated login failed")
+	ErrEmailNotVerified         = errors.New("email is not verified by the identity provider")
+
+	// Passkey errors
+	ErrPasskeyNotFound           = errors.New("passkey not found")
+	ErrPasskeyExists             = errors.New("passkey already registered")
+	ErrInvalidPasskeyChallenge   = errors.New("invalid or expired passkey challenge")
+	ErrInvalidPasskey            = errors.New("invalid passkey")
+	ErrPasskeyVerificationFailed = errors.New("passkey verification failed")
+	ErrPasskeyCloned             = errors.New("passkey sign counter did not increase, it may have been cloned")
+
+	// Session errors
+	ErrSessionNotFound = errors.New("session not found")
+
+	// Organization errors
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrTeamNotFound         = errors.New("team not found")
+	ErrMembershipNotFound   = errors.New("membership not found")
+	ErrMembershipExists     = errors.New("user is already a member")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrLastOwner            = errors.New("an organization needs at least one owner")
+
+	// User invitation errors
+	ErrUserInvitationNotFound = errors.New("user invitation not found")
+	ErrUserInvitationExists   = errors.New("an invitation is already pending for this email")
+	ErrInvalidUserInvitation  = errors.New("invalid or expired invitation")
+
+	// Tenant errors
+	ErrTenantRequired = errors.New("tenant required")
+	ErrUnknownTenant  = errors.New("unknown tenant")
+
+	// Storage errors
+	ErrUnavailable = errors.New("storage is unavailable, try again later")
+
+	// User event errors
+	ErrInvalidUserEventType = errors.New("invalid user event type")
+	ErrInvalidUserEventID   = errors.New("unknown or expired user event id")
+
+	// Impersonation errors
+	ErrCannotImpersonate = errors.New("user cannot be impersonated")
+	ErrImpersonating     = errors.New("not allowed while impersonating")
+
+	// Magic link errors
+	ErrInvalidMagicLink  = errors.New("invalid or expired sign in link")
+	ErrTooManyMagicLinks = errors.New("too many sign in links requested, try again later")
+
+	// SCIM errors
+	ErrInvalidSCIMFilter    = errors.New("invalid filter")
+	ErrInvalidSCIMPath      = errors.New("invalid attribute path")
+	ErrInvalidSCIMValue     = errors.New("invalid attribute value")
+	ErrSCIMReadOnly         = errors.New("attribute is read-only")
+	ErrSCIMResourceNotFound = errors.New("resource not found")
+
+	// MFA errors
+	ErrMFANotEnrolled     = errors.New("mfa is not enrolled")
+	ErrMFAAlreadyEnabled  = errors.New("mfa is already enabled")
+	ErrMFANotEnabled      = errors.New("mfa is not enabled")
+	ErrInvalidMFACode     = errors.New("invalid mfa code")
+	ErrMFATooManyAttempts = errors.New("too many failed mfa attempts, try again later")
+
 	// Jwt errors
 	ErrEmptyPassword      = errors.New("password cannot be empty")
 	ErrInvalidTokenSecret = errors.New("invalid token secret")
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entities.APIKey) error
+	GetByID(ctx context.Context, id entities.ID) (*entities.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error)
+	GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.APIKey, error)
+	Update(ctx context.Context, key *entities.APIKey) error
+	// MarkUsed records that the key was used at t, leaving the rest of it as
+	// stored.
+	MarkUsed(ctx context.Context, id entities.ID, t time.Time) error
+}
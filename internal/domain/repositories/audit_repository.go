@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, event *entities.AuditEvent) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+type FederatedLoginRepository interface {
+	Create(ctx context.Context, login *entities.FederatedLogin) error
+	// Consume returns the pending login and deletes it in one step, so that
+	// a state can complete at most one login.
+	Consume(ctx context.Context, stateHash string) (*entities.FederatedLogin, error)
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+type MagicLinkRepository interface {
+	Create(ctx context.Context, link *entities.MagicLink) error
+	// CountSince returns how many links were requested for the email since
+	// the given time.
+	CountSince(ctx context.Context, email string, since time.Time) (int64, error)
+	// Consume returns the link and deletes it in one step, so that a link
+	// can sign in at most once.
+	Consume(ctx context.Context, tokenHash string) (*entities.MagicLink, error)
+}
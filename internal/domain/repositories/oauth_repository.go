@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *entities.OAuthClient) error
+	GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error)
+}
+
+type AuthorizationCodeRepository interface {
+	Create(ctx context.Context, code *entities.AuthorizationCode) error
+	// Consume returns the code and deletes it in one step, so that a code
+	// can be redeemed at most once.
+	Consume(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error)
+}
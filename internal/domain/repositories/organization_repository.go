@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+// OrganizationRepository only sees the organizations of the tenant of ctx,
+// like UserRepository.
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *entities.Organization) error
+	GetByID(ctx context.Context, id entities.ID) (*entities.Organization, error)
+	Update(ctx context.Context, organization *entities.Organization) error
+	Delete(ctx context.Context, id entities.ID) error
+}
+
+type TeamRepository interface {
+	Create(ctx context.Context, team *entities.Team) error
+	GetByID(ctx context.Context, id entities.ID) (*entities.Team, error)
+	GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Team, error)
+	Update(ctx context.Context, team *entities.Team) error
+	Delete(ctx context.Context, id entities.ID) error
+	DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error
+}
+
+// MembershipRepository stores organization and team memberships. A user has
+// at most one membership per organization and one per team.
+type MembershipRepository interface {
+	Create(ctx context.Context, membership *entities.Membership) error
+	// Get returns the user's membership of the organization, or of the team
+	// unless teamID is zero.
+	Get(ctx context.Context, organizationID, teamID entities.ID, userID entities.UserID) (*entities.Membership, error)
+	// GetByOrganizationID returns the memberships of the organization and of
+	// all its teams.
+	GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Membership, error)
+	GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Membership, error)
+	Update(ctx context.Context, membership *entities.Membership) error
+	Delete(ctx context.Context, id entities.ID) error
+	DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error
+	DeleteByTeamID(ctx context.Context, teamID entities.ID) error
+}
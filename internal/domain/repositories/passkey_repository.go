@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+type PasskeyRepository interface {
+	Create(ctx context.Context, passkey *entities.Passkey) error
+	GetByID(ctx context.Context, id string) (*entities.Passkey, error)
+	GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Passkey, error)
+	Update(ctx context.Context, passkey *entities.Passkey) error
+	Delete(ctx context.Context, id string) error
+}
+
+type PasskeyCeremonyRepository interface {
+	Create(ctx context.Context, ceremony *entities.PasskeyCeremony) error
+	// Consume returns the ceremony and deletes it in one step, so that a
+	// challenge can be answered at most once.
+	Consume(ctx context.Context, challengeHash string) (*entities.PasskeyCeremony, error)
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entities.Session) error
+	GetByID(ctx context.Context, id entities.ID) (*entities.Session, error)
+	GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Session, error)
+	Update(ctx context.Context, session *entities.Session) error
+}
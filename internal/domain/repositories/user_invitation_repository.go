@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+// UserInvitationRepository stores invitations in the tenant of ctx.
+type UserInvitationRepository interface {
+	Create(ctx context.Context, invitation *entities.UserInvitation) error
+	GetByID(ctx context.Context, id entities.ID) (*entities.UserInvitation, error)
+	// GetByTokenHash is not scoped to a tenant: the token alone names the
+	// invitation, and with it the tenant the account is created in.
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error)
+	// GetByEmail returns the invitations sent to the email, in any state.
+	// Emails match under the email policy, as they do for users.
+	GetByEmail(ctx context.Context, email string) ([]*entities.UserInvitation, error)
+	// GetAll returns the invitations of the tenant, newest first.
+	GetAll(ctx context.Context) ([]*entities.UserInvitation, error)
+	Update(ctx context.Context, invitation *entities.UserInvitation) error
+}
@@ -2,17 +2,108 @@ package repositories
 
 import (
 	"context"
+	"sort"
 
 	"github.com/wonyus/backend-challenge/internal/domain/entities"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// UserRepository only sees the users of the tenant of ctx, set with
+// services.WithTenant. Without a tenant every method fails with
+// ErrTenantRequired. Emails are unique per tenant.
 type UserRepository interface {
 	Create(ctx context.Context, user *entities.User) error
-	GetByID(ctx context.Context, id primitive.ObjectID) (*entities.User, error)
+	GetByID(ctx context.Context, id entities.UserID) (*entities.User, error)
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	// GetByIdentity finds the user linked to the account at an external
+	// identity provider.
+	GetByIdentity(ctx context.Context, provider, subject string) (*entities.User, error)
+	// GetAll returns every user of the tenant, oldest first.
 	GetAll(ctx context.Context) ([]*entities.User, error)
+	// Find returns the page of users of the tenant query selects, and how
+	// many users match it on all pages.
+	Find(ctx context.Context, query UserQuery) ([]*entities.User, int64, error)
 	Update(ctx context.Context, user *entities.User) error
-	Delete(ctx context.Context, id primitive.ObjectID) error
+	Delete(ctx context.Context, id entities.UserID) error
 	Count(ctx context.Context) (int64, error)
 }
+
+// UserSort is the field users are ordered by.
+type UserSort string
+
+const (
+	UserSortCreatedAt UserSort = "createdAt"
+	UserSortName      UserSort = "name"
+	UserSortEmail     UserSort = "email"
+)
+
+// UserQuery selects users of the tenant. The zero query selects every user,
+// oldest first. Users comparing equal on Sort are ordered by ID, so pages
+// never overlap.
+type UserQuery struct {
+	// Email matches the user with the same email under the email policy.
+	Email string
+	// ExternalID matches users with this ID in an external system.
+	ExternalID string
+	// Disabled, when set, matches only disabled or only enabled users.
+	Disabled *bool
+
+	// Sort defaults to UserSortCreatedAt. Strings are compared byte by byte.
+	Sort       UserSort
+	Descending bool
+
+	// Offset users are skipped, and at most Limit returned. A Limit of 0
+	// returns them all.
+	Offset int
+	Limit  int
+}
+
+// Select applies the query to users, all of the tenant, for stores that
+// cannot query themselves. emailKey is the key of an email under the email
+// policy. It returns the page and the number of matching users.
+func (q UserQuery) Select(users []*entities.User, emailKey func(string) string) ([]*entities.User, int64) {
+	var email string
+	if q.Email != "" {
+		email = emailKey(q.Email)
+	}
+
+	var matched []*entities.User
+	for _, user := range users {
+		if email != "" && user.NormalizedEmail != email ||
+			q.ExternalID != "" && user.ExternalID != q.ExternalID ||
+			q.Disabled != nil && user.Disabled != *q.Disabled {
+			continue
+		}
+		matched = append(matched, user)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if q.Descending {
+			a, b = b, a
+		}
+		switch q.Sort {
+		case UserSortName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case UserSortEmail:
+			if a.Email != b.Email {
+				return a.Email < b.Email
+			}
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.ID.String() < b.ID.String()
+	})
+
+	total := int64(len(matched))
+	if q.Offset > 0 {
+		matched = matched[min(q.Offset, len(matched)):]
+	}
+	if q.Limit > 0 && q.Limit < len(matched) {
+		matched = matched[:q.Limit]
+	}
+	return matched, total
+}
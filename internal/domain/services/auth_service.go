@@ -2,13 +2,67 @@ package services
 
 import (
 	"context"
+	"crypto"
+	"time"
 
 	"github.com/wonyus/backend-challenge/internal/domain/entities"
 )
 
+// AccessTokenTTL is how long tokens from GenerateToken stay valid.
+const AccessTokenTTL = 24 * time.Hour
+
+// ImpersonationTokenTTL is how long tokens from GenerateImpersonationToken
+// stay valid.
+const ImpersonationTokenTTL = 15 * time.Minute
+
+// Token is the verified content of a token: its user and the scopes it was
+// issued with. Scopes is nil only for tokens that predate scopes, which carry
+// every scope of the user, and empty for tokens carrying none. SessionID is
+// empty for tokens that predate sessions. Actor is the administrator acting
+// as User, and nil unless the token is an impersonation token.
+type Token struct {
+	User       *entities.User
+	TenantID   string
+	Scopes     []string
+	SessionID  string
+	AuthMethod string
+	Actor      *entities.User
+}
+
+// IDToken describes an OpenID Connect ID token to issue to a client.
+type IDToken struct {
+	User     *entities.User
+	Audience string
+	Nonce    string
+	Scopes   []string
+	AuthTime time.Time
+}
+
+// PublicKey is a verification key published to relying parties.
+type PublicKey struct {
+	KeyID     string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
 type AuthService interface {
-	HashPassword(password string) (string, error)
-	ComparePassword(hashedPassword, password string) error
-	GenerateToken(ctx context.Context, user *entities.User) (string, error)
-	ValidateToken(ctx context.Context, token string) (*entities.User, error)
+	// GenerateToken starts a session for a sign in with authMethod, recording
+	// the Client of ctx, and issues an access token for it.
+	GenerateToken(ctx context.Context, user *entities.User, scopes []string, authMethod string) (string, error)
+	// GenerateImpersonationToken issues a short-lived access token that lets
+	// actor act as user, recording actor in the act claim of RFC 8693.
+	GenerateImpersonationToken(ctx context.Context, actor, user *entities.User, scopes []string) (string, error)
+	// ValidateToken also rejects tokens whose session was revoked, and
+	// impersonation tokens whose actor is no longer an active administrator.
+	// The user is looked up in the tenant of the token, which must match the
+	// tenant of ctx if it has one.
+	ValidateToken(ctx context.Context, token string) (*Token, error)
+	// GenerateMFAToken issues a short-lived token proving the first factor,
+	// authMethod, succeeded. It is only accepted by ValidateMFAToken.
+	GenerateMFAToken(ctx context.Context, user *entities.User, scopes []string, authMethod string) (string, error)
+	ValidateMFAToken(ctx context.Context, token string) (*Token, error)
+	// GenerateIDToken signs with an asymmetric key from PublicKeys, so that
+	// other applications can verify ID tokens without a shared secret.
+	GenerateIDToken(ctx context.Context, idToken *IDToken) (string, error)
+	PublicKeys() []PublicKey
 }
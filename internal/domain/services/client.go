@@ -0,0 +1,23 @@
+package services
+
+import "context"
+
+// Client describes the device a request comes from, as far as the server can
+// tell. Servers attach it to the request context so that sign ins can record
+// where they happened.
+type Client struct {
+	IPAddress string
+	UserAgent string
+}
+
+type clientContextKey struct{}
+
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext returns the client of the request, or a zero Client.
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientContextKey{}).(Client)
+	return client
+}
@@ -0,0 +1,23 @@
+package services
+
+import "context"
+
+// ExternalClaims are the verified claims about a user returned by an external
+// identity provider.
+type ExternalClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// IdentityProvider is an external OpenID Connect provider users can sign in
+// with. The caller keeps state, nonce and the PKCE verifier between the
+// redirect and the callback.
+type IdentityProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems the authorization code and returns the claims of the
+	// verified ID token, which must carry the given nonce.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalClaims, error)
+}
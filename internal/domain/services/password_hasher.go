@@ -0,0 +1,9 @@
+package services
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Compare(hashedPassword, password string) error
+	// NeedsRehash reports whether the hash was produced by an algorithm or
+	// with parameters other than the current ones and should be replaced.
+	NeedsRehash(hashedPassword string) bool
+}
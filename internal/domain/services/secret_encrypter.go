@@ -0,0 +1,8 @@
+package services
+
+// SecretEncrypter protects secrets that have to be stored in a recoverable
+// form, such as TOTP seeds.
+type SecretEncrypter interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
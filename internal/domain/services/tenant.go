@@ -0,0 +1,17 @@
+package services
+
+import "context"
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx scoped to the tenant. User repositories
+// only see users of that tenant.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ctx is scoped to.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
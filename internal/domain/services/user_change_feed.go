@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+// UserWatch selects the events a watcher of a UserChangeFeed gets.
+type UserWatch struct {
+	// Types are the event types sent, all of them when empty.
+	Types []entities.UserEventType
+	// After is the ID of the last event the watcher saw. Events after it are
+	// sent first, then new ones. When empty only new events are sent.
+	After string
+	// Consumer, when set, names a durable watcher of the tenant. The feed
+	// remembers the last event it handled and resumes after it when After is
+	// empty, even across restarts.
+	Consumer string
+}
+
+// UserChangeFeed reports changes to the users of the tenant of ctx, including
+// changes made by other processes when the store shares them.
+type UserChangeFeed interface {
+	// Watch calls handle with each event in turn until ctx ends, when it
+	// returns nil, or handle fails, when it returns its error. An After the
+	// feed no longer knows fails with ErrInvalidUserEventID.
+	Watch(ctx context.Context, watch UserWatch, handle func(*entities.UserEvent) error) error
+}
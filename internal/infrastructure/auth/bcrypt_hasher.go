@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const BcryptAlgorithm = "bcrypt"
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a bcrypt algorithm using cost, or bcrypt.DefaultCost
+// when cost is zero.
+func NewBcryptHasher(cost int) PasswordAlgorithm {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &bcryptHasher{
+		cost: cost,
+	}
+}
+
+func (h *bcryptHasher) Name() string {
+	return BcryptAlgorithm
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedBytes), nil
+}
+
+func (h *bcryptHasher) Compare(hashedPassword, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+}
+
+func (h *bcryptHasher) Matches(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2a$") ||
+		strings.HasPrefix(hashedPassword, "$2b$") ||
+		strings.HasPrefix(hashedPassword, "$2y$")
+}
+
+func (h *bcryptHasher) Outdated(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return true
+	}
+	return cost != h.cost
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"strings"
+
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+type CredentialType int
+
+const (
+	BearerCredential CredentialType = iota + 1
+	APIKeyCredential
+)
+
+// ParseCredentials picks the credential from an Authorization value
+// ("Bearer <jwt>" or "ApiKey <key>") or, failing that, an X-API-Key value.
+// HTTP headers and gRPC metadata carry them the same way.
+func ParseCredentials(authorization, apiKey string) (CredentialType, string, error) {
+	if authorization == "" {
+		if apiKey == "" {
+			return 0, "", domainErrors.ErrUnauthorized
+		}
+		return APIKeyCredential, apiKey, nil
+	}
+
+	scheme, credential, ok := strings.Cut(authorization, " ")
+	if !ok || credential == "" || strings.Contains(credential, " ") {
+		return 0, "", domainErrors.ErrInvalidToken
+	}
+
+	switch {
+	case strings.EqualFold(scheme, "Bearer"):
+		return BearerCredential, credential, nil
+	case strings.EqualFold(scheme, "ApiKey"):
+		return APIKeyCredential, credential, nil
+	default:
+		return 0, "", domainErrors.ErrInvalidToken
+	}
+}
@@ -2,6 +2,9 @@ package auth
 
 import (
 	"context"
+	"crypto/rsa"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,52 +12,168 @@ import (
 	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
 	"github.com/wonyus/backend-challenge/internal/domain/repositories"
 	"github.com/wonyus/backend-challenge/internal/domain/services"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	mfaTokenTTL = 5 * time.Minute
+	idTokenTTL  = time.Hour
+
+	// purposeMFA marks tokens that only allow completing an MFA challenge.
+	purposeMFA = "mfa"
+
+	// sessionTouchInterval limits how often validating a token writes the
+	// last seen time of its session.
+	sessionTouchInterval = time.Minute
 )
 
 type jwtService struct {
 	secret   []byte
 	userRepo repositories.UserRepository
+
+	// sessionRepo is optional, without it tokens are not tied to sessions
+	sessionRepo repositories.SessionRepository
+
+	// issuer, signingKey and keyID are only needed to issue ID tokens
+	issuer     string
+	signingKey *rsa.PrivateKey
+	keyID      string
 }
 
 type Claims struct {
 	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	// Tenant is empty on tokens issued before tenants existed, which belong
+	// to the default tenant
+	Tenant  string `json:"tenant,omitempty"`
+	Email   string `json:"email"`
+	Purpose string `json:"purpose,omitempty"`
+	// AuthMethod is how the user signed in, see the entities.AuthMethod constants
+	AuthMethod string `json:"auth_method,omitempty"`
+	// Scopes are the scopes the token carries, an empty list carrying none.
+	// Tokens issued before it existed lack it and carry every scope of their
+	// user, or those of Scope when set.
+	Scopes []string `json:"scopes"`
+	// Scope is a space-delimited list, as in OAuth2 access tokens
+	Scope string `json:"scope,omitempty"`
+	// Actor is set on impersonation tokens, see RFC 8693 section 4.1
+	Actor *ActorClaim `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ActorClaim identifies the administrator acting as the token's user.
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// IDTokenClaims are the OpenID Connect claims of an ID token. Name and Email
+// are only filled in when the profile and email scopes were granted.
+type IDTokenClaims struct {
+	Nonce    string           `json:"nonce,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	Name     string           `json:"name,omitempty"`
+	Email    string           `json:"email,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(secret string, userRepo repositories.UserRepository) services.AuthService {
-	return &jwtService{
+type JWTServiceOption func(*jwtService)
+
+// WithIDTokenSigning enables ID tokens, signed with RS256 by key and issued
+// by issuer.
+func WithIDTokenSigning(issuer string, key *rsa.PrivateKey) JWTServiceOption {
+	return func(s *jwtService) {
+		s.issuer = issuer
+		s.signingKey = key
+		s.keyID = KeyThumbprint(&key.PublicKey)
+	}
+}
+
+// WithSessions records a session for every access token, using the jti claim
+// to link them, and rejects tokens whose session is no longer active.
+func WithSessions(repo repositories.SessionRepository) JWTServiceOption {
+	return func(s *jwtService) {
+		s.sessionRepo = repo
+	}
+}
+
+func NewJWTService(secret string, userRepo repositories.UserRepository, opts ...JWTServiceOption) services.AuthService {
+	s := &jwtService{
 		secret:   []byte(secret),
 		userRepo: userRepo,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *jwtService) GenerateToken(ctx context.Context, user *entities.User) (string, error) {
+func (s *jwtService) GenerateToken(ctx context.Context, user *entities.User, scopes []string, authMethod string) (string, error) {
+	return s.generate(ctx, user, nil, scopes, authMethod, "", services.AccessTokenTTL)
+}
+
+func (s *jwtService) GenerateMFAToken(ctx context.Context, user *entities.User, scopes []string, authMethod string) (string, error) {
+	return s.generate(ctx, user, nil, scopes, authMethod, purposeMFA, mfaTokenTTL)
+}
+
+func (s *jwtService) GenerateImpersonationToken(ctx context.Context, actor, user *entities.User, scopes []string) (string, error) {
+	if !actor.IsActive() {
+		return "", domainErrors.ErrUserDisabled
+	}
+	return s.generate(ctx, user, actor, scopes, entities.AuthMethodImpersonation, "", services.ImpersonationTokenTTL)
+}
+
+func (s *jwtService) generate(ctx context.Context, user, actor *entities.User, scopes []string, authMethod, purpose string, ttl time.Duration) (string, error) {
 	if string(s.secret) == "" {
 		return "", domainErrors.ErrInvalidTokenSecret
 	}
 
-	expirationTime := time.Now().Add(24 * time.Hour)
+	if !user.IsActive() {
+		return "", domainErrors.ErrUserDisabled
+	}
+
+	expirationTime := time.Now().Add(ttl)
 	claims := &Claims{
-		UserID: user.ID.Hex(),
-		Email:  user.Email,
+		UserID:     user.ID.String(),
+		Tenant:     user.TenantID,
+		Email:      user.Email,
+		Purpose:    purpose,
+		AuthMethod: authMethod,
+		Scopes:     append([]string{}, scopes...),
+		Scope:      strings.Join(scopes, " "),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if actor != nil {
+		claims.Actor = &ActorClaim{Subject: actor.ID.String()}
+	}
+
+	// MFA tokens only lead to an access token, which gets its own session
+	if s.sessionRepo != nil && purpose == "" {
+		client := services.ClientFromContext(ctx)
+		session := entities.NewSession(user.ID, authMethod, client.IPAddress, client.UserAgent, expirationTime)
+		if err := s.sessionRepo.Create(ctx, session); err != nil {
+			return "", err
+		}
+		claims.ID = session.ID.String()
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(s.secret)
 }
 
-func (s *jwtService) ValidateToken(ctx context.Context, tokenString string) (*entities.User, error) {
+func (s *jwtService) ValidateToken(ctx context.Context, tokenString string) (*services.Token, error) {
+	return s.validate(ctx, tokenString, "")
+}
+
+func (s *jwtService) ValidateMFAToken(ctx context.Context, tokenString string) (*services.Token, error) {
+	return s.validate(ctx, tokenString, purposeMFA)
+}
+
+func (s *jwtService) validate(ctx context.Context, tokenString, purpose string) (*services.Token, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		return s.secret, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, domainErrors.ErrInvalidToken
@@ -64,31 +183,163 @@ func (s *jwtService) ValidateToken(ctx context.Context, tokenString string) (*en
 		return nil, domainErrors.ErrInvalidToken
 	}
 
-	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	// An MFA challenge token must never be usable as an access token
+	if claims.Purpose != purpose {
+		return nil, domainErrors.ErrInvalidToken
+	}
+
+	userID, err := entities.ParseUserID(claims.UserID)
 	if err != nil {
 		return nil, domainErrors.ErrInvalidToken
 	}
 
+	// A token only works in its own tenant. Without a tenant in ctx, the
+	// token decides.
+	tenantID := claims.Tenant
+	if tenantID == "" {
+		tenantID = entities.DefaultTenant
+	}
+	if requested, ok := services.TenantFromContext(ctx); ok && requested != tenantID {
+		return nil, domainErrors.ErrInvalidToken
+	}
+	ctx = services.WithTenant(ctx, tenantID)
+
 	user, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, domainErrors.ErrUserNotFound
 	}
 
-	return user, nil
+	if !user.IsActive() {
+		return nil, domainErrors.ErrUserDisabled
+	}
+
+	if claims.ID != "" {
+		if err := s.checkSession(ctx, claims.ID, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var actor *entities.User
+	if claims.Actor != nil {
+		if actor, err = s.loadActor(ctx, claims.Actor.Subject); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only tokens issued before the list of scopes existed lack it
+	scopes := claims.Scopes
+	if scopes == nil && claims.Scope != "" {
+		scopes = strings.Fields(claims.Scope)
+	}
+
+	return &services.Token{
+		User:       user,
+		TenantID:   tenantID,
+		Scopes:     scopes,
+		SessionID:  claims.ID,
+		AuthMethod: claims.AuthMethod,
+		Actor:      actor,
+	}, nil
 }
 
-func (s *jwtService) HashPassword(password string) (string, error) {
-	if password == "" {
-		return "", domainErrors.ErrEmptyPassword
+// loadActor returns the administrator behind an impersonation token. The
+// token dies with the actor's account or admin rights.
+func (s *jwtService) loadActor(ctx context.Context, actorID string) (*entities.User, error) {
+	id, err := entities.ParseUserID(actorID)
+	if err != nil {
+		return nil, domainErrors.ErrInvalidToken
+	}
+
+	actor, err := s.userRepo.GetByID(ctx, id)
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return nil, err
 	}
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
+		return nil, domainErrors.ErrInvalidToken
+	}
 
-		return "", err
+	if !actor.IsActive() || !entities.HasScope(actor.GrantedScopes(), entities.ScopeAdmin) {
+		return nil, domainErrors.ErrInvalidToken
 	}
-	return string(hashedBytes), nil
+
+	return actor, nil
 }
 
-func (s *jwtService) ComparePassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// checkSession rejects tokens whose session was revoked, expired or belongs
+// to someone else. Tokens issued before sessions existed carry no jti and
+// are not checked.
+func (s *jwtService) checkSession(ctx context.Context, sessionID string, userID entities.UserID) error {
+	if s.sessionRepo == nil {
+		return nil
+	}
+
+	id, err := entities.ParseID(sessionID)
+	if err != nil {
+		return domainErrors.ErrInvalidToken
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrSessionNotFound) {
+			return domainErrors.ErrInvalidToken
+		}
+		return err
+	}
+
+	now := time.Now()
+	if session.UserID != userID || !session.IsActive(now) {
+		return domainErrors.ErrInvalidToken
+	}
+
+	// Best effort, a failed write must not fail the request
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		session.Touch(now)
+		_ = s.sessionRepo.Update(ctx, session)
+	}
+
+	return nil
+}
+
+func (s *jwtService) GenerateIDToken(ctx context.Context, idToken *services.IDToken) (string, error) {
+	if s.signingKey == nil {
+		return "", domainErrors.ErrInvalidTokenSecret
+	}
+
+	now := time.Now()
+	claims := &IDTokenClaims{
+		Nonce:    idToken.Nonce,
+		AuthTime: jwt.NewNumericDate(idToken.AuthTime),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   idToken.User.ID.String(),
+			Audience:  jwt.ClaimStrings{idToken.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(idTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	if entities.HasScope(idToken.Scopes, entities.ScopeProfile) {
+		claims.Name = idToken.User.Name
+	}
+	if entities.HasScope(idToken.Scopes, entities.ScopeEmail) {
+		claims.Email = idToken.User.Email
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.signingKey)
+}
+
+func (s *jwtService) PublicKeys() []services.PublicKey {
+	if s.signingKey == nil {
+		return nil
+	}
+
+	return []services.PublicKey{{
+		KeyID:     s.keyID,
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		Key:       &s.signingKey.PublicKey,
+	}}
 }
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/memory"
+)
+
+func TestJWTService_Scopes(t *testing.T) {
+	ctx := services.WithTenant(context.Background(), entities.DefaultTenant)
+	userRepo := memory.NewUserRepository(entities.EmailPolicy{})
+	jwtService := NewJWTService("test-secret", userRepo)
+	user, err := entities.NewUser("Dana", "dana@example.com", "hash")
+	assert.NoError(t, err)
+	assert.NoError(t, userRepo.Create(ctx, user))
+
+	// sign issues a token with claims as an earlier version would have
+	sign := func(t *testing.T, claims jwt.MapClaims) string {
+		claims["user_id"] = user.ID.String()
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+		assert.NoError(t, err)
+		return token
+	}
+
+	for name, test := range map[string]struct {
+		scopes []string
+		want   []string
+	}{
+		"Some scopes": {scopes: []string{entities.ScopeUsersRead, entities.ScopeEmail}, want: []string{entities.ScopeUsersRead, entities.ScopeEmail}},
+		"No scopes":   {scopes: []string{}, want: []string{}},
+		"Nil scopes":  {scopes: nil, want: []string{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			token, err := jwtService.GenerateToken(ctx, user, test.scopes, entities.AuthMethodPassword)
+			assert.NoError(t, err)
+
+			verified, err := jwtService.ValidateToken(ctx, token)
+			if assert.NoError(t, err) {
+				// Carrying no scopes never reads as carrying every scope
+				assert.NotNil(t, verified.Scopes)
+				assert.Equal(t, test.want, verified.Scopes)
+			}
+		})
+	}
+
+	t.Run("Tokens with a space-delimited scope", func(t *testing.T) {
+		verified, err := jwtService.ValidateToken(ctx, sign(t, jwt.MapClaims{"scope": "users:read email"}))
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{entities.ScopeUsersRead, entities.ScopeEmail}, verified.Scopes)
+		}
+	})
+
+	t.Run("Tokens that predate scopes", func(t *testing.T) {
+		verified, err := jwtService.ValidateToken(ctx, sign(t, jwt.MapClaims{}))
+		if assert.NoError(t, err) {
+			assert.Nil(t, verified.Scopes)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+var (
+	// ErrUnknownHashFormat is returned when a stored hash is not recognised
+	// by any registered algorithm.
+	ErrUnknownHashFormat = errors.New("unknown password hash format")
+	ErrPasswordMismatch  = errors.New("password does not match")
+)
+
+// PasswordAlgorithm is a single hashing scheme that the hasher registry can
+// produce or verify. Algorithms identify their own hashes by prefix.
+type PasswordAlgorithm interface {
+	Name() string
+	Hash(password string) (string, error)
+	Compare(hashedPassword, password string) error
+	// Matches reports whether the hash was produced by this algorithm.
+	Matches(hashedPassword string) bool
+	// Outdated reports whether the hash was produced by this algorithm but
+	// with parameters that differ from the configured ones.
+	Outdated(hashedPassword string) bool
+}
+
+type passwordHasher struct {
+	current    PasswordAlgorithm
+	algorithms []PasswordAlgorithm
+}
+
+// NewPasswordHasher returns a hasher that hashes new passwords with current
+// and verifies existing hashes with whichever registered algorithm matches
+// their prefix. Hashes from any algorithm other than current, or from current
+// with outdated parameters, are reported as needing a rehash.
+func NewPasswordHasher(current PasswordAlgorithm, legacy ...PasswordAlgorithm) services.PasswordHasher {
+	return &passwordHasher{
+		current:    current,
+		algorithms: append([]PasswordAlgorithm{current}, legacy...),
+	}
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	if password == "" {
+		return "", domainErrors.ErrEmptyPassword
+	}
+	return h.current.Hash(password)
+}
+
+func (h *passwordHasher) Compare(hashedPassword, password string) error {
+	algorithm := h.algorithmFor(hashedPassword)
+	if algorithm == nil {
+		return ErrUnknownHashFormat
+	}
+	return algorithm.Compare(hashedPassword, password)
+}
+
+func (h *passwordHasher) NeedsRehash(hashedPassword string) bool {
+	if !h.current.Matches(hashedPassword) {
+		return true
+	}
+	return h.current.Outdated(hashedPassword)
+}
+
+func (h *passwordHasher) algorithmFor(hashedPassword string) PasswordAlgorithm {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Matches(hashedPassword) {
+			return algorithm
+		}
+	}
+	return nil
+}
+
+// NewConfiguredPasswordHasher builds a hasher that hashes with the named
+// algorithm and still verifies hashes of every other supported algorithm, so
+// switching algorithms migrates users transparently as they log in.
+func NewConfiguredPasswordHasher(algorithm string, bcryptCost int, argon2idParams Argon2idParams) (services.PasswordHasher, error) {
+	bcryptHasher := NewBcryptHasher(bcryptCost)
+	argon2idHasher := NewArgon2idHasher(argon2idParams)
+
+	switch algorithm {
+	case "", BcryptAlgorithm:
+		return NewPasswordHasher(bcryptHasher, argon2idHasher), nil
+	case Argon2idAlgorithm:
+		return NewPasswordHasher(argon2idHasher, bcryptHasher), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", algorithm)
+	}
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+// Small Argon2id parameters keep the tests fast.
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestPasswordHasher_Bcrypt(t *testing.T) {
+	hasher := NewPasswordHasher(NewBcryptHasher(4))
+
+	hash, err := hasher.Hash("password")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$04$"))
+	assert.NoError(t, hasher.Compare(hash, "password"))
+	assert.Error(t, hasher.Compare(hash, "wrong"))
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	t.Run("Cost bump needs rehash", func(t *testing.T) {
+		bumped := NewPasswordHasher(NewBcryptHasher(5))
+		assert.NoError(t, bumped.Compare(hash, "password"))
+		assert.True(t, bumped.NeedsRehash(hash))
+	})
+}
+
+func TestPasswordHasher_Argon2id(t *testing.T) {
+	hasher := NewPasswordHasher(NewArgon2idHasher(testArgon2idParams))
+
+	hash, err := hasher.Hash("password")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	assert.NoError(t, hasher.Compare(hash, "password"))
+	assert.ErrorIs(t, hasher.Compare(hash, "wrong"), ErrPasswordMismatch)
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	t.Run("Salted", func(t *testing.T) {
+		other, err := hasher.Hash("password")
+		assert.NoError(t, err)
+		assert.NotEqual(t, hash, other)
+	})
+
+	t.Run("Parameter change needs rehash", func(t *testing.T) {
+		params := testArgon2idParams
+		params.Iterations = 2
+		tuned := NewPasswordHasher(NewArgon2idHasher(params))
+		assert.NoError(t, tuned.Compare(hash, "password"))
+		assert.True(t, tuned.NeedsRehash(hash))
+	})
+
+	t.Run("Malformed hash", func(t *testing.T) {
+		assert.Error(t, hasher.Compare("$argon2id$v=19$m=1024,t=1,p=1$not-base64!$", "password"))
+		assert.True(t, hasher.NeedsRehash("$argon2id$garbage"))
+	})
+
+	t.Run("Hash with invalid parameters", func(t *testing.T) {
+		parts := strings.Split(hash, "$")
+		salt, key := parts[4], parts[5]
+		for name, malformed := range map[string]string{
+			"No parallelism":    "$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key,
+			"No iterations":     "$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key,
+			"Too little memory": "$argon2id$v=19$m=15,t=1,p=2$" + salt + "$" + key,
+			"Empty salt":        "$argon2id$v=19$m=1024,t=1,p=1$$" + key,
+			"Empty key":         "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$",
+			"Short key":         "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$" + key[:8],
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Rejected without deriving a key, which could panic or match
+				// any password
+				err := hasher.Compare(malformed, "anything")
+				assert.ErrorIs(t, err, errInvalidArgon2idHash)
+				assert.True(t, hasher.NeedsRehash(malformed))
+			})
+		}
+	})
+}
+
+func TestPasswordHasher_Registry(t *testing.T) {
+	bcryptHasher := NewBcryptHasher(4)
+	argon2idHasher := NewArgon2idHasher(testArgon2idParams)
+
+	legacyHash, err := bcryptHasher.Hash("password")
+	assert.NoError(t, err)
+
+	hasher := NewPasswordHasher(argon2idHasher, bcryptHasher)
+
+	t.Run("Verifies legacy algorithm by prefix", func(t *testing.T) {
+		assert.NoError(t, hasher.Compare(legacyHash, "password"))
+		assert.Error(t, hasher.Compare(legacyHash, "wrong"))
+	})
+
+	t.Run("Legacy algorithm needs rehash", func(t *testing.T) {
+		assert.True(t, hasher.NeedsRehash(legacyHash))
+	})
+
+	t.Run("Hashes with current algorithm", func(t *testing.T) {
+		hash, err := hasher.Hash("password")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$"))
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		assert.ErrorIs(t, hasher.Compare("plaintext", "plaintext"), ErrUnknownHashFormat)
+		assert.True(t, hasher.NeedsRehash("plaintext"))
+	})
+
+	t.Run("Empty password", func(t *testing.T) {
+		_, err := hasher.Hash("")
+		assert.ErrorIs(t, err, domainErrors.ErrEmptyPassword)
+	})
+}
+
+func TestNewConfiguredPasswordHasher(t *testing.T) {
+	hasher, err := NewConfiguredPasswordHasher(Argon2idAlgorithm, 4, testArgon2idParams)
+	assert.NoError(t, err)
+	hash, err := hasher.Hash("password")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$"))
+
+	hasher, err = NewConfiguredPasswordHasher("", 4, testArgon2idParams)
+	assert.NoError(t, err)
+	hash, err = hasher.Hash("password")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$04$"))
+
+	_, err = NewConfiguredPasswordHasher("md5", 4, testArgon2idParams)
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+)
+
+const signingKeyBits = 2048
+
+var ErrInvalidSigningKey = errors.New("invalid RSA signing key")
+
+// LoadSigningKey reads a PEM encoded RSA private key in PKCS #1 or PKCS #8
+// form.
+func LoadSigningKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidSigningKey
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidSigningKey
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidSigningKey
+	}
+	return key, nil
+}
+
+// GenerateSigningKey creates a throwaway key. ID tokens signed with it stop
+// verifying once the process restarts.
+func GenerateSigningKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, signingKeyBits)
+}
+
+// KeyThumbprint is the RFC 7638 JWK thumbprint of key, used as its key ID.
+func KeyThumbprint(key *rsa.PublicKey) string {
+	// Members in lexicographic order, without whitespace
+	jwk := `{"e":"` + encodeBigInt(big.NewInt(int64(key.E))) + `","kty":"RSA","n":"` + encodeBigInt(key.N) + `"}`
+	sum := sha256.Sum256([]byte(jwk))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+// TenantHeader names the tenant of a request explicitly. gRPC metadata uses
+// the lowercase form.
+const TenantHeader = "X-Tenant-ID"
+
+// Tenants are the tenants a deployment serves. The default tenant is always
+// among them.
+type Tenants struct {
+	known map[string]bool
+}
+
+func NewTenants(ids ...string) (*Tenants, error) {
+	t := &Tenants{known: map[string]bool{entities.DefaultTenant: true}}
+	for _, id := range ids {
+		if !entities.IsValidTenantID(id) {
+			return nil, fmt.Errorf("invalid tenant ID %q", id)
+		}
+		t.known[id] = true
+	}
+	return t, nil
+}
+
+// Resolve checks that a tenant named by a request exists.
+func (t *Tenants) Resolve(id string) (string, error) {
+	if !t.known[id] {
+		return "", domainErrors.ErrUnknownTenant
+	}
+	return id, nil
+}
+
+// IDs lists the tenants in order.
+func (t *Tenants) IDs() []string {
+	ids := make([]string, 0, len(t.known))
+	for id := range t.known {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -10,9 +11,177 @@ import (
 type Config struct {
 	MongoURI     string `yaml:"mongoUri" json:"mongoUri"`
 	DatabaseName string `yaml:"databaseName" json:"databaseName"`
-	JWTSecret    string `yaml:"jwtSecret" json:"jwtSecret"`
-	HTTPPort     string `yaml:"httpPort" json:"httpPort"`
-	GRPCPort     string `yaml:"grpcPort" json:"grpcPort"`
+	// Mongo tunes the MongoDB client and how repositories use it.
+	Mongo Mongo `yaml:"mongo" json:"mongo"`
+	// Storage picks the database the data is kept in.
+	Storage Storage `yaml:"storage" json:"storage"`
+	// UserCache keeps users looked up by ID in memory, in front of Storage.
+	UserCache UserCache `yaml:"userCache" json:"userCache"`
+	// UserEvents tunes the feed of changes to users.
+	UserEvents UserEvents `yaml:"userEvents" json:"userEvents"`
+	// Deprecated: RunMigrations is the old name of Storage.RunMigrations,
+	// which Load turns on when it is set.
+	RunMigrations bool   `yaml:"runMigrations" json:"runMigrations"`
+	JWTSecret     string `yaml:"jwtSecret" json:"jwtSecret"`
+	HTTPPort      string `yaml:"httpPort" json:"httpPort"`
+	GRPCPort      string `yaml:"grpcPort" json:"grpcPort"`
+
+	// EnumerationSafeRegistration makes registration respond identically
+	// whether or not the email is already taken, notifying the owner by email.
+	EnumerationSafeRegistration bool `yaml:"enumerationSafeRegistration" json:"enumerationSafeRegistration"`
+
+	// EmailLocalPart decides whether the part of an email before the @ is
+	// compared case sensitively ("caseSensitive") or not ("caseInsensitive",
+	// the default). EmailIgnoreSubaddress also ignores a +tag in it. Stored
+	// users keep the keys they were saved with, so settle these before users
+	// sign up.
+	EmailLocalPart        string `yaml:"emailLocalPart" json:"emailLocalPart"`
+	EmailIgnoreSubaddress bool   `yaml:"emailIgnoreSubaddress" json:"emailIgnoreSubaddress"`
+
+	// UserIDFormat is the format new user IDs are generated in: "objectId"
+	// (the default), "uuidv7" or "ulid". IDs in any of them are accepted, so
+	// it can be changed without touching existing users.
+	UserIDFormat string `yaml:"userIdFormat" json:"userIdFormat"`
+
+	SMTPHost     string `yaml:"smtpHost" json:"smtpHost"`
+	SMTPPort     string `yaml:"smtpPort" json:"smtpPort"`
+	SMTPUsername string `yaml:"smtpUsername" json:"smtpUsername"`
+	SMTPPassword string `yaml:"smtpPassword" json:"smtpPassword"`
+	MailFrom     string `yaml:"mailFrom" json:"mailFrom"`
+
+	// PasswordHashAlgorithm is used for new hashes ("bcrypt" or "argon2id").
+	// Hashes made with the other algorithm or older parameters are upgraded
+	// on the next successful login.
+	PasswordHashAlgorithm string `yaml:"passwordHashAlgorithm" json:"passwordHashAlgorithm"`
+	BcryptCost            int    `yaml:"bcryptCost" json:"bcryptCost"`
+	Argon2Memory          uint32 `yaml:"argon2Memory" json:"argon2Memory"`
+	Argon2Iterations      uint32 `yaml:"argon2Iterations" json:"argon2Iterations"`
+	Argon2Parallelism     uint8  `yaml:"argon2Parallelism" json:"argon2Parallelism"`
+
+	// MFAEncryptionKey is a base64 encoded 32 byte key used to encrypt TOTP
+	// secrets at rest.
+	MFAEncryptionKey string `yaml:"mfaEncryptionKey" json:"mfaEncryptionKey"`
+	MFAIssuer        string `yaml:"mfaIssuer" json:"mfaIssuer"`
+
+	// OIDCIssuer is the public base URL of the OpenID Connect provider.
+	// OIDCSigningKeyFile holds the PEM encoded RSA key for ID tokens; without
+	// it a temporary key is generated at startup.
+	OIDCIssuer         string `yaml:"oidcIssuer" json:"oidcIssuer"`
+	OIDCSigningKeyFile string `yaml:"oidcSigningKeyFile" json:"oidcSigningKeyFile"`
+
+	// SCIMToken is a dedicated bearer token for the SCIM provisioning
+	// endpoints. Empty disables it; API keys with the scim:provision scope
+	// still work.
+	SCIMToken string `yaml:"scimToken" json:"scimToken"`
+
+	// MagicLinkURL is where mailed sign in links point, for example a page of
+	// the web app that calls the callback. It defaults to the callback route
+	// under OIDCIssuer.
+	MagicLinkURL string `yaml:"magicLinkUrl" json:"magicLinkUrl"`
+
+	// InvitationURL is where mailed invitation links point: a page of the web
+	// app that asks for a name and password and posts them, with the token,
+	// to the accept route. It defaults to /invitations/accept under
+	// OIDCIssuer.
+	InvitationURL string `yaml:"invitationUrl" json:"invitationUrl"`
+
+	// WebAuthnRPID is the domain passkeys are bound to and WebAuthnOrigins
+	// the origins the web app runs on. Both default to OIDCIssuer.
+	WebAuthnRPID    string   `yaml:"webauthnRpId" json:"webauthnRpId"`
+	WebAuthnRPName  string   `yaml:"webauthnRpName" json:"webauthnRpName"`
+	WebAuthnOrigins []string `yaml:"webauthnOrigins" json:"webauthnOrigins"`
+
+	// Tenants lists the tenants besides the default one. A request names its
+	// tenant in the X-Tenant-ID header or, when TenantDomain is set, as the
+	// subdomain in front of it.
+	Tenants      []string `yaml:"tenants" json:"tenants"`
+	TenantDomain string   `yaml:"tenantDomain" json:"tenantDomain"`
+
+	// FederatedProviders are external OpenID Connect providers users can
+	// sign in with.
+	FederatedProviders []FederatedProvider `yaml:"federatedProviders" json:"federatedProviders"`
+}
+
+// Storage configures where the data is kept. Driver is "mongodb" (the
+// default), which keeps it in the database at MongoURI, or one of these,
+// which need no MongoDB:
+//
+//   - "sqlite" or "postgres": DSN is the SQLite database file or the
+//     PostgreSQL connection string.
+//   - "bolt": DSN is the bolt file. With BackupDir set, a snapshot of the
+//     file is written there every BackupInterval (a day by default).
+//   - "memory", for development, which keeps the data until the process
+//     exits.
+//
+// RunMigrations applies pending schema migrations to MongoDB or the SQL
+// database at startup. Instances starting together wait for each other, so
+// only one of them migrates. Bolt and memory need none.
+type Storage struct {
+	Driver         string        `yaml:"driver" json:"driver"`
+	DSN            string        `yaml:"dsn" json:"dsn"`
+	RunMigrations  bool          `yaml:"runMigrations" json:"runMigrations"`
+	BackupDir      string        `yaml:"backupDir" json:"backupDir"`
+	BackupInterval time.Duration `yaml:"backupInterval" json:"backupInterval"`
+}
+
+// Mongo tunes the MongoDB client. Options left empty keep what MongoURI, or
+// else the driver, says. WriteConcern is "majority" or a number of members.
+//
+// Every operation of a repository gets OperationTimeout per attempt. Reads
+// failing with a transient error, such as a network error or timeout, are
+// retried up to MaxRetries times, waiting a random time of up to
+// RetryBackoff, doubled for every retry; writes only when they never reached
+// a server. BreakerThreshold transient failures in a row make operations
+// fail fast as unavailable for BreakerCooldown, after which a single
+// operation probes whether MongoDB recovered. Zero disables each of them.
+type Mongo struct {
+	AppName                string        `yaml:"appName" json:"appName"`
+	MinPoolSize            uint64        `yaml:"minPoolSize" json:"minPoolSize"`
+	MaxPoolSize            uint64        `yaml:"maxPoolSize" json:"maxPoolSize"`
+	MaxConnIdleTime        time.Duration `yaml:"maxConnIdleTime" json:"maxConnIdleTime"`
+	ConnectTimeout         time.Duration `yaml:"connectTimeout" json:"connectTimeout"`
+	ServerSelectionTimeout time.Duration `yaml:"serverSelectionTimeout" json:"serverSelectionTimeout"`
+	ReadPreference         string        `yaml:"readPreference" json:"readPreference"`
+	ReadConcern            string        `yaml:"readConcern" json:"readConcern"`
+	WriteConcern           string        `yaml:"writeConcern" json:"writeConcern"`
+	Compressors            []string      `yaml:"compressors" json:"compressors"`
+
+	OperationTimeout time.Duration `yaml:"operationTimeout" json:"operationTimeout"`
+	MaxRetries       int           `yaml:"maxRetries" json:"maxRetries"`
+	RetryBackoff     time.Duration `yaml:"retryBackoff" json:"retryBackoff"`
+	BreakerThreshold int           `yaml:"breakerThreshold" json:"breakerThreshold"`
+	BreakerCooldown  time.Duration `yaml:"breakerCooldown" json:"breakerCooldown"`
+}
+
+// UserCache configures caching users in process memory, which spares the
+// user store the lookup every authenticated request makes. Changes made
+// through another instance are seen up to TTL late, including disabling or
+// deleting a user. Size defaults to 10000 users and TTL to 30 seconds.
+type UserCache struct {
+	Enabled bool          `yaml:"enabled" json:"enabled"`
+	Size    int           `yaml:"size" json:"size"`
+	TTL     time.Duration `yaml:"ttl" json:"ttl"`
+}
+
+// UserEvents configures streaming changes to users. With users in MongoDB
+// the feed is a change stream, which sees the changes of every instance.
+// Otherwise it only sees the changes made through this instance and keeps
+// the last History of them in memory, 1000 by default. Heartbeat is how often
+// an idle Server-Sent Events stream sends a comment, 15 seconds by default.
+type UserEvents struct {
+	History   int           `yaml:"history" json:"history"`
+	Heartbeat time.Duration `yaml:"heartbeat" json:"heartbeat"`
+}
+
+// FederatedProvider configures sign in through an external OpenID Connect
+// provider. RedirectURL defaults to the callback route under OIDCIssuer.
+type FederatedProvider struct {
+	Name         string   `yaml:"name" json:"name"`
+	Issuer       string   `yaml:"issuer" json:"issuer"`
+	ClientID     string   `yaml:"clientId" json:"clientId"`
+	ClientSecret string   `yaml:"clientSecret" json:"clientSecret"`
+	RedirectURL  string   `yaml:"redirectUrl" json:"redirectUrl"`
+	Scopes       []string `yaml:"scopes" json:"scopes"`
 }
 
 // Load reads configuration from environment variables or defaults.
@@ -31,5 +200,9 @@ func Load() *Config {
 	if err := viper.Unmarshal(&config); err != nil {
 		fmt.Println("Error unmarshalling config:", err)
 	}
+	if config.RunMigrations {
+		fmt.Println("runMigrations is deprecated, use storage.runMigrations instead")
+		config.Storage.RunMigrations = true
+	}
 	return &config
 }
@@ -0,0 +1,301 @@
+package federation
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+var defaultScopes = []string{"openid", "email", "profile"}
+
+// ProviderConfig describes an external OpenID Connect provider. RedirectURL
+// is this service's callback and must be registered with the provider.
+type ProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// providerMetadata is the part of the discovery document the relying party
+// needs.
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type idTokenClaims struct {
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	Name            string `json:"name"`
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+type oidcProvider struct {
+	config ProviderConfig
+	client *http.Client
+
+	mutex    sync.Mutex
+	metadata *providerMetadata
+	keys     map[string]*rsa.PublicKey
+}
+
+// NewOIDCProvider creates a relying party for the provider. Discovery and
+// signing keys are fetched on first use and cached; keys are refetched when
+// an ID token names an unknown key.
+func NewOIDCProvider(config ProviderConfig, client *http.Client) services.IdentityProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = defaultScopes
+	}
+	return &oidcProvider{
+		config: config,
+		client: client,
+	}
+}
+
+func (p *oidcProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authorizationURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint", domainErrors.ErrFederatedLoginFailed)
+	}
+	query := authorizationURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authorizationURL.RawQuery = query.Encode()
+
+	return authorizationURL.String(), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*services.ExternalClaims, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	var tokens tokenResponse
+	if err := p.doJSON(req, &tokens); err != nil && tokens.Error == "" {
+		return nil, err
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("%w: token endpoint returned %s", domainErrors.ErrFederatedLoginFailed, tokens.Error)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id token in token response", domainErrors.ErrFederatedLoginFailed)
+	}
+
+	claims, err := p.verifyIDToken(ctx, tokens.IDToken, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", domainErrors.ErrFederatedLoginFailed)
+	}
+
+	return &services.ExternalClaims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+func (p *oidcProvider) verifyIDToken(ctx context.Context, idToken string, metadata *providerMetadata) (*idTokenClaims, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		return p.signingKey(ctx, metadata, keyID)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid id token: %v", domainErrors.ErrFederatedLoginFailed, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: id token has no subject", domainErrors.ErrFederatedLoginFailed)
+	}
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: id token was issued to another client", domainErrors.ErrFederatedLoginFailed)
+	}
+	return claims, nil
+}
+
+// discover fetches and caches the provider's discovery document.
+func (p *oidcProvider) discover(ctx context.Context) (*providerMetadata, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var metadata providerMetadata
+	if err := p.doJSON(req, &metadata); err != nil {
+		return nil, err
+	}
+	if metadata.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("%w: discovery issuer %q does not match %q", domainErrors.ErrFederatedLoginFailed, metadata.Issuer, p.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete discovery document", domainErrors.ErrFederatedLoginFailed)
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// signingKey returns the provider's key with the given ID, refetching the key
+// set once when the key is not known yet, so that key rotation is picked up.
+func (p *oidcProvider) signingKey(ctx context.Context, metadata *providerMetadata, keyID string) (*rsa.PublicKey, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if key, ok := p.keys[keyID]; ok {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys(ctx, metadata.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	key, ok := keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+	return key, nil
+}
+
+func (p *oidcProvider) fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.doJSON(req, &keySet); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, key := range keySet.Keys {
+		if key.KeyType != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		publicKey, err := rsaPublicKey(key)
+		if err != nil {
+			continue
+		}
+		keys[key.KeyID] = publicKey
+	}
+	return keys, nil
+}
+
+// doJSON sends the request and decodes the JSON body. The body is decoded for
+// error responses too, where it carries the OAuth error code.
+func (p *oidcProvider) doJSON(req *http.Request, out interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domainErrors.ErrFederatedLoginFailed, err)
+	}
+	defer resp.Body.Close()
+
+	decodeErr := json.NewDecoder(resp.Body).Decode(out)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %d", domainErrors.ErrFederatedLoginFailed, req.URL.Path, resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("%w: invalid response from %s", domainErrors.ErrFederatedLoginFailed, req.URL.Path)
+	}
+	return nil
+}
+
+func rsaPublicKey(key jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,106 @@
+package federation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/federation/mockidp"
+)
+
+const (
+	testRedirectURL  = "https://backend.example.com/api/auth/federated/corp/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mJ92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func TestOIDCProvider(t *testing.T) {
+	idp := mockidp.New("backend", "backend-secret")
+	defer idp.Close()
+	idp.SetUser(mockidp.User{Subject: "corp-1", Email: "user@example.com", EmailVerified: true, Name: "Test User"})
+
+	ctx := context.Background()
+	challenge := sha256.Sum256([]byte(testCodeVerifier))
+	noRedirects := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	newProvider := func(clientSecret string) *oidcProvider {
+		return NewOIDCProvider(ProviderConfig{
+			Name:         "corp",
+			Issuer:       idp.Issuer(),
+			ClientID:     idp.ClientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  testRedirectURL,
+		}, nil).(*oidcProvider)
+	}
+
+	// authorize follows the authorization URL and returns the issued code
+	authorize := func(t *testing.T, provider *oidcProvider, nonce string) string {
+		authorizationURL, err := provider.AuthCodeURL(ctx, "state-1", nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+		assert.NoError(t, err)
+
+		response, err := noRedirects.Get(authorizationURL)
+		assert.NoError(t, err)
+		response.Body.Close()
+		callback, err := url.Parse(response.Header.Get("Location"))
+		assert.NoError(t, err)
+		assert.Equal(t, "state-1", callback.Query().Get("state"))
+		return callback.Query().Get("code")
+	}
+
+	t.Run("Exchange verifies the ID token", func(t *testing.T) {
+		provider := newProvider(idp.ClientSecret)
+		code := authorize(t, provider, "nonce-1")
+
+		claims, err := provider.Exchange(ctx, code, testCodeVerifier, "nonce-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "corp-1", claims.Subject)
+		assert.Equal(t, "user@example.com", claims.Email)
+		assert.True(t, claims.EmailVerified)
+		assert.Equal(t, "Test User", claims.Name)
+	})
+
+	t.Run("Nonce mismatch", func(t *testing.T) {
+		provider := newProvider(idp.ClientSecret)
+		code := authorize(t, provider, "nonce-1")
+
+		claims, err := provider.Exchange(ctx, code, testCodeVerifier, "nonce-2")
+		assert.Nil(t, claims)
+		assert.ErrorIs(t, err, domainErrors.ErrFederatedLoginFailed)
+	})
+
+	t.Run("Wrong code verifier", func(t *testing.T) {
+		provider := newProvider(idp.ClientSecret)
+		code := authorize(t, provider, "nonce-1")
+
+		claims, err := provider.Exchange(ctx, code, "wrong-verifier", "nonce-1")
+		assert.Nil(t, claims)
+		assert.ErrorIs(t, err, domainErrors.ErrFederatedLoginFailed)
+	})
+
+	t.Run("Wrong client secret", func(t *testing.T) {
+		provider := newProvider("wrong-secret")
+		code := authorize(t, provider, "nonce-1")
+
+		claims, err := provider.Exchange(ctx, code, testCodeVerifier, "nonce-1")
+		assert.Nil(t, claims)
+		assert.ErrorIs(t, err, domainErrors.ErrFederatedLoginFailed)
+	})
+
+	t.Run("Issuer mismatch", func(t *testing.T) {
+		provider := NewOIDCProvider(ProviderConfig{
+			Name:        "corp",
+			Issuer:      idp.Issuer() + "/",
+			ClientID:    idp.ClientID,
+			RedirectURL: testRedirectURL,
+		}, nil)
+
+		_, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "challenge")
+		assert.ErrorIs(t, err, domainErrors.ErrFederatedLoginFailed)
+	})
+}
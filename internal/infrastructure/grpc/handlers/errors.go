@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"errors"
+
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// serviceErrorCode returns code for a service error, unless the storage
+// behind the service is unavailable, which the client may retry later.
+func serviceErrorCode(err error, code codes.Code) codes.Code {
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return codes.Unavailable
+	}
+	return code
+}
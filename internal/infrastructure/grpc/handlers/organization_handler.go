@@ -0,0 +1,477 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/grpc/interceptors"
+	pb "github.com/wonyus/backend-challenge/internal/infrastructure/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OrganizationServiceScopes lists the scopes each OrganizationService method
+// requires. The roles of the caller in the organization are checked on top.
+var OrganizationServiceScopes = map[string][]string{
+	pb.OrganizationService_CreateOrganization_FullMethodName: {entities.ScopeUsersWrite},
+	pb.OrganizationService_GetOrganization_FullMethodName:    {entities.ScopeUsersRead},
+	pb.OrganizationService_ListOrganizations_FullMethodName:  {entities.ScopeUsersRead},
+	pb.OrganizationService_UpdateOrganization_FullMethodName: {entities.ScopeUsersWrite},
+	pb.OrganizationService_DeleteOrganization_FullMethodName: {entities.ScopeUsersWrite},
+	pb.OrganizationService_ListMembers_FullMethodName:        {entities.ScopeUsersRead},
+	pb.OrganizationService_InviteMember_FullMethodName:       {entities.ScopeUsersWrite},
+	pb.OrganizationService_UpdateMember_FullMethodName:       {entities.ScopeUsersWrite},
+	pb.OrganizationService_RemoveMember_FullMethodName:       {entities.ScopeUsersWrite},
+	pb.OrganizationService_ListInvitations_FullMethodName:    {entities.ScopeUsersRead},
+	pb.OrganizationService_AcceptInvitation_FullMethodName:   {entities.ScopeUsersWrite},
+	pb.OrganizationService_DeclineInvitation_FullMethodName:  {entities.ScopeUsersWrite},
+	pb.OrganizationService_CreateTeam_FullMethodName:         {entities.ScopeUsersWrite},
+	pb.OrganizationService_GetTeam_FullMethodName:            {entities.ScopeUsersRead},
+	pb.OrganizationService_ListTeams_FullMethodName:          {entities.ScopeUsersRead},
+	pb.OrganizationService_UpdateTeam_FullMethodName:         {entities.ScopeUsersWrite},
+	pb.OrganizationService_DeleteTeam_FullMethodName:         {entities.ScopeUsersWrite},
+	pb.OrganizationService_ListTeamMembers_FullMethodName:    {entities.ScopeUsersRead},
+	pb.OrganizationService_SetTeamMember_FullMethodName:      {entities.ScopeUsersWrite},
+	pb.OrganizationService_RemoveTeamMember_FullMethodName:   {entities.ScopeUsersWrite},
+}
+
+type OrganizationGRPCHandler struct {
+	pb.UnimplementedOrganizationServiceServer
+	organizationService ports.OrganizationService
+}
+
+func NewOrganizationGRPCHandler(organizationService ports.OrganizationService) *OrganizationGRPCHandler {
+	return &OrganizationGRPCHandler{
+		organizationService: organizationService,
+	}
+}
+
+func (h *OrganizationGRPCHandler) CreateOrganization(ctx context.Context, req *pb.CreateOrganizationRequest) (*pb.Organization, error) {
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Name) < 2 {
+		return nil, status.Error(codes.InvalidArgument, "name must be at least 2 characters")
+	}
+
+	organization, err := h.organizationService.CreateOrganization(ctx, caller, &dto.CreateOrganizationRequest{Name: req.Name})
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBOrganization(organization), nil
+}
+
+func (h *OrganizationGRPCHandler) GetOrganization(ctx context.Context, req *pb.GetOrganizationRequest) (*pb.Organization, error) {
+	caller, ids, err := organizationCall(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, err := h.organizationService.GetOrganization(ctx, caller, ids[0])
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBOrganization(organization), nil
+}
+
+func (h *OrganizationGRPCHandler) ListOrganizations(ctx context.Context, req *pb.ListOrganizationsRequest) (*pb.ListOrganizationsResponse, error) {
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if caller.User == nil {
+		return nil, status.Error(codes.PermissionDenied, domainErrors.ErrForbidden.Error())
+	}
+
+	organizations, err := h.organizationService.ListOrganizations(ctx, caller.User.ID)
+	if err != nil {
+		return nil, organizationError(err)
+	}
+
+	response := &pb.ListOrganizationsResponse{}
+	for i := range organizations.Organizations {
+		response.Organizations = append(response.Organizations, toPBOrganization(&organizations.Organizations[i]))
+	}
+	return response, nil
+}
+
+func (h *OrganizationGRPCHandler) UpdateOrganization(ctx context.Context, req *pb.UpdateOrganizationRequest) (*pb.Organization, error) {
+	caller, ids, err := organizationCall(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Name) < 2 {
+		return nil, status.Error(codes.InvalidArgument, "name must be at least 2 characters")
+	}
+
+	organization, err := h.organizationService.UpdateOrganization(ctx, caller, ids[0], &dto.UpdateOrganizationRequest{Name: req.Name})
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBOrganization(organization), nil
+}
+
+func (h *OrganizationGRPCHandler) DeleteOrganization(ctx context.Context, req *pb.DeleteOrganizationRequest) (*pb.DeleteOrganizationResponse, error) {
+	caller, ids, err := organizationCall(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.organizationService.DeleteOrganization(ctx, caller, ids[0]); err != nil {
+		return nil, organizationError(err)
+	}
+	return &pb.DeleteOrganizationResponse{Success: true}, nil
+}
+
+func (h *OrganizationGRPCHandler) ListMembers(ctx context.Context, req *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := h.organizationService.ListMembers(ctx, caller, ids[0])
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBMembers(members), nil
+}
+
+func (h *OrganizationGRPCHandler) InviteMember(ctx context.Context, req *pb.InviteMemberRequest) (*pb.Member, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := h.organizationService.InviteMember(ctx, caller, ids[0], &dto.InviteMemberRequest{Email: req.Email, Role: req.Role})
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBMember(member), nil
+}
+
+func (h *OrganizationGRPCHandler) UpdateMember(ctx context.Context, req *pb.UpdateMemberRequest) (*pb.Member, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := memberID(req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := h.organizationService.UpdateMember(ctx, caller, ids[0], userID, &dto.MemberRoleRequest{Role: req.Role})
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBMember(member), nil
+}
+
+func (h *OrganizationGRPCHandler) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := memberID(req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.organizationService.RemoveMember(ctx, caller, ids[0], userID); err != nil {
+		return nil, organizationError(err)
+	}
+	return &pb.RemoveMemberResponse{Success: true}, nil
+}
+
+func (h *OrganizationGRPCHandler) ListInvitations(ctx context.Context, req *pb.ListInvitationsRequest) (*pb.ListMembershipsResponse, error) {
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if caller.User == nil {
+		return nil, status.Error(codes.PermissionDenied, domainErrors.ErrForbidden.Error())
+	}
+
+	invitations, err := h.organizationService.ListInvitations(ctx, caller.User.ID)
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return &pb.ListMembershipsResponse{Memberships: toPBMemberships(invitations.Memberships)}, nil
+}
+
+func (h *OrganizationGRPCHandler) AcceptInvitation(ctx context.Context, req *pb.AcceptInvitationRequest) (*pb.Membership, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	if caller.User == nil {
+		return nil, status.Error(codes.PermissionDenied, domainErrors.ErrForbidden.Error())
+	}
+
+	membership, err := h.organizationService.AcceptInvitation(ctx, caller.User.ID, ids[0])
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBMembership(membership), nil
+}
+
+func (h *OrganizationGRPCHandler) DeclineInvitation(ctx context.Context, req *pb.DeclineInvitationRequest) (*pb.DeclineInvitationResponse, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	if caller.User == nil {
+		return nil, status.Error(codes.PermissionDenied, domainErrors.ErrForbidden.Error())
+	}
+
+	if err := h.organizationService.DeclineInvitation(ctx, caller.User.ID, ids[0]); err != nil {
+		return nil, organizationError(err)
+	}
+	return &pb.DeclineInvitationResponse{Success: true}, nil
+}
+
+func (h *OrganizationGRPCHandler) CreateTeam(ctx context.Context, req *pb.CreateTeamRequest) (*pb.Team, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Name) < 2 {
+		return nil, status.Error(codes.InvalidArgument, "name must be at least 2 characters")
+	}
+
+	team, err := h.organizationService.CreateTeam(ctx, caller, ids[0], &dto.CreateTeamRequest{Name: req.Name})
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBTeam(team), nil
+}
+
+func (h *OrganizationGRPCHandler) GetTeam(ctx context.Context, req *pb.GetTeamRequest) (*pb.Team, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId, req.TeamId)
+	if err != nil {
+		return nil, err
+	}
+
+	team, err := h.organizationService.GetTeam(ctx, caller, ids[0], ids[1])
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBTeam(team), nil
+}
+
+func (h *OrganizationGRPCHandler) ListTeams(ctx context.Context, req *pb.ListTeamsRequest) (*pb.ListTeamsResponse, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := h.organizationService.ListTeams(ctx, caller, ids[0])
+	if err != nil {
+		return nil, organizationError(err)
+	}
+
+	response := &pb.ListTeamsResponse{}
+	for i := range teams.Teams {
+		response.Teams = append(response.Teams, toPBTeam(&teams.Teams[i]))
+	}
+	return response, nil
+}
+
+func (h *OrganizationGRPCHandler) UpdateTeam(ctx context.Context, req *pb.UpdateTeamRequest) (*pb.Team, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId, req.TeamId)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Name) < 2 {
+		return nil, status.Error(codes.InvalidArgument, "name must be at least 2 characters")
+	}
+
+	team, err := h.organizationService.UpdateTeam(ctx, caller, ids[0], ids[1], &dto.UpdateTeamRequest{Name: req.Name})
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBTeam(team), nil
+}
+
+func (h *OrganizationGRPCHandler) DeleteTeam(ctx context.Context, req *pb.DeleteTeamRequest) (*pb.DeleteTeamResponse, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId, req.TeamId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.organizationService.DeleteTeam(ctx, caller, ids[0], ids[1]); err != nil {
+		return nil, organizationError(err)
+	}
+	return &pb.DeleteTeamResponse{Success: true}, nil
+}
+
+func (h *OrganizationGRPCHandler) ListTeamMembers(ctx context.Context, req *pb.ListTeamMembersRequest) (*pb.ListMembersResponse, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId, req.TeamId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := h.organizationService.ListTeamMembers(ctx, caller, ids[0], ids[1])
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBMembers(members), nil
+}
+
+func (h *OrganizationGRPCHandler) SetTeamMember(ctx context.Context, req *pb.SetTeamMemberRequest) (*pb.Member, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId, req.TeamId)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := memberID(req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := h.organizationService.SetTeamMember(ctx, caller, ids[0], ids[1], userID, &dto.MemberRoleRequest{Role: req.Role})
+	if err != nil {
+		return nil, organizationError(err)
+	}
+	return toPBMember(member), nil
+}
+
+func (h *OrganizationGRPCHandler) RemoveTeamMember(ctx context.Context, req *pb.RemoveTeamMemberRequest) (*pb.RemoveTeamMemberResponse, error) {
+	caller, ids, err := organizationCall(ctx, req.OrganizationId, req.TeamId)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := memberID(req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.organizationService.RemoveTeamMember(ctx, caller, ids[0], ids[1], userID); err != nil {
+		return nil, organizationError(err)
+	}
+	return &pb.RemoveTeamMemberResponse{Success: true}, nil
+}
+
+func callerFromContext(ctx context.Context) (*dto.Principal, error) {
+	caller, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	return caller, nil
+}
+
+// organizationCall returns the caller and the parsed IDs.
+func organizationCall(ctx context.Context, hexIDs ...string) (*dto.Principal, []entities.ID, error) {
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]entities.ID, len(hexIDs))
+	for i, hexID := range hexIDs {
+		id, err := entities.ParseID(hexID)
+		if err != nil {
+			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid ID: %v", err)
+		}
+		ids[i] = id
+	}
+	return caller, ids, nil
+}
+
+// memberID parses the ID of a member.
+func memberID(id string) (entities.UserID, error) {
+	userID, err := entities.ParseUserID(id)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+	return userID, nil
+}
+
+func organizationError(err error) error {
+	switch {
+	case errors.Is(err, domainErrors.ErrOrganizationNotFound), errors.Is(err, domainErrors.ErrTeamNotFound),
+		errors.Is(err, domainErrors.ErrMembershipNotFound), errors.Is(err, domainErrors.ErrInvitationNotFound),
+		errors.Is(err, domainErrors.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domainErrors.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domainErrors.ErrMembershipExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domainErrors.ErrLastOwner):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domainErrors.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "organization request failed: %v", err)
+	}
+}
+
+func toPBOrganization(organization *dto.OrganizationResponse) *pb.Organization {
+	return &pb.Organization{
+		Id:        organization.ID.String(),
+		Name:      organization.Name,
+		Role:      organization.Role,
+		CreatedAt: organization.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt: organization.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func toPBTeam(team *dto.TeamResponse) *pb.Team {
+	return &pb.Team{
+		Id:             team.ID.String(),
+		OrganizationId: team.OrganizationID.String(),
+		Name:           team.Name,
+		CreatedAt:      team.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:      team.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func toPBMember(member *dto.MemberResponse) *pb.Member {
+	return &pb.Member{
+		UserId:    member.UserID.String(),
+		Name:      member.Name,
+		Email:     member.Email,
+		Role:      member.Role,
+		Status:    member.Status,
+		CreatedAt: member.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+func toPBMembers(members *dto.MembersListResponse) *pb.ListMembersResponse {
+	response := &pb.ListMembersResponse{}
+	for i := range members.Members {
+		response.Members = append(response.Members, toPBMember(&members.Members[i]))
+	}
+	return response
+}
+
+func toPBMembership(membership *dto.MembershipResponse) *pb.Membership {
+	response := &pb.Membership{
+		OrganizationId:   membership.OrganizationID.String(),
+		OrganizationName: membership.OrganizationName,
+		TeamName:         membership.TeamName,
+		Role:             membership.Role,
+		Status:           membership.Status,
+		CreatedAt:        membership.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if membership.TeamID != nil {
+		response.TeamId = membership.TeamID.String()
+	}
+	if membership.InvitedBy != nil {
+		response.InvitedBy = membership.InvitedBy.String()
+	}
+	return response
+}
+
+func toPBMemberships(memberships []dto.MembershipResponse) []*pb.Membership {
+	response := make([]*pb.Membership, 0, len(memberships))
+	for i := range memberships {
+		response = append(response, toPBMembership(&memberships[i]))
+	}
+	return response
+}
@@ -2,23 +2,39 @@ package handlers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/wonyus/backend-challenge/internal/application/dto"
 	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
 	pb "github.com/wonyus/backend-challenge/internal/infrastructure/grpc/proto"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// UserServiceScopes lists the scopes each UserService method requires.
+var UserServiceScopes = map[string][]string{
+	pb.UserService_CreateUser_FullMethodName:  {entities.ScopeUsersWrite},
+	pb.UserService_GetUser_FullMethodName:     {entities.ScopeUsersRead},
+	pb.UserService_GetAllUsers_FullMethodName: {entities.ScopeUsersRead},
+	pb.UserService_UpdateUser_FullMethodName:  {entities.ScopeUsersWrite},
+	pb.UserService_DeleteUser_FullMethodName:  {entities.ScopeUsersDelete},
+	pb.UserService_WatchUsers_FullMethodName:  {entities.ScopeUsersRead},
+}
+
 type UserGRPCHandler struct {
 	pb.UnimplementedUserServiceServer
-	userService ports.UserService
+	userService         ports.UserService
+	organizationService ports.OrganizationService
+	userEventService    ports.UserEventService
 }
 
-func NewUserGRPCHandler(userService ports.UserService) *UserGRPCHandler {
+func NewUserGRPCHandler(userService ports.UserService, organizationService ports.OrganizationService, userEventService ports.UserEventService) *UserGRPCHandler {
 	return &UserGRPCHandler{
-		userService: userService,
+		userService:         userService,
+		organizationService: organizationService,
+		userEventService:    userEventService,
 	}
 }
 
@@ -31,11 +47,11 @@ func (h *UserGRPCHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequ
 
 	user, err := h.userService.CreateUser(ctx, createReq)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "failed to create user: %v", err)
+		return nil, status.Errorf(serviceErrorCode(err, codes.InvalidArgument), "failed to create user: %v", err)
 	}
 
 	return &pb.CreateUserResponse{
-		Id:        user.ID.Hex(),
+		Id:        user.ID.String(),
 		Name:      user.Name,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
@@ -43,34 +59,43 @@ func (h *UserGRPCHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequ
 }
 
 func (h *UserGRPCHandler) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
-	id, err := primitive.ObjectIDFromHex(req.Id)
+	id, err := entities.ParseUserID(req.Id)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
 
 	user, err := h.userService.GetUserByID(ctx, id)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "user not found: %v", err)
+		return nil, status.Errorf(serviceErrorCode(err, codes.NotFound), "user not found: %v", err)
 	}
 
-	return &pb.GetUserResponse{
-		Id:        user.ID.Hex(),
+	response := &pb.GetUserResponse{
+		Id:        user.ID.String(),
 		Name:      user.Name,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
-	}, nil
+	}
+	if req.IncludeMemberships {
+		memberships, err := h.organizationService.GetUserMemberships(ctx, id)
+		if err != nil {
+			return nil, status.Errorf(serviceErrorCode(err, codes.Internal), "failed to get memberships: %v", err)
+		}
+		response.Memberships = toPBMemberships(memberships)
+	}
+
+	return response, nil
 }
 
 func (h *UserGRPCHandler) GetAllUsers(ctx context.Context, req *pb.GetAllUsersRequest) (*pb.GetAllUsersResponse, error) {
 	users, err := h.userService.GetAllUsers(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get users: %v", err)
+		return nil, status.Errorf(serviceErrorCode(err, codes.Internal), "failed to get users: %v", err)
 	}
 
 	var pbUsers []*pb.GetUserResponse
 	for _, user := range users.Users {
 		pbUsers = append(pbUsers, &pb.GetUserResponse{
-			Id:        user.ID.Hex(),
+			Id:        user.ID.String(),
 			Name:      user.Name,
 			Email:     user.Email,
 			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
@@ -82,7 +107,7 @@ func (h *UserGRPCHandler) GetAllUsers(ctx context.Context, req *pb.GetAllUsersRe
 	}, nil
 }
 func (h *UserGRPCHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	id, err := primitive.ObjectIDFromHex(req.Id)
+	id, err := entities.ParseUserID(req.Id)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
@@ -94,11 +119,11 @@ func (h *UserGRPCHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequ
 
 	user, err := h.userService.UpdateUser(ctx, id, updateReq)
 	if err != nil {
-		return nil, status.Errorf(codes.InvalidArgument, "failed to update user: %v", err)
+		return nil, status.Errorf(serviceErrorCode(err, codes.InvalidArgument), "failed to update user: %v", err)
 	}
 
 	return &pb.UpdateUserResponse{
-		Id:        user.ID.Hex(),
+		Id:        user.ID.String(),
 		Name:      user.Name,
 		Email:     user.Email,
 		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
@@ -106,16 +131,50 @@ func (h *UserGRPCHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequ
 }
 
 func (h *UserGRPCHandler) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
-	id, err := primitive.ObjectIDFromHex(req.Id)
+	id, err := entities.ParseUserID(req.Id)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
 
 	if err := h.userService.DeleteUser(ctx, id); err != nil {
-		return nil, status.Errorf(codes.NotFound, "failed to delete user: %v", err)
+		return nil, status.Errorf(serviceErrorCode(err, codes.NotFound), "failed to delete user: %v", err)
 	}
 
 	return &pb.DeleteUserResponse{
 		Success: true,
 	}, nil
 }
+
+func (h *UserGRPCHandler) WatchUsers(req *pb.WatchUsersRequest, stream pb.UserService_WatchUsersServer) error {
+	watchReq := &dto.WatchUsersRequest{
+		Types:       req.Types,
+		ResumeAfter: req.ResumeAfter,
+		Consumer:    req.Consumer,
+	}
+
+	err := h.userEventService.WatchUsers(stream.Context(), watchReq, func(event *dto.UserEvent) error {
+		response := &pb.UserEvent{
+			Id:         event.ID,
+			Type:       event.Type,
+			UserId:     event.UserID.String(),
+			OccurredAt: event.OccurredAt.Format("2006-01-02T15:04:05Z"),
+		}
+		if event.User != nil {
+			response.User = &pb.GetUserResponse{
+				Id:        event.User.ID.String(),
+				Name:      event.User.Name,
+				Email:     event.User.Email,
+				CreatedAt: event.User.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			}
+		}
+		return stream.Send(response)
+	})
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, domainErrors.ErrInvalidUserEventType), errors.Is(err, domainErrors.ErrInvalidUserEventID):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Errorf(serviceErrorCode(err, codes.Internal), "failed to watch users: %v", err)
+	}
+}
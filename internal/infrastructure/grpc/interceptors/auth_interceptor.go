@@ -0,0 +1,175 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// reflectionServicePrefix is left unauthenticated so tools like grpcurl can
+// still list the available services.
+const reflectionServicePrefix = "/grpc.reflection."
+
+// tenantMetadataKey is the gRPC counterpart of the X-Tenant-ID header.
+const tenantMetadataKey = "x-tenant-id"
+
+type contextKey string
+
+const principalContextKey contextKey = "principal"
+
+// PrincipalFromContext returns the caller stored by the auth interceptors.
+func PrincipalFromContext(ctx context.Context) (*dto.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey).(*dto.Principal)
+	return principal, ok
+}
+
+type AuthInterceptor struct {
+	authService   ports.AuthService
+	apiKeyService ports.APIKeyService
+	tenants       *auth.Tenants
+	methodScopes  map[string][]string
+}
+
+// NewAuthInterceptor authenticates every call and checks the scopes listed
+// for its full method name. Methods missing from methodScopes are denied.
+// Calls are scoped to the tenant in the "x-tenant-id" metadata, or to the
+// tenant of the caller's token.
+func NewAuthInterceptor(authService ports.AuthService, apiKeyService ports.APIKeyService, tenants *auth.Tenants, methodScopes map[string][]string) *AuthInterceptor {
+	return &AuthInterceptor{
+		authService:   authService,
+		apiKeyService: apiKeyService,
+		tenants:       tenants,
+		methodScopes:  methodScopes,
+	}
+}
+
+func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (i *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (i *AuthInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, reflectionServicePrefix) {
+		return ctx, nil
+	}
+
+	scopes, ok := i.methodScopes[method]
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, "method has no declared scopes")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	explicit := false
+	if tenantID := firstValue(md, tenantMetadataKey); tenantID != "" {
+		resolved, err := i.tenants.Resolve(tenantID)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		ctx = services.WithTenant(ctx, resolved)
+		explicit = true
+	} else {
+		ctx = services.WithTenant(ctx, entities.DefaultTenant)
+	}
+
+	principal, err := i.authenticate(ctx, explicit)
+	if err != nil {
+		return nil, err
+	}
+	if principal.TenantID != "" {
+		ctx = services.WithTenant(ctx, principal.TenantID)
+	}
+
+	for _, scope := range scopes {
+		if !entities.HasScope(principal.Scopes, scope) {
+			return nil, status.Errorf(codes.PermissionDenied, "%v: requires %s", domainErrors.ErrInsufficientScope, strings.Join(scopes, " "))
+		}
+	}
+
+	return context.WithValue(ctx, principalContextKey, principal), nil
+}
+
+// authenticate reads the same credentials as the HTTP middleware, from the
+// "authorization" or "x-api-key" metadata. Unless the call named its tenant,
+// a token is validated against the tenant it was issued in.
+func (i *AuthInterceptor) authenticate(ctx context.Context, tenantExplicit bool) (*dto.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	credentialType, credential, err := auth.ParseCredentials(firstValue(md, "authorization"), firstValue(md, "x-api-key"))
+	if errors.Is(err, domainErrors.ErrUnauthorized) {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+	}
+
+	var principal *dto.Principal
+	switch credentialType {
+	case auth.APIKeyCredential:
+		principal, err = i.apiKeyService.ValidateAPIKey(ctx, credential)
+		if errors.Is(err, domainErrors.ErrUnavailable) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+	default:
+		if !tenantExplicit {
+			ctx = services.WithTenant(ctx, "")
+		}
+		principal, err = i.authService.ValidateToken(ctx, credential)
+		if errors.Is(err, domainErrors.ErrUnavailable) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+	}
+
+	// Impersonation is only audited over HTTP
+	if principal.Actor != nil {
+		return nil, status.Error(codes.PermissionDenied, domainErrors.ErrImpersonating.Error())
+	}
+
+	return principal, nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
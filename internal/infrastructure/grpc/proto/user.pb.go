@@ -150,10 +150,11 @@ func (x *CreateUserResponse) GetCreatedAt() string {
 }
 
 type GetUserRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	IncludeMemberships bool                   `protobuf:"varint,2,opt,name=include_memberships,json=includeMemberships,proto3" json:"include_memberships,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *GetUserRequest) Reset() {
@@ -193,12 +194,20 @@ func (x *GetUserRequest) GetId() string {
 	return ""
 }
 
+func (x *GetUserRequest) GetIncludeMemberships() bool {
+	if x != nil {
+		return x.IncludeMemberships
+	}
+	return false
+}
+
 type GetUserResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Memberships   []*Membership          `protobuf:"bytes,5,rep,name=memberships,proto3" json:"memberships,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -261,6 +270,13 @@ func (x *GetUserResponse) GetCreatedAt() string {
 	return ""
 }
 
+func (x *GetUserResponse) GetMemberships() []*Membership {
+	if x != nil {
+		return x.Memberships
+	}
+	return nil
+}
+
 type GetAllUsersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -557,11 +573,152 @@ func (x *DeleteUserResponse) GetSuccess() bool {
 	return false
 }
 
+type WatchUsersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// created, updated or deleted; all of them when empty
+	Types []string `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	// the id of the last event seen, to resume after it
+	ResumeAfter string `protobuf:"bytes,2,opt,name=resume_after,json=resumeAfter,proto3" json:"resume_after,omitempty"`
+	// names a watcher whose position is remembered, resumed from when
+	// resume_after is empty
+	Consumer      string `protobuf:"bytes,3,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchUsersRequest) Reset() {
+	*x = WatchUsersRequest{}
+	mi := &file_internal_infrastructure_grpc_proto_user_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchUsersRequest) ProtoMessage() {}
+
+func (x *WatchUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_infrastructure_grpc_proto_user_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchUsersRequest.ProtoReflect.Descriptor instead.
+func (*WatchUsersRequest) Descriptor() ([]byte, []int) {
+	return file_internal_infrastructure_grpc_proto_user_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchUsersRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *WatchUsersRequest) GetResumeAfter() string {
+	if x != nil {
+		return x.ResumeAfter
+	}
+	return ""
+}
+
+func (x *WatchUsersRequest) GetConsumer() string {
+	if x != nil {
+		return x.Consumer
+	}
+	return ""
+}
+
+type UserEvent struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type   string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	UserId string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// missing from deletions
+	User          *GetUserResponse `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	OccurredAt    string           `protobuf:"bytes,5,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserEvent) Reset() {
+	*x = UserEvent{}
+	mi := &file_internal_infrastructure_grpc_proto_user_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserEvent) ProtoMessage() {}
+
+func (x *UserEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_infrastructure_grpc_proto_user_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserEvent.ProtoReflect.Descriptor instead.
+func (*UserEvent) Descriptor() ([]byte, []int) {
+	return file_internal_infrastructure_grpc_proto_user_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UserEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UserEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UserEvent) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UserEvent) GetUser() *GetUserResponse {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *UserEvent) GetOccurredAt() string {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return ""
+}
+
 var File_internal_infrastructure_grpc_proto_user_proto protoreflect.FileDescriptor
 
 const file_internal_infrastructure_grpc_proto_user_proto_rawDesc = "" +
 	"\n" +
-	"-internal/infrastructure/grpc/proto/user.proto\x12\x04user\"Y\n" +
+	"-internal/infrastructure/grpc/proto/user.proto\x12\x04user\x1a5internal/infrastructure/grpc/proto/organization.proto\"Y\n" +
 	"\x11CreateUserRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1a\n" +
@@ -571,15 +728,17 @@ const file_internal_infrastructure_grpc_proto_user_proto_rawDesc = "" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
 	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\x04 \x01(\tR\tcreatedAt\" \n" +
+	"created_at\x18\x04 \x01(\tR\tcreatedAt\"Q\n" +
 	"\x0eGetUserRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"j\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12/\n" +
+	"\x13include_memberships\x18\x02 \x01(\bR\x12includeMemberships\"\xa6\x01\n" +
 	"\x0fGetUserResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
 	"\x05email\x18\x03 \x01(\tR\x05email\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\x04 \x01(\tR\tcreatedAt\"\x14\n" +
+	"created_at\x18\x04 \x01(\tR\tcreatedAt\x12:\n" +
+	"\vmemberships\x18\x05 \x03(\v2\x18.organization.MembershipR\vmemberships\"\x14\n" +
 	"\x12GetAllUsersRequest\"B\n" +
 	"\x13GetAllUsersResponse\x12+\n" +
 	"\x05users\x18\x01 \x03(\v2\x15.user.GetUserResponseR\x05users\"M\n" +
@@ -596,7 +755,18 @@ const file_internal_infrastructure_grpc_proto_user_proto_rawDesc = "" +
 	"\x11DeleteUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\".\n" +
 	"\x12DeleteUserResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xcc\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"h\n" +
+	"\x11WatchUsersRequest\x12\x14\n" +
+	"\x05types\x18\x01 \x03(\tR\x05types\x12!\n" +
+	"\fresume_after\x18\x02 \x01(\tR\vresumeAfter\x12\x1a\n" +
+	"\bconsumer\x18\x03 \x01(\tR\bconsumer\"\x94\x01\n" +
+	"\tUserEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12)\n" +
+	"\x04user\x18\x04 \x01(\v2\x15.user.GetUserResponseR\x04user\x12\x1f\n" +
+	"\voccurred_at\x18\x05 \x01(\tR\n" +
+	"occurredAt2\x86\x03\n" +
 	"\vUserService\x12?\n" +
 	"\n" +
 	"CreateUser\x12\x17.user.CreateUserRequest\x1a\x18.user.CreateUserResponse\x126\n" +
@@ -605,7 +775,9 @@ const file_internal_infrastructure_grpc_proto_user_proto_rawDesc = "" +
 	"\n" +
 	"UpdateUser\x12\x17.user.UpdateUserRequest\x1a\x18.user.UpdateUserResponse\x12?\n" +
 	"\n" +
-	"DeleteUser\x12\x17.user.DeleteUserRequest\x1a\x18.user.DeleteUserResponseB$Z\"internal/infrastructure/grpc/protob\x06proto3"
+	"DeleteUser\x12\x17.user.DeleteUserRequest\x1a\x18.user.DeleteUserResponse\x128\n" +
+	"\n" +
+	"WatchUsers\x12\x17.user.WatchUsersRequest\x1a\x0f.user.UserEvent0\x01B$Z\"internal/infrastructure/grpc/protob\x06proto3"
 
 var (
 	file_internal_infrastructure_grpc_proto_user_proto_rawDescOnce sync.Once
@@ -619,7 +791,7 @@ func file_internal_infrastructure_grpc_proto_user_proto_rawDescGZIP() []byte {
 	return file_internal_infrastructure_grpc_proto_user_proto_rawDescData
 }
 
-var file_internal_infrastructure_grpc_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_internal_infrastructure_grpc_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_internal_infrastructure_grpc_proto_user_proto_goTypes = []any{
 	(*CreateUserRequest)(nil),   // 0: user.CreateUserRequest
 	(*CreateUserResponse)(nil),  // 1: user.CreateUserResponse
@@ -631,24 +803,31 @@ var file_internal_infrastructure_grpc_proto_user_proto_goTypes = []any{
 	(*UpdateUserResponse)(nil),  // 7: user.UpdateUserResponse
 	(*DeleteUserRequest)(nil),   // 8: user.DeleteUserRequest
 	(*DeleteUserResponse)(nil),  // 9: user.DeleteUserResponse
+	(*WatchUsersRequest)(nil),   // 10: user.WatchUsersRequest
+	(*UserEvent)(nil),           // 11: user.UserEvent
+	(*Membership)(nil),          // 12: organization.Membership
 }
 var file_internal_infrastructure_grpc_proto_user_proto_depIdxs = []int32{
-	3, // 0: user.GetAllUsersResponse.users:type_name -> user.GetUserResponse
-	0, // 1: user.UserService.CreateUser:input_type -> user.CreateUserRequest
-	2, // 2: user.UserService.GetUser:input_type -> user.GetUserRequest
-	4, // 3: user.UserService.GetAllUsers:input_type -> user.GetAllUsersRequest
-	6, // 4: user.UserService.UpdateUser:input_type -> user.UpdateUserRequest
-	8, // 5: user.UserService.DeleteUser:input_type -> user.DeleteUserRequest
-	1, // 6: user.UserService.CreateUser:output_type -> user.CreateUserResponse
-	3, // 7: user.UserService.GetUser:output_type -> user.GetUserResponse
-	5, // 8: user.UserService.GetAllUsers:output_type -> user.GetAllUsersResponse
-	7, // 9: user.UserService.UpdateUser:output_type -> user.UpdateUserResponse
-	9, // 10: user.UserService.DeleteUser:output_type -> user.DeleteUserResponse
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	12, // 0: user.GetUserResponse.memberships:type_name -> organization.Membership
+	3,  // 1: user.GetAllUsersResponse.users:type_name -> user.GetUserResponse
+	3,  // 2: user.UserEvent.user:type_name -> user.GetUserResponse
+	0,  // 3: user.UserService.CreateUser:input_type -> user.CreateUserRequest
+	2,  // 4: user.UserService.GetUser:input_type -> user.GetUserRequest
+	4,  // 5: user.UserService.GetAllUsers:input_type -> user.GetAllUsersRequest
+	6,  // 6: user.UserService.UpdateUser:input_type -> user.UpdateUserRequest
+	8,  // 7: user.UserService.DeleteUser:input_type -> user.DeleteUserRequest
+	10, // 8: user.UserService.WatchUsers:input_type -> user.WatchUsersRequest
+	1,  // 9: user.UserService.CreateUser:output_type -> user.CreateUserResponse
+	3,  // 10: user.UserService.GetUser:output_type -> user.GetUserResponse
+	5,  // 11: user.UserService.GetAllUsers:output_type -> user.GetAllUsersResponse
+	7,  // 12: user.UserService.UpdateUser:output_type -> user.UpdateUserResponse
+	9,  // 13: user.UserService.DeleteUser:output_type -> user.DeleteUserResponse
+	11, // 14: user.UserService.WatchUsers:output_type -> user.UserEvent
+	9,  // [9:15] is the sub-list for method output_type
+	3,  // [3:9] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_internal_infrastructure_grpc_proto_user_proto_init() }
@@ -656,13 +835,14 @@ func file_internal_infrastructure_grpc_proto_user_proto_init() {
 	if File_internal_infrastructure_grpc_proto_user_proto != nil {
 		return
 	}
+	file_internal_infrastructure_grpc_proto_organization_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_infrastructure_grpc_proto_user_proto_rawDesc), len(file_internal_infrastructure_grpc_proto_user_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+	"github.com/wonyus/backend-challenge/pkg/validator"
+)
+
+type APIKeyHandler struct {
+	apiKeyService ports.APIKeyService
+	validator     *validator.Validator
+}
+
+func NewAPIKeyHandler(apiKeyService ports.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		validator:     validator.New(),
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizeOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The key gets no more scopes than the credential creating it
+	principal, _ := middleware.PrincipalFromContext(r.Context())
+	response, err := h.apiKeyService.CreateAPIKey(r.Context(), principal, userID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), apiKeyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizeOwner(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.apiKeyService.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), apiKeyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizeOwner(w, r)
+	if !ok {
+		return
+	}
+
+	keyID, err := entities.ParseID(mux.Vars(r)["keyId"])
+	if err != nil {
+		http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+		http.Error(w, err.Error(), apiKeyErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeOwner resolves the {id} path variable and only lets users manage
+// their own credentials. Service accounts are ordinary users that
+// authenticate with their own credentials.
+func authorizeOwner(w http.ResponseWriter, r *http.Request) (entities.UserID, bool) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+
+	userID, err := entities.ParseUserID(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return "", false
+	}
+
+	if user.ID != userID {
+		http.Error(w, domainErrors.ErrForbidden.Error(), http.StatusForbidden)
+		return "", false
+	}
+
+	return userID, true
+}
+
+func apiKeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErrors.ErrInvalidScope), errors.Is(err, domainErrors.ErrInvalidExpiry):
+		return http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrUserNotFound), errors.Is(err, domainErrors.ErrAPIKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_APIKey_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mock_ports.NewMockAPIKeyService(ctrl)
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+
+	executeWithRequest := func(userID string, jsonBody []byte) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/users/%s/api-keys", userID), strings.NewReader(string(jsonBody)))
+		req = req.WithContext(middleware.WithUser(req.Context(), user))
+		req = mux.SetURLVars(req, map[string]string{"id": userID})
+		apiKeyHandler := NewAPIKeyHandler(mockAPIKeyService)
+		s := http.NewServeMux()
+		s.HandleFunc("/api/users/{id}/api-keys", apiKeyHandler.CreateAPIKey)
+		s.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		expectedReq := &dto.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"users:read"}}
+		mockAPIKeyService.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any(), user.ID, expectedReq).Return(&dto.CreateAPIKeyResponse{
+			APIKeyResponse: dto.APIKeyResponse{ID: entities.NewID(), Name: "ci", Prefix: "bck_0123456789ab"},
+			Key:            "bck_0123456789ab_secret",
+		}, nil)
+		response := executeWithRequest(user.ID.String(), []byte(`{"name": "ci", "scopes": ["users:read"]}`))
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.Equal(t, "no-store", response.Header().Get("Cache-Control"))
+		assert.Contains(t, response.Body.String(), "bck_0123456789ab_secret")
+	})
+
+	t.Run("Invalid Scope", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any(), user.ID, gomock.Any()).Return(nil, domainErrors.ErrInvalidScope)
+		response := executeWithRequest(user.ID.String(), []byte(`{"name": "ci", "scopes": [" "]}`))
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Bad Request", func(t *testing.T) {
+		response := executeWithRequest(user.ID.String(), []byte(`{}`))
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("Other User", func(t *testing.T) {
+		response := executeWithRequest(entities.NewID().String(), []byte(`{"name": "ci"}`))
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("Invalid User ID", func(t *testing.T) {
+		response := executeWithRequest("invalid-id", []byte(`{"name": "ci"}`))
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestHandler_APIKey_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mock_ports.NewMockAPIKeyService(ctrl)
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+
+	executeWithRequest := func(user *dto.UserResponse) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/users/x/api-keys", nil)
+		userID := entities.NewObjectIDUserID().String()
+		if user != nil {
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+			userID = user.ID.String()
+		}
+		req = mux.SetURLVars(req, map[string]string{"id": userID})
+		apiKeyHandler := NewAPIKeyHandler(mockAPIKeyService)
+		s := http.NewServeMux()
+		s.HandleFunc("/api/users/{id}/api-keys", apiKeyHandler.ListAPIKeys)
+		s.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().ListAPIKeys(gomock.Any(), user.ID).Return(&dto.APIKeysListResponse{
+			APIKeys: []dto.APIKeyResponse{{ID: entities.NewID(), Name: "ci", Prefix: "bck_0123456789ab"}},
+			Total:   1,
+		}, nil)
+		response := executeWithRequest(user)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), "bck_0123456789ab")
+		assert.NotContains(t, response.Body.String(), `"key"`)
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().ListAPIKeys(gomock.Any(), user.ID).Return(nil, assert.AnError)
+		response := executeWithRequest(user)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		response := executeWithRequest(nil)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+}
+
+func TestHandler_APIKey_Revoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mock_ports.NewMockAPIKeyService(ctrl)
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+	keyID := entities.NewID()
+
+	executeWithRequest := func(vars map[string]string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/users/x/api-keys/y", nil)
+		req = req.WithContext(middleware.WithUser(req.Context(), user))
+		req = mux.SetURLVars(req, vars)
+		apiKeyHandler := NewAPIKeyHandler(mockAPIKeyService)
+		s := http.NewServeMux()
+		s.HandleFunc("/api/users/{id}/api-keys/{keyId}", apiKeyHandler.RevokeAPIKey)
+		s.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().RevokeAPIKey(gomock.Any(), user.ID, keyID).Return(nil)
+		response := executeWithRequest(map[string]string{"id": user.ID.String(), "keyId": keyID.String()})
+		assert.Equal(t, http.StatusNoContent, response.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().RevokeAPIKey(gomock.Any(), user.ID, keyID).Return(domainErrors.ErrAPIKeyNotFound)
+		response := executeWithRequest(map[string]string{"id": user.ID.String(), "keyId": keyID.String()})
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Invalid Key ID", func(t *testing.T) {
+		response := executeWithRequest(map[string]string{"id": user.ID.String(), "keyId": "invalid-id"})
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/wonyus/backend-challenge/internal/application/dto"
 	"github.com/wonyus/backend-challenge/internal/application/ports"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
 	"github.com/wonyus/backend-challenge/pkg/validator"
 )
 
@@ -35,7 +37,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	response, err := h.authService.Register(r.Context(), &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -57,8 +59,12 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response, err := h.authService.Login(r.Context(), &req)
+	if errors.Is(err, domainErrors.ErrInvalidScope) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusUnauthorized))
 		return
 	}
 
@@ -10,8 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
 	mock_ports "github.com/wonyus/backend-challenge/mock/port"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/mock/gomock"
 )
 
@@ -23,7 +23,7 @@ func TestHandler_Auth_Register(t *testing.T) {
 
 	var (
 		ctx = context.Background()
-		id  = primitive.NewObjectID()
+		id  = entities.NewUserID()
 	)
 
 	mockRequest := &dto.CreateUserRequest{
@@ -33,7 +33,7 @@ func TestHandler_Auth_Register(t *testing.T) {
 	}
 
 	mockResponse := &dto.RegisterResponse{
-		ID:      id,
+		ID:      &id,
 		Message: "User registered successfully",
 	}
 	executeWithRequest := func(method string, jsonRequestBody []byte) *httptest.ResponseRecorder {
@@ -97,7 +97,7 @@ func TestHandler_Auth_Login(t *testing.T) {
 
 	var (
 		ctx = context.Background()
-		id  = primitive.NewObjectID()
+		id  = entities.NewUserID()
 	)
 
 	mockRequest := &dto.LoginRequest{
@@ -107,7 +107,7 @@ func TestHandler_Auth_Login(t *testing.T) {
 
 	mockResponse := &dto.LoginResponse{
 		Token: "mocked_token",
-		User: dto.UserResponse{
+		User: &dto.UserResponse{
 			ID:    id,
 			Email: "test@example.com",
 			Name:  "Test User",
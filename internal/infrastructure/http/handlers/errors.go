@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+// serviceErrorStatus returns status for a service error, unless the storage
+// behind the service is unavailable, which the client may retry later.
+func serviceErrorStatus(err error, status int) int {
+	if errors.Is(err, domainErrors.ErrUnavailable) {
+		return http.StatusServiceUnavailable
+	}
+	return status
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+const (
+	// federatedStateCookie binds the login state to the browser that started
+	// the login, so that a callback cannot be replayed in another session.
+	federatedStateCookie = "federated_state"
+	federatedCookiePath  = "/api/auth/federated/"
+)
+
+type FederationHandler struct {
+	federationService ports.FederationService
+}
+
+func NewFederationHandler(federationService ports.FederationService) *FederationHandler {
+	return &FederationHandler{
+		federationService: federationService,
+	}
+}
+
+func (h *FederationHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.federationService.Providers())
+}
+
+// Login redirects the user to sign in at the identity provider.
+func (h *FederationHandler) Login(w http.ResponseWriter, r *http.Request) {
+	start, err := h.federationService.StartLogin(r.Context(), mux.Vars(r)["provider"])
+	if err != nil {
+		http.Error(w, err.Error(), federationErrorStatus(err))
+		return
+	}
+
+	// The cookie has to survive the top-level redirect back from the
+	// provider, which SameSite=Strict would drop
+	http.SetCookie(w, &http.Cookie{
+		Name:     federatedStateCookie,
+		Value:    start.State,
+		Path:     federatedCookiePath,
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, start.AuthorizationURL, http.StatusFound)
+}
+
+// Callback completes the login when the identity provider redirects back.
+func (h *FederationHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	state := query.Get("state")
+
+	cookie, err := r.Cookie(federatedStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		http.Error(w, domainErrors.ErrInvalidFederatedState.Error(), http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     federatedStateCookie,
+		Path:     federatedCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if providerError := query.Get("error"); providerError != "" {
+		http.Error(w, domainErrors.ErrFederatedLoginFailed.Error()+": "+providerError, http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.federationService.CompleteLogin(r.Context(), mux.Vars(r)["provider"], state, query.Get("code"))
+	if err != nil {
+		http.Error(w, err.Error(), federationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
+
+func federationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErrors.ErrIdentityProviderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainErrors.ErrInvalidFederatedState):
+		return http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrEmailNotVerified):
+		return http.StatusForbidden
+	case errors.Is(err, domainErrors.ErrFederatedLoginFailed):
+		return http.StatusUnauthorized
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
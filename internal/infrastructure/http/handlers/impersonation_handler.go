@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+)
+
+type ImpersonationHandler struct {
+	impersonationService ports.ImpersonationService
+}
+
+func NewImpersonationHandler(impersonationService ports.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+	}
+}
+
+// Impersonate issues the caller, an administrator, a token for acting as
+// the user in the path.
+func (h *ImpersonationHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := entities.ParseUserID(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.impersonationService.Impersonate(r.Context(), actor.ID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), impersonationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
+
+func impersonationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErrors.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainErrors.ErrForbidden), errors.Is(err, domainErrors.ErrCannotImpersonate),
+		errors.Is(err, domainErrors.ErrUserDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
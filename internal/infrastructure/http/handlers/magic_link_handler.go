@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/pkg/validator"
+)
+
+type MagicLinkHandler struct {
+	magicLinkService ports.MagicLinkService
+	validator        *validator.Validator
+}
+
+func NewMagicLinkHandler(magicLinkService ports.MagicLinkService) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkService: magicLinkService,
+		validator:        validator.New(),
+	}
+}
+
+// Request mails a sign in link. It answers the same for unknown emails.
+func (h *MagicLinkHandler) Request(w http.ResponseWriter, r *http.Request) {
+	var req dto.MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.magicLinkService.RequestLink(r.Context(), &req)
+	if errors.Is(err, domainErrors.ErrTooManyMagicLinks) {
+		w.Header().Set("Retry-After", "900")
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Callback signs the user in with the link from the email.
+func (h *MagicLinkHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	// Keep the token out of the Referer of anything the response loads
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	query := r.URL.Query()
+	response, err := h.magicLinkService.Login(r.Context(), query.Get("token"), query.Get("email"))
+	if errors.Is(err, domainErrors.ErrInvalidMagicLink) || errors.Is(err, domainErrors.ErrUserDisabled) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
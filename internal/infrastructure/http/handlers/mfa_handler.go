@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+	"github.com/wonyus/backend-challenge/pkg/validator"
+)
+
+type MFAHandler struct {
+	mfaService ports.MFAService
+	validator  *validator.Validator
+}
+
+func NewMFAHandler(mfaService ports.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+		validator:  validator.New(),
+	}
+}
+
+func (h *MFAHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.mfaService.Enroll(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), mfaErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MFAHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.mfaService.Confirm(r.Context(), user.ID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), mfaErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *MFAHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.mfaService.Disable(r.Context(), user.ID, &req); err != nil {
+		http.Error(w, err.Error(), mfaErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.mfaService.Verify(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), mfaErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErrors.ErrInvalidToken), errors.Is(err, domainErrors.ErrInvalidMFACode):
+		return http.StatusUnauthorized
+	case errors.Is(err, domainErrors.ErrMFATooManyAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domainErrors.ErrMFAAlreadyEnabled), errors.Is(err, domainErrors.ErrMFANotEnabled), errors.Is(err, domainErrors.ErrMFANotEnrolled):
+		return http.StatusConflict
+	case errors.Is(err, domainErrors.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
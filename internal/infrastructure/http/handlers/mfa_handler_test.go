@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_MFA_Enroll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMFAService := mock_ports.NewMockMFAService(ctrl)
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+
+	executeWithRequest := func(user *dto.UserResponse) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/auth/mfa/enroll", nil)
+		if user != nil {
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+		}
+		mfaHandler := NewMFAHandler(mockMFAService)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/auth/mfa/enroll", mfaHandler.Enroll)
+		mux.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockMFAService.EXPECT().Enroll(gomock.Any(), user.ID).Return(&dto.MFAEnrollResponse{Secret: "SECRET", OTPAuthURI: "otpauth://totp/x"}, nil)
+		response := executeWithRequest(user)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), "otpauth://totp/x")
+	})
+
+	t.Run("Already Enabled", func(t *testing.T) {
+		mockMFAService.EXPECT().Enroll(gomock.Any(), user.ID).Return(nil, domainErrors.ErrMFAAlreadyEnabled)
+		response := executeWithRequest(user)
+		assert.Equal(t, http.StatusConflict, response.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		response := executeWithRequest(nil)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+}
+
+func TestHandler_MFA_Confirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMFAService := mock_ports.NewMockMFAService(ctrl)
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+
+	executeWithRequest := func(jsonBody []byte) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/auth/mfa/confirm", strings.NewReader(string(jsonBody)))
+		req = req.WithContext(middleware.WithUser(req.Context(), user))
+		mfaHandler := NewMFAHandler(mockMFAService)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/auth/mfa/confirm", mfaHandler.Confirm)
+		mux.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockMFAService.EXPECT().Confirm(gomock.Any(), user.ID, &dto.MFACodeRequest{Code: "123456"}).Return(&dto.MFARecoveryCodesResponse{RecoveryCodes: []string{"abcd-efgh"}}, nil)
+		response := executeWithRequest([]byte(`{"code": "123456"}`))
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), "abcd-efgh")
+	})
+
+	t.Run("Invalid Code", func(t *testing.T) {
+		mockMFAService.EXPECT().Confirm(gomock.Any(), user.ID, &dto.MFACodeRequest{Code: "000000"}).Return(nil, domainErrors.ErrInvalidMFACode)
+		response := executeWithRequest([]byte(`{"code": "000000"}`))
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("Bad Request", func(t *testing.T) {
+		response := executeWithRequest([]byte(`{}`))
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestHandler_MFA_Disable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMFAService := mock_ports.NewMockMFAService(ctrl)
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+
+	executeWithRequest := func(jsonBody []byte) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/auth/mfa/disable", strings.NewReader(string(jsonBody)))
+		req = req.WithContext(middleware.WithUser(req.Context(), user))
+		mfaHandler := NewMFAHandler(mockMFAService)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/auth/mfa/disable", mfaHandler.Disable)
+		mux.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockMFAService.EXPECT().Disable(gomock.Any(), user.ID, &dto.MFACodeRequest{Code: "123456"}).Return(nil)
+		response := executeWithRequest([]byte(`{"code": "123456"}`))
+		assert.Equal(t, http.StatusNoContent, response.Code)
+	})
+
+	t.Run("Not Enabled", func(t *testing.T) {
+		mockMFAService.EXPECT().Disable(gomock.Any(), user.ID, &dto.MFACodeRequest{Code: "123456"}).Return(domainErrors.ErrMFANotEnabled)
+		response := executeWithRequest([]byte(`{"code": "123456"}`))
+		assert.Equal(t, http.StatusConflict, response.Code)
+	})
+}
+
+func TestHandler_MFA_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMFAService := mock_ports.NewMockMFAService(ctrl)
+
+	executeWithRequest := func(jsonBody []byte) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/auth/mfa/verify", strings.NewReader(string(jsonBody)))
+		mfaHandler := NewMFAHandler(mockMFAService)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/auth/mfa/verify", mfaHandler.Verify)
+		mux.ServeHTTP(response, req)
+		return response
+	}
+
+	mockRequest := &dto.MFAVerifyRequest{MFAToken: "challenge", Code: "123456"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockMFAService.EXPECT().Verify(gomock.Any(), mockRequest).Return(&dto.LoginResponse{Token: "mocked_token"}, nil)
+		response := executeWithRequest([]byte(`{"mfa_token": "challenge", "code": "123456"}`))
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), "mocked_token")
+	})
+
+	t.Run("Invalid Code", func(t *testing.T) {
+		mockMFAService.EXPECT().Verify(gomock.Any(), mockRequest).Return(nil, domainErrors.ErrInvalidMFACode)
+		response := executeWithRequest([]byte(`{"mfa_token": "challenge", "code": "123456"}`))
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("Locked Out", func(t *testing.T) {
+		mockMFAService.EXPECT().Verify(gomock.Any(), mockRequest).Return(nil, domainErrors.ErrMFATooManyAttempts)
+		response := executeWithRequest([]byte(`{"mfa_token": "challenge", "code": "123456"}`))
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	})
+
+	t.Run("Bad Request", func(t *testing.T) {
+		response := executeWithRequest([]byte(`{"code": "123456"}`))
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
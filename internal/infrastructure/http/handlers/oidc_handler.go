@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+	"github.com/wonyus/backend-challenge/pkg/validator"
+)
+
+// loginTemplate is the sign-in page of the authorization endpoint. It posts
+// the authorization request back along with the credentials, and asks for a
+// TOTP code in a second step when the account has MFA enabled.
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in</title></head>
+<body>
+<h1>Sign in</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}{{if .MFAToken}}<input type="hidden" name="mfa_token" value="{{.MFAToken}}">
+<label>Authentication code <input name="code" autocomplete="one-time-code" required></label>
+{{else}}<label>Email <input type="email" name="email" autocomplete="username" required></label>
+<label>Password <input type="password" name="password" autocomplete="current-password" required></label>
+{{end}}<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+type loginPage struct {
+	Params   map[string]string
+	MFAToken string
+	Error    string
+}
+
+type oauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type OIDCHandler struct {
+	oidcService ports.OIDCService
+	authService ports.AuthService
+	mfaService  ports.MFAService
+	validator   *validator.Validator
+}
+
+func NewOIDCHandler(oidcService ports.OIDCService, authService ports.AuthService, mfaService ports.MFAService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		authService: authService,
+		mfaService:  mfaService,
+		validator:   validator.New(),
+	}
+}
+
+func (h *OIDCHandler) Discovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.oidcService.Discovery())
+}
+
+func (h *OIDCHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.oidcService.JWKS())
+}
+
+// Authorize signs the user in and redirects back to the client with an
+// authorization code. A user that already has an access token may present
+// it as a bearer token instead of filling in the sign-in form.
+func (h *OIDCHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	req := &dto.AuthorizeRequest{
+		ResponseType:        r.Form.Get("response_type"),
+		ClientID:            r.Form.Get("client_id"),
+		RedirectURI:         r.Form.Get("redirect_uri"),
+		Scope:               r.Form.Get("scope"),
+		State:               r.Form.Get("state"),
+		Nonce:               r.Form.Get("nonce"),
+		CodeChallenge:       r.Form.Get("code_challenge"),
+		CodeChallengeMethod: r.Form.Get("code_challenge_method"),
+	}
+
+	if err := h.oidcService.ValidateAuthorizeRequest(r.Context(), req); err != nil {
+		h.authorizeError(w, r, req, err)
+		return
+	}
+
+	accessToken, ok := bearerToken(r)
+	if !ok {
+		if r.Method != http.MethodPost {
+			h.renderLogin(w, http.StatusOK, req, "", "")
+			return
+		}
+
+		if accessToken, ok = h.signIn(w, r, req); !ok {
+			return
+		}
+	}
+
+	// As in AuthMiddleware, the tenant of the token applies unless the
+	// request named one
+	ctx := r.Context()
+	if !middleware.TenantExplicit(ctx) {
+		ctx = domainServices.WithTenant(ctx, "")
+	}
+	response, err := h.oidcService.Authorize(ctx, accessToken, req)
+	if err != nil {
+		h.authorizeError(w, r, req, err)
+		return
+	}
+
+	h.redirect(w, r, response.RedirectURI, url.Values{"code": {response.Code}}, response.State)
+}
+
+func (h *OIDCHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, domainErrors.ErrInvalidOAuthRequest)
+		return
+	}
+
+	req := &dto.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+	}
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		req.ClientID, _ = url.QueryUnescape(clientID)
+		req.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	response, err := h.oidcService.Token(r.Context(), req)
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *OIDCHandler) UserInfo(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.oidcService.UserInfo(r.Context(), principal)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusForbidden))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *OIDCHandler) RegisterClient(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateOAuthClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.oidcService.RegisterClient(r.Context(), &req)
+	if err != nil {
+		status := serviceErrorStatus(err, http.StatusInternalServerError)
+		if errors.Is(err, domainErrors.ErrInvalidRedirectURI) || errors.Is(err, domainErrors.ErrInvalidScope) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// signIn checks the credentials posted from the sign-in page and returns an
+// access token. On failure it renders the page again.
+func (h *OIDCHandler) signIn(w http.ResponseWriter, r *http.Request, req *dto.AuthorizeRequest) (string, bool) {
+	if mfaToken := r.PostForm.Get("mfa_token"); mfaToken != "" {
+		response, err := h.mfaService.Verify(r.Context(), &dto.MFAVerifyRequest{MFAToken: mfaToken, Code: r.PostForm.Get("code")})
+		if errors.Is(err, domainErrors.ErrInvalidToken) {
+			h.renderLogin(w, http.StatusUnauthorized, req, "", "Your sign-in expired, please start again")
+			return "", false
+		}
+		if err != nil {
+			h.renderLogin(w, http.StatusUnauthorized, req, mfaToken, "Invalid authentication code")
+			return "", false
+		}
+		return response.Token, true
+	}
+
+	loginReq := &dto.LoginRequest{Email: r.PostForm.Get("email"), Password: r.PostForm.Get("password")}
+	if err := h.validator.Validate(loginReq); err != nil {
+		h.renderLogin(w, http.StatusBadRequest, req, "", err.Error())
+		return "", false
+	}
+
+	response, err := h.authService.Login(r.Context(), loginReq)
+	if err != nil {
+		h.renderLogin(w, http.StatusUnauthorized, req, "", "Invalid email or password")
+		return "", false
+	}
+
+	if response.MFARequired {
+		h.renderLogin(w, http.StatusOK, req, response.MFAToken, "")
+		return "", false
+	}
+
+	return response.Token, true
+}
+
+func (h *OIDCHandler) renderLogin(w http.ResponseWriter, status int, req *dto.AuthorizeRequest, mfaToken, message string) {
+	page := loginPage{
+		Params: map[string]string{
+			"response_type":         req.ResponseType,
+			"client_id":             req.ClientID,
+			"redirect_uri":          req.RedirectURI,
+			"scope":                 req.Scope,
+			"state":                 req.State,
+			"nonce":                 req.Nonce,
+			"code_challenge":        req.CodeChallenge,
+			"code_challenge_method": req.CodeChallengeMethod,
+		},
+		MFAToken: mfaToken,
+		Error:    message,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	w.WriteHeader(status)
+	loginTemplate.Execute(w, page)
+}
+
+// authorizeError reports problems with the client or redirect URI to the
+// user directly, and everything else to the client through the redirect.
+func (h *OIDCHandler) authorizeError(w http.ResponseWriter, r *http.Request, req *dto.AuthorizeRequest, err error) {
+	if errors.Is(err, domainErrors.ErrInvalidClient) || errors.Is(err, domainErrors.ErrInvalidRedirectURI) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	code, _ := oauthErrorCode(err)
+	h.redirect(w, r, req.RedirectURI, url.Values{"error": {code}, "error_description": {err.Error()}}, req.State)
+}
+
+func (h *OIDCHandler) redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values, state string) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, domainErrors.ErrInvalidRedirectURI.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func writeOAuthError(w http.ResponseWriter, err error) {
+	code, status := oauthErrorCode(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(oauthErrorResponse{Error: code, ErrorDescription: err.Error()})
+}
+
+// oauthErrorCode maps errors to the error codes of RFC 6749.
+func oauthErrorCode(err error) (string, int) {
+	switch {
+	case errors.Is(err, domainErrors.ErrInvalidClient):
+		return "invalid_client", http.StatusUnauthorized
+	case errors.Is(err, domainErrors.ErrInvalidGrant):
+		return "invalid_grant", http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrInvalidScope):
+		return "invalid_scope", http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrUnsupportedGrantType):
+		return "unsupported_grant_type", http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrUnsupportedResponseType):
+		return "unsupported_response_type", http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrInvalidOAuthRequest):
+		return "invalid_request", http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrInvalidToken), errors.Is(err, domainErrors.ErrUserNotFound):
+		return "access_denied", http.StatusUnauthorized
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return "temporarily_unavailable", http.StatusServiceUnavailable
+	default:
+		return "server_error", http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+	"github.com/wonyus/backend-challenge/pkg/validator"
+)
+
+type OrganizationHandler struct {
+	organizationService ports.OrganizationService
+	validator           *validator.Validator
+}
+
+func NewOrganizationHandler(organizationService ports.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		validator:           validator.New(),
+	}
+}
+
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreateOrganizationRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	response, err := h.organizationService.CreateOrganization(r.Context(), principal, &req)
+	writeOrganizationResponse(w, http.StatusCreated, response, err)
+}
+
+// ListOrganizations lists the caller's organizations.
+func (h *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok || principal.User == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.organizationService.ListOrganizations(r.Context(), principal.User.ID)
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+
+	response, err := h.organizationService.GetOrganization(r.Context(), principal, ids[0])
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateOrganizationRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	response, err := h.organizationService.UpdateOrganization(r.Context(), principal, ids[0], &req)
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+
+	err := h.organizationService.DeleteOrganization(r.Context(), principal, ids[0])
+	writeOrganizationResponse(w, http.StatusNoContent, nil, err)
+}
+
+func (h *OrganizationHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+
+	response, err := h.organizationService.ListMembers(r.Context(), principal, ids[0])
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) InviteMember(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+
+	var req dto.InviteMemberRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	response, err := h.organizationService.InviteMember(r.Context(), principal, ids[0], &req)
+	writeOrganizationResponse(w, http.StatusCreated, response, err)
+}
+
+func (h *OrganizationHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+	userID, ok := memberID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MemberRoleRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	response, err := h.organizationService.UpdateMember(r.Context(), principal, ids[0], userID, &req)
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+	userID, ok := memberID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.organizationService.RemoveMember(r.Context(), principal, ids[0], userID)
+	writeOrganizationResponse(w, http.StatusNoContent, nil, err)
+}
+
+// ListMyInvitations lists the organizations the caller has been invited to.
+func (h *OrganizationHandler) ListMyInvitations(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok || principal.User == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.organizationService.ListInvitations(r.Context(), principal.User.ID)
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) AcceptMyInvitation(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+	if principal.User == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.organizationService.AcceptInvitation(r.Context(), principal.User.ID, ids[0])
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) DeclineMyInvitation(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+	if principal.User == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.organizationService.DeclineInvitation(r.Context(), principal.User.ID, ids[0])
+	writeOrganizationResponse(w, http.StatusNoContent, nil, err)
+}
+
+func (h *OrganizationHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateTeamRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	response, err := h.organizationService.CreateTeam(r.Context(), principal, ids[0], &req)
+	writeOrganizationResponse(w, http.StatusCreated, response, err)
+}
+
+func (h *OrganizationHandler) ListTeams(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId")
+	if !ok {
+		return
+	}
+
+	response, err := h.organizationService.ListTeams(r.Context(), principal, ids[0])
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId", "teamId")
+	if !ok {
+		return
+	}
+
+	response, err := h.organizationService.GetTeam(r.Context(), principal, ids[0], ids[1])
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId", "teamId")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateTeamRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	response, err := h.organizationService.UpdateTeam(r.Context(), principal, ids[0], ids[1], &req)
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId", "teamId")
+	if !ok {
+		return
+	}
+
+	err := h.organizationService.DeleteTeam(r.Context(), principal, ids[0], ids[1])
+	writeOrganizationResponse(w, http.StatusNoContent, nil, err)
+}
+
+func (h *OrganizationHandler) ListTeamMembers(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId", "teamId")
+	if !ok {
+		return
+	}
+
+	response, err := h.organizationService.ListTeamMembers(r.Context(), principal, ids[0], ids[1])
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+// SetTeamMember adds a member to the team or changes their role in it.
+func (h *OrganizationHandler) SetTeamMember(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId", "teamId")
+	if !ok {
+		return
+	}
+	userID, ok := memberID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.MemberRoleRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	response, err := h.organizationService.SetTeamMember(r.Context(), principal, ids[0], ids[1], userID, &req)
+	writeOrganizationResponse(w, http.StatusOK, response, err)
+}
+
+func (h *OrganizationHandler) RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	principal, ids, ok := organizationRequest(w, r, "orgId", "teamId")
+	if !ok {
+		return
+	}
+	userID, ok := memberID(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.organizationService.RemoveTeamMember(r.Context(), principal, ids[0], ids[1], userID)
+	writeOrganizationResponse(w, http.StatusNoContent, nil, err)
+}
+
+func (h *OrganizationHandler) decode(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+
+	if err := h.validator.Validate(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// organizationRequest returns the caller and the IDs in the named path
+// variables.
+func organizationRequest(w http.ResponseWriter, r *http.Request, names ...string) (*dto.Principal, []entities.ID, bool) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	vars := mux.Vars(r)
+	ids := make([]entities.ID, len(names))
+	for i, name := range names {
+		id, err := entities.ParseID(vars[name])
+		if err != nil {
+			http.Error(w, "Invalid "+name, http.StatusBadRequest)
+			return nil, nil, false
+		}
+		ids[i] = id
+	}
+	return principal, ids, true
+}
+
+// memberID returns the user ID in the userId path variable.
+func memberID(w http.ResponseWriter, r *http.Request) (entities.UserID, bool) {
+	userID, err := entities.ParseUserID(mux.Vars(r)["userId"])
+	if err != nil {
+		http.Error(w, "Invalid userId", http.StatusBadRequest)
+		return "", false
+	}
+	return userID, true
+}
+
+func writeOrganizationResponse(w http.ResponseWriter, status int, response interface{}, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), organizationErrorStatus(err))
+		return
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func organizationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErrors.ErrOrganizationNotFound), errors.Is(err, domainErrors.ErrTeamNotFound),
+		errors.Is(err, domainErrors.ErrMembershipNotFound), errors.Is(err, domainErrors.ErrInvitationNotFound),
+		errors.Is(err, domainErrors.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainErrors.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domainErrors.ErrMembershipExists), errors.Is(err, domainErrors.ErrLastOwner):
+		return http.StatusConflict
+	case errors.Is(err, domainErrors.ErrInvalidRole):
+		return http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
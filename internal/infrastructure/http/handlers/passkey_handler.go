@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/pkg/validator"
+)
+
+type PasskeyHandler struct {
+	passkeyService ports.PasskeyService
+	validator      *validator.Validator
+}
+
+func NewPasskeyHandler(passkeyService ports.PasskeyService) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: passkeyService,
+		validator:      validator.New(),
+	}
+}
+
+// BeginRegistration returns the options for navigator.credentials.create.
+func (h *PasskeyHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizeOwner(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.passkeyService.BeginRegistration(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), passkeyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RegisterPasskey stores the credential created by the browser.
+func (h *PasskeyHandler) RegisterPasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizeOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.RegisterPasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.passkeyService.FinishRegistration(r.Context(), userID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), passkeyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *PasskeyHandler) ListPasskeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizeOwner(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.passkeyService.ListPasskeys(r.Context(), userID)
+	if err != nil {
+		http.Error(w, err.Error(), passkeyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *PasskeyHandler) RenamePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizeOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.RenamePasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.passkeyService.RenamePasskey(r.Context(), userID, mux.Vars(r)["passkeyId"], &req)
+	if err != nil {
+		http.Error(w, err.Error(), passkeyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *PasskeyHandler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizeOwner(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.passkeyService.DeletePasskey(r.Context(), userID, mux.Vars(r)["passkeyId"]); err != nil {
+		http.Error(w, err.Error(), passkeyErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BeginLogin returns the options for navigator.credentials.get.
+func (h *PasskeyHandler) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	response, err := h.passkeyService.BeginLogin(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), passkeyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Login signs the user in with the assertion from the browser.
+func (h *PasskeyHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.PasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.passkeyService.FinishLogin(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), passkeyErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(response)
+}
+
+func passkeyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErrors.ErrInvalidPasskeyChallenge), errors.Is(err, domainErrors.ErrInvalidPasskey):
+		return http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrPasskeyVerificationFailed),
+		errors.Is(err, domainErrors.ErrPasskeyCloned),
+		errors.Is(err, domainErrors.ErrUserDisabled):
+		return http.StatusUnauthorized
+	case errors.Is(err, domainErrors.ErrUserNotFound), errors.Is(err, domainErrors.ErrPasskeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainErrors.ErrPasskeyExists):
+		return http.StatusConflict
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
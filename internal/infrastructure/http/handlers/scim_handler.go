@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+const scimContentType = "application/scim+json"
+
+type SCIMHandler struct {
+	scimService ports.SCIMService
+}
+
+func NewSCIMHandler(scimService ports.SCIMService) *SCIMHandler {
+	return &SCIMHandler{
+		scimService: scimService,
+	}
+}
+
+func (h *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	listQuery := &dto.SCIMListQuery{Filter: query.Get("filter")}
+
+	if startIndex := query.Get("startIndex"); startIndex != "" {
+		value, err := strconv.Atoi(startIndex)
+		if err != nil {
+			writeSCIMError(w, http.StatusBadRequest, "invalidValue", "startIndex must be an integer")
+			return
+		}
+		listQuery.StartIndex = value
+	}
+	if count := query.Get("count"); count != "" {
+		value, err := strconv.Atoi(count)
+		if err != nil {
+			writeSCIMError(w, http.StatusBadRequest, "invalidValue", "count must be an integer")
+			return
+		}
+		listQuery.Count = &value
+	}
+
+	response, err := h.scimService.ListUsers(r.Context(), listQuery)
+	if err != nil {
+		writeSCIMServiceError(w, err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, response)
+}
+
+func (h *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req dto.SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	response, err := h.scimService.CreateUser(r.Context(), &req)
+	if err != nil {
+		writeSCIMServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", response.Meta.Location)
+	writeSCIM(w, http.StatusCreated, response)
+}
+
+func (h *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	response, err := h.scimService.GetUser(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeSCIMServiceError(w, err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, response)
+}
+
+func (h *SCIMHandler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	var req dto.SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	response, err := h.scimService.ReplaceUser(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		writeSCIMServiceError(w, err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, response)
+}
+
+func (h *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	var req dto.SCIMPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	response, err := h.scimService.PatchUser(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		writeSCIMServiceError(w, err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, response)
+}
+
+func (h *SCIMHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if err := h.scimService.DeleteUser(r.Context(), mux.Vars(r)["id"]); err != nil {
+		writeSCIMServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SCIMHandler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeSCIM(w, http.StatusOK, h.scimService.ServiceProviderConfig())
+}
+
+func (h *SCIMHandler) ResourceTypes(w http.ResponseWriter, r *http.Request) {
+	writeSCIM(w, http.StatusOK, h.scimService.ResourceTypes())
+}
+
+func (h *SCIMHandler) ResourceType(w http.ResponseWriter, r *http.Request) {
+	response, err := h.scimService.ResourceType(mux.Vars(r)["id"])
+	if err != nil {
+		writeSCIMServiceError(w, err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, response)
+}
+
+func (h *SCIMHandler) Schemas(w http.ResponseWriter, r *http.Request) {
+	writeSCIM(w, http.StatusOK, h.scimService.Schemas())
+}
+
+func (h *SCIMHandler) Schema(w http.ResponseWriter, r *http.Request) {
+	response, err := h.scimService.Schema(mux.Vars(r)["id"])
+	if err != nil {
+		writeSCIMServiceError(w, err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, response)
+}
+
+func writeSCIM(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeSCIMServiceError maps service errors to SCIM errors (RFC 7644
+// section 3.12).
+func writeSCIMServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domainErrors.ErrUserNotFound), errors.Is(err, domainErrors.ErrSCIMResourceNotFound):
+		writeSCIMError(w, http.StatusNotFound, "", err.Error())
+	case errors.Is(err, domainErrors.ErrUserAlreadyExists):
+		writeSCIMError(w, http.StatusConflict, "uniqueness", err.Error())
+	case errors.Is(err, domainErrors.ErrInvalidSCIMFilter):
+		writeSCIMError(w, http.StatusBadRequest, "invalidFilter", err.Error())
+	case errors.Is(err, domainErrors.ErrInvalidSCIMPath):
+		writeSCIMError(w, http.StatusBadRequest, "invalidPath", err.Error())
+	case errors.Is(err, domainErrors.ErrInvalidSCIMValue):
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+	case errors.Is(err, domainErrors.ErrSCIMReadOnly):
+		writeSCIMError(w, http.StatusBadRequest, "mutability", err.Error())
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		writeSCIMError(w, http.StatusServiceUnavailable, "", err.Error())
+	default:
+		writeSCIMError(w, http.StatusInternalServerError, "", err.Error())
+	}
+}
+
+func writeSCIMError(w http.ResponseWriter, status int, scimType, detail string) {
+	writeSCIM(w, status, &dto.SCIMErrorResponse{
+		Schemas:  []string{dto.SCIMErrorSchema},
+		Status:   strconv.Itoa(status),
+		SCIMType: scimType,
+		Detail:   detail,
+	})
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+)
+
+type SessionHandler struct {
+	sessionService ports.SessionService
+}
+
+func NewSessionHandler(sessionService ports.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// ListMySessions lists the devices the caller is signed in on.
+func (h *SessionHandler) ListMySessions(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.sessionService.ListSessions(r.Context(), principal.User.ID, principal.SessionID)
+	if err != nil {
+		http.Error(w, err.Error(), sessionErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeMySession signs the caller out of one of their devices, which may
+// be the current one.
+func (h *SessionHandler) RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(r.Context(), principal.User.ID, mux.Vars(r)["sessionId"]); err != nil {
+		http.Error(w, err.Error(), sessionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSessions lists the sessions of any user, for administrators.
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := entities.ParseUserID(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.sessionService.ListSessions(r.Context(), userID, "")
+	if err != nil {
+		http.Error(w, err.Error(), sessionErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeSession revokes a session of any user, for administrators.
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := entities.ParseUserID(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(r.Context(), userID, mux.Vars(r)["sessionId"]); err != nil {
+		http.Error(w, err.Error(), sessionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func sessionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErrors.ErrUserNotFound), errors.Is(err, domainErrors.ErrSessionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+)
+
+type UserEventHandler struct {
+	userEventService ports.UserEventService
+	heartbeat        time.Duration
+}
+
+// NewUserEventHandler sends a comment every heartbeat while no event comes,
+// so proxies keep idle streams open. A heartbeat of 0 sends none.
+func NewUserEventHandler(userEventService ports.UserEventService, heartbeat time.Duration) *UserEventHandler {
+	return &UserEventHandler{
+		userEventService: userEventService,
+		heartbeat:        heartbeat,
+	}
+}
+
+// WatchUsers streams changes to the users of the tenant as Server-Sent
+// Events, named after the event type. ?types=created,deleted selects event
+// types and ?consumer= names a watcher whose position is remembered. The
+// Last-Event-ID header, which EventSource sends when it reconnects, or else
+// ?after=, resumes after an event.
+//
+// Errors after the stream started, such as an event ID that can no longer
+// be resumed after, are sent as an "error" event before the stream ends.
+func (h *UserEventHandler) WatchUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &dto.WatchUsersRequest{
+		ResumeAfter: r.Header.Get("Last-Event-ID"),
+		Consumer:    query.Get("consumer"),
+	}
+	if req.ResumeAfter == "" {
+		req.ResumeAfter = query.Get("after")
+	}
+	if types := query.Get("types"); types != "" {
+		req.Types = strings.Split(types, ",")
+		for _, eventType := range req.Types {
+			if !entities.UserEventType(eventType).IsValid() {
+				http.Error(w, fmt.Sprintf("%v: %q", domainErrors.ErrInvalidUserEventType, eventType), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	stream, err := openEventStream(w)
+	if err != nil {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	defer stream.keepAlive(h.heartbeat)()
+
+	err = h.userEventService.WatchUsers(r.Context(), req, func(event *dto.UserEvent) error {
+		return stream.send(event.ID, event.Type, event)
+	})
+	if err != nil && r.Context().Err() == nil {
+		stream.send("", "error", dto.MessageResponse{Message: err.Error()})
+	}
+}
+
+// eventStream writes Server-Sent Events. Writes are serialized, as heartbeats
+// come from another goroutine.
+type eventStream struct {
+	mutex      sync.Mutex
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+func openEventStream(w http.ResponseWriter) (*eventStream, error) {
+	controller := http.NewResponseController(w)
+	// The stream lasts as long as the client listens, beyond the write
+	// timeout of the server
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := controller.Flush(); err != nil {
+		return nil, err
+	}
+	return &eventStream{w: w, controller: controller}, nil
+}
+
+func (s *eventStream) send(id, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var message strings.Builder
+	if id != "" {
+		fmt.Fprintf(&message, "id: %s\n", id)
+	}
+	fmt.Fprintf(&message, "event: %s\ndata: %s\n\n", event, payload)
+	return s.write(message.String())
+}
+
+func (s *eventStream) write(message string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := fmt.Fprint(s.w, message); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+// keepAlive sends a comment every interval until the returned function is
+// called.
+func (s *eventStream) keepAlive(interval time.Duration) func() {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if s.write(": keep-alive\n\n") != nil {
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_UserEvent_WatchUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserEventService := mock_ports.NewMockUserEventService(ctrl)
+	handler := NewUserEventHandler(mockUserEventService, 0)
+	id := entities.NewUserID()
+	occurredAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	execute := func(target string, header http.Header) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", target, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		handler.WatchUsers(response, req)
+		return response
+	}
+
+	t.Run("Streams events", func(t *testing.T) {
+		mockUserEventService.EXPECT().
+			WatchUsers(gomock.Any(), &dto.WatchUsersRequest{Types: []string{"created", "deleted"}, ResumeAfter: "41", Consumer: "mailer"}, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, req *dto.WatchUsersRequest, send func(*dto.UserEvent) error) error {
+				assert.NoError(t, send(&dto.UserEvent{ID: "42", Type: "created", UserID: id, User: &dto.UserResponse{ID: id, Name: "Test User"}, OccurredAt: occurredAt}))
+				assert.NoError(t, send(&dto.UserEvent{ID: "43", Type: "deleted", UserID: id, OccurredAt: occurredAt}))
+				return nil
+			}).Times(1)
+
+		response := execute("/api/users/events?types=created,deleted&consumer=mailer", http.Header{"Last-Event-Id": {"41"}})
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/event-stream", response.Header().Get("Content-Type"))
+		assert.Equal(t, "id: 42\nevent: created\n"+
+			`data: {"id":"42","type":"created","user_id":"`+id.String()+`","user":{"id":"`+id.String()+`","name":"Test User","email":"","created_at":"0001-01-01T00:00:00Z"},"occurred_at":"2024-01-02T03:04:05Z"}`+"\n\n"+
+			"id: 43\nevent: deleted\n"+
+			`data: {"id":"43","type":"deleted","user_id":"`+id.String()+`","occurred_at":"2024-01-02T03:04:05Z"}`+"\n\n",
+			response.Body.String())
+	})
+
+	t.Run("Resumes after the event in the query", func(t *testing.T) {
+		mockUserEventService.EXPECT().
+			WatchUsers(gomock.Any(), &dto.WatchUsersRequest{ResumeAfter: "7"}, gomock.Any()).
+			Return(nil).Times(1)
+
+		response := execute("/api/users/events?after=7", nil)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Invalid Event Type", func(t *testing.T) {
+		response := execute("/api/users/events?types=created,renamed", nil)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Contains(t, response.Body.String(), `"renamed"`)
+	})
+
+	t.Run("Sends errors as events", func(t *testing.T) {
+		mockUserEventService.EXPECT().
+			WatchUsers(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(domainErrors.ErrInvalidUserEventID).Times(1)
+
+		response := execute("/api/users/events", http.Header{"Last-Event-Id": {"expired"}})
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "event: error\ndata: {\"message\":\""+domainErrors.ErrInvalidUserEventID.Error()+"\"}\n\n", response.Body.String())
+	})
+}
+
+func TestEventStream_KeepAlive(t *testing.T) {
+	response := httptest.NewRecorder()
+	stream, err := openEventStream(response)
+	assert.NoError(t, err)
+
+	stop := stream.keepAlive(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+	assert.Contains(t, response.Body.String(), ": keep-alive\n\n")
+}
@@ -7,19 +7,21 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/wonyus/backend-challenge/internal/application/dto"
 	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
 	"github.com/wonyus/backend-challenge/pkg/validator"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UserHandler struct {
-	userService ports.UserService
-	validator   *validator.Validator
+	userService         ports.UserService
+	organizationService ports.OrganizationService
+	validator           *validator.Validator
 }
 
-func NewUserHandler(userService ports.UserService) *UserHandler {
+func NewUserHandler(userService ports.UserService, organizationService ports.OrganizationService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		validator:   validator.New(),
+		userService:         userService,
+		organizationService: organizationService,
+		validator:           validator.New(),
 	}
 }
 
@@ -37,7 +39,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userService.CreateUser(r.Context(), &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusBadRequest))
 		return
 	}
 
@@ -48,7 +50,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	id, err := primitive.ObjectIDFromHex(vars["id"])
+	id, err := entities.ParseUserID(vars["id"])
 	if err != nil {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
@@ -56,10 +58,19 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userService.GetUserByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusNotFound))
 		return
 	}
 
+	// ?include=memberships adds the user's organizations and teams
+	if r.URL.Query().Get("include") == "memberships" {
+		user.Memberships, err = h.organizationService.GetUserMemberships(r.Context(), id)
+		if err != nil {
+			http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
@@ -67,7 +78,7 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := h.userService.GetAllUsers(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -77,7 +88,7 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	id, err := primitive.ObjectIDFromHex(vars["id"])
+	id, err := entities.ParseUserID(vars["id"])
 	if err != nil {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
@@ -96,7 +107,7 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userService.UpdateUser(r.Context(), id, &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusNotFound))
 		return
 	}
 
@@ -106,14 +117,14 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	id, err := primitive.ObjectIDFromHex(vars["id"])
+	id, err := entities.ParseUserID(vars["id"])
 	if err != nil {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
 
 	if err := h.userService.DeleteUser(r.Context(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), serviceErrorStatus(err, http.StatusNotFound))
 		return
 	}
 
@@ -12,8 +12,9 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
 	mock_ports "github.com/wonyus/backend-challenge/mock/port"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/mock/gomock"
 )
 
@@ -25,7 +26,7 @@ func TestHandler_User_CreateUser(t *testing.T) {
 
 	var (
 		ctx = context.Background()
-		id  = primitive.NewObjectID()
+		id  = entities.NewUserID()
 	)
 
 	mockRequest := &dto.CreateUserRequest{
@@ -43,7 +44,7 @@ func TestHandler_User_CreateUser(t *testing.T) {
 	executeWithRequest := func(method string, jsonRequestBody []byte) *httptest.ResponseRecorder {
 		response := httptest.NewRecorder()
 		req, _ := http.NewRequest(method, "/api/users", strings.NewReader(string(jsonRequestBody)))
-		userHandler := NewUserHandler(mockUserService)
+		userHandler := NewUserHandler(mockUserService, nil)
 		mux := http.NewServeMux()
 		mux.HandleFunc("/api/users", userHandler.CreateUser)
 		mux.ServeHTTP(response, req)
@@ -97,10 +98,11 @@ func TestHandler_User_GetUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserService := mock_ports.NewMockUserService(ctrl)
+	mockOrganizationService := mock_ports.NewMockOrganizationService(ctrl)
 
 	var (
 		// ctx = context.Background()
-		id = primitive.NewObjectID()
+		id = entities.NewUserID()
 	)
 
 	mockResponse := &dto.UserResponse{
@@ -112,7 +114,7 @@ func TestHandler_User_GetUser(t *testing.T) {
 	executeWithRequest := func(method string, userID string, vars map[string]string) *httptest.ResponseRecorder {
 		response := httptest.NewRecorder()
 		req, _ := http.NewRequest(method, fmt.Sprintf("/api/users/%s", userID), nil)
-		userHandler := NewUserHandler(mockUserService)
+		userHandler := NewUserHandler(mockUserService, mockOrganizationService)
 		s := http.NewServeMux()
 		s.HandleFunc("/api/users/{id}", userHandler.GetUser)
 		req = mux.SetURLVars(req, vars)
@@ -121,11 +123,21 @@ func TestHandler_User_GetUser(t *testing.T) {
 	}
 	t.Run("Success", func(t *testing.T) {
 		mockUserService.EXPECT().GetUserByID(gomock.Any(), id).Return(mockResponse, nil)
-		vars := map[string]string{"id": id.Hex()}
-		response := executeWithRequest(http.MethodGet, id.Hex(), vars)
+		vars := map[string]string{"id": id.String()}
+		response := executeWithRequest(http.MethodGet, id.String(), vars)
 		assert.Equal(t, http.StatusOK, response.Code)
 	})
 
+	t.Run("Includes memberships", func(t *testing.T) {
+		memberships := []dto.MembershipResponse{{OrganizationID: entities.NewID(), OrganizationName: "Acme", Role: "owner", Status: "active"}}
+		mockUserService.EXPECT().GetUserByID(gomock.Any(), id).Return(&dto.UserResponse{ID: id, Name: "Test User"}, nil)
+		mockOrganizationService.EXPECT().GetUserMemberships(gomock.Any(), id).Return(memberships, nil)
+		vars := map[string]string{"id": id.String()}
+		response := executeWithRequest(http.MethodGet, id.String()+"?include=memberships", vars)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), `"organization_name":"Acme"`)
+	})
+
 	t.Run("Invalid User ID", func(t *testing.T) {
 		vars := map[string]string{"ids": "invalid-id"}
 		response := executeWithRequest(http.MethodGet, "invalid-id", vars)
@@ -134,10 +146,17 @@ func TestHandler_User_GetUser(t *testing.T) {
 
 	t.Run("User Not Found", func(t *testing.T) {
 		mockUserService.EXPECT().GetUserByID(gomock.Any(), id).Return(nil, errors.New("user not found"))
-		vars := map[string]string{"id": id.Hex()}
-		response := executeWithRequest(http.MethodGet, id.Hex(), vars)
+		vars := map[string]string{"id": id.String()}
+		response := executeWithRequest(http.MethodGet, id.String(), vars)
 		assert.Equal(t, http.StatusNotFound, response.Code)
 	})
+
+	t.Run("Storage Unavailable", func(t *testing.T) {
+		mockUserService.EXPECT().GetUserByID(gomock.Any(), id).Return(nil, domainErrors.ErrUnavailable)
+		vars := map[string]string{"id": id.String()}
+		response := executeWithRequest(http.MethodGet, id.String(), vars)
+		assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	})
 }
 
 func TestHandler_User_GetAllUsers(t *testing.T) {
@@ -152,12 +171,12 @@ func TestHandler_User_GetAllUsers(t *testing.T) {
 	mockResponse := &dto.UsersListResponse{
 		Users: []dto.UserResponse{
 			{
-				ID:    primitive.NewObjectID(),
+				ID:    entities.NewUserID(),
 				Name:  "Test User 1",
 				Email: "test1@example.com",
 			},
 			{
-				ID:    primitive.NewObjectID(),
+				ID:    entities.NewUserID(),
 				Name:  "Test User 2",
 				Email: "test2@example.com",
 			},
@@ -168,7 +187,7 @@ func TestHandler_User_GetAllUsers(t *testing.T) {
 	executeWithRequest := func(method string) *httptest.ResponseRecorder {
 		response := httptest.NewRecorder()
 		req, _ := http.NewRequest(method, fmt.Sprintf("/api/users"), nil)
-		userHandler := NewUserHandler(mockUserService)
+		userHandler := NewUserHandler(mockUserService, nil)
 		s := http.NewServeMux()
 		s.HandleFunc("/api/users", userHandler.GetAllUsers)
 		s.ServeHTTP(response, req)
@@ -195,8 +214,8 @@ func TestHandler_User_Update(t *testing.T) {
 	mockUserService := mock_ports.NewMockUserService(ctrl)
 
 	var (
-		id         = primitive.NewObjectID()
-		idNotFound = primitive.NewObjectID()
+		id         = entities.NewUserID()
+		idNotFound = entities.NewUserID()
 	)
 	mockRequest := &dto.UpdateUserRequest{
 		Name:  "Updated User",
@@ -212,7 +231,7 @@ func TestHandler_User_Update(t *testing.T) {
 	executeWithRequest := func(method string, userID string, vars map[string]string, jsonBody []byte) *httptest.ResponseRecorder {
 		response := httptest.NewRecorder()
 		req, _ := http.NewRequest(method, fmt.Sprintf("/api/users/%s", userID), strings.NewReader(string(jsonBody)))
-		userHandler := NewUserHandler(mockUserService)
+		userHandler := NewUserHandler(mockUserService, nil)
 		s := http.NewServeMux()
 		s.HandleFunc("/api/users/{id}", userHandler.UpdateUser)
 		req = mux.SetURLVars(req, vars)
@@ -226,8 +245,8 @@ func TestHandler_User_Update(t *testing.T) {
 		}`)
 
 		mockUserService.EXPECT().UpdateUser(gomock.Any(), id, mockRequest).Return(mockResponse, nil)
-		vars := map[string]string{"id": id.Hex()}
-		response := executeWithRequest(http.MethodGet, id.Hex(), vars, jsonBody)
+		vars := map[string]string{"id": id.String()}
+		response := executeWithRequest(http.MethodGet, id.String(), vars, jsonBody)
 		assert.Equal(t, http.StatusOK, response.Code)
 	})
 
@@ -246,8 +265,8 @@ func TestHandler_User_Update(t *testing.T) {
 			"name": "Updated User",
 			"email": "Test@update.com",
 		}`)
-		vars := map[string]string{"id": id.Hex()}
-		response := executeWithRequest(http.MethodGet, id.Hex(), vars, jsonBody)
+		vars := map[string]string{"id": id.String()}
+		response := executeWithRequest(http.MethodGet, id.String(), vars, jsonBody)
 		assert.Equal(t, http.StatusBadRequest, response.Code)
 	})
 
@@ -257,8 +276,8 @@ func TestHandler_User_Update(t *testing.T) {
 			"email": "Test@update.com"
 		}`)
 
-		vars := map[string]string{"id": id.Hex()}
-		response := executeWithRequest(http.MethodGet, id.Hex(), vars, jsonBody)
+		vars := map[string]string{"id": id.String()}
+		response := executeWithRequest(http.MethodGet, id.String(), vars, jsonBody)
 		assert.Equal(t, http.StatusBadRequest, response.Code)
 	})
 
@@ -269,8 +288,8 @@ func TestHandler_User_Update(t *testing.T) {
 		}`)
 
 		mockUserService.EXPECT().UpdateUser(gomock.Any(), gomock.Any(), mockRequest).Return(nil, errors.New("user not found"))
-		vars := map[string]string{"id": idNotFound.Hex()}
-		response := executeWithRequest(http.MethodGet, idNotFound.Hex(), vars, jsonBody)
+		vars := map[string]string{"id": idNotFound.String()}
+		response := executeWithRequest(http.MethodGet, idNotFound.String(), vars, jsonBody)
 		assert.Equal(t, http.StatusNotFound, response.Code)
 	})
 }
@@ -282,14 +301,14 @@ func TestHandler_User_DeleteUser(t *testing.T) {
 	mockUserService := mock_ports.NewMockUserService(ctrl)
 
 	var (
-		id         = primitive.NewObjectID()
-		idNotFound = primitive.NewObjectID()
+		id         = entities.NewUserID()
+		idNotFound = entities.NewUserID()
 	)
 
 	executeWithRequest := func(method string, userID string, vars map[string]string) *httptest.ResponseRecorder {
 		response := httptest.NewRecorder()
 		req, _ := http.NewRequest(method, fmt.Sprintf("/api/users/%s", userID), nil)
-		userHandler := NewUserHandler(mockUserService)
+		userHandler := NewUserHandler(mockUserService, nil)
 		s := http.NewServeMux()
 		s.HandleFunc("/api/users/{id}", userHandler.DeleteUser)
 		req = mux.SetURLVars(req, vars)
@@ -298,8 +317,8 @@ func TestHandler_User_DeleteUser(t *testing.T) {
 	}
 	t.Run("Success", func(t *testing.T) {
 		mockUserService.EXPECT().DeleteUser(gomock.Any(), id).Return(nil)
-		vars := map[string]string{"id": id.Hex()}
-		response := executeWithRequest(http.MethodDelete, id.Hex(), vars)
+		vars := map[string]string{"id": id.String()}
+		response := executeWithRequest(http.MethodDelete, id.String(), vars)
 		assert.Equal(t, http.StatusNoContent, response.Code)
 	})
 
@@ -311,8 +330,8 @@ func TestHandler_User_DeleteUser(t *testing.T) {
 
 	t.Run("User Not Found", func(t *testing.T) {
 		mockUserService.EXPECT().DeleteUser(gomock.Any(), idNotFound).Return(errors.New("user not found"))
-		vars := map[string]string{"id": idNotFound.Hex()}
-		response := executeWithRequest(http.MethodDelete, idNotFound.Hex(), vars)
+		vars := map[string]string{"id": idNotFound.String()}
+		response := executeWithRequest(http.MethodDelete, idNotFound.String(), vars)
 		assert.Equal(t, http.StatusNotFound, response.Code)
 	})
 }
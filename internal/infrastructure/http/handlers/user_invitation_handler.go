@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+	"github.com/wonyus/backend-challenge/pkg/validator"
+)
+
+type UserInvitationHandler struct {
+	invitationService ports.UserInvitationService
+	validator         *validator.Validator
+}
+
+func NewUserInvitationHandler(invitationService ports.UserInvitationService) *UserInvitationHandler {
+	return &UserInvitationHandler{
+		invitationService: invitationService,
+		validator:         validator.New(),
+	}
+}
+
+// CreateInvitation mails an invitation on behalf of the caller.
+func (h *UserInvitationHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	inviter, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreateUserInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.invitationService.CreateInvitation(r.Context(), inviter.ID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), userInvitationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *UserInvitationHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	response, err := h.invitationService.ListInvitations(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), userInvitationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *UserInvitationHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	id, err := entities.ParseID(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid invitation ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.invitationService.RevokeInvitation(r.Context(), id); err != nil {
+		http.Error(w, err.Error(), userInvitationErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcceptInvitation creates the invited account with the token from the
+// invitation email.
+func (h *UserInvitationHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req dto.AcceptUserInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.invitationService.AcceptInvitation(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), userInvitationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func userInvitationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domainErrors.ErrUserInvitationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainErrors.ErrUserInvitationExists), errors.Is(err, domainErrors.ErrUserAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, domainErrors.ErrInvalidUserInvitation), errors.Is(err, domainErrors.ErrInvalidScope):
+		return http.StatusBadRequest
+	case errors.Is(err, domainErrors.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,44 +2,215 @@ package middleware
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/wonyus/backend-challenge/internal/application/dto"
 	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
 )
 
+type contextKey string
+
+const principalContextKey contextKey = "principal"
+
+// WithPrincipal returns a copy of ctx carrying the authenticated caller.
+func WithPrincipal(ctx context.Context, principal *dto.Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey, principal)
+}
+
+// PrincipalFromContext returns the caller stored by Authenticate.
+func PrincipalFromContext(ctx context.Context) (*dto.Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey).(*dto.Principal)
+	return principal, ok
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user with no
+// scopes.
+func WithUser(ctx context.Context, user *dto.UserResponse) context.Context {
+	return WithPrincipal(ctx, &dto.Principal{User: user})
+}
+
+// UserFromContext returns the user stored by Authenticate.
+func UserFromContext(ctx context.Context) (*dto.UserResponse, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return principal.User, true
+}
+
 type AuthMiddleware struct {
-	authService ports.AuthService
+	authService          ports.AuthService
+	apiKeyService        ports.APIKeyService
+	serviceTokens        []serviceToken
+	impersonationService ports.ImpersonationService
 }
 
-func NewAuthMiddleware(authService ports.AuthService) *AuthMiddleware {
-	return &AuthMiddleware{
-		authService: authService,
+// serviceToken is a static bearer token for a machine client that is not a
+// user, such as a provisioning system.
+type serviceToken struct {
+	hash      [sha256.Size]byte
+	principal *dto.Principal
+}
+
+type AuthMiddlewareOption func(*AuthMiddleware)
+
+// WithServiceToken accepts token as a bearer token that carries only the given
+// scopes. The caller has no user account; its principal is named after name.
+// An empty token is ignored.
+func WithServiceToken(name, token string, scopes ...string) AuthMiddlewareOption {
+	return func(m *AuthMiddleware) {
+		if token == "" {
+			return
+		}
+		m.serviceTokens = append(m.serviceTokens, serviceToken{
+			hash:      sha256.Sum256([]byte(token)),
+			principal: &dto.Principal{User: &dto.UserResponse{Name: name}, Scopes: scopes},
+		})
+	}
+}
+
+// WithImpersonationAudit records every request made with an impersonation
+// token in the audit trail before handling it. Requests that cannot be
+// recorded are refused.
+func WithImpersonationAudit(impersonationService ports.ImpersonationService) AuthMiddlewareOption {
+	return func(m *AuthMiddleware) {
+		m.impersonationService = impersonationService
+	}
+}
+
+func NewAuthMiddleware(authService ports.AuthService, apiKeyService ports.APIKeyService, opts ...AuthMiddlewareOption) *AuthMiddleware {
+	m := &AuthMiddleware{
+		authService:   authService,
+		apiKeyService: apiKeyService,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
+// Authenticate accepts a JWT ("Authorization: Bearer ...") or an API key
+// ("Authorization: ApiKey ..." or "X-API-Key: ..."). A JWT of another tenant
+// than the one the request names is rejected; when the request names none,
+// the JWT's tenant is used.
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
+		credentialType, credential, err := auth.ParseCredentials(r.Header.Get("Authorization"), r.Header.Get("X-API-Key"))
+		if errors.Is(err, domainErrors.ErrUnauthorized) {
 			http.Error(w, "Authorization header required", http.StatusUnauthorized)
 			return
 		}
-
-		bearerToken := strings.Split(authHeader, " ")
-		if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
+		if err != nil {
 			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
 			return
 		}
 
-		user, err := m.authService.ValidateToken(r.Context(), bearerToken[1])
-		if err != nil {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+		var principal *dto.Principal
+		switch credentialType {
+		case auth.BearerCredential:
+			if principal = m.serviceTokenPrincipal(credential); principal != nil {
+				break
+			}
+			ctx := r.Context()
+			if !TenantExplicit(ctx) {
+				ctx = services.WithTenant(ctx, "")
+			}
+			principal, err = m.authService.ValidateToken(ctx, credential)
+			if errors.Is(err, domainErrors.ErrUnavailable) {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+		case auth.APIKeyCredential:
+			principal, err = m.apiKeyService.ValidateAPIKey(r.Context(), credential)
+			if errors.Is(err, domainErrors.ErrUnavailable) {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Invalid API key", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		ctx := r.Context()
+		if principal.TenantID != "" {
+			ctx = services.WithTenant(ctx, principal.TenantID)
+		}
+
+		if principal.Actor != nil {
+			logActor(ctx, principal.Actor.ID.String())
+			if m.impersonationService != nil {
+				if err := m.impersonationService.RecordRequest(ctx, principal.Actor.ID, principal.User.ID, r.Method, r.URL.Path); err != nil {
+					http.Error(w, "Failed to record audit event", http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+
+		// Add principal to context
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(ctx, principal)))
+	})
+}
+
+// serviceTokenPrincipal returns the principal of a matching service token.
+// Hashes are compared so that every comparison takes the same time.
+func (m *AuthMiddleware) serviceTokenPrincipal(credential string) *dto.Principal {
+	hash := sha256.Sum256([]byte(credential))
+	var principal *dto.Principal
+	for _, token := range m.serviceTokens {
+		if subtle.ConstantTimeCompare(hash[:], token.hash[:]) == 1 {
+			principal = token.principal
+		}
+	}
+	return principal
+}
+
+// RejectImpersonation refuses requests made while impersonating, for routes
+// that manage credentials. It must run after Authenticate.
+func (m *AuthMiddleware) RejectImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal, ok := PrincipalFromContext(r.Context()); ok && principal.Actor != nil {
+			http.Error(w, domainErrors.ErrImpersonating.Error(), http.StatusForbidden)
 			return
 		}
 
-		// Add user to context
-		ctx := context.WithValue(r.Context(), "user", user)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r)
 	})
 }
+
+// RequireScopes rejects requests whose credential lacks any of the scopes.
+// It must run after Authenticate.
+func (m *AuthMiddleware) RequireScopes(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			for _, scope := range scopes {
+				if !entities.HasScope(principal.Scopes, scope) {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, strings.Join(scopes, " ")))
+					http.Error(w, domainErrors.ErrInsufficientScope.Error(), http.StatusForbidden)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
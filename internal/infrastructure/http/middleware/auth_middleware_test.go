@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	mock_ports "github.com/wonyus/backend-challenge/mock/port"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMiddleware_Auth_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_ports.NewMockAuthService(ctrl)
+	mockAPIKeyService := mock_ports.NewMockAPIKeyService(ctrl)
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+	principal := &dto.Principal{User: user, Scopes: []string{entities.ScopeUsersRead}}
+
+	executeWithRequest := func(headers map[string]string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/users", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contextPrincipal, ok := PrincipalFromContext(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, principal, contextPrincipal)
+		})
+		NewAuthMiddleware(mockAuthService, mockAPIKeyService).Authenticate(next).ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Bearer token", func(t *testing.T) {
+		mockAuthService.EXPECT().ValidateToken(gomock.Any(), "jwt").Return(principal, nil)
+		response := executeWithRequest(map[string]string{"Authorization": "Bearer jwt"})
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("ApiKey authorization", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().ValidateAPIKey(gomock.Any(), "bck_key").Return(principal, nil)
+		response := executeWithRequest(map[string]string{"Authorization": "ApiKey bck_key"})
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("X-API-Key header", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().ValidateAPIKey(gomock.Any(), "bck_key").Return(principal, nil)
+		response := executeWithRequest(map[string]string{"X-API-Key": "bck_key"})
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Invalid API key", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().ValidateAPIKey(gomock.Any(), "bck_key").Return(nil, domainErrors.ErrInvalidAPIKey)
+		response := executeWithRequest(map[string]string{"X-API-Key": "bck_key"})
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("Storage unavailable", func(t *testing.T) {
+		mockAuthService.EXPECT().ValidateToken(gomock.Any(), "jwt").Return(nil, domainErrors.ErrUnavailable)
+		response := executeWithRequest(map[string]string{"Authorization": "Bearer jwt"})
+		assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	})
+
+	t.Run("Missing credentials", func(t *testing.T) {
+		response := executeWithRequest(nil)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("Unknown scheme", func(t *testing.T) {
+		response := executeWithRequest(map[string]string{"Authorization": "Basic abc"})
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+}
+
+func TestMiddleware_Auth_Impersonation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_ports.NewMockAuthService(ctrl)
+	mockImpersonationService := mock_ports.NewMockImpersonationService(ctrl)
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+	actor := &dto.UserResponse{ID: entities.NewUserID(), Email: "admin@example.com"}
+	principal := &dto.Principal{User: user, Scopes: entities.DefaultUserScopes, Actor: actor}
+	authMiddleware := NewAuthMiddleware(mockAuthService, nil, WithImpersonationAudit(mockImpersonationService))
+
+	executeWithRequest := func(handler http.Handler) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/api/users/x", nil)
+		req.Header.Set("Authorization", "Bearer jwt")
+		authMiddleware.Authenticate(handler).ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Audits every request", func(t *testing.T) {
+		mockAuthService.EXPECT().ValidateToken(gomock.Any(), "jwt").Return(principal, nil)
+		mockImpersonationService.EXPECT().RecordRequest(gomock.Any(), actor.ID, user.ID, http.MethodPut, "/api/users/x").Return(nil)
+		response := executeWithRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Refuses requests it cannot audit", func(t *testing.T) {
+		mockAuthService.EXPECT().ValidateToken(gomock.Any(), "jwt").Return(principal, nil)
+		mockImpersonationService.EXPECT().RecordRequest(gomock.Any(), actor.ID, user.ID, http.MethodPut, "/api/users/x").Return(assert.AnError)
+		response := executeWithRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("handler must not run")
+		}))
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	})
+
+	t.Run("Rejects credential changes", func(t *testing.T) {
+		mockAuthService.EXPECT().ValidateToken(gomock.Any(), "jwt").Return(principal, nil)
+		mockImpersonationService.EXPECT().RecordRequest(gomock.Any(), actor.ID, user.ID, http.MethodPut, "/api/users/x").Return(nil)
+		response := executeWithRequest(authMiddleware.RejectImpersonation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("handler must not run")
+		})))
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("Lets users change their own credentials", func(t *testing.T) {
+		mockAuthService.EXPECT().ValidateToken(gomock.Any(), "jwt").Return(&dto.Principal{User: user, Scopes: entities.DefaultUserScopes}, nil)
+		response := executeWithRequest(authMiddleware.RejectImpersonation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
+
+func TestMiddleware_Auth_RequireScopes(t *testing.T) {
+	user := &dto.UserResponse{ID: entities.NewUserID(), Email: "test@example.com"}
+
+	executeWithRequest := func(principal *dto.Principal, scopes ...string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/users/x", nil)
+		if principal != nil {
+			req = req.WithContext(WithPrincipal(req.Context(), principal))
+		}
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		NewAuthMiddleware(nil, nil).RequireScopes(scopes...)(next).ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("Granted scope", func(t *testing.T) {
+		response := executeWithRequest(&dto.Principal{User: user, Scopes: entities.DefaultUserScopes}, entities.ScopeUsersDelete)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Admin scope grants everything", func(t *testing.T) {
+		response := executeWithRequest(&dto.Principal{User: user, Scopes: []string{entities.ScopeAdmin}}, entities.ScopeUsersDelete, entities.ScopeUsersWrite)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Missing scope", func(t *testing.T) {
+		response := executeWithRequest(&dto.Principal{User: user, Scopes: []string{entities.ScopeUsersRead}}, entities.ScopeUsersDelete)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		assert.Contains(t, response.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		response := executeWithRequest(nil, entities.ScopeUsersRead)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+// ClientInfo records the address and user agent of the caller on the request
+// context, for sessions to show where a sign in happened. Forwarding headers
+// are ignored since any client can set them.
+func ClientInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		ctx := services.WithClient(r.Context(), services.Client{
+			IPAddress: ip,
+			UserAgent: r.UserAgent(),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
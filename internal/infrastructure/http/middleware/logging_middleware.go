@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -18,6 +19,28 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the writer of the server, so
+// streaming handlers can flush and lift the write deadline.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// requestLog collects details that handlers further down add to the log line
+// of a request.
+type requestLog struct {
+	actorID string
+}
+
+const requestLogContextKey contextKey = "requestLog"
+
+// logActor notes on the log line of the request that an administrator made
+// it while impersonating the user.
+func logActor(ctx context.Context, actorID string) {
+	if log, ok := ctx.Value(requestLogContextKey).(*requestLog); ok {
+		log.actorID = actorID
+	}
+}
+
 type LoggingMiddleware struct {
 	logger *logger.Logger
 }
@@ -37,15 +60,20 @@ func (m *LoggingMiddleware) Middleware(next http.Handler) http.Handler {
 			statusCode:     http.StatusOK,
 		}
 
-		next.ServeHTTP(rw, r)
+		log := &requestLog{}
+		next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), requestLogContextKey, log)))
 
 		duration := time.Since(start)
-		m.logger.Println(fmt.Sprintf(
+		line := fmt.Sprintf(
 			"[%s] %s | %d | %v",
 			r.Method,
 			r.URL.Path,
 			rw.statusCode,
 			duration,
-		))
+		)
+		if log.actorID != "" {
+			line += " | impersonated by " + log.actorID
+		}
+		m.logger.Println(line)
 	})
 }
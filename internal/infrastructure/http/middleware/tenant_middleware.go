@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+)
+
+const tenantExplicitContextKey contextKey = "tenantExplicit"
+
+// TenantExplicit reports whether the request named its tenant, rather than
+// falling back to the default one.
+func TenantExplicit(ctx context.Context) bool {
+	explicit, _ := ctx.Value(tenantExplicitContextKey).(bool)
+	return explicit
+}
+
+type TenantMiddleware struct {
+	tenants    *auth.Tenants
+	baseDomain string
+}
+
+// NewTenantMiddleware resolves tenants from the X-Tenant-ID header or, when
+// baseDomain is set, from the subdomain of the host, as in
+// acme.<baseDomain>.
+func NewTenantMiddleware(tenants *auth.Tenants, baseDomain string) *TenantMiddleware {
+	return &TenantMiddleware{
+		tenants:    tenants,
+		baseDomain: strings.ToLower(strings.TrimPrefix(baseDomain, ".")),
+	}
+}
+
+// Middleware scopes the request to its tenant. Requests that name no tenant
+// get the default one, unless a bearer token names it later on.
+func (m *TenantMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID := r.Header.Get(auth.TenantHeader)
+		if tenantID == "" {
+			tenantID = m.subdomain(r.Host)
+		}
+
+		ctx := r.Context()
+		if tenantID == "" {
+			ctx = services.WithTenant(ctx, entities.DefaultTenant)
+		} else {
+			resolved, err := m.tenants.Resolve(tenantID)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			ctx = context.WithValue(services.WithTenant(ctx, resolved), tenantExplicitContextKey, true)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// subdomain returns the label in front of the base domain, if any.
+func (m *TenantMiddleware) subdomain(host string) string {
+	if m.baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+m.baseDomain)
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
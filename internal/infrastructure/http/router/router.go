@@ -1,16 +1,66 @@
 package router
 
 import (
+	"net/http"
+
 	"github.com/gorilla/mux"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/http/handlers"
 	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
 )
 
-func NewRouter(userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware, loggingMiddleware *middleware.LoggingMiddleware) *mux.Router {
+func NewRouter(userHandler *handlers.UserHandler, userEventHandler *handlers.UserEventHandler, authHandler *handlers.AuthHandler, mfaHandler *handlers.MFAHandler, apiKeyHandler *handlers.APIKeyHandler, oidcHandler *handlers.OIDCHandler, federationHandler *handlers.FederationHandler, magicLinkHandler *handlers.MagicLinkHandler, passkeyHandler *handlers.PasskeyHandler, sessionHandler *handlers.SessionHandler, impersonationHandler *handlers.ImpersonationHandler, organizationHandler *handlers.OrganizationHandler, userInvitationHandler *handlers.UserInvitationHandler, scimHandler *handlers.SCIMHandler, tenantMiddleware *middleware.TenantMiddleware, authMiddleware *middleware.AuthMiddleware, loggingMiddleware *middleware.LoggingMiddleware) *mux.Router {
 	r := mux.NewRouter()
 
 	// Apply logging middleware to all routes
 	r.Use(loggingMiddleware.Middleware)
+	r.Use(middleware.ClientInfo)
+	r.Use(tenantMiddleware.Middleware)
+
+	// scoped wraps a protected route with the scopes it requires
+	scoped := func(handler http.HandlerFunc, scopes ...string) http.Handler {
+		return authMiddleware.RequireScopes(scopes...)(handler)
+	}
+
+	// credentials wraps a route that manages credentials, which an
+	// administrator impersonating the user must not touch
+	credentials := func(handler http.HandlerFunc, scopes ...string) http.Handler {
+		return authMiddleware.RejectImpersonation(scoped(handler, scopes...))
+	}
+
+	// OpenID Connect discovery (public)
+	r.HandleFunc("/.well-known/openid-configuration", oidcHandler.Discovery).Methods("GET")
+	r.HandleFunc("/.well-known/jwks.json", oidcHandler.JWKS).Methods("GET")
+
+	// OAuth2 routes (public, the authorization endpoint signs users in itself)
+	oauth := r.PathPrefix("/oauth").Subrouter()
+	oauth.HandleFunc("/authorize", oidcHandler.Authorize).Methods("GET", "POST")
+	oauth.HandleFunc("/token", oidcHandler.Token).Methods("POST")
+
+	// OAuth2 client registration (protected)
+	clients := oauth.PathPrefix("/clients").Subrouter()
+	clients.Use(authMiddleware.Authenticate)
+	clients.Handle("", scoped(oidcHandler.RegisterClient, entities.ScopeAdmin)).Methods("POST")
+
+	// UserInfo (protected)
+	userInfo := r.PathPrefix("/userinfo").Subrouter()
+	userInfo.Use(authMiddleware.Authenticate)
+	userInfo.Handle("", scoped(oidcHandler.UserInfo, entities.ScopeOpenID)).Methods("GET", "POST")
+
+	// SCIM provisioning (protected, dedicated token or scim:provision)
+	scim := r.PathPrefix("/scim/v2").Subrouter()
+	scim.Use(authMiddleware.Authenticate)
+	scim.Handle("/ServiceProviderConfig", scoped(scimHandler.ServiceProviderConfig, entities.ScopeSCIM)).Methods("GET")
+	scim.Handle("/ResourceTypes", scoped(scimHandler.ResourceTypes, entities.ScopeSCIM)).Methods("GET")
+	scim.Handle("/ResourceTypes/{id}", scoped(scimHandler.ResourceType, entities.ScopeSCIM)).Methods("GET")
+	scim.Handle("/Schemas", scoped(scimHandler.Schemas, entities.ScopeSCIM)).Methods("GET")
+	scim.Handle("/Schemas/{id}", scoped(scimHandler.Schema, entities.ScopeSCIM)).Methods("GET")
+	scim.Handle("/Users", scoped(scimHandler.ListUsers, entities.ScopeSCIM)).Methods("GET")
+	scim.Handle("/Users", scoped(scimHandler.CreateUser, entities.ScopeSCIM)).Methods("POST")
+	scim.Handle("/Users/{id}", scoped(scimHandler.GetUser, entities.ScopeSCIM)).Methods("GET")
+	scim.Handle("/Users/{id}", scoped(scimHandler.ReplaceUser, entities.ScopeSCIM)).Methods("PUT")
+	scim.Handle("/Users/{id}", scoped(scimHandler.PatchUser, entities.ScopeSCIM)).Methods("PATCH")
+	scim.Handle("/Users/{id}", scoped(scimHandler.DeleteUser, entities.ScopeSCIM)).Methods("DELETE")
 
 	// API prefix
 	api := r.PathPrefix("/api").Subrouter()
@@ -19,15 +69,78 @@ func NewRouter(userHandler *handlers.UserHandler, authHandler *handlers.AuthHand
 	auth := api.PathPrefix("/auth").Subrouter()
 	auth.HandleFunc("/register", authHandler.Register).Methods("POST")
 	auth.HandleFunc("/login", authHandler.Login).Methods("POST")
+	auth.HandleFunc("/mfa/verify", mfaHandler.Verify).Methods("POST")
+	auth.HandleFunc("/magic-link", magicLinkHandler.Request).Methods("POST")
+	auth.HandleFunc("/magic-link/callback", magicLinkHandler.Callback).Methods("GET")
+	auth.HandleFunc("/passkeys/login/options", passkeyHandler.BeginLogin).Methods("POST")
+	auth.HandleFunc("/passkeys/login", passkeyHandler.Login).Methods("POST")
+	auth.HandleFunc("/invitations/accept", userInvitationHandler.AcceptInvitation).Methods("POST")
+
+	// Federated login routes (public)
+	auth.HandleFunc("/federated", federationHandler.ListProviders).Methods("GET")
+	auth.HandleFunc("/federated/{provider}/login", federationHandler.Login).Methods("GET")
+	auth.HandleFunc("/federated/{provider}/callback", federationHandler.Callback).Methods("GET")
+
+	// MFA management routes (protected)
+	mfa := auth.PathPrefix("/mfa").Subrouter()
+	mfa.Use(authMiddleware.Authenticate)
+	mfa.Handle("/enroll", credentials(mfaHandler.Enroll, entities.ScopeUsersWrite)).Methods("POST")
+	mfa.Handle("/confirm", credentials(mfaHandler.Confirm, entities.ScopeUsersWrite)).Methods("POST")
+	mfa.Handle("/disable", credentials(mfaHandler.Disable, entities.ScopeUsersWrite)).Methods("POST")
 
 	// User routes (protected)
 	users := api.PathPrefix("/users").Subrouter()
 	users.Use(authMiddleware.Authenticate)
-	users.HandleFunc("", userHandler.CreateUser).Methods("POST")
-	users.HandleFunc("", userHandler.GetAllUsers).Methods("GET")
-	users.HandleFunc("/{id}", userHandler.GetUser).Methods("GET")
-	users.HandleFunc("/{id}", userHandler.UpdateUser).Methods("PUT")
-	users.HandleFunc("/{id}", userHandler.DeleteUser).Methods("DELETE")
+	users.Handle("", scoped(userHandler.CreateUser, entities.ScopeUsersWrite)).Methods("POST")
+	users.Handle("", scoped(userHandler.GetAllUsers, entities.ScopeUsersRead)).Methods("GET")
+	users.Handle("/events", scoped(userEventHandler.WatchUsers, entities.ScopeUsersRead)).Methods("GET")
+	users.Handle("/me/sessions", scoped(sessionHandler.ListMySessions, entities.ScopeUsersRead)).Methods("GET")
+	users.Handle("/me/sessions/{sessionId}", credentials(sessionHandler.RevokeMySession, entities.ScopeUsersWrite)).Methods("DELETE")
+	users.Handle("/me/invitations", scoped(organizationHandler.ListMyInvitations, entities.ScopeUsersRead)).Methods("GET")
+	users.Handle("/me/invitations/{orgId}/accept", scoped(organizationHandler.AcceptMyInvitation, entities.ScopeUsersWrite)).Methods("POST")
+	users.Handle("/me/invitations/{orgId}", scoped(organizationHandler.DeclineMyInvitation, entities.ScopeUsersWrite)).Methods("DELETE")
+	users.Handle("/{id}", scoped(userHandler.GetUser, entities.ScopeUsersRead)).Methods("GET")
+	users.Handle("/{id}", credentials(userHandler.UpdateUser, entities.ScopeUsersWrite)).Methods("PUT")
+	users.Handle("/{id}", scoped(userHandler.DeleteUser, entities.ScopeUsersDelete)).Methods("DELETE")
+	users.Handle("/{id}/api-keys", credentials(apiKeyHandler.CreateAPIKey, entities.ScopeUsersWrite)).Methods("POST")
+	users.Handle("/{id}/api-keys", scoped(apiKeyHandler.ListAPIKeys, entities.ScopeUsersRead)).Methods("GET")
+	users.Handle("/{id}/api-keys/{keyId}", credentials(apiKeyHandler.RevokeAPIKey, entities.ScopeUsersWrite)).Methods("DELETE")
+	users.Handle("/{id}/passkeys/registration", credentials(passkeyHandler.BeginRegistration, entities.ScopeUsersWrite)).Methods("POST")
+	users.Handle("/{id}/passkeys", credentials(passkeyHandler.RegisterPasskey, entities.ScopeUsersWrite)).Methods("POST")
+	users.Handle("/{id}/passkeys", scoped(passkeyHandler.ListPasskeys, entities.ScopeUsersRead)).Methods("GET")
+	users.Handle("/{id}/passkeys/{passkeyId}", credentials(passkeyHandler.RenamePasskey, entities.ScopeUsersWrite)).Methods("PATCH")
+	users.Handle("/{id}/passkeys/{passkeyId}", credentials(passkeyHandler.DeletePasskey, entities.ScopeUsersWrite)).Methods("DELETE")
+	users.Handle("/{id}/sessions", scoped(sessionHandler.ListSessions, entities.ScopeAdmin)).Methods("GET")
+	users.Handle("/{id}/sessions/{sessionId}", credentials(sessionHandler.RevokeSession, entities.ScopeAdmin)).Methods("DELETE")
+
+	// Organization routes (protected, members read and owners or admins manage)
+	organizations := api.PathPrefix("/organizations").Subrouter()
+	organizations.Use(authMiddleware.Authenticate)
+	organizations.Handle("", scoped(organizationHandler.CreateOrganization, entities.ScopeUsersWrite)).Methods("POST")
+	organizations.Handle("", scoped(organizationHandler.ListOrganizations, entities.ScopeUsersRead)).Methods("GET")
+	organizations.Handle("/{orgId}", scoped(organizationHandler.GetOrganization, entities.ScopeUsersRead)).Methods("GET")
+	organizations.Handle("/{orgId}", scoped(organizationHandler.UpdateOrganization, entities.ScopeUsersWrite)).Methods("PUT")
+	organizations.Handle("/{orgId}", scoped(organizationHandler.DeleteOrganization, entities.ScopeUsersWrite)).Methods("DELETE")
+	organizations.Handle("/{orgId}/members", scoped(organizationHandler.ListMembers, entities.ScopeUsersRead)).Methods("GET")
+	organizations.Handle("/{orgId}/members", scoped(organizationHandler.InviteMember, entities.ScopeUsersWrite)).Methods("POST")
+	organizations.Handle("/{orgId}/members/{userId}", scoped(organizationHandler.UpdateMember, entities.ScopeUsersWrite)).Methods("PUT")
+	organizations.Handle("/{orgId}/members/{userId}", scoped(organizationHandler.RemoveMember, entities.ScopeUsersWrite)).Methods("DELETE")
+	organizations.Handle("/{orgId}/teams", scoped(organizationHandler.CreateTeam, entities.ScopeUsersWrite)).Methods("POST")
+	organizations.Handle("/{orgId}/teams", scoped(organizationHandler.ListTeams, entities.ScopeUsersRead)).Methods("GET")
+	organizations.Handle("/{orgId}/teams/{teamId}", scoped(organizationHandler.GetTeam, entities.ScopeUsersRead)).Methods("GET")
+	organizations.Handle("/{orgId}/teams/{teamId}", scoped(organizationHandler.UpdateTeam, entities.ScopeUsersWrite)).Methods("PUT")
+	organizations.Handle("/{orgId}/teams/{teamId}", scoped(organizationHandler.DeleteTeam, entities.ScopeUsersWrite)).Methods("DELETE")
+	organizations.Handle("/{orgId}/teams/{teamId}/members", scoped(organizationHandler.ListTeamMembers, entities.ScopeUsersRead)).Methods("GET")
+	organizations.Handle("/{orgId}/teams/{teamId}/members/{userId}", scoped(organizationHandler.SetTeamMember, entities.ScopeUsersWrite)).Methods("PUT")
+	organizations.Handle("/{orgId}/teams/{teamId}/members/{userId}", scoped(organizationHandler.RemoveTeamMember, entities.ScopeUsersWrite)).Methods("DELETE")
+
+	// Admin routes (protected)
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(authMiddleware.Authenticate)
+	admin.Handle("/users/{id}/impersonate", credentials(impersonationHandler.Impersonate, entities.ScopeAdmin)).Methods("POST")
+	admin.Handle("/invitations", scoped(userInvitationHandler.CreateInvitation, entities.ScopeAdmin)).Methods("POST")
+	admin.Handle("/invitations", scoped(userInvitationHandler.ListInvitations, entities.ScopeAdmin)).Methods("GET")
+	admin.Handle("/invitations/{id}", scoped(userInvitationHandler.RevokeInvitation, entities.ScopeAdmin)).Methods("DELETE")
 
 	return r
 }
@@ -0,0 +1,1544 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/application/dto"
+	"github.com/wonyus/backend-challenge/internal/application/services"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	domainServices "github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/auth"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/crypto"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/federation"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/federation/mockidp"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/handlers"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/http/middleware"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/memory"
+	"github.com/wonyus/backend-challenge/pkg/logger"
+	"github.com/wonyus/backend-challenge/pkg/webauthn"
+	"github.com/wonyus/backend-challenge/pkg/webauthn/softauthn"
+)
+
+// defaultTenantCtx scopes direct repository access to the tenant requests
+// fall back to.
+var defaultTenantCtx = domainServices.WithTenant(context.Background(), entities.DefaultTenant)
+
+const (
+	testEncryptionKey = "Q100ExqbW2d+rjyzaB8gBlZhyqExXaZCfkzFiHQD07w="
+	testSCIMToken     = "test-scim-token"
+)
+
+type testServer struct {
+	*httptest.Server
+	userRepo       repositories.UserRepository
+	passwordHasher domainServices.PasswordHasher
+	idp            *mockidp.Server
+	mailer         *testMailer
+	audit          *testAuditLog
+}
+
+// testMailer keeps the last message sent to each recipient.
+type testMailer struct {
+	mutex    sync.Mutex
+	messages map[string]string
+}
+
+func (m *testMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.messages[to] = body
+	return nil
+}
+
+func (m *testMailer) lastMessage(to string) string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.messages[to]
+}
+
+// testAuditLog keeps every audit event in order.
+type testAuditLog struct {
+	mutex  sync.Mutex
+	events []entities.AuditEvent
+}
+
+func (l *testAuditLog) Create(ctx context.Context, event *entities.AuditEvent) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.events = append(l.events, *event)
+	return nil
+}
+
+func (l *testAuditLog) all() []entities.AuditEvent {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return append([]entities.AuditEvent(nil), l.events...)
+}
+
+// newTestServer wires the router with in-memory repositories, the way
+// cmd/http does with MongoDB. The mock identity provider is configured as
+// the "corp" federated provider.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(nil)
+	issuer := "http://" + server.Listener.Addr().String()
+
+	signingKey, err := auth.GenerateSigningKey()
+	assert.NoError(t, err)
+	encrypter, err := crypto.NewAESEncrypter(testEncryptionKey)
+	assert.NoError(t, err)
+
+	idp := mockidp.New("backend", "backend-secret")
+	t.Cleanup(idp.Close)
+	corp := federation.NewOIDCProvider(federation.ProviderConfig{
+		Name:         "corp",
+		Issuer:       idp.Issuer(),
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  issuer + "/api/auth/federated/corp/callback",
+	}, nil)
+
+	userFeed := memory.NewUserChangeFeed(100)
+	userEventService := services.NewUserEventService(userFeed)
+	server.Config.RegisterOnShutdown(userEventService.Close)
+	userRepo := userFeed.UserRepository(memory.NewUserRepository(entities.EmailPolicy{}))
+	passwordHasher := auth.NewPasswordHasher(auth.NewBcryptHasher(4))
+	sessionRepo := memory.NewSessionRepository()
+	jwtService := auth.NewJWTService("test-secret", userRepo, auth.WithIDTokenSigning(issuer, signingKey), auth.WithSessions(sessionRepo))
+
+	userService := services.NewUserService(userRepo, passwordHasher)
+	authService := services.NewAuthService(userRepo, jwtService, passwordHasher)
+	mfaService := services.NewMFAService(userRepo, jwtService, encrypter, "Test")
+	apiKeyService := services.NewAPIKeyService(memory.NewAPIKeyRepository(), userRepo)
+	oidcService := services.NewOIDCService(memory.NewOAuthClientRepository(), memory.NewAuthorizationCodeRepository(), userRepo, jwtService, issuer)
+	federationService := services.NewFederationService(memory.NewFederatedLoginRepository(), userRepo, userService, jwtService, corp)
+	mailer := &testMailer{messages: make(map[string]string)}
+	magicLinkService := services.NewMagicLinkService(memory.NewMagicLinkRepository(), userRepo, jwtService, mailer, issuer+"/api/auth/magic-link/callback")
+	relyingParty := &webauthn.RelyingParty{ID: "127.0.0.1", Name: "Test", Origins: []string{issuer}}
+	passkeyService := services.NewPasskeyService(memory.NewPasskeyRepository(), memory.NewPasskeyCeremonyRepository(), userRepo, jwtService, relyingParty)
+	sessionService := services.NewSessionService(sessionRepo, userRepo)
+	audit := &testAuditLog{}
+	impersonationService := services.NewImpersonationService(userRepo, audit, jwtService)
+	scimService := services.NewSCIMService(userService, userRepo, issuer)
+	organizationService := services.NewOrganizationService(memory.NewOrganizationRepository(), memory.NewTeamRepository(), memory.NewMembershipRepository(), userRepo)
+	userInvitationService := services.NewUserInvitationService(memory.NewUserInvitationRepository(entities.EmailPolicy{}), userRepo, passwordHasher, mailer, issuer+"/invitations/accept")
+	tenants, err := auth.NewTenants("acme", "globex")
+	assert.NoError(t, err)
+
+	server.Config.Handler = NewRouter(
+		handlers.NewUserHandler(userService, organizationService),
+		handlers.NewUserEventHandler(userEventService, time.Minute),
+		handlers.NewAuthHandler(authService),
+		handlers.NewMFAHandler(mfaService),
+		handlers.NewAPIKeyHandler(apiKeyService),
+		handlers.NewOIDCHandler(oidcService, authService, mfaService),
+		handlers.NewFederationHandler(federationService),
+		handlers.NewMagicLinkHandler(magicLinkService),
+		handlers.NewPasskeyHandler(passkeyService),
+		handlers.NewSessionHandler(sessionService),
+		handlers.NewImpersonationHandler(impersonationService),
+		handlers.NewOrganizationHandler(organizationService),
+		handlers.NewUserInvitationHandler(userInvitationService),
+		handlers.NewSCIMHandler(scimService),
+		middleware.NewTenantMiddleware(tenants, "example.test"),
+		middleware.NewAuthMiddleware(authService, apiKeyService, middleware.WithServiceToken("scim", testSCIMToken, entities.ScopeSCIM), middleware.WithImpersonationAudit(impersonationService)),
+		middleware.NewLoggingMiddleware(logger.New()),
+	)
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return &testServer{Server: server, userRepo: userRepo, passwordHasher: passwordHasher, idp: idp, mailer: mailer, audit: audit}
+}
+
+// createUser stores a user directly, so tests can choose its scopes.
+func (s *testServer) createUser(t *testing.T, email, password string, scopes ...string) *entities.User {
+	t.Helper()
+
+	hash, err := s.passwordHasher.Hash(password)
+	assert.NoError(t, err)
+	user, err := entities.NewUser("Test User", email, hash)
+	assert.NoError(t, err)
+	user.Scopes = scopes
+	assert.NoError(t, s.userRepo.Create(defaultTenantCtx, user))
+	return user
+}
+
+func (s *testServer) login(t *testing.T, email, password string) string {
+	t.Helper()
+
+	var response dto.LoginResponse
+	status := s.postJSON(t, "/api/auth/login", "", map[string]string{"email": email, "password": password}, &response)
+	assert.Equal(t, http.StatusOK, status)
+	return response.Token
+}
+
+func (s *testServer) postJSON(t *testing.T, path, token string, body, out interface{}) int {
+	t.Helper()
+	return s.sendJSON(t, http.MethodPost, path, token, body, out)
+}
+
+func (s *testServer) sendJSON(t *testing.T, method, path, token string, body, out interface{}) int {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req, err := http.NewRequest(method, s.URL+path, strings.NewReader(string(data)))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return s.do(t, req, out)
+}
+
+// newBrowser returns a client of its own, so that cookies and redirect
+// policies do not leak between tests.
+func (s *testServer) newBrowser(checkRedirect func(req *http.Request, via []*http.Request) error) *http.Client {
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{Transport: s.Client().Transport, Jar: jar, CheckRedirect: checkRedirect}
+}
+
+func (s *testServer) do(t *testing.T, req *http.Request, out interface{}) int {
+	t.Helper()
+
+	response, err := s.Client().Do(req)
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	if out != nil && response.StatusCode < http.StatusBadRequest {
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(out))
+	}
+	return response.StatusCode
+}
+
+func TestRouter_OIDC_AuthorizationCodeFlow(t *testing.T) {
+	server := newTestServer(t)
+	server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+	user := server.createUser(t, "user@example.com", "user-password")
+
+	// Discovery points at this server
+	var discovery dto.OpenIDConfiguration
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/.well-known/openid-configuration", nil)
+	assert.Equal(t, http.StatusOK, server.do(t, req, &discovery))
+	assert.Equal(t, server.URL, discovery.Issuer)
+	assert.Equal(t, server.URL+"/oauth/token", discovery.TokenEndpoint)
+
+	// Only admins may register clients
+	clientReq := map[string]interface{}{"name": "Internal App", "redirect_uris": []string{"https://app.example.com/callback"}}
+	userToken := server.login(t, "user@example.com", "user-password")
+	assert.Equal(t, http.StatusForbidden, server.postJSON(t, "/oauth/clients", userToken, clientReq, nil))
+
+	var client dto.OAuthClientResponse
+	adminToken := server.login(t, "admin@example.com", "admin-password")
+	assert.Equal(t, http.StatusCreated, server.postJSON(t, "/oauth/clients", adminToken, clientReq, &client))
+	assert.NotEmpty(t, client.ClientSecret)
+
+	verifier := "dBjftJeZ4CVP-mJ92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := sha256.Sum256([]byte(verifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {client.ClientID},
+		"redirect_uri":          {"https://app.example.com/callback"},
+		"scope":                 {"openid profile email users:read"},
+		"state":                 {"af0ifjsldkj"},
+		"nonce":                 {"n-0S6_WzA2Mj"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	noRedirects := server.newBrowser(func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+
+	// The authorization endpoint shows a sign-in page
+	response, err := noRedirects.Get(server.URL + "/oauth/authorize?" + params.Encode())
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Header.Get("Content-Type"), "text/html")
+
+	// Wrong password keeps the user on the page
+	form := url.Values{"email": {"user@example.com"}, "password": {"wrong"}}
+	for key, values := range params {
+		form[key] = values
+	}
+	response, err = noRedirects.PostForm(server.URL+"/oauth/authorize", form)
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// Signing in redirects back with a code and the state
+	form.Set("password", "user-password")
+	response, err = noRedirects.PostForm(server.URL+"/oauth/authorize", form)
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	location, err := url.Parse(response.Header.Get("Location"))
+	assert.NoError(t, err)
+	assert.Equal(t, "app.example.com", location.Host)
+	assert.Equal(t, "af0ifjsldkj", location.Query().Get("state"))
+	code := location.Query().Get("code")
+	assert.NotEmpty(t, code)
+
+	exchange := func(code string) (*http.Response, dto.TokenResponse) {
+		tokenForm := url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"redirect_uri":  {"https://app.example.com/callback"},
+			"code_verifier": {verifier},
+		}
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/oauth/token", strings.NewReader(tokenForm.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(client.ClientID, client.ClientSecret)
+		response, err := server.Client().Do(req)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		var tokens dto.TokenResponse
+		json.NewDecoder(response.Body).Decode(&tokens)
+		return response, tokens
+	}
+
+	response, tokens := exchange(code)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "no-store", response.Header.Get("Cache-Control"))
+	assert.Equal(t, "openid profile email users:read", tokens.Scope)
+
+	// The ID token verifies against the published key set
+	var jwks dto.JSONWebKeySet
+	req, _ = http.NewRequest(http.MethodGet, discovery.JWKSURI, nil)
+	assert.Equal(t, http.StatusOK, server.do(t, req, &jwks))
+	assert.Len(t, jwks.Keys, 1)
+
+	claims := &auth.IDTokenClaims{}
+	_, err = jwt.ParseWithClaims(tokens.IDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, jwks.Keys[0].KeyID, token.Header["kid"])
+		return publicKeyFromJWK(t, jwks.Keys[0]), nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(discovery.Issuer), jwt.WithAudience(client.ClientID))
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+	assert.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+	assert.Equal(t, "user@example.com", claims.Email)
+
+	// The access token works for userinfo and for the granted API scope only
+	var userInfo dto.UserInfoResponse
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	assert.Equal(t, http.StatusOK, server.do(t, req, &userInfo))
+	assert.Equal(t, user.ID.String(), userInfo.Subject)
+	assert.Equal(t, "Test User", userInfo.Name)
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/api/users/"+user.ID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	assert.Equal(t, http.StatusOK, server.do(t, req, nil))
+
+	req, _ = http.NewRequest(http.MethodDelete, server.URL+"/api/users/"+user.ID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	assert.Equal(t, http.StatusForbidden, server.do(t, req, nil))
+
+	// A code can only be redeemed once
+	response, _ = exchange(code)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	// Regular login tokens do not carry openid
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+userToken)
+	assert.Equal(t, http.StatusForbidden, server.do(t, req, nil))
+}
+
+func publicKeyFromJWK(t *testing.T, key dto.JSONWebKey) *rsa.PublicKey {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	assert.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	assert.NoError(t, err)
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+}
+
+func TestRouter_FederatedLogin(t *testing.T) {
+	server := newTestServer(t)
+
+	// signIn runs the whole redirect chain in a fresh browser
+	signIn := func(t *testing.T) (*http.Response, dto.LoginResponse) {
+		t.Helper()
+
+		response, err := server.newBrowser(nil).Get(server.URL + "/api/auth/federated/corp/login")
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		var login dto.LoginResponse
+		if response.StatusCode == http.StatusOK {
+			assert.NoError(t, json.NewDecoder(response.Body).Decode(&login))
+		}
+		return response, login
+	}
+
+	t.Run("Lists providers", func(t *testing.T) {
+		var providers dto.IdentityProvidersResponse
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/auth/federated", nil)
+		assert.Equal(t, http.StatusOK, server.do(t, req, &providers))
+		assert.Equal(t, []string{"corp"}, providers.Providers)
+	})
+
+	t.Run("Provisions a new user on first sign in", func(t *testing.T) {
+		server.idp.SetUser(mockidp.User{Subject: "corp-1", Email: "new@example.com", EmailVerified: true, Name: "New Hire"})
+
+		response, login := signIn(t)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.NotEmpty(t, login.Token)
+		assert.Equal(t, "new@example.com", login.User.Email)
+		assert.Equal(t, "New Hire", login.User.Name)
+
+		user, err := server.userRepo.GetByEmail(defaultTenantCtx, "new@example.com")
+		assert.NoError(t, err)
+		assert.True(t, user.HasIdentity("corp", "corp-1"))
+		assert.False(t, user.HasPassword())
+
+		// There is no password to sign in with
+		status := server.postJSON(t, "/api/auth/login", "", map[string]string{"email": "new@example.com", "password": ""}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+		status = server.postJSON(t, "/api/auth/login", "", map[string]string{"email": "new@example.com", "password": "guess"}, nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("Finds the user by subject when the email changed", func(t *testing.T) {
+		server.idp.SetUser(mockidp.User{Subject: "corp-1", Email: "renamed@example.com", EmailVerified: true})
+
+		response, login := signIn(t)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "new@example.com", login.User.Email)
+	})
+
+	t.Run("Links an existing user by verified email", func(t *testing.T) {
+		existing := server.createUser(t, "local@example.com", "local-password")
+		server.idp.SetUser(mockidp.User{Subject: "corp-2", Email: "local@example.com", EmailVerified: true})
+
+		response, login := signIn(t)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, existing.ID, login.User.ID)
+
+		user, _ := server.userRepo.GetByID(defaultTenantCtx, existing.ID)
+		assert.True(t, user.HasIdentity("corp", "corp-2"))
+		assert.NotEmpty(t, server.login(t, "local@example.com", "local-password"))
+	})
+
+	t.Run("Does not link an unverified email", func(t *testing.T) {
+		server.createUser(t, "victim@example.com", "victim-password")
+		server.idp.SetUser(mockidp.User{Subject: "corp-3", Email: "victim@example.com", EmailVerified: false})
+
+		response, _ := signIn(t)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	})
+
+	t.Run("Rejects a callback from another browser", func(t *testing.T) {
+		server.idp.SetUser(mockidp.User{Subject: "corp-1", Email: "new@example.com", EmailVerified: true})
+
+		// Stop at the provider's redirect back and replay it without the cookie
+		noRedirects := server.newBrowser(func(req *http.Request, via []*http.Request) error {
+			if strings.HasPrefix(req.URL.String(), server.URL+"/") {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		})
+		response, err := noRedirects.Get(server.URL + "/api/auth/federated/corp/login")
+		assert.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusFound, response.StatusCode)
+
+		req, _ := http.NewRequest(http.MethodGet, response.Header.Get("Location"), nil)
+		assert.Equal(t, http.StatusBadRequest, server.do(t, req, nil))
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/auth/federated/other/login", nil)
+		assert.Equal(t, http.StatusNotFound, server.do(t, req, nil))
+	})
+}
+
+func TestRouter_SCIM(t *testing.T) {
+	server := newTestServer(t)
+	admin := server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+	user := server.createUser(t, "user@example.com", "user-password")
+	userToken := server.login(t, "user@example.com", "user-password")
+
+	get := func(path, token string, out interface{}) int {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return server.do(t, req, out)
+	}
+
+	t.Run("Requires the SCIM token or scope", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, get("/scim/v2/Users", "", nil))
+		assert.Equal(t, http.StatusForbidden, get("/scim/v2/Users", userToken, nil))
+
+		// The SCIM token is useless elsewhere
+		assert.Equal(t, http.StatusForbidden, get("/api/users", testSCIMToken, nil))
+
+		// An API key with the scope works too
+		var key dto.CreateAPIKeyResponse
+		status := server.postJSON(t, "/api/users/"+admin.ID.String()+"/api-keys", server.login(t, "admin@example.com", "admin-password"),
+			map[string]interface{}{"name": "hr", "scopes": []string{entities.ScopeSCIM}}, &key)
+		assert.Equal(t, http.StatusCreated, status)
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/scim/v2/ServiceProviderConfig", nil)
+		req.Header.Set("X-API-Key", key.Key)
+		var config dto.SCIMServiceProviderConfig
+		assert.Equal(t, http.StatusOK, server.do(t, req, &config))
+		assert.True(t, config.Patch.Supported)
+		assert.True(t, config.Filter.Supported)
+	})
+
+	var created dto.SCIMUser
+	t.Run("Creates a user", func(t *testing.T) {
+		body := map[string]interface{}{
+			"schemas":    []string{dto.SCIMUserSchema},
+			"userName":   "hr1@example.com",
+			"externalId": "E-1",
+			"name":       map[string]string{"givenName": "Barbara", "familyName": "Jensen"},
+			"password":   "initial-password",
+		}
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/scim/v2/Users", nil)
+		data, _ := json.Marshal(body)
+		req.Body = io.NopCloser(strings.NewReader(string(data)))
+		req.Header.Set("Authorization", "Bearer "+testSCIMToken)
+		req.Header.Set("Content-Type", "application/scim+json")
+		response, err := server.Client().Do(req)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Equal(t, "application/scim+json", response.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(&created))
+		assert.Equal(t, server.URL+"/scim/v2/Users/"+created.ID, response.Header.Get("Location"))
+		assert.Equal(t, "hr1@example.com", created.UserName)
+		assert.Equal(t, "Barbara Jensen", created.DisplayName)
+		assert.Equal(t, "E-1", created.ExternalID)
+		assert.True(t, *created.Active)
+
+		// The initial password signs in
+		assert.NotEmpty(t, server.login(t, "hr1@example.com", "initial-password"))
+
+		// A second create conflicts
+		var scimError dto.SCIMErrorResponse
+		req, _ = http.NewRequest(http.MethodPost, server.URL+"/scim/v2/Users", strings.NewReader(string(data)))
+		req.Header.Set("Authorization", "Bearer "+testSCIMToken)
+		response, err = server.Client().Do(req)
+		assert.NoError(t, err)
+		defer response.Body.Close()
+		assert.Equal(t, http.StatusConflict, response.StatusCode)
+		json.NewDecoder(response.Body).Decode(&scimError)
+		assert.Equal(t, "uniqueness", scimError.SCIMType)
+		assert.Equal(t, "409", scimError.Status)
+	})
+
+	t.Run("Filters and paginates", func(t *testing.T) {
+		var list dto.SCIMListResponse
+		assert.Equal(t, http.StatusOK, get("/scim/v2/Users?filter="+url.QueryEscape(`userName eq "HR1@example.com"`), testSCIMToken, &list))
+		assert.Equal(t, 1, list.TotalResults)
+
+		assert.Equal(t, http.StatusOK, get("/scim/v2/Users?startIndex=2&count=1", testSCIMToken, &list))
+		assert.Equal(t, 3, list.TotalResults)
+		assert.Equal(t, 2, list.StartIndex)
+		assert.Equal(t, 1, list.ItemsPerPage)
+
+		assert.Equal(t, http.StatusBadRequest, get("/scim/v2/Users?filter="+url.QueryEscape(`userName eq`), testSCIMToken, nil))
+	})
+
+	t.Run("Deactivates and reactivates with PATCH", func(t *testing.T) {
+		userToken := server.login(t, "hr1@example.com", "initial-password")
+
+		// Azure AD sends booleans as strings
+		var patched dto.SCIMUser
+		status := server.sendJSON(t, http.MethodPatch, "/scim/v2/Users/"+created.ID, testSCIMToken, map[string]interface{}{
+			"schemas":    []string{dto.SCIMPatchOpSchema},
+			"Operations": []map[string]interface{}{{"op": "Replace", "path": "active", "value": "False"}},
+		}, &patched)
+		assert.Equal(t, http.StatusOK, status)
+		assert.False(t, *patched.Active)
+
+		// Existing tokens and new logins stop working
+		assert.Equal(t, http.StatusUnauthorized, get("/api/users/"+created.ID, userToken, nil))
+		assert.Equal(t, http.StatusUnauthorized, server.postJSON(t, "/api/auth/login", "",
+			map[string]string{"email": "hr1@example.com", "password": "initial-password"}, nil))
+
+		// Okta sends attributes without a path
+		status = server.sendJSON(t, http.MethodPatch, "/scim/v2/Users/"+created.ID, testSCIMToken, map[string]interface{}{
+			"schemas":    []string{dto.SCIMPatchOpSchema},
+			"Operations": []map[string]interface{}{{"op": "replace", "value": map[string]interface{}{"active": true, "displayName": "Babs Jensen"}}},
+		}, &patched)
+		assert.Equal(t, http.StatusOK, status)
+		assert.True(t, *patched.Active)
+		assert.Equal(t, "Babs Jensen", patched.DisplayName)
+		assert.Equal(t, "E-1", patched.ExternalID)
+		assert.NotEmpty(t, server.login(t, "hr1@example.com", "initial-password"))
+
+		status = server.sendJSON(t, http.MethodPatch, "/scim/v2/Users/"+created.ID, testSCIMToken, map[string]interface{}{
+			"Operations": []map[string]interface{}{{"op": "replace", "path": "nickName", "value": "Babs"}},
+		}, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("Replaces a user", func(t *testing.T) {
+		var replaced dto.SCIMUser
+		status := server.sendJSON(t, http.MethodPut, "/scim/v2/Users/"+user.ID.String(), testSCIMToken, map[string]interface{}{
+			"schemas":     []string{dto.SCIMUserSchema},
+			"userName":    "user@example.com",
+			"displayName": "Renamed User",
+		}, &replaced)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "Renamed User", replaced.DisplayName)
+		assert.Empty(t, replaced.ExternalID)
+	})
+
+	t.Run("Deletes a user", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodDelete, server.URL+"/scim/v2/Users/"+created.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+testSCIMToken)
+		assert.Equal(t, http.StatusNoContent, server.do(t, req, nil))
+		assert.Equal(t, http.StatusNotFound, get("/scim/v2/Users/"+created.ID, testSCIMToken, nil))
+	})
+
+	t.Run("Discovery", func(t *testing.T) {
+		var resourceType dto.SCIMResourceType
+		assert.Equal(t, http.StatusOK, get("/scim/v2/ResourceTypes/User", testSCIMToken, &resourceType))
+		assert.Equal(t, dto.SCIMUserSchema, resourceType.Schema)
+
+		var schema dto.SCIMSchema
+		assert.Equal(t, http.StatusOK, get("/scim/v2/Schemas/"+dto.SCIMUserSchema, testSCIMToken, &schema))
+		assert.Equal(t, "userName", schema.Attributes[0].Name)
+
+		assert.Equal(t, http.StatusOK, get("/scim/v2/Schemas", testSCIMToken, nil))
+		assert.Equal(t, http.StatusOK, get("/scim/v2/ResourceTypes", testSCIMToken, nil))
+		assert.Equal(t, http.StatusNotFound, get("/scim/v2/ResourceTypes/Group", testSCIMToken, nil))
+	})
+}
+
+func TestRouter_APIKeyScopes(t *testing.T) {
+	server := newTestServer(t)
+	user := server.createUser(t, "user@example.com", "user-password")
+	keysPath := "/api/users/" + user.ID.String() + "/api-keys"
+
+	// A token down-scoped to reading and writing users
+	var login dto.LoginResponse
+	credentials := map[string]string{"email": user.Email, "password": "user-password", "scope": entities.ScopeUsersRead + " " + entities.ScopeUsersWrite}
+	assert.Equal(t, http.StatusOK, server.postJSON(t, "/api/auth/login", "", credentials, &login))
+
+	t.Run("Cannot create a key with more scopes", func(t *testing.T) {
+		body := map[string]interface{}{"name": "ci", "scopes": []string{entities.ScopeUsersDelete}}
+		assert.Equal(t, http.StatusBadRequest, server.postJSON(t, keysPath, login.Token, body, nil))
+	})
+
+	t.Run("Keys without scopes get the scopes of the token", func(t *testing.T) {
+		var key dto.CreateAPIKeyResponse
+		assert.Equal(t, http.StatusCreated, server.postJSON(t, keysPath, login.Token, map[string]string{"name": "ci"}, &key))
+		assert.ElementsMatch(t, []string{entities.ScopeUsersRead, entities.ScopeUsersWrite}, key.Scopes)
+
+		req, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/users/"+user.ID.String(), nil)
+		req.Header.Set("X-API-Key", key.Key)
+		assert.Equal(t, http.StatusForbidden, server.do(t, req, nil))
+	})
+
+	t.Run("Keys without scopes of an unrestricted token get them all", func(t *testing.T) {
+		var key dto.CreateAPIKeyResponse
+		token := server.login(t, user.Email, "user-password")
+		assert.Equal(t, http.StatusCreated, server.postJSON(t, keysPath, token, map[string]string{"name": "admin"}, &key))
+		assert.Empty(t, key.Scopes)
+	})
+}
+
+func TestRouter_MagicLink(t *testing.T) {
+	server := newTestServer(t)
+	user, err := entities.NewUser("Passwordless User", "nopassword@example.com", "")
+	assert.NoError(t, err)
+	assert.NoError(t, server.userRepo.Create(defaultTenantCtx, user))
+
+	requestLink := func(email string) int {
+		var response dto.MagicLinkResponse
+		status := server.postJSON(t, "/api/auth/magic-link", "", map[string]string{"email": email}, &response)
+		if status == http.StatusAccepted {
+			assert.NotEmpty(t, response.Message)
+		}
+		return status
+	}
+	mailedLink := func(email string) string {
+		message := server.mailer.lastMessage(email)
+		start := strings.Index(message, server.URL)
+		assert.NotEqual(t, -1, start)
+		return strings.Fields(message[start:])[0]
+	}
+	callback := func(link string, out interface{}) int {
+		req, err := http.NewRequest(http.MethodGet, link, nil)
+		assert.NoError(t, err)
+		return server.do(t, req, out)
+	}
+
+	t.Run("Signs in with the mailed link once", func(t *testing.T) {
+		assert.Equal(t, http.StatusAccepted, requestLink(user.Email))
+		link := mailedLink(user.Email)
+
+		var response dto.LoginResponse
+		assert.Equal(t, http.StatusOK, callback(link, &response))
+		assert.Equal(t, user.ID, response.User.ID)
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/users/"+user.ID.String(), nil)
+		req.Header.Set("Authorization", "Bearer "+response.Token)
+		assert.Equal(t, http.StatusOK, server.do(t, req, nil))
+
+		assert.Equal(t, http.StatusUnauthorized, callback(link, nil))
+	})
+
+	t.Run("Link is bound to the email", func(t *testing.T) {
+		assert.Equal(t, http.StatusAccepted, requestLink(user.Email))
+		link, err := url.Parse(mailedLink(user.Email))
+		assert.NoError(t, err)
+
+		query := link.Query()
+		query.Set("email", "attacker@example.com")
+		link.RawQuery = query.Encode()
+		assert.Equal(t, http.StatusUnauthorized, callback(link.String(), nil))
+	})
+
+	t.Run("Signs in with any case of the email", func(t *testing.T) {
+		assert.Equal(t, http.StatusAccepted, requestLink("NoPassword@Example.COM"))
+		link := mailedLink("NoPassword@Example.COM")
+
+		var response dto.LoginResponse
+		assert.Equal(t, http.StatusOK, callback(link, &response))
+		assert.Equal(t, user.ID, response.User.ID)
+	})
+
+	t.Run("Unknown emails get the same answer", func(t *testing.T) {
+		assert.Equal(t, http.StatusAccepted, requestLink("unknown@example.com"))
+		assert.Empty(t, server.mailer.lastMessage("unknown@example.com"))
+	})
+
+	t.Run("Is rate limited per email", func(t *testing.T) {
+		email := "limited@example.com"
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusAccepted, requestLink(email))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, requestLink(email))
+		assert.Equal(t, http.StatusTooManyRequests, requestLink("Limited@Example.com"))
+	})
+
+	t.Run("Rejects an invalid email", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, requestLink("not-an-email"))
+	})
+}
+
+func TestRouter_EmailIdentity(t *testing.T) {
+	server := newTestServer(t)
+
+	body := map[string]string{"name": "Carol", "email": " Carol@Example.COM ", "password": "carol-password"}
+	assert.Equal(t, http.StatusCreated, server.postJSON(t, "/api/auth/register", "", body, nil))
+	carol, err := server.userRepo.GetByEmail(defaultTenantCtx, "carol@example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Carol@example.com", carol.Email)
+	}
+
+	t.Run("Signs in with any case of the email", func(t *testing.T) {
+		for _, email := range []string{"carol@example.com", "CAROL@EXAMPLE.COM"} {
+			assert.NotEmpty(t, server.login(t, email, "carol-password"), email)
+		}
+	})
+
+	t.Run("Rejects the email in another case", func(t *testing.T) {
+		body := map[string]string{"name": "Carol Twin", "email": "carol@example.com", "password": "twin-password"}
+		assert.Equal(t, http.StatusBadRequest, server.postJSON(t, "/api/auth/register", "", body, nil))
+	})
+}
+
+func TestRouter_UserIDFormats(t *testing.T) {
+	server := newTestServer(t)
+	admin := server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+	token := server.login(t, admin.Email, "admin-password")
+	t.Cleanup(func() { entities.SetUserIDGenerator(entities.NewObjectIDUserID) })
+
+	for _, format := range []string{entities.UserIDObjectID, entities.UserIDUUIDv7, entities.UserIDULID} {
+		t.Run(format, func(t *testing.T) {
+			generate, err := entities.NewUserIDGenerator(format)
+			assert.NoError(t, err)
+			entities.SetUserIDGenerator(generate)
+
+			var registered dto.RegisterResponse
+			body := map[string]string{"name": "Erin", "email": format + "@example.com", "password": "erin-password"}
+			assert.Equal(t, http.StatusCreated, server.postJSON(t, "/api/auth/register", "", body, &registered))
+			if !assert.NotNil(t, registered.ID) {
+				return
+			}
+			id, err := entities.ParseUserID(registered.ID.String())
+			assert.NoError(t, err)
+			assert.Equal(t, *registered.ID, id)
+
+			// Users keep their IDs whatever generates new ones
+			entities.SetUserIDGenerator(entities.NewObjectIDUserID)
+			var user dto.UserResponse
+			assert.Equal(t, http.StatusOK, server.sendJSON(t, http.MethodGet, "/api/users/"+id.String(), token, nil, &user))
+			assert.Equal(t, id, user.ID)
+		})
+	}
+
+	t.Run("Accepts any case of an ID", func(t *testing.T) {
+		var user dto.UserResponse
+		path := "/api/users/" + strings.ToUpper(admin.ID.String())
+		assert.Equal(t, http.StatusOK, server.sendJSON(t, http.MethodGet, path, token, nil, &user))
+		assert.Equal(t, admin.ID, user.ID)
+	})
+
+	t.Run("Rejects a malformed ID", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, server.sendJSON(t, http.MethodGet, "/api/users/not-an-id", token, nil, nil))
+	})
+}
+
+func TestRouter_Passkeys(t *testing.T) {
+	server := newTestServer(t)
+	user := server.createUser(t, "staff@example.com", "password123")
+	token := server.login(t, user.Email, "password123")
+	authenticator := softauthn.New(server.URL)
+	passkeysPath := "/api/users/" + user.ID.String() + "/passkeys"
+
+	register := func(t *testing.T, name string) (dto.PasskeyResponse, int) {
+		var options dto.PasskeyRegistrationOptions
+		assert.Equal(t, http.StatusOK, server.postJSON(t, passkeysPath+"/registration", token, nil, &options))
+		credential, err := authenticator.Create(options.PublicKey)
+		assert.NoError(t, err)
+
+		var passkey dto.PasskeyResponse
+		status := server.postJSON(t, passkeysPath, token, dto.RegisterPasskeyRequest{Name: name, Credential: *credential}, &passkey)
+		return passkey, status
+	}
+	login := func(t *testing.T, authenticator *softauthn.Authenticator) (dto.LoginResponse, int) {
+		var options dto.PasskeyLoginOptions
+		assert.Equal(t, http.StatusOK, server.postJSON(t, "/api/auth/passkeys/login/options", "", nil, &options))
+		assertion, err := authenticator.Get(options.PublicKey)
+		assert.NoError(t, err)
+
+		var response dto.LoginResponse
+		status := server.postJSON(t, "/api/auth/passkeys/login", "", dto.PasskeyLoginRequest{Credential: *assertion}, &response)
+		return response, status
+	}
+
+	passkey, status := register(t, "Laptop")
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "Laptop", passkey.Name)
+
+	t.Run("Signs in with the passkey", func(t *testing.T) {
+		response, status := login(t, authenticator)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, user.ID, response.User.ID)
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/users/"+user.ID.String(), nil)
+		req.Header.Set("Authorization", "Bearer "+response.Token)
+		assert.Equal(t, http.StatusOK, server.do(t, req, nil))
+	})
+
+	t.Run("Rejects another origin", func(t *testing.T) {
+		phished := authenticator.Clone()
+		phished.Origin = "https://phishing.example"
+		_, status := login(t, phished)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("Manages passkeys", func(t *testing.T) {
+		var list dto.PasskeysListResponse
+		req, _ := http.NewRequest(http.MethodGet, server.URL+passkeysPath, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		assert.Equal(t, http.StatusOK, server.do(t, req, &list))
+		assert.Equal(t, 1, list.Total)
+		assert.NotNil(t, list.Passkeys[0].LastUsedAt)
+
+		var renamed dto.PasskeyResponse
+		assert.Equal(t, http.StatusOK, server.sendJSON(t, http.MethodPatch, passkeysPath+"/"+passkey.ID, token, map[string]string{"name": "Work laptop"}, &renamed))
+		assert.Equal(t, "Work laptop", renamed.Name)
+
+		other := server.createUser(t, "other@example.com", "password123")
+		otherToken := server.login(t, other.Email, "password123")
+		req, _ = http.NewRequest(http.MethodDelete, server.URL+passkeysPath+"/"+passkey.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+otherToken)
+		assert.Equal(t, http.StatusForbidden, server.do(t, req, nil))
+
+		req, _ = http.NewRequest(http.MethodDelete, server.URL+passkeysPath+"/"+passkey.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		assert.Equal(t, http.StatusNoContent, server.do(t, req, nil))
+
+		_, status := login(t, authenticator)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+}
+
+func TestRouter_Sessions(t *testing.T) {
+	server := newTestServer(t)
+	admin := server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+	user := server.createUser(t, "user@example.com", "user-password")
+	adminToken := server.login(t, admin.Email, "admin-password")
+
+	// loginFrom signs the user in from a device with the given user agent
+	loginFrom := func(t *testing.T, userAgent string) string {
+		body := strings.NewReader(`{"email": "user@example.com", "password": "user-password"}`)
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/auth/login", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		var response dto.LoginResponse
+		assert.Equal(t, http.StatusOK, server.do(t, req, &response))
+		return response.Token
+	}
+	list := func(t *testing.T, path, token string) (dto.SessionsListResponse, int) {
+		var sessions dto.SessionsListResponse
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return sessions, server.do(t, req, &sessions)
+	}
+	revoke := func(t *testing.T, path, token string) int {
+		req, _ := http.NewRequest(http.MethodDelete, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return server.do(t, req, nil)
+	}
+	currentSession := func(t *testing.T, token string) string {
+		sessions, status := list(t, "/api/users/me/sessions", token)
+		assert.Equal(t, http.StatusOK, status)
+		for _, session := range sessions.Sessions {
+			if session.Current {
+				return session.ID
+			}
+		}
+		t.Fatal("no current session")
+		return ""
+	}
+
+	laptopToken := loginFrom(t, "Laptop")
+	phoneToken := loginFrom(t, "Phone")
+
+	t.Run("Lists the devices of the caller", func(t *testing.T) {
+		sessions, status := list(t, "/api/users/me/sessions", laptopToken)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 2, sessions.Total)
+
+		userAgents := make(map[string]dto.SessionResponse)
+		for _, session := range sessions.Sessions {
+			userAgents[session.UserAgent] = session
+			assert.Equal(t, entities.AuthMethodPassword, session.AuthMethod)
+			assert.Equal(t, "127.0.0.1", session.IPAddress)
+		}
+		assert.True(t, userAgents["Laptop"].Current)
+		assert.False(t, userAgents["Phone"].Current)
+	})
+
+	t.Run("Revokes another device", func(t *testing.T) {
+		phoneSession := currentSession(t, phoneToken)
+		assert.Equal(t, http.StatusNoContent, revoke(t, "/api/users/me/sessions/"+phoneSession, laptopToken))
+		assert.Equal(t, http.StatusNotFound, revoke(t, "/api/users/me/sessions/"+phoneSession, laptopToken))
+
+		_, status := list(t, "/api/users/me/sessions", phoneToken)
+		assert.Equal(t, http.StatusUnauthorized, status)
+		sessions, _ := list(t, "/api/users/me/sessions", laptopToken)
+		assert.Equal(t, 1, sessions.Total)
+	})
+
+	t.Run("Hides sessions of other users", func(t *testing.T) {
+		adminSession := currentSession(t, adminToken)
+		assert.Equal(t, http.StatusNotFound, revoke(t, "/api/users/me/sessions/"+adminSession, laptopToken))
+		assert.Equal(t, http.StatusForbidden, revoke(t, "/api/users/"+admin.ID.String()+"/sessions/"+adminSession, laptopToken))
+		_, status := list(t, "/api/users/"+admin.ID.String()+"/sessions", laptopToken)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("Lets admins manage any user's sessions", func(t *testing.T) {
+		sessions, status := list(t, "/api/users/"+user.ID.String()+"/sessions", adminToken)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, 1, sessions.Total)
+		assert.False(t, sessions.Sessions[0].Current)
+
+		assert.Equal(t, http.StatusNoContent, revoke(t, "/api/users/"+user.ID.String()+"/sessions/"+sessions.Sessions[0].ID, adminToken))
+		_, status = list(t, "/api/users/me/sessions", laptopToken)
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		_, status = list(t, "/api/users/"+entities.NewID().String()+"/sessions", adminToken)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("Signs out of the current device", func(t *testing.T) {
+		token := loginFrom(t, "Tablet")
+		assert.Equal(t, http.StatusNoContent, revoke(t, "/api/users/me/sessions/"+currentSession(t, token), token))
+		_, status := list(t, "/api/users/me/sessions", token)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+}
+
+func TestRouter_Impersonation(t *testing.T) {
+	server := newTestServer(t)
+	admin := server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+	user := server.createUser(t, "user@example.com", "user-password")
+	adminToken := server.login(t, admin.Email, "admin-password")
+	userToken := server.login(t, user.Email, "user-password")
+	impersonatePath := "/api/admin/users/" + user.ID.String() + "/impersonate"
+
+	get := func(t *testing.T, path, token string, out interface{}) int {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return server.do(t, req, out)
+	}
+
+	t.Run("Only admins may impersonate", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, server.postJSON(t, "/api/admin/users/"+admin.ID.String()+"/impersonate", userToken, nil, nil))
+		assert.Equal(t, http.StatusForbidden, server.postJSON(t, "/api/admin/users/"+admin.ID.String()+"/impersonate", adminToken, nil, nil))
+		assert.Equal(t, http.StatusNotFound, server.postJSON(t, "/api/admin/users/"+entities.NewID().String()+"/impersonate", adminToken, nil, nil))
+		assert.Empty(t, server.audit.all())
+	})
+
+	var impersonation dto.ImpersonationResponse
+	assert.Equal(t, http.StatusOK, server.postJSON(t, impersonatePath, adminToken, nil, &impersonation))
+	assert.Equal(t, user.ID, impersonation.User.ID)
+	assert.Equal(t, admin.ID, impersonation.Actor.ID)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), impersonation.ExpiresAt, time.Minute)
+	token := impersonation.Token
+
+	t.Run("Acts as the user", func(t *testing.T) {
+		var fetched dto.UserResponse
+		assert.Equal(t, http.StatusOK, get(t, "/api/users/"+user.ID.String(), token, &fetched))
+		assert.Equal(t, user.Email, fetched.Email)
+
+		var sessions dto.SessionsListResponse
+		assert.Equal(t, http.StatusOK, get(t, "/api/users/me/sessions", token, &sessions))
+		assert.Equal(t, 2, sessions.Total)
+		methods := []string{sessions.Sessions[0].AuthMethod, sessions.Sessions[1].AuthMethod}
+		assert.Contains(t, methods, entities.AuthMethodImpersonation)
+	})
+
+	t.Run("Cannot change credentials", func(t *testing.T) {
+		update := map[string]string{"email": "attacker@example.com"}
+		assert.Equal(t, http.StatusForbidden, server.sendJSON(t, http.MethodPut, "/api/users/"+user.ID.String(), token, update, nil))
+		assert.Equal(t, http.StatusForbidden, server.postJSON(t, "/api/auth/mfa/enroll", token, nil, nil))
+		assert.Equal(t, http.StatusForbidden, server.postJSON(t, "/api/users/"+user.ID.String()+"/api-keys", token, map[string]string{"name": "Backdoor"}, nil))
+		assert.Equal(t, http.StatusForbidden, server.postJSON(t, "/api/users/"+user.ID.String()+"/passkeys/registration", token, nil, nil))
+
+		stored, err := server.userRepo.GetByID(defaultTenantCtx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, user.Email, stored.Email)
+	})
+
+	t.Run("Cannot be exchanged for a client token", func(t *testing.T) {
+		var client dto.OAuthClientResponse
+		clientReq := map[string]interface{}{"name": "Internal App", "redirect_uris": []string{"https://app.example.com/callback"}}
+		assert.Equal(t, http.StatusCreated, server.postJSON(t, "/oauth/clients", adminToken, clientReq, &client))
+
+		challenge := sha256.Sum256([]byte("dBjftJeZ4CVP-mJ92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+		params := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {client.ClientID},
+			"redirect_uri":          {"https://app.example.com/callback"},
+			"scope":                 {"openid users:read"},
+			"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+			"code_challenge_method": {"S256"},
+		}
+		noRedirects := server.newBrowser(func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		})
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/oauth/authorize?"+params.Encode(), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		response, err := noRedirects.Do(req)
+		assert.NoError(t, err)
+		response.Body.Close()
+
+		location, err := url.Parse(response.Header.Get("Location"))
+		assert.NoError(t, err)
+		assert.Empty(t, location.Query().Get("code"))
+		assert.NotEmpty(t, location.Query().Get("error"))
+	})
+
+	t.Run("Audits every request", func(t *testing.T) {
+		events := server.audit.all()
+		assert.Equal(t, entities.AuditImpersonationStarted, events[0].Action)
+		assert.Equal(t, admin.ID, events[0].ActorID)
+		assert.Equal(t, user.ID, events[0].SubjectID)
+
+		paths := make([]string, 0, len(events))
+		for _, event := range events[1:] {
+			assert.Equal(t, entities.AuditImpersonatedRequest, event.Action)
+			assert.Equal(t, admin.ID, event.ActorID)
+			assert.Equal(t, user.ID, event.SubjectID)
+			paths = append(paths, event.Method+" "+event.Path)
+		}
+		assert.Contains(t, paths, "GET /api/users/"+user.ID.String())
+		assert.Contains(t, paths, "PUT /api/users/"+user.ID.String())
+		assert.Contains(t, paths, "POST /api/auth/mfa/enroll")
+	})
+
+	t.Run("Ends when the admin loses admin rights", func(t *testing.T) {
+		stored, err := server.userRepo.GetByID(defaultTenantCtx, admin.ID)
+		assert.NoError(t, err)
+		stored.Scopes = nil
+		assert.NoError(t, server.userRepo.Update(defaultTenantCtx, stored))
+
+		assert.Equal(t, http.StatusUnauthorized, get(t, "/api/users/"+user.ID.String(), token, nil))
+	})
+}
+
+func TestRouter_Tenants(t *testing.T) {
+	server := newTestServer(t)
+	acmeCtx := domainServices.WithTenant(context.Background(), "acme")
+
+	// The same email signs up in two tenants, with different passwords
+	defaultUser := server.createUser(t, "same@example.com", "default-password")
+	hash, err := server.passwordHasher.Hash("acme-password")
+	assert.NoError(t, err)
+	acmeUser, err := entities.NewUser("Acme User", "same@example.com", hash)
+	assert.NoError(t, err)
+	assert.NoError(t, server.userRepo.Create(acmeCtx, acmeUser))
+
+	send := func(t *testing.T, method, path, tenant, token string, body, out interface{}) int {
+		t.Helper()
+
+		data, err := json.Marshal(body)
+		assert.NoError(t, err)
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(string(data)))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			req.Header.Set(auth.TenantHeader, tenant)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return server.do(t, req, out)
+	}
+	login := func(t *testing.T, tenant, password string) (int, string) {
+		t.Helper()
+
+		var response dto.LoginResponse
+		status := send(t, http.MethodPost, "/api/auth/login", tenant, "", map[string]string{"email": "same@example.com", "password": password}, &response)
+		return status, response.Token
+	}
+
+	t.Run("Logs in within the tenant of the header", func(t *testing.T) {
+		status, _ := login(t, "acme", "acme-password")
+		assert.Equal(t, http.StatusOK, status)
+		status, _ = login(t, "acme", "default-password")
+		assert.Equal(t, http.StatusUnauthorized, status)
+		status, _ = login(t, "", "acme-password")
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("Logs in within the tenant of the subdomain", func(t *testing.T) {
+		data, err := json.Marshal(map[string]string{"email": "same@example.com", "password": "acme-password"})
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/auth/login", strings.NewReader(string(data)))
+		assert.NoError(t, err)
+		req.Host = "acme.example.test"
+
+		var response dto.LoginResponse
+		assert.Equal(t, http.StatusOK, server.do(t, req, &response))
+		assert.Equal(t, acmeUser.ID, response.User.ID)
+	})
+
+	t.Run("Registers the same email in another tenant", func(t *testing.T) {
+		body := map[string]string{"name": "Globex User", "email": "same@example.com", "password": "globex-password"}
+		assert.Equal(t, http.StatusCreated, send(t, http.MethodPost, "/api/auth/register", "globex", "", body, nil))
+		assert.Equal(t, http.StatusBadRequest, send(t, http.MethodPost, "/api/auth/register", "globex", "", body, nil))
+	})
+
+	t.Run("Scopes a token to its tenant", func(t *testing.T) {
+		_, token := login(t, "acme", "acme-password")
+
+		// Without a header the token's tenant applies
+		var list dto.UsersListResponse
+		assert.Equal(t, http.StatusOK, send(t, http.MethodGet, "/api/users", "", token, nil, &list))
+		assert.Equal(t, 1, list.Total)
+		if assert.Len(t, list.Users, 1) {
+			assert.Equal(t, acmeUser.ID, list.Users[0].ID)
+		}
+		assert.Equal(t, http.StatusOK, send(t, http.MethodGet, "/api/users", "acme", token, nil, nil))
+
+		// Users of other tenants do not exist for it
+		assert.Equal(t, http.StatusNotFound, send(t, http.MethodGet, "/api/users/"+defaultUser.ID.String(), "", token, nil, nil))
+
+		// And it is no credential in another tenant
+		assert.Equal(t, http.StatusUnauthorized, send(t, http.MethodGet, "/api/users", "globex", token, nil, nil))
+		assert.Equal(t, http.StatusUnauthorized, send(t, http.MethodGet, "/api/users", entities.DefaultTenant, token, nil, nil))
+	})
+
+	t.Run("Signs in with a magic link to the tenant it was requested in", func(t *testing.T) {
+		assert.Equal(t, http.StatusAccepted, send(t, http.MethodPost, "/api/auth/magic-link", "acme", "", map[string]string{"email": "same@example.com"}, nil))
+		message := server.mailer.lastMessage("same@example.com")
+		start := strings.Index(message, server.URL)
+		assert.NotEqual(t, -1, start)
+
+		// The callback names no tenant
+		req, err := http.NewRequest(http.MethodGet, strings.Fields(message[start:])[0], nil)
+		assert.NoError(t, err)
+		var response dto.LoginResponse
+		assert.Equal(t, http.StatusOK, server.do(t, req, &response))
+		assert.Equal(t, acmeUser.ID, response.User.ID)
+		assert.Equal(t, http.StatusOK, send(t, http.MethodGet, "/api/users/"+acmeUser.ID.String(), "", response.Token, nil, nil))
+	})
+
+	t.Run("Authorizes OAuth clients with a token of its tenant", func(t *testing.T) {
+		server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+		var client dto.OAuthClientResponse
+		clientReq := map[string]interface{}{"name": "Internal App", "redirect_uris": []string{"https://app.example.com/callback"}}
+		assert.Equal(t, http.StatusCreated, server.postJSON(t, "/oauth/clients", server.login(t, "admin@example.com", "admin-password"), clientReq, &client))
+
+		verifier := "dBjftJeZ4CVP-mJ92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		challenge := sha256.Sum256([]byte(verifier))
+		params := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {client.ClientID},
+			"redirect_uri":          {"https://app.example.com/callback"},
+			"scope":                 {"openid users:read"},
+			"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+			"code_challenge_method": {"S256"},
+		}
+		noRedirects := server.newBrowser(func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		})
+
+		// Neither request names the tenant
+		_, token := login(t, "acme", "acme-password")
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/oauth/authorize?"+params.Encode(), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		response, err := noRedirects.Do(req)
+		assert.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusFound, response.StatusCode)
+		location, err := url.Parse(response.Header.Get("Location"))
+		assert.NoError(t, err)
+		code := location.Query().Get("code")
+		assert.NotEmpty(t, code)
+
+		tokenForm := url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"redirect_uri":  {"https://app.example.com/callback"},
+			"code_verifier": {verifier},
+		}
+		req, _ = http.NewRequest(http.MethodPost, server.URL+"/oauth/token", strings.NewReader(tokenForm.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(client.ClientID, client.ClientSecret)
+		var tokens dto.TokenResponse
+		assert.Equal(t, http.StatusOK, server.do(t, req, &tokens))
+
+		var user dto.UserResponse
+		assert.Equal(t, http.StatusOK, send(t, http.MethodGet, "/api/users/"+acmeUser.ID.String(), "", tokens.AccessToken, nil, &user))
+		assert.Equal(t, acmeUser.ID, user.ID)
+	})
+
+	t.Run("Rejects unknown tenants", func(t *testing.T) {
+		status, _ := login(t, "initech", "acme-password")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestRouter_Organizations(t *testing.T) {
+	server := newTestServer(t)
+	owner := server.createUser(t, "owner@example.com", "owner-password", entities.DefaultUserScopes...)
+	member := server.createUser(t, "member@example.com", "member-password", entities.DefaultUserScopes...)
+	outsider := server.createUser(t, "outsider@example.com", "outsider-password", entities.DefaultUserScopes...)
+	ownerToken := server.login(t, owner.Email, "owner-password")
+	memberToken := server.login(t, member.Email, "member-password")
+	outsiderToken := server.login(t, outsider.Email, "outsider-password")
+
+	get := func(t *testing.T, path, token string, out interface{}) int {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return server.do(t, req, out)
+	}
+
+	var organization dto.OrganizationResponse
+	assert.Equal(t, http.StatusCreated, server.postJSON(t, "/api/organizations", ownerToken, dto.CreateOrganizationRequest{Name: "Acme"}, &organization))
+	assert.Equal(t, entities.OrganizationRoleOwner, organization.Role)
+	organizationPath := "/api/organizations/" + organization.ID.String()
+
+	t.Run("Invites members who accept or decline", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, server.postJSON(t, organizationPath+"/members", ownerToken, dto.InviteMemberRequest{Email: member.Email, Role: entities.OrganizationRoleMember}, nil))
+		assert.Equal(t, http.StatusCreated, server.postJSON(t, organizationPath+"/members", ownerToken, dto.InviteMemberRequest{Email: outsider.Email, Role: entities.OrganizationRoleMember}, nil))
+		assert.Equal(t, http.StatusConflict, server.postJSON(t, organizationPath+"/members", ownerToken, dto.InviteMemberRequest{Email: member.Email, Role: entities.OrganizationRoleMember}, nil))
+		assert.Equal(t, http.StatusNotFound, server.postJSON(t, organizationPath+"/members", ownerToken, dto.InviteMemberRequest{Email: "nobody@example.com", Role: entities.OrganizationRoleMember}, nil))
+
+		var invitations dto.MembershipsListResponse
+		assert.Equal(t, http.StatusOK, get(t, "/api/users/me/invitations", memberToken, &invitations))
+		assert.Equal(t, 1, invitations.Total)
+		assert.Equal(t, "Acme", invitations.Memberships[0].OrganizationName)
+		assert.Equal(t, owner.ID, *invitations.Memberships[0].InvitedBy)
+
+		// Invited users are not members yet
+		assert.Equal(t, http.StatusNotFound, get(t, organizationPath, memberToken, nil))
+
+		var accepted dto.MembershipResponse
+		assert.Equal(t, http.StatusOK, server.postJSON(t, "/api/users/me/invitations/"+organization.ID.String()+"/accept", memberToken, nil, &accepted))
+		assert.Equal(t, entities.MembershipActive, accepted.Status)
+		assert.Equal(t, http.StatusNoContent, server.sendJSON(t, http.MethodDelete, "/api/users/me/invitations/"+organization.ID.String(), outsiderToken, nil, nil))
+
+		var members dto.MembersListResponse
+		assert.Equal(t, http.StatusOK, get(t, organizationPath+"/members", memberToken, &members))
+		assert.Equal(t, 2, members.Total)
+		assert.Equal(t, http.StatusNotFound, get(t, organizationPath, outsiderToken, nil))
+	})
+
+	t.Run("Lets only owners and admins manage", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, server.sendJSON(t, http.MethodPut, organizationPath, memberToken, dto.UpdateOrganizationRequest{Name: "Renamed"}, nil))
+		assert.Equal(t, http.StatusForbidden, server.postJSON(t, organizationPath+"/teams", memberToken, dto.CreateTeamRequest{Name: "Platform"}, nil))
+		assert.Equal(t, http.StatusNotFound, server.sendJSON(t, http.MethodPut, organizationPath, outsiderToken, dto.UpdateOrganizationRequest{Name: "Renamed"}, nil))
+		assert.Equal(t, http.StatusConflict, server.sendJSON(t, http.MethodDelete, organizationPath+"/members/"+owner.ID.String(), ownerToken, nil, nil))
+		assert.Equal(t, http.StatusBadRequest, server.sendJSON(t, http.MethodPut, organizationPath+"/members/"+member.ID.String(), ownerToken, dto.MemberRoleRequest{Role: "superuser"}, nil))
+	})
+
+	t.Run("Groups members into teams", func(t *testing.T) {
+		var team dto.TeamResponse
+		assert.Equal(t, http.StatusCreated, server.postJSON(t, organizationPath+"/teams", ownerToken, dto.CreateTeamRequest{Name: "Platform"}, &team))
+		teamPath := organizationPath + "/teams/" + team.ID.String()
+
+		assert.Equal(t, http.StatusOK, server.sendJSON(t, http.MethodPut, teamPath+"/members/"+member.ID.String(), ownerToken, dto.MemberRoleRequest{Role: entities.TeamRoleMaintainer}, nil))
+		assert.Equal(t, http.StatusNotFound, server.sendJSON(t, http.MethodPut, teamPath+"/members/"+outsider.ID.String(), ownerToken, dto.MemberRoleRequest{Role: entities.TeamRoleMember}, nil))
+
+		// Maintainers manage their own team
+		assert.Equal(t, http.StatusOK, server.sendJSON(t, http.MethodPut, teamPath, memberToken, dto.UpdateTeamRequest{Name: "Infrastructure"}, nil))
+
+		var members dto.MembersListResponse
+		assert.Equal(t, http.StatusOK, get(t, teamPath+"/members", ownerToken, &members))
+		assert.Equal(t, 1, members.Total)
+		assert.Equal(t, member.ID, members.Members[0].UserID)
+
+		var user dto.UserResponse
+		assert.Equal(t, http.StatusOK, get(t, "/api/users/"+member.ID.String()+"?include=memberships", memberToken, &user))
+		assert.Len(t, user.Memberships, 2)
+		for _, membership := range user.Memberships {
+			if membership.TeamID != nil {
+				assert.Equal(t, "Infrastructure", membership.TeamName)
+				assert.Equal(t, entities.TeamRoleMaintainer, membership.Role)
+			}
+		}
+
+		user = dto.UserResponse{}
+		assert.Equal(t, http.StatusOK, get(t, "/api/users/"+member.ID.String(), memberToken, &user))
+		assert.Empty(t, user.Memberships)
+	})
+
+	t.Run("Removes members with their team memberships", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, server.sendJSON(t, http.MethodDelete, organizationPath+"/members/"+member.ID.String(), memberToken, nil, nil))
+
+		var user dto.UserResponse
+		assert.Equal(t, http.StatusOK, get(t, "/api/users/"+member.ID.String()+"?include=memberships", memberToken, &user))
+		assert.Empty(t, user.Memberships)
+
+		var organizations dto.OrganizationsListResponse
+		assert.Equal(t, http.StatusOK, get(t, "/api/organizations", memberToken, &organizations))
+		assert.Equal(t, 0, organizations.Total)
+	})
+}
+
+func TestRouter_UserInvitations(t *testing.T) {
+	server := newTestServer(t)
+	admin := server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+	user := server.createUser(t, "user@example.com", "user-password")
+	adminToken := server.login(t, admin.Email, "admin-password")
+	userToken := server.login(t, user.Email, "user-password")
+
+	invite := func(t *testing.T, token string, req dto.CreateUserInvitationRequest, out interface{}) int {
+		return server.postJSON(t, "/api/admin/invitations", token, req, out)
+	}
+	list := func(t *testing.T) dto.UserInvitationsListResponse {
+		var invitations dto.UserInvitationsListResponse
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/admin/invitations", nil)
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		assert.Equal(t, http.StatusOK, server.do(t, req, &invitations))
+		return invitations
+	}
+	mailedToken := func(t *testing.T, email string) string {
+		message := server.mailer.lastMessage(email)
+		start := strings.Index(message, server.URL)
+		assert.NotEqual(t, -1, start)
+		link, err := url.Parse(strings.Fields(message[start:])[0])
+		assert.NoError(t, err)
+		assert.Equal(t, "/invitations/accept", link.Path)
+		return link.Query().Get("token")
+	}
+	accept := func(t *testing.T, req *http.Request, out interface{}) int {
+		req.Header.Set("Content-Type", "application/json")
+		return server.do(t, req, out)
+	}
+	acceptRequest := func(token, name, password string) *http.Request {
+		body, _ := json.Marshal(dto.AcceptUserInvitationRequest{Token: token, Name: name, Password: password})
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/auth/invitations/accept", strings.NewReader(string(body)))
+		return req
+	}
+
+	t.Run("Invitee sets a name and password", func(t *testing.T) {
+		var invitation dto.UserInvitationResponse
+		assert.Equal(t, http.StatusCreated, invite(t, adminToken, dto.CreateUserInvitationRequest{Email: "new@example.com", Scopes: []string{entities.ScopeUsersRead}}, &invitation))
+		assert.Equal(t, entities.UserInvitationPending, invitation.Status)
+		assert.Equal(t, admin.ID, invitation.InvitedBy)
+		assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), invitation.ExpiresAt, time.Minute)
+		token := mailedToken(t, "new@example.com")
+
+		// The token names the tenant, not the request
+		req := acceptRequest(token, "New User", "new-password")
+		req.Header.Set(auth.TenantHeader, "acme")
+		var created dto.UserResponse
+		assert.Equal(t, http.StatusCreated, accept(t, req, &created))
+		assert.Equal(t, "New User", created.Name)
+		assert.Equal(t, "new@example.com", created.Email)
+
+		var login dto.LoginResponse
+		assert.Equal(t, http.StatusOK, server.postJSON(t, "/api/auth/login", "", map[string]string{"email": "new@example.com", "password": "new-password"}, &login))
+		assert.Equal(t, entities.ScopeUsersRead, login.Scope)
+		assert.Equal(t, http.StatusForbidden, server.postJSON(t, "/api/users", login.Token, dto.CreateUserRequest{Name: "Other", Email: "other@example.com", Password: "password"}, nil))
+
+		assert.Equal(t, http.StatusBadRequest, accept(t, acceptRequest(token, "Again", "new-password"), nil))
+		invitations := list(t)
+		assert.Equal(t, 1, invitations.Total)
+		assert.Equal(t, entities.UserInvitationAccepted, invitations.Invitations[0].Status)
+		assert.Equal(t, created.ID, *invitations.Invitations[0].UserID)
+	})
+
+	t.Run("Revoked invitations cannot be accepted", func(t *testing.T) {
+		var invitation dto.UserInvitationResponse
+		assert.Equal(t, http.StatusCreated, invite(t, adminToken, dto.CreateUserInvitationRequest{Email: "revoked@example.com"}, &invitation))
+		token := mailedToken(t, "revoked@example.com")
+
+		path := "/api/admin/invitations/" + invitation.ID.String()
+		assert.Equal(t, http.StatusNoContent, server.sendJSON(t, http.MethodDelete, path, adminToken, nil, nil))
+		assert.Equal(t, http.StatusBadRequest, server.sendJSON(t, http.MethodDelete, path, adminToken, nil, nil))
+		assert.Equal(t, http.StatusNotFound, server.sendJSON(t, http.MethodDelete, "/api/admin/invitations/"+entities.NewID().String(), adminToken, nil, nil))
+		assert.Equal(t, http.StatusBadRequest, accept(t, acceptRequest(token, "Revoked User", "password"), nil))
+
+		// A new invitation can be sent once the old one is revoked
+		assert.Equal(t, http.StatusCreated, invite(t, adminToken, dto.CreateUserInvitationRequest{Email: "revoked@example.com"}, nil))
+	})
+
+	t.Run("Rejects conflicting invitations", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, invite(t, adminToken, dto.CreateUserInvitationRequest{Email: user.Email}, nil))
+		assert.Equal(t, http.StatusConflict, invite(t, adminToken, dto.CreateUserInvitationRequest{Email: "revoked@example.com"}, nil))
+		assert.Equal(t, http.StatusConflict, invite(t, adminToken, dto.CreateUserInvitationRequest{Email: "Revoked@example.com"}, nil))
+		assert.Equal(t, http.StatusBadRequest, invite(t, adminToken, dto.CreateUserInvitationRequest{Email: "scopes@example.com", Scopes: []string{"unknown"}}, nil))
+		assert.Equal(t, http.StatusBadRequest, accept(t, acceptRequest("forged", "Forged User", "password"), nil))
+	})
+
+	t.Run("Only admins invite", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, invite(t, userToken, dto.CreateUserInvitationRequest{Email: "sneaky@example.com"}, nil))
+		assert.Empty(t, server.mailer.lastMessage("sneaky@example.com"))
+	})
+}
+
+func TestRouter_UserEvents(t *testing.T) {
+	server := newTestServer(t)
+	admin := server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+	adminToken := server.login(t, admin.Email, "admin-password")
+
+	watch := func(t *testing.T, query, token string) *http.Response {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		t.Cleanup(cancel)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/users/events"+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		response, err := server.Client().Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		t.Cleanup(func() { response.Body.Close() })
+		return response
+	}
+
+	t.Run("Streams changes as they happen", func(t *testing.T) {
+		// Watching from the start of the feed, the admin created above comes
+		// first
+		response := watch(t, "?after=0&types=created,deleted", adminToken)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+		events := bufio.NewReader(response.Body)
+
+		var user dto.UserResponse
+		assert.Equal(t, http.StatusCreated, server.postJSON(t, "/api/users", adminToken, dto.CreateUserRequest{Name: "New User", Email: "new@example.com", Password: "password123"}, &user))
+		assert.Equal(t, http.StatusNoContent, server.sendJSON(t, http.MethodDelete, "/api/users/"+user.ID.String(), adminToken, nil, nil))
+
+		for _, want := range []struct {
+			event string
+			id    entities.UserID
+		}{{"created", admin.ID}, {"created", user.ID}, {"deleted", user.ID}} {
+			var event dto.UserEvent
+			name := readServerSentEvent(t, events, &event)
+			assert.Equal(t, want.event, name)
+			assert.Equal(t, want.id, event.UserID)
+		}
+	})
+
+	t.Run("Shutting down ends the stream", func(t *testing.T) {
+		server := newTestServer(t)
+		admin := server.createUser(t, "admin@example.com", "admin-password", entities.ScopeAdmin)
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/users/events?after=0", nil)
+		req.Header.Set("Authorization", "Bearer "+server.login(t, admin.Email, "admin-password"))
+		response, err := server.Client().Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		defer response.Body.Close()
+		events := bufio.NewReader(response.Body)
+		var event dto.UserEvent
+		assert.Equal(t, "created", readServerSentEvent(t, events, &event))
+
+		// Shutdown waits for requests to finish, which the stream only does
+		// because shutting down ends it
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if !assert.NoError(t, server.Config.Shutdown(ctx)) {
+			t.FailNow()
+		}
+		_, err = io.ReadAll(events)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Requires authentication", func(t *testing.T) {
+		response := watch(t, "", "")
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("Rejects unknown event types", func(t *testing.T) {
+		response := watch(t, "?types=renamed", adminToken)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+// readServerSentEvent reads the next event from a stream, skipping comments,
+// decodes its data into out and returns its name.
+func readServerSentEvent(t *testing.T, stream *bufio.Reader, out interface{}) string {
+	t.Helper()
+
+	var name, data string
+	for {
+		line, err := stream.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && data != "":
+			assert.NoError(t, json.Unmarshal([]byte(data), out))
+			return name
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
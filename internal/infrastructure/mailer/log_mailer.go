@@ -0,0 +1,25 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/application/ports"
+	"github.com/wonyus/backend-challenge/pkg/logger"
+)
+
+// logMailer writes outgoing emails to the application log. It is used when no
+// SMTP server is configured, e.g. during local development.
+type logMailer struct {
+	logger *logger.Logger
+}
+
+func NewLogMailer(logger *logger.Logger) ports.Mailer {
+	return &logMailer{
+		logger: logger,
+	}
+}
+
+func (m *logMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.logger.Info("Sending email to", to, "|", subject, "|", body)
+	return nil
+}
@@ -0,0 +1,107 @@
+package bolt
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.etcd.io/bbolt"
+)
+
+type apiKeyRepository struct {
+	db *DB
+}
+
+func NewAPIKeyRepository(db *DB) repositories.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		prefixes := tx.Bucket(bucketAPIKeyPrefixes)
+		if prefixes.Get([]byte(key.Prefix)) != nil {
+			return domainErrors.ErrAPIKeyExists
+		}
+		if err := prefixes.Put([]byte(key.Prefix), []byte(key.ID)); err != nil {
+			return err
+		}
+		return put(tx, bucketAPIKeys, []byte(key.ID), key)
+	})
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id entities.ID) (*entities.APIKey, error) {
+	return r.find(func(tx *bbolt.Tx) []byte {
+		return []byte(id)
+	})
+}
+
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	return r.find(func(tx *bbolt.Tx) []byte {
+		return tx.Bucket(bucketAPIKeyPrefixes).Get([]byte(prefix))
+	})
+}
+
+// find returns the key whose ID lookup returns.
+func (r *apiKeyRepository) find(lookup func(tx *bbolt.Tx) []byte) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		id := lookup(tx)
+		if id == nil {
+			return domainErrors.ErrAPIKeyNotFound
+		}
+		found, err := get(tx, bucketAPIKeys, id, &key)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return domainErrors.ErrAPIKeyNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.APIKey, error) {
+	var keys []*entities.APIKey
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		keys, err = list(tx, bucketAPIKeys, nil, func(key *entities.APIKey) bool {
+			return key.UserID == userID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	return replace(r.db, bucketAPIKeys, []byte(key.ID), key, domainErrors.ErrAPIKeyNotFound)
+}
+
+func (r *apiKeyRepository) MarkUsed(ctx context.Context, id entities.ID, t time.Time) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		var key entities.APIKey
+		found, err := get(tx, bucketAPIKeys, []byte(id), &key)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return domainErrors.ErrAPIKeyNotFound
+		}
+		key.MarkUsed(t)
+		return put(tx, bucketAPIKeys, []byte(id), &key)
+	})
+}
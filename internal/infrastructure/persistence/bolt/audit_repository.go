@@ -0,0 +1,23 @@
+package bolt
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.etcd.io/bbolt"
+)
+
+type auditRepository struct {
+	db *DB
+}
+
+func NewAuditRepository(db *DB) repositories.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, event *entities.AuditEvent) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketAuditEvents, []byte(event.ID), event)
+	})
+}
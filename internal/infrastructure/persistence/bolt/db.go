@@ -0,0 +1,245 @@
+// Package bolt keeps everything in a single bbolt database file, for
+// single-node installations without MongoDB. Every write is a transaction
+// synced to disk before it returns, so a crash neither loses a write that
+// succeeded nor leaves one half done.
+package bolt
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Driver is the storage.driver option selecting this adapter.
+const Driver = "bolt"
+
+var (
+	bucketUsers              = []byte("users")
+	bucketUserEmails         = []byte("user_emails")
+	bucketUserIdentities     = []byte("user_identities")
+	bucketAPIKeys            = []byte("api_keys")
+	bucketAPIKeyPrefixes     = []byte("api_key_prefixes")
+	bucketAuditEvents        = []byte("audit_events")
+	bucketFederatedLogins    = []byte("federated_logins")
+	bucketMagicLinks         = []byte("magic_links")
+	bucketOAuthClients       = []byte("oauth_clients")
+	bucketAuthorizationCodes = []byte("authorization_codes")
+	bucketPasskeys           = []byte("passkeys")
+	bucketPasskeyCeremonies  = []byte("passkey_ceremonies")
+	bucketSessions           = []byte("sessions")
+	bucketOrganizations      = []byte("organizations")
+	bucketTeams              = []byte("teams")
+	bucketMemberships        = []byte("memberships")
+	bucketUserInvitations    = []byte("user_invitations")
+)
+
+var buckets = [][]byte{
+	bucketUsers, bucketUserEmails, bucketUserIdentities, bucketAPIKeys, bucketAPIKeyPrefixes,
+	bucketAuditEvents, bucketFederatedLogins, bucketMagicLinks, bucketOAuthClients,
+	bucketAuthorizationCodes, bucketPasskeys, bucketPasskeyCeremonies, bucketSessions,
+	bucketOrganizations, bucketTeams, bucketMemberships, bucketUserInvitations,
+}
+
+// DB is an open database file. Only one process can have it open at a time.
+type DB struct {
+	bolt *bbolt.DB
+}
+
+// Open opens the database file at path, creating it if it does not exist.
+func Open(path string) (*DB, error) {
+	// Without a timeout a second process would wait for the file forever
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{bolt: db}, nil
+}
+
+func (db *DB) Close() error {
+	return db.bolt.Close()
+}
+
+// Backup writes a consistent snapshot of the database to w. Writes go on
+// while it runs.
+func (db *DB) Backup(w io.Writer) error {
+	return db.bolt.View(func(tx *bbolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// Snapshot backs the database up to a new file in dir, named after the
+// time, and returns its path. The file only appears once it is complete, so
+// a crash never leaves a partial snapshot behind. Opening the snapshot
+// instead of the database restores it.
+func (db *DB) Snapshot(dir string) (string, error) {
+	file, err := os.CreateTemp(dir, ".snapshot-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if err := db.Backup(file); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "snapshot-"+time.Now().UTC().Format("20060102T150405.000Z")+".db")
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// tenantKey is the key of a record of the tenant, which keeps the records
+// of each tenant together.
+func tenantKey(tenantID string, parts ...string) []byte {
+	key := []byte(tenantID)
+	for _, part := range parts {
+		key = append(key, 0)
+		key = append(key, part...)
+	}
+	return key
+}
+
+func encode(record interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(record); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// get decodes the record at key into record and reports whether there is
+// one. Records are decoded afresh on every read, so callers never share them.
+func get(tx *bbolt.Tx, bucket, key []byte, record interface{}) (bool, error) {
+	data := tx.Bucket(bucket).Get(key)
+	if data == nil {
+		return false, nil
+	}
+	return true, gob.NewDecoder(bytes.NewReader(data)).Decode(record)
+}
+
+func put(tx *bbolt.Tx, bucket, key []byte, record interface{}) error {
+	data, err := encode(record)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucket).Put(key, data)
+}
+
+// list decodes the records of bucket whose keys start with prefix and
+// returns those match accepts, in the order of their keys. A nil match
+// accepts every record.
+func list[T any](tx *bbolt.Tx, bucket, prefix []byte, match func(*T) bool) ([]*T, error) {
+	var records []*T
+	cursor := tx.Bucket(bucket).Cursor()
+	for key, data := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Next() {
+		record := new(T)
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(record); err != nil {
+			return nil, err
+		}
+		if match == nil || match(record) {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// removeAll deletes the records of bucket match accepts.
+func removeAll[T any](tx *bbolt.Tx, bucket []byte, match func(*T) bool) error {
+	var keys [][]byte
+	cursor := tx.Bucket(bucket).Cursor()
+	for key, data := cursor.First(); key != nil; key, data = cursor.Next() {
+		record := new(T)
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(record); err != nil {
+			return err
+		}
+		if match(record) {
+			// Deleting while iterating would skip records
+			keys = append(keys, append([]byte(nil), key...))
+		}
+	}
+	for _, key := range keys {
+		if err := tx.Bucket(bucket).Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load returns the record at key, or notFound if there is none.
+func load[T any](db *DB, bucket, key []byte, notFound error) (*T, error) {
+	record := new(T)
+	err := db.bolt.View(func(tx *bbolt.Tx) error {
+		found, err := get(tx, bucket, key, record)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return notFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// replace stores record at key, or returns notFound if there is no record
+// to replace.
+func replace(db *DB, bucket, key []byte, record interface{}, notFound error) error {
+	return db.bolt.Update(func(tx *bbolt.Tx) error {
+		if tx.Bucket(bucket).Get(key) == nil {
+			return notFound
+		}
+		return put(tx, bucket, key, record)
+	})
+}
+
+// consume returns the record at key and deletes it in one step.
+func consume[T any](db *DB, bucket, key []byte, notFound error) (*T, error) {
+	record := new(T)
+	err := db.bolt.Update(func(tx *bbolt.Tx) error {
+		found, err := get(tx, bucket, key, record)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return notFound
+		}
+		return tx.Bucket(bucket).Delete(key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,28 @@
+package bolt
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.etcd.io/bbolt"
+)
+
+type federatedLoginRepository struct {
+	db *DB
+}
+
+func NewFederatedLoginRepository(db *DB) repositories.FederatedLoginRepository {
+	return &federatedLoginRepository{db: db}
+}
+
+func (r *federatedLoginRepository) Create(ctx context.Context, login *entities.FederatedLogin) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketFederatedLogins, []byte(login.StateHash), login)
+	})
+}
+
+func (r *federatedLoginRepository) Consume(ctx context.Context, stateHash string) (*entities.FederatedLogin, error) {
+	return consume[entities.FederatedLogin](r.db, bucketFederatedLogins, []byte(stateHash), domainErrors.ErrInvalidFederatedState)
+}
@@ -0,0 +1,41 @@
+package bolt
+
+import (
+	"context"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.etcd.io/bbolt"
+)
+
+type magicLinkRepository struct {
+	db *DB
+}
+
+func NewMagicLinkRepository(db *DB) repositories.MagicLinkRepository {
+	return &magicLinkRepository{db: db}
+}
+
+func (r *magicLinkRepository) Create(ctx context.Context, link *entities.MagicLink) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketMagicLinks, []byte(link.TokenHash), link)
+	})
+}
+
+func (r *magicLinkRepository) CountSince(ctx context.Context, email string, since time.Time) (int64, error) {
+	var links []*entities.MagicLink
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		links, err = list(tx, bucketMagicLinks, nil, func(link *entities.MagicLink) bool {
+			return link.Email == email && !link.CreatedAt.Before(since)
+		})
+		return err
+	})
+	return int64(len(links)), err
+}
+
+func (r *magicLinkRepository) Consume(ctx context.Context, tokenHash string) (*entities.MagicLink, error) {
+	return consume[entities.MagicLink](r.db, bucketMagicLinks, []byte(tokenHash), domainErrors.ErrInvalidMagicLink)
+}
@@ -0,0 +1,49 @@
+package bolt
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.etcd.io/bbolt"
+)
+
+type oauthClientRepository struct {
+	db *DB
+}
+
+func NewOAuthClientRepository(db *DB) repositories.OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+func (r *oauthClientRepository) Create(ctx context.Context, client *entities.OAuthClient) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		if tx.Bucket(bucketOAuthClients).Get([]byte(client.ClientID)) != nil {
+			return domainErrors.ErrOAuthClientExists
+		}
+		return put(tx, bucketOAuthClients, []byte(client.ClientID), client)
+	})
+}
+
+func (r *oauthClientRepository) GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	return load[entities.OAuthClient](r.db, bucketOAuthClients, []byte(clientID), domainErrors.ErrOAuthClientNotFound)
+}
+
+type authorizationCodeRepository struct {
+	db *DB
+}
+
+func NewAuthorizationCodeRepository(db *DB) repositories.AuthorizationCodeRepository {
+	return &authorizationCodeRepository{db: db}
+}
+
+func (r *authorizationCodeRepository) Create(ctx context.Context, code *entities.AuthorizationCode) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketAuthorizationCodes, []byte(code.CodeHash), code)
+	})
+}
+
+func (r *authorizationCodeRepository) Consume(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error) {
+	return consume[entities.AuthorizationCode](r.db, bucketAuthorizationCodes, []byte(codeHash), domainErrors.ErrAuthorizationCodeNotFound)
+}
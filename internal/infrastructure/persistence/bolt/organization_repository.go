@@ -0,0 +1,239 @@
+package bolt
+
+import (
+	"context"
+	"sort"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"go.etcd.io/bbolt"
+)
+
+// Organizations are keyed by tenant, so one of another tenant is never found.
+type organizationRepository struct {
+	db *DB
+}
+
+func NewOrganizationRepository(db *DB) repositories.OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, organization *entities.Organization) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	organization.TenantID = tenantID
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketOrganizations, tenantKey(tenantID, organization.ID.String()), organization)
+	})
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Organization, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	return load[entities.Organization](r.db, bucketOrganizations, tenantKey(tenantID, id.String()), domainErrors.ErrOrganizationNotFound)
+}
+
+func (r *organizationRepository) Update(ctx context.Context, organization *entities.Organization) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	organization.TenantID = tenantID
+	return replace(r.db, bucketOrganizations, tenantKey(tenantID, organization.ID.String()), organization, domainErrors.ErrOrganizationNotFound)
+}
+
+func (r *organizationRepository) Delete(ctx context.Context, id entities.ID) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		organizations := tx.Bucket(bucketOrganizations)
+		key := tenantKey(tenantID, id.String())
+		if organizations.Get(key) == nil {
+			return domainErrors.ErrOrganizationNotFound
+		}
+		return organizations.Delete(key)
+	})
+}
+
+type teamRepository struct {
+	db *DB
+}
+
+func NewTeamRepository(db *DB) repositories.TeamRepository {
+	return &teamRepository{db: db}
+}
+
+func (r *teamRepository) Create(ctx context.Context, team *entities.Team) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketTeams, []byte(team.ID), team)
+	})
+}
+
+func (r *teamRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Team, error) {
+	return load[entities.Team](r.db, bucketTeams, []byte(id), domainErrors.ErrTeamNotFound)
+}
+
+func (r *teamRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Team, error) {
+	var teams []*entities.Team
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		teams, err = list(tx, bucketTeams, nil, func(team *entities.Team) bool {
+			return team.OrganizationID == organizationID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+
+	return teams, nil
+}
+
+func (r *teamRepository) Update(ctx context.Context, team *entities.Team) error {
+	return replace(r.db, bucketTeams, []byte(team.ID), team, domainErrors.ErrTeamNotFound)
+}
+
+func (r *teamRepository) Delete(ctx context.Context, id entities.ID) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		teams := tx.Bucket(bucketTeams)
+		if teams.Get([]byte(id)) == nil {
+			return domainErrors.ErrTeamNotFound
+		}
+		return teams.Delete([]byte(id))
+	})
+}
+
+func (r *teamRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return removeAll(tx, bucketTeams, func(team *entities.Team) bool {
+			return team.OrganizationID == organizationID
+		})
+	})
+}
+
+type membershipRepository struct {
+	db *DB
+}
+
+func NewMembershipRepository(db *DB) repositories.MembershipRepository {
+	return &membershipRepository{db: db}
+}
+
+func (r *membershipRepository) Create(ctx context.Context, membership *entities.Membership) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		// Unique like the (organization_id, team_id, user_id) index in MongoDB
+		existing, err := r.find(tx, membership.OrganizationID, membership.TeamID, membership.UserID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return domainErrors.ErrMembershipExists
+		}
+		return put(tx, bucketMemberships, []byte(membership.ID), membership)
+	})
+}
+
+func (r *membershipRepository) Get(ctx context.Context, organizationID, teamID entities.ID, userID entities.UserID) (*entities.Membership, error) {
+	var membership *entities.Membership
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		membership, err = r.find(tx, organizationID, teamID, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, domainErrors.ErrMembershipNotFound
+	}
+
+	return membership, nil
+}
+
+// find returns the membership, or nil.
+func (r *membershipRepository) find(tx *bbolt.Tx, organizationID, teamID entities.ID, userID entities.UserID) (*entities.Membership, error) {
+	memberships, err := list(tx, bucketMemberships, nil, func(membership *entities.Membership) bool {
+		return membership.OrganizationID == organizationID && membership.TeamID == teamID && membership.UserID == userID
+	})
+	if err != nil || len(memberships) == 0 {
+		return nil, err
+	}
+	return memberships[0], nil
+}
+
+func (r *membershipRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Membership, error) {
+	return r.filter(func(membership *entities.Membership) bool {
+		return membership.OrganizationID == organizationID
+	})
+}
+
+func (r *membershipRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Membership, error) {
+	return r.filter(func(membership *entities.Membership) bool {
+		return membership.UserID == userID
+	})
+}
+
+// filter returns the matching memberships, oldest first.
+func (r *membershipRepository) filter(match func(*entities.Membership) bool) ([]*entities.Membership, error) {
+	var memberships []*entities.Membership
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		memberships, err = list(tx, bucketMemberships, nil, match)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+	})
+
+	return memberships, nil
+}
+
+func (r *membershipRepository) Update(ctx context.Context, membership *entities.Membership) error {
+	return replace(r.db, bucketMemberships, []byte(membership.ID), membership, domainErrors.ErrMembershipNotFound)
+}
+
+func (r *membershipRepository) Delete(ctx context.Context, id entities.ID) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		memberships := tx.Bucket(bucketMemberships)
+		if memberships.Get([]byte(id)) == nil {
+			return domainErrors.ErrMembershipNotFound
+		}
+		return memberships.Delete([]byte(id))
+	})
+}
+
+func (r *membershipRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return removeAll(tx, bucketMemberships, func(membership *entities.Membership) bool {
+			return membership.OrganizationID == organizationID
+		})
+	})
+}
+
+func (r *membershipRepository) DeleteByTeamID(ctx context.Context, teamID entities.ID) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return removeAll(tx, bucketMemberships, func(membership *entities.Membership) bool {
+			return membership.TeamID == teamID
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package bolt
+
+import (
+	"context"
+	"sort"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.etcd.io/bbolt"
+)
+
+type passkeyRepository struct {
+	db *DB
+}
+
+func NewPasskeyRepository(db *DB) repositories.PasskeyRepository {
+	return &passkeyRepository{db: db}
+}
+
+func (r *passkeyRepository) Create(ctx context.Context, passkey *entities.Passkey) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		if tx.Bucket(bucketPasskeys).Get([]byte(passkey.ID)) != nil {
+			return domainErrors.ErrPasskeyExists
+		}
+		return put(tx, bucketPasskeys, []byte(passkey.ID), passkey)
+	})
+}
+
+func (r *passkeyRepository) GetByID(ctx context.Context, id string) (*entities.Passkey, error) {
+	return load[entities.Passkey](r.db, bucketPasskeys, []byte(id), domainErrors.ErrPasskeyNotFound)
+}
+
+func (r *passkeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Passkey, error) {
+	var passkeys []*entities.Passkey
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		passkeys, err = list(tx, bucketPasskeys, nil, func(passkey *entities.Passkey) bool {
+			return passkey.UserID == userID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(passkeys, func(i, j int) bool {
+		return passkeys[i].CreatedAt.Before(passkeys[j].CreatedAt)
+	})
+
+	return passkeys, nil
+}
+
+func (r *passkeyRepository) Update(ctx context.Context, passkey *entities.Passkey) error {
+	return replace(r.db, bucketPasskeys, []byte(passkey.ID), passkey, domainErrors.ErrPasskeyNotFound)
+}
+
+func (r *passkeyRepository) Delete(ctx context.Context, id string) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		passkeys := tx.Bucket(bucketPasskeys)
+		if passkeys.Get([]byte(id)) == nil {
+			return domainErrors.ErrPasskeyNotFound
+		}
+		return passkeys.Delete([]byte(id))
+	})
+}
+
+type passkeyCeremonyRepository struct {
+	db *DB
+}
+
+func NewPasskeyCeremonyRepository(db *DB) repositories.PasskeyCeremonyRepository {
+	return &passkeyCeremonyRepository{db: db}
+}
+
+func (r *passkeyCeremonyRepository) Create(ctx context.Context, ceremony *entities.PasskeyCeremony) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketPasskeyCeremonies, []byte(ceremony.ChallengeHash), ceremony)
+	})
+}
+
+func (r *passkeyCeremonyRepository) Consume(ctx context.Context, challengeHash string) (*entities.PasskeyCeremony, error) {
+	return consume[entities.PasskeyCeremony](r.db, bucketPasskeyCeremonies, []byte(challengeHash), domainErrors.ErrInvalidPasskeyChallenge)
+}
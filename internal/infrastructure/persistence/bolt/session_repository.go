@@ -0,0 +1,53 @@
+package bolt
+
+import (
+	"context"
+	"sort"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.etcd.io/bbolt"
+)
+
+type sessionRepository struct {
+	db *DB
+}
+
+func NewSessionRepository(db *DB) repositories.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketSessions, []byte(session.ID), session)
+	})
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Session, error) {
+	return load[entities.Session](r.db, bucketSessions, []byte(id), domainErrors.ErrSessionNotFound)
+}
+
+func (r *sessionRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Session, error) {
+	var sessions []*entities.Session
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		sessions, err = list(tx, bucketSessions, nil, func(session *entities.Session) bool {
+			return session.UserID == userID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *entities.Session) error {
+	return replace(r.db, bucketSessions, []byte(session.ID), session, domainErrors.ErrSessionNotFound)
+}
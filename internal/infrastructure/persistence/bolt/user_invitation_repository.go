@@ -0,0 +1,110 @@
+package bolt
+
+import (
+	"context"
+	"sort"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"go.etcd.io/bbolt"
+)
+
+// Invitations are keyed by tenant, so one of another tenant is never found.
+type userInvitationRepository struct {
+	db     *DB
+	policy entities.EmailPolicy
+}
+
+// NewUserInvitationRepository finds invitations by the key of their email
+// under the policy, which must be the one of the user repository.
+func NewUserInvitationRepository(db *DB, policy entities.EmailPolicy) repositories.UserInvitationRepository {
+	return &userInvitationRepository{db: db, policy: policy}
+}
+
+func (r *userInvitationRepository) Create(ctx context.Context, invitation *entities.UserInvitation) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	invitation.TenantID = tenantID
+	invitation.NormalizedEmail = r.policy.Key(invitation.Email)
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		return put(tx, bucketUserInvitations, tenantKey(tenantID, invitation.ID.String()), invitation)
+	})
+}
+
+func (r *userInvitationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.UserInvitation, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	return load[entities.UserInvitation](r.db, bucketUserInvitations, tenantKey(tenantID, id.String()), domainErrors.ErrUserInvitationNotFound)
+}
+
+func (r *userInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error) {
+	var invitations []*entities.UserInvitation
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		invitations, err = list(tx, bucketUserInvitations, nil, func(invitation *entities.UserInvitation) bool {
+			return invitation.TokenHash == tokenHash
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(invitations) == 0 {
+		return nil, domainErrors.ErrUserInvitationNotFound
+	}
+
+	return invitations[0], nil
+}
+
+func (r *userInvitationRepository) GetByEmail(ctx context.Context, email string) ([]*entities.UserInvitation, error) {
+	key := r.policy.Key(email)
+	return r.list(ctx, func(invitation *entities.UserInvitation) bool {
+		return invitation.NormalizedEmail == key
+	})
+}
+
+func (r *userInvitationRepository) GetAll(ctx context.Context) ([]*entities.UserInvitation, error) {
+	return r.list(ctx, nil)
+}
+
+// list returns the matching invitations of the tenant, newest first.
+func (r *userInvitationRepository) list(ctx context.Context, match func(*entities.UserInvitation) bool) ([]*entities.UserInvitation, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	var invitations []*entities.UserInvitation
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		invitations, err = list(tx, bucketUserInvitations, tenantKey(tenantID, ""), match)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+
+	return invitations, nil
+}
+
+func (r *userInvitationRepository) Update(ctx context.Context, invitation *entities.UserInvitation) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	invitation.TenantID = tenantID
+	return replace(r.db, bucketUserInvitations, tenantKey(tenantID, invitation.ID.String()), invitation, domainErrors.ErrUserInvitationNotFound)
+}
@@ -0,0 +1,362 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"go.etcd.io/bbolt"
+)
+
+// userRecord is how a user is stored. It is mapped to and from entities.User
+// field by field, so the stored shape does not follow changes to the entity
+// by accident.
+type userRecord struct {
+	ID              string
+	TenantID        string
+	Name            string
+	Email           string
+	NormalizedEmail string
+	Password        string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Scopes          []string
+	Identities      []identityRecord
+	ExternalID      string
+	Disabled        bool
+	MFA             mfaRecord
+}
+
+type identityRecord struct {
+	Provider string
+	Subject  string
+	Email    string
+	LinkedAt time.Time
+}
+
+type mfaRecord struct {
+	Enabled        bool
+	Secret         string
+	LastUsedStep   int64
+	RecoveryCodes  []string
+	FailedAttempts int
+	LockedUntil    time.Time
+}
+
+func newUserRecord(user *entities.User) *userRecord {
+	identities := make([]identityRecord, 0, len(user.Identities))
+	for _, identity := range user.Identities {
+		identities = append(identities, identityRecord{
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+
+	return &userRecord{
+		ID:              user.ID.String(),
+		TenantID:        user.TenantID,
+		Name:            user.Name,
+		Email:           user.Email,
+		NormalizedEmail: user.NormalizedEmail,
+		Password:        user.Password,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		Scopes:          user.Scopes,
+		Identities:      identities,
+		ExternalID:      user.ExternalID,
+		Disabled:        user.Disabled,
+		MFA: mfaRecord{
+			Enabled:        user.MFA.Enabled,
+			Secret:         user.MFA.Secret,
+			LastUsedStep:   user.MFA.LastUsedStep,
+			RecoveryCodes:  user.MFA.RecoveryCodes,
+			FailedAttempts: user.MFA.FailedAttempts,
+			LockedUntil:    user.MFA.LockedUntil,
+		},
+	}
+}
+
+func (r *userRecord) toEntity() *entities.User {
+	var identities []entities.ExternalIdentity
+	for _, identity := range r.Identities {
+		identities = append(identities, entities.ExternalIdentity{
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+
+	return &entities.User{
+		ID:              entities.UserID(r.ID),
+		TenantID:        r.TenantID,
+		Name:            r.Name,
+		Email:           r.Email,
+		NormalizedEmail: r.NormalizedEmail,
+		Password:        r.Password,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+		Scopes:          r.Scopes,
+		Identities:      identities,
+		ExternalID:      r.ExternalID,
+		Disabled:        r.Disabled,
+		MFA: entities.MFA{
+			Enabled:        r.MFA.Enabled,
+			Secret:         r.MFA.Secret,
+			LastUsedStep:   r.MFA.LastUsedStep,
+			RecoveryCodes:  r.MFA.RecoveryCodes,
+			FailedAttempts: r.MFA.FailedAttempts,
+			LockedUntil:    r.MFA.LockedUntil,
+		},
+	}
+}
+
+// emailKey and identityKeys return the keys of the record in the
+// user_emails and user_identities indexes, which map them to the ID of their
+// user and are unique per tenant.
+func (r *userRecord) emailKey() []byte {
+	return tenantKey(r.TenantID, r.NormalizedEmail)
+}
+
+func (r *userRecord) identityKeys() [][]byte {
+	keys := make([][]byte, 0, len(r.Identities))
+	for _, identity := range r.Identities {
+		keys = append(keys, tenantKey(r.TenantID, identity.Provider, identity.Subject))
+	}
+	return keys
+}
+
+type userRepository struct {
+	db     *DB
+	policy entities.EmailPolicy
+}
+
+// NewUserRepository tells emails apart by their key under the policy.
+func NewUserRepository(db *DB, policy entities.EmailPolicy) repositories.UserRepository {
+	return &userRepository{
+		db:     db,
+		policy: policy,
+	}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *entities.User) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	user.TenantID = tenantID
+	user.NormalizedEmail = r.policy.Key(user.Email)
+	record := newUserRecord(user)
+
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		key := tenantKey(tenantID, record.ID)
+		if tx.Bucket(bucketUsers).Get(key) != nil {
+			return domainErrors.ErrUserAlreadyExists
+		}
+		return r.put(tx, record, nil)
+	})
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id entities.UserID) (*entities.User, error) {
+	return r.find(ctx, func(tx *bbolt.Tx, tenantID string) []byte {
+		return tenantKey(tenantID, id.String())
+	})
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	return r.find(ctx, func(tx *bbolt.Tx, tenantID string) []byte {
+		return r.indexed(tx, tenantID, bucketUserEmails, tenantKey(tenantID, r.policy.Key(email)))
+	})
+}
+
+func (r *userRepository) GetByIdentity(ctx context.Context, provider, subject string) (*entities.User, error) {
+	return r.find(ctx, func(tx *bbolt.Tx, tenantID string) []byte {
+		return r.indexed(tx, tenantID, bucketUserIdentities, tenantKey(tenantID, provider, subject))
+	})
+}
+
+// indexed returns the key of the user of the tenant the index maps key to,
+// or nil.
+func (r *userRepository) indexed(tx *bbolt.Tx, tenantID string, index, key []byte) []byte {
+	id := tx.Bucket(index).Get(key)
+	if id == nil {
+		return nil
+	}
+	return tenantKey(tenantID, string(id))
+}
+
+// find returns the user at the key lookup returns within the tenant of ctx.
+func (r *userRepository) find(ctx context.Context, lookup func(tx *bbolt.Tx, tenantID string) []byte) (*entities.User, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	var record userRecord
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		key := lookup(tx, tenantID)
+		if key == nil {
+			return domainErrors.ErrUserNotFound
+		}
+		found, err := get(tx, bucketUsers, key, &record)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return domainErrors.ErrUserNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record.toEntity(), nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
+	users, _, err := r.Find(ctx, repositories.UserQuery{})
+	return users, err
+}
+
+// Find reads every user of the tenant to select from them; bolt has no
+// indexes but those the repository keeps.
+func (r *userRepository) Find(ctx context.Context, query repositories.UserQuery) ([]*entities.User, int64, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, 0, domainErrors.ErrTenantRequired
+	}
+
+	var records []*userRecord
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		var err error
+		records, err = list[userRecord](tx, bucketUsers, tenantKey(tenantID, ""), nil)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*entities.User, 0, len(records))
+	for _, record := range records {
+		users = append(users, record.toEntity())
+	}
+	page, total := query.Select(users, r.policy.Key)
+	return page, total, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *entities.User) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	user.NormalizedEmail = r.policy.Key(user.Email)
+
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		var existing userRecord
+		found, err := get(tx, bucketUsers, tenantKey(tenantID, user.ID.String()), &existing)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return domainErrors.ErrUserNotFound
+		}
+
+		// Users never move between tenants, and keep when they were created
+		user.TenantID = tenantID
+		record := newUserRecord(user)
+		record.CreatedAt = existing.CreatedAt
+		return r.put(tx, record, &existing)
+	})
+}
+
+func (r *userRepository) Delete(ctx context.Context, id entities.UserID) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	return r.db.bolt.Update(func(tx *bbolt.Tx) error {
+		key := tenantKey(tenantID, id.String())
+		var existing userRecord
+		found, err := get(tx, bucketUsers, key, &existing)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return domainErrors.ErrUserNotFound
+		}
+
+		if err := r.unindex(tx, &existing); err != nil {
+			return err
+		}
+		return tx.Bucket(bucketUsers).Delete(key)
+	})
+}
+
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return 0, domainErrors.ErrTenantRequired
+	}
+
+	var count int64
+	err := r.db.bolt.View(func(tx *bbolt.Tx) error {
+		prefix := tenantKey(tenantID, "")
+		cursor := tx.Bucket(bucketUsers).Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// put stores record, replacing existing, and moves the index entries of
+// existing to it. An email or identity taken by another user of the tenant
+// means the user already exists.
+func (r *userRepository) put(tx *bbolt.Tx, record, existing *userRecord) error {
+	if existing != nil {
+		if err := r.unindex(tx, existing); err != nil {
+			return err
+		}
+	}
+
+	emails := tx.Bucket(bucketUserEmails)
+	if emails.Get(record.emailKey()) != nil {
+		return domainErrors.ErrUserAlreadyExists
+	}
+	if err := emails.Put(record.emailKey(), []byte(record.ID)); err != nil {
+		return err
+	}
+
+	identities := tx.Bucket(bucketUserIdentities)
+	for _, key := range record.identityKeys() {
+		if identities.Get(key) != nil {
+			return domainErrors.ErrUserAlreadyExists
+		}
+		if err := identities.Put(key, []byte(record.ID)); err != nil {
+			return err
+		}
+	}
+
+	return put(tx, bucketUsers, tenantKey(record.TenantID, record.ID), record)
+}
+
+// unindex removes the index entries of record.
+func (r *userRepository) unindex(tx *bbolt.Tx, record *userRecord) error {
+	if err := tx.Bucket(bucketUserEmails).Delete(record.emailKey()); err != nil {
+		return err
+	}
+	for _, key := range record.identityKeys() {
+		if err := tx.Bucket(bucketUserIdentities).Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
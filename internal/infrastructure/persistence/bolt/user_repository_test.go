@@ -0,0 +1,90 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/persistencetest"
+)
+
+// newDatabase returns an empty database in a file of its own.
+func newDatabase(t *testing.T, path string) *DB {
+	t.Helper()
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestUserRepository(t *testing.T) {
+	persistencetest.UserRepository(t, func(t *testing.T) repositories.UserRepository {
+		return NewUserRepository(newDatabase(t, filepath.Join(t.TempDir(), "data.db")), entities.EmailPolicy{})
+	})
+}
+
+func TestUserRepository_Durable(t *testing.T) {
+	ctx := services.WithTenant(context.Background(), "acme")
+	path := filepath.Join(t.TempDir(), "data.db")
+
+	db, err := Open(path)
+	assert.NoError(t, err)
+	user, err := entities.NewUser("Dana", "Dana@Example.com", "hash")
+	assert.NoError(t, err)
+	assert.NoError(t, NewUserRepository(db, entities.EmailPolicy{}).Create(ctx, user))
+	assert.NoError(t, db.Close())
+
+	repo := NewUserRepository(newDatabase(t, path), entities.EmailPolicy{})
+	found, err := repo.GetByEmail(ctx, "dana@example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, user.ID, found.ID)
+	}
+
+	// Reads return copies, so changing one changes nothing until Update
+	found.Name = "Changed"
+	again, err := repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Dana", again.Name)
+}
+
+func TestDB_Snapshot(t *testing.T) {
+	ctx := services.WithTenant(context.Background(), "acme")
+	db := newDatabase(t, filepath.Join(t.TempDir(), "data.db"))
+
+	user, err := entities.NewUser("Dana", "dana@example.com", "hash")
+	assert.NoError(t, err)
+	assert.NoError(t, NewUserRepository(db, entities.EmailPolicy{}).Create(ctx, user))
+
+	dir := t.TempDir()
+	path, err := db.Snapshot(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, filepath.Dir(path))
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{path}, files, "no temporary file is left behind")
+
+	// Writes after the snapshot are not in it
+	later, err := entities.NewUser("Eli", "eli@example.com", "hash")
+	assert.NoError(t, err)
+	assert.NoError(t, NewUserRepository(db, entities.EmailPolicy{}).Create(ctx, later))
+
+	restored := NewUserRepository(newDatabase(t, path), entities.EmailPolicy{})
+	found, err := restored.GetByEmail(ctx, "dana@example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, user.ID, found.ID)
+	}
+	count, err := restored.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestAPIKeyRepository_MarkUsed(t *testing.T) {
+	persistencetest.APIKeyMarkUsed(t, NewAPIKeyRepository(newDatabase(t, filepath.Join(t.TempDir(), "data.db"))))
+}
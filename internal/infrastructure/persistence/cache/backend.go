@@ -0,0 +1,111 @@
+// Package cache keeps users in front of the user store, so the lookup every
+// authenticated request makes rarely reaches the database.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+// Backend holds cached users by key. The in-process LRU is one; a cache
+// shared between instances can stand in for it. Backends must be safe for
+// concurrent use, and report a failure to read as a miss: the cache only
+// ever saves work.
+type Backend interface {
+	// Get returns the user at key, or false if there is none or it expired.
+	// The caller may change the user.
+	Get(ctx context.Context, key string) (*entities.User, bool)
+	// Set stores user at key. The caller may change user afterwards.
+	Set(ctx context.Context, key string, user *entities.User)
+	Delete(ctx context.Context, key string)
+}
+
+// LRU is a Backend in process memory. It holds at most size users, dropping
+// the least recently used, each for at most ttl.
+type LRU struct {
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]*list.Element
+	// recent has the most recently used entry at the front
+	recent *list.List
+	mutex  sync.Mutex
+}
+
+type lruEntry struct {
+	key       string
+	user      *entities.User
+	expiresAt time.Time
+}
+
+func NewLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+		recent:  list.New(),
+	}
+}
+
+func (c *LRU) Get(ctx context.Context, key string) (*entities.User, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.recent.MoveToFront(element)
+	return entry.user.Clone(), true
+}
+
+func (c *LRU) Set(ctx context.Context, key string, user *entities.User) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := &lruEntry{key: key, user: user.Clone(), expiresAt: c.now().Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recent.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.recent.PushFront(entry)
+	for c.recent.Len() > c.size {
+		c.remove(c.recent.Back())
+	}
+}
+
+func (c *LRU) Delete(ctx context.Context, key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Len is the number of users held, some of which may have expired.
+func (c *LRU) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.recent.Len()
+}
+
+// remove drops an entry. The caller must hold the lock.
+func (c *LRU) remove(element *list.Element) {
+	c.recent.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"golang.org/x/sync/singleflight"
+)
+
+// UserRepository caches the users GetByID finds in a Backend, and passes
+// every other method through to the store. Update and Delete drop the user
+// from the cache, so this process never sees a stale user; other processes
+// sharing the store but not the backend see changes once the cached user
+// expires.
+type UserRepository struct {
+	repositories.UserRepository
+	backend Backend
+	loading singleflight.Group
+	// generation changes whenever a user is dropped, so a load that started
+	// before does not cache what it read. Loads check it and cache under
+	// storing, which invalidations hold too, so none comes in between.
+	generation atomic.Uint64
+	storing    sync.Mutex
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	loads         atomic.Int64
+	invalidations atomic.Int64
+}
+
+// Stats counts what the cache did since it was created.
+type Stats struct {
+	Hits   int64
+	Misses int64
+	// Loads are the reads of the store misses made. Concurrent misses of one
+	// user share a load.
+	Loads         int64
+	Invalidations int64
+}
+
+// HitRate is the share of lookups the cache answered.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d loads=%d invalidations=%d hitRate=%.2f", s.Hits, s.Misses, s.Loads, s.Invalidations, s.HitRate())
+}
+
+func NewUserRepository(store repositories.UserRepository, backend Backend) *UserRepository {
+	return &UserRepository{
+		UserRepository: store,
+		backend:        backend,
+	}
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id entities.UserID) (*entities.User, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	key := userKey(tenantID, id)
+	if user, ok := r.backend.Get(ctx, key); ok {
+		r.hits.Add(1)
+		return user, nil
+	}
+	r.misses.Add(1)
+
+	generation := r.generation.Load()
+	user, err, _ := r.loading.Do(key, func() (interface{}, error) {
+		r.loads.Add(1)
+		// Callers waiting for the load should not fail because the first
+		// one gave up
+		user, err := r.UserRepository.GetByID(context.WithoutCancel(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		r.storing.Lock()
+		defer r.storing.Unlock()
+		if r.generation.Load() == generation {
+			r.backend.Set(ctx, key, user)
+		}
+		return user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Every caller gets a user of its own
+	return user.(*entities.User).Clone(), nil
+}
+
+// Update drops the user from the cache even when it fails, since a failed
+// write may still have reached the store.
+func (r *UserRepository) Update(ctx context.Context, user *entities.User) error {
+	err := r.UserRepository.Update(ctx, user)
+	r.invalidate(ctx, user.ID)
+	return err
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id entities.UserID) error {
+	err := r.UserRepository.Delete(ctx, id)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// Stats returns what the cache did so far.
+func (r *UserRepository) Stats() Stats {
+	return Stats{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Loads:         r.loads.Load(),
+		Invalidations: r.invalidations.Load(),
+	}
+}
+
+func (r *UserRepository) invalidate(ctx context.Context, id entities.UserID) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	key := userKey(tenantID, id)
+	r.storing.Lock()
+	defer r.storing.Unlock()
+	r.generation.Add(1)
+	r.loading.Forget(key)
+	r.backend.Delete(ctx, key)
+	r.invalidations.Add(1)
+}
+
+// userKey is the key of a user in the backend. IDs are only unique within a
+// tenant.
+func userKey(tenantID string, id entities.UserID) string {
+	return "user:" + tenantID + ":" + id.String()
+}
@@ -0,0 +1,254 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/memory"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/persistencetest"
+)
+
+// countingStore counts the lookups by ID reaching the store, and holds them
+// until release is closed if it is set.
+type countingStore struct {
+	repositories.UserRepository
+	lookups atomic.Int64
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *countingStore) GetByID(ctx context.Context, id entities.UserID) (*entities.User, error) {
+	s.lookups.Add(1)
+	if s.release != nil {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	return s.UserRepository.GetByID(ctx, id)
+}
+
+func newStore(t *testing.T, ctx context.Context) (*countingStore, *entities.User) {
+	t.Helper()
+
+	store := &countingStore{UserRepository: memory.NewUserRepository(entities.EmailPolicy{})}
+	user, err := entities.NewUser("Dana", "dana@example.com", "hash")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Create(ctx, user))
+	return store, user
+}
+
+func TestUserRepository(t *testing.T) {
+	persistencetest.UserRepository(t, func(t *testing.T) repositories.UserRepository {
+		return NewUserRepository(memory.NewUserRepository(entities.EmailPolicy{}), NewLRU(100, time.Minute))
+	})
+}
+
+func TestUserRepository_GetByID(t *testing.T) {
+	ctx := services.WithTenant(context.Background(), "acme")
+	store, user := newStore(t, ctx)
+	repo := NewUserRepository(store, NewLRU(100, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		found, err := repo.GetByID(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Dana", found.Name)
+	}
+	assert.Equal(t, int64(1), store.lookups.Load())
+	assert.Equal(t, Stats{Hits: 2, Misses: 1, Loads: 1}, repo.Stats())
+
+	// Misses are not cached
+	_, err := repo.GetByID(ctx, entities.NewUserID())
+	assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+
+	t.Run("Keeps tenants apart", func(t *testing.T) {
+		_, err := repo.GetByID(services.WithTenant(context.Background(), "globex"), user.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+		_, err = repo.GetByID(context.Background(), user.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrTenantRequired)
+	})
+}
+
+func TestUserRepository_Invalidation(t *testing.T) {
+	ctx := services.WithTenant(context.Background(), "acme")
+	store, user := newStore(t, ctx)
+	repo := NewUserRepository(store, NewLRU(100, time.Minute))
+
+	found, err := repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	found.Name = "Updated"
+	assert.NoError(t, repo.Update(ctx, found))
+
+	found, err = repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated", found.Name)
+
+	assert.NoError(t, repo.Delete(ctx, user.ID))
+	_, err = repo.GetByID(ctx, user.ID)
+	assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+	assert.Equal(t, int64(2), repo.Stats().Invalidations)
+}
+
+func TestUserRepository_CollapsesMisses(t *testing.T) {
+	ctx := services.WithTenant(context.Background(), "acme")
+	store, user := newStore(t, ctx)
+	store.started = make(chan struct{}, 10)
+	store.release = make(chan struct{})
+	repo := NewUserRepository(store, NewLRU(100, time.Minute))
+
+	var wg sync.WaitGroup
+	users := make([]*entities.User, 10)
+	for i := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found, err := repo.GetByID(ctx, user.ID)
+			assert.NoError(t, err)
+			users[i] = found
+		}()
+	}
+	<-store.started
+	// Give the other lookups time to miss and join the load
+	time.Sleep(50 * time.Millisecond)
+	close(store.release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), store.lookups.Load())
+	assert.Equal(t, Stats{Misses: 10, Loads: 1}, repo.Stats())
+	users[0].Name = "Changed"
+	assert.Equal(t, "Dana", users[1].Name, "callers sharing a load get users of their own")
+}
+
+func TestUserRepository_DoesNotCacheStaleLoads(t *testing.T) {
+	ctx := services.WithTenant(context.Background(), "acme")
+	store, user := newStore(t, ctx)
+	store.started = make(chan struct{}, 1)
+	store.release = make(chan struct{})
+	repo := NewUserRepository(store, NewLRU(100, time.Minute))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := repo.GetByID(ctx, user.ID)
+		assert.NoError(t, err)
+	}()
+	<-store.started
+
+	// The user changes while the load is under way, so what it read may be
+	// stale
+	updated := user.Clone()
+	updated.Name = "Updated"
+	assert.NoError(t, repo.Update(ctx, updated))
+	close(store.release)
+	<-done
+
+	store.release = nil
+	found, err := repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated", found.Name)
+	assert.Equal(t, int64(2), store.lookups.Load())
+}
+
+// pausingBackend holds the first Set until release is closed, after telling
+// setting.
+type pausingBackend struct {
+	Backend
+	setting chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (b *pausingBackend) Set(ctx context.Context, key string, user *entities.User) {
+	b.once.Do(func() {
+		close(b.setting)
+		<-b.release
+	})
+	b.Backend.Set(ctx, key, user)
+}
+
+func TestUserRepository_InvalidationDuringSet(t *testing.T) {
+	ctx := services.WithTenant(context.Background(), "acme")
+	store, user := newStore(t, ctx)
+	backend := &pausingBackend{Backend: NewLRU(100, time.Minute), setting: make(chan struct{}), release: make(chan struct{})}
+	repo := NewUserRepository(store, backend)
+
+	loaded := make(chan struct{})
+	go func() {
+		defer close(loaded)
+		_, err := repo.GetByID(ctx, user.ID)
+		assert.NoError(t, err)
+	}()
+	<-backend.setting
+
+	// The user changes after the load checked for changes but before it
+	// cached what it read. The update waits for the load to cache it, then
+	// drops it.
+	updated := make(chan struct{})
+	go func() {
+		defer close(updated)
+		changed := user.Clone()
+		changed.Name = "Updated"
+		assert.NoError(t, repo.Update(ctx, changed))
+	}()
+	select {
+	case <-updated:
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(backend.release)
+	<-loaded
+	<-updated
+
+	_, cached := backend.Get(ctx, userKey("acme", user.ID))
+	assert.False(t, cached, "the user read before the update is cached")
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := NewLRU(2, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	user := func(name string) *entities.User {
+		return &entities.User{ID: entities.NewUserID(), Name: name}
+	}
+	cache.Set(ctx, "a", user("A"))
+	cache.Set(ctx, "b", user("B"))
+
+	t.Run("Returns copies", func(t *testing.T) {
+		found, ok := cache.Get(ctx, "a")
+		assert.True(t, ok)
+		found.Name = "Changed"
+		found, _ = cache.Get(ctx, "a")
+		assert.Equal(t, "A", found.Name)
+	})
+
+	t.Run("Drops the least recently used", func(t *testing.T) {
+		// a was just read, so b goes
+		cache.Set(ctx, "c", user("C"))
+		assert.Equal(t, 2, cache.Len())
+		_, ok := cache.Get(ctx, "b")
+		assert.False(t, ok)
+		_, ok = cache.Get(ctx, "a")
+		assert.True(t, ok)
+	})
+
+	t.Run("Expires", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		_, ok := cache.Get(ctx, "a")
+		assert.False(t, ok)
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("Deletes", func(t *testing.T) {
+		cache.Set(ctx, "d", user("D"))
+		cache.Delete(ctx, "d")
+		_, ok := cache.Get(ctx, "d")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+// apiKeyRepository stores copies of the keys and hands out copies, so callers
+// never change a stored key outside the lock.
+type apiKeyRepository struct {
+	keys     map[entities.ID]*entities.APIKey
+	prefixes map[string]entities.ID
+	mutex    sync.RWMutex
+}
+
+func NewAPIKeyRepository() repositories.APIKeyRepository {
+	return &apiKeyRepository{
+		keys:     make(map[entities.ID]*entities.APIKey),
+		prefixes: make(map[string]entities.ID),
+	}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.prefixes[key.Prefix]; exists {
+		return domainErrors.ErrAPIKeyExists
+	}
+
+	r.keys[key.ID] = key.Clone()
+	r.prefixes[key.Prefix] = key.ID
+	return nil
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id entities.ID) (*entities.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, domainErrors.ErrAPIKeyNotFound
+	}
+
+	return key.Clone(), nil
+}
+
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	id, exists := r.prefixes[prefix]
+	if !exists {
+		return nil, domainErrors.ErrAPIKeyNotFound
+	}
+
+	return r.keys[id].Clone(), nil
+}
+
+func (r *apiKeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var keys []*entities.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, key.Clone())
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.keys[key.ID]; !exists {
+		return domainErrors.ErrAPIKeyNotFound
+	}
+
+	r.keys[key.ID] = key.Clone()
+	return nil
+}
+
+func (r *apiKeyRepository) MarkUsed(ctx context.Context, id entities.ID, t time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return domainErrors.ErrAPIKeyNotFound
+	}
+
+	used := key.Clone()
+	used.MarkUsed(t)
+	r.keys[id] = used
+	return nil
+}
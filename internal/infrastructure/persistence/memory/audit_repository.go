@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type auditRepository struct {
+	events []*entities.AuditEvent
+	mutex  sync.Mutex
+}
+
+func NewAuditRepository() repositories.AuditRepository {
+	return &auditRepository{}
+}
+
+func (r *auditRepository) Create(ctx context.Context, event *entities.AuditEvent) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = append(r.events, event)
+	return nil
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type federatedLoginRepository struct {
+	logins map[string]*entities.FederatedLogin
+	mutex  sync.Mutex
+}
+
+func NewFederatedLoginRepository() repositories.FederatedLoginRepository {
+	return &federatedLoginRepository{
+		logins: make(map[string]*entities.FederatedLogin),
+	}
+}
+
+func (r *federatedLoginRepository) Create(ctx context.Context, login *entities.FederatedLogin) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.logins[login.StateHash] = login
+	return nil
+}
+
+func (r *federatedLoginRepository) Consume(ctx context.Context, stateHash string) (*entities.FederatedLogin, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	login, exists := r.logins[stateHash]
+	if !exists {
+		return nil, domainErrors.ErrInvalidFederatedState
+	}
+
+	delete(r.logins, stateHash)
+	return login, nil
+}
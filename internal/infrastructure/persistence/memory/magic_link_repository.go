@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type magicLinkRepository struct {
+	links map[string]*entities.MagicLink
+	mutex sync.Mutex
+}
+
+func NewMagicLinkRepository() repositories.MagicLinkRepository {
+	return &magicLinkRepository{
+		links: make(map[string]*entities.MagicLink),
+	}
+}
+
+func (r *magicLinkRepository) Create(ctx context.Context, link *entities.MagicLink) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.links[link.TokenHash] = link
+	return nil
+}
+
+func (r *magicLinkRepository) CountSince(ctx context.Context, email string, since time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var count int64
+	for _, link := range r.links {
+		if link.Email == email && !link.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *magicLinkRepository) Consume(ctx context.Context, tokenHash string) (*entities.MagicLink, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	link, exists := r.links[tokenHash]
+	if !exists {
+		return nil, domainErrors.ErrInvalidMagicLink
+	}
+
+	delete(r.links, tokenHash)
+	return link, nil
+}
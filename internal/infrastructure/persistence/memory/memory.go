@@ -0,0 +1,7 @@
+// Package memory keeps everything in process memory, for tests and
+// development. Nothing survives a restart, and every process has data of its
+// own.
+package memory
+
+// Driver is the storage.driver option selecting this adapter.
+const Driver = "memory"
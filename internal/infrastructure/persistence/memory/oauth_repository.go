@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type oauthClientRepository struct {
+	clients map[string]*entities.OAuthClient
+	mutex   sync.RWMutex
+}
+
+func NewOAuthClientRepository() repositories.OAuthClientRepository {
+	return &oauthClientRepository{
+		clients: make(map[string]*entities.OAuthClient),
+	}
+}
+
+func (r *oauthClientRepository) Create(ctx context.Context, client *entities.OAuthClient) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.clients[client.ClientID]; exists {
+		return domainErrors.ErrOAuthClientExists
+	}
+
+	r.clients[client.ClientID] = client
+	return nil
+}
+
+func (r *oauthClientRepository) GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	client, exists := r.clients[clientID]
+	if !exists {
+		return nil, domainErrors.ErrOAuthClientNotFound
+	}
+
+	return client, nil
+}
+
+type authorizationCodeRepository struct {
+	codes map[string]*entities.AuthorizationCode
+	mutex sync.Mutex
+}
+
+func NewAuthorizationCodeRepository() repositories.AuthorizationCodeRepository {
+	return &authorizationCodeRepository{
+		codes: make(map[string]*entities.AuthorizationCode),
+	}
+}
+
+func (r *authorizationCodeRepository) Create(ctx context.Context, code *entities.AuthorizationCode) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.codes[code.CodeHash] = code
+	return nil
+}
+
+func (r *authorizationCodeRepository) Consume(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	code, exists := r.codes[codeHash]
+	if !exists {
+		return nil, domainErrors.ErrAuthorizationCodeNotFound
+	}
+
+	delete(r.codes, codeHash)
+	return code, nil
+}
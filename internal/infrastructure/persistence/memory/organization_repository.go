@@ -0,0 +1,305 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+type organizationRepository struct {
+	organizations map[entities.ID]*entities.Organization
+	mutex         sync.RWMutex
+}
+
+func NewOrganizationRepository() repositories.OrganizationRepository {
+	return &organizationRepository{
+		organizations: make(map[entities.ID]*entities.Organization),
+	}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, organization *entities.Organization) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	organization.TenantID = tenantID
+	r.organizations[organization.ID] = organization
+	return nil
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Organization, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.get(tenantID, id)
+}
+
+// get returns the organization with id if it belongs to the tenant. The
+// caller must hold the lock.
+func (r *organizationRepository) get(tenantID string, id entities.ID) (*entities.Organization, error) {
+	organization, exists := r.organizations[id]
+	if !exists || organization.TenantID != tenantID {
+		return nil, domainErrors.ErrOrganizationNotFound
+	}
+
+	return organization, nil
+}
+
+func (r *organizationRepository) Update(ctx context.Context, organization *entities.Organization) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, err := r.get(tenantID, organization.ID); err != nil {
+		return err
+	}
+
+	organization.TenantID = tenantID
+	r.organizations[organization.ID] = organization
+	return nil
+}
+
+func (r *organizationRepository) Delete(ctx context.Context, id entities.ID) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, err := r.get(tenantID, id); err != nil {
+		return err
+	}
+
+	delete(r.organizations, id)
+	return nil
+}
+
+type teamRepository struct {
+	teams map[entities.ID]*entities.Team
+	mutex sync.RWMutex
+}
+
+func NewTeamRepository() repositories.TeamRepository {
+	return &teamRepository{
+		teams: make(map[entities.ID]*entities.Team),
+	}
+}
+
+func (r *teamRepository) Create(ctx context.Context, team *entities.Team) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.teams[team.ID] = team
+	return nil
+}
+
+func (r *teamRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Team, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	team, exists := r.teams[id]
+	if !exists {
+		return nil, domainErrors.ErrTeamNotFound
+	}
+
+	return team, nil
+}
+
+func (r *teamRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Team, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var teams []*entities.Team
+	for _, team := range r.teams {
+		if team.OrganizationID == organizationID {
+			teams = append(teams, team)
+		}
+	}
+
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+
+	return teams, nil
+}
+
+func (r *teamRepository) Update(ctx context.Context, team *entities.Team) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.teams[team.ID]; !exists {
+		return domainErrors.ErrTeamNotFound
+	}
+
+	r.teams[team.ID] = team
+	return nil
+}
+
+func (r *teamRepository) Delete(ctx context.Context, id entities.ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.teams[id]; !exists {
+		return domainErrors.ErrTeamNotFound
+	}
+
+	delete(r.teams, id)
+	return nil
+}
+
+func (r *teamRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, team := range r.teams {
+		if team.OrganizationID == organizationID {
+			delete(r.teams, id)
+		}
+	}
+	return nil
+}
+
+type membershipRepository struct {
+	memberships map[entities.ID]*entities.Membership
+	mutex       sync.RWMutex
+}
+
+func NewMembershipRepository() repositories.MembershipRepository {
+	return &membershipRepository{
+		memberships: make(map[entities.ID]*entities.Membership),
+	}
+}
+
+func (r *membershipRepository) Create(ctx context.Context, membership *entities.Membership) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Unique like the (organization_id, team_id, user_id) index in MongoDB
+	if r.find(membership.OrganizationID, membership.TeamID, membership.UserID) != nil {
+		return domainErrors.ErrMembershipExists
+	}
+
+	r.memberships[membership.ID] = membership
+	return nil
+}
+
+func (r *membershipRepository) Get(ctx context.Context, organizationID, teamID entities.ID, userID entities.UserID) (*entities.Membership, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	membership := r.find(organizationID, teamID, userID)
+	if membership == nil {
+		return nil, domainErrors.ErrMembershipNotFound
+	}
+
+	return membership, nil
+}
+
+// find returns the membership, or nil. The caller must hold the lock.
+func (r *membershipRepository) find(organizationID, teamID entities.ID, userID entities.UserID) *entities.Membership {
+	for _, membership := range r.memberships {
+		if membership.OrganizationID == organizationID && membership.TeamID == teamID && membership.UserID == userID {
+			return membership
+		}
+	}
+	return nil
+}
+
+func (r *membershipRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Membership, error) {
+	return r.filter(func(membership *entities.Membership) bool {
+		return membership.OrganizationID == organizationID
+	}), nil
+}
+
+func (r *membershipRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Membership, error) {
+	return r.filter(func(membership *entities.Membership) bool {
+		return membership.UserID == userID
+	}), nil
+}
+
+// filter returns the matching memberships, oldest first.
+func (r *membershipRepository) filter(match func(*entities.Membership) bool) []*entities.Membership {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var memberships []*entities.Membership
+	for _, membership := range r.memberships {
+		if match(membership) {
+			memberships = append(memberships, membership)
+		}
+	}
+
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+	})
+
+	return memberships
+}
+
+func (r *membershipRepository) Update(ctx context.Context, membership *entities.Membership) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.memberships[membership.ID]; !exists {
+		return domainErrors.ErrMembershipNotFound
+	}
+
+	r.memberships[membership.ID] = membership
+	return nil
+}
+
+func (r *membershipRepository) Delete(ctx context.Context, id entities.ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.memberships[id]; !exists {
+		return domainErrors.ErrMembershipNotFound
+	}
+
+	delete(r.memberships, id)
+	return nil
+}
+
+func (r *membershipRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, membership := range r.memberships {
+		if membership.OrganizationID == organizationID {
+			delete(r.memberships, id)
+		}
+	}
+	return nil
+}
+
+func (r *membershipRepository) DeleteByTeamID(ctx context.Context, teamID entities.ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, membership := range r.memberships {
+		if membership.TeamID == teamID {
+			delete(r.memberships, id)
+		}
+	}
+	return nil
+}
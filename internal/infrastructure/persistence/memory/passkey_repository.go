@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type passkeyRepository struct {
+	passkeys map[string]*entities.Passkey
+	mutex    sync.RWMutex
+}
+
+func NewPasskeyRepository() repositories.PasskeyRepository {
+	return &passkeyRepository{
+		passkeys: make(map[string]*entities.Passkey),
+	}
+}
+
+func (r *passkeyRepository) Create(ctx context.Context, passkey *entities.Passkey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.passkeys[passkey.ID]; exists {
+		return domainErrors.ErrPasskeyExists
+	}
+
+	r.passkeys[passkey.ID] = passkey
+	return nil
+}
+
+func (r *passkeyRepository) GetByID(ctx context.Context, id string) (*entities.Passkey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	passkey, exists := r.passkeys[id]
+	if !exists {
+		return nil, domainErrors.ErrPasskeyNotFound
+	}
+
+	return passkey, nil
+}
+
+func (r *passkeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Passkey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var passkeys []*entities.Passkey
+	for _, passkey := range r.passkeys {
+		if passkey.UserID == userID {
+			passkeys = append(passkeys, passkey)
+		}
+	}
+
+	sort.Slice(passkeys, func(i, j int) bool {
+		return passkeys[i].CreatedAt.Before(passkeys[j].CreatedAt)
+	})
+
+	return passkeys, nil
+}
+
+func (r *passkeyRepository) Update(ctx context.Context, passkey *entities.Passkey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.passkeys[passkey.ID]; !exists {
+		return domainErrors.ErrPasskeyNotFound
+	}
+
+	r.passkeys[passkey.ID] = passkey
+	return nil
+}
+
+func (r *passkeyRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.passkeys[id]; !exists {
+		return domainErrors.ErrPasskeyNotFound
+	}
+
+	delete(r.passkeys, id)
+	return nil
+}
+
+type passkeyCeremonyRepository struct {
+	ceremonies map[string]*entities.PasskeyCeremony
+	mutex      sync.Mutex
+}
+
+func NewPasskeyCeremonyRepository() repositories.PasskeyCeremonyRepository {
+	return &passkeyCeremonyRepository{
+		ceremonies: make(map[string]*entities.PasskeyCeremony),
+	}
+}
+
+func (r *passkeyCeremonyRepository) Create(ctx context.Context, ceremony *entities.PasskeyCeremony) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ceremonies[ceremony.ChallengeHash] = ceremony
+	return nil
+}
+
+func (r *passkeyCeremonyRepository) Consume(ctx context.Context, challengeHash string) (*entities.PasskeyCeremony, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ceremony, exists := r.ceremonies[challengeHash]
+	if !exists {
+		return nil, domainErrors.ErrInvalidPasskeyChallenge
+	}
+
+	delete(r.ceremonies, challengeHash)
+	return ceremony, nil
+}
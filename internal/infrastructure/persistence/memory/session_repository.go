@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type sessionRepository struct {
+	sessions map[entities.ID]*entities.Session
+	mutex    sync.RWMutex
+}
+
+func NewSessionRepository() repositories.SessionRepository {
+	return &sessionRepository{
+		sessions: make(map[entities.ID]*entities.Session),
+	}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Session, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	session, exists := r.sessions[id]
+	if !exists {
+		return nil, domainErrors.ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
+func (r *sessionRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Session, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var sessions []*entities.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *entities.Session) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.sessions[session.ID]; !exists {
+		return domainErrors.ErrSessionNotFound
+	}
+
+	r.sessions[session.ID] = session
+	return nil
+}
@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+// UserChangeFeed keeps the last events published to it. It only sees the
+// changes made in this process through the repositories UserRepository
+// returns, and forgets events and consumer positions on restart. Event IDs
+// count the events published, so an ID is valid as long as the event is kept.
+type UserChangeFeed struct {
+	mutex    sync.Mutex
+	history  []*entities.UserEvent
+	size     int
+	sequence uint64
+	// changed is closed, and replaced, whenever an event is published
+	changed   chan struct{}
+	positions map[tenantConsumer]uint64
+}
+
+type tenantConsumer struct {
+	tenantID string
+	consumer string
+}
+
+// NewUserChangeFeed keeps the last size events, which watchers resuming
+// after an older one cannot get.
+func NewUserChangeFeed(size int) *UserChangeFeed {
+	return &UserChangeFeed{
+		size:      max(size, 1),
+		changed:   make(chan struct{}),
+		positions: make(map[tenantConsumer]uint64),
+	}
+}
+
+// Publish sets the ID of the event, and its time when missing, and sends it
+// to the watchers of its tenant.
+func (f *UserChangeFeed) Publish(event *entities.UserEvent) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.sequence++
+	event.ID = strconv.FormatUint(f.sequence, 10)
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	f.history = append(f.history, event)
+	if len(f.history) > f.size {
+		f.history = f.history[len(f.history)-f.size:]
+	}
+
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *UserChangeFeed) Watch(ctx context.Context, watch services.UserWatch, handle func(*entities.UserEvent) error) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	key := tenantConsumer{tenantID, watch.Consumer}
+
+	f.mutex.Lock()
+	position := f.sequence
+	switch {
+	case watch.After != "":
+		after, err := strconv.ParseUint(watch.After, 10, 64)
+		if err != nil || after > f.sequence {
+			f.mutex.Unlock()
+			return domainErrors.ErrInvalidUserEventID
+		}
+		position = after
+	case watch.Consumer != "":
+		if saved, ok := f.positions[key]; ok {
+			position = saved
+		}
+	}
+	f.mutex.Unlock()
+
+	for {
+		events, changed, err := f.since(position)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if event.TenantID == tenantID && watching(watch.Types, event.Type) {
+				if err := handle(copyUserEvent(event)); err != nil {
+					return err
+				}
+			}
+			position++
+			if watch.Consumer != "" {
+				f.remember(key, position)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// since returns the events published after position, and the channel closed
+// when the next one is.
+func (f *UserChangeFeed) since(position uint64) ([]*entities.UserEvent, chan struct{}, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	oldest := f.sequence - uint64(len(f.history))
+	if position < oldest {
+		// Events after position were dropped
+		return nil, nil, domainErrors.ErrInvalidUserEventID
+	}
+	return f.history[position-oldest:], f.changed, nil
+}
+
+func (f *UserChangeFeed) remember(key tenantConsumer, position uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.positions[key] = position
+}
+
+func watching(types []entities.UserEventType, eventType entities.UserEventType) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// copyUserEvent lets every watcher change the event it gets, user included,
+// without touching what the others get.
+func copyUserEvent(event *entities.UserEvent) *entities.UserEvent {
+	copied := *event
+	if event.User != nil {
+		copied.User = event.User.Clone()
+	}
+	return &copied
+}
+
+// UserRepository returns repo publishing the users created, updated and
+// deleted through it to the feed.
+func (f *UserChangeFeed) UserRepository(repo repositories.UserRepository) repositories.UserRepository {
+	return &publishingUserRepository{UserRepository: repo, feed: f}
+}
+
+type publishingUserRepository struct {
+	repositories.UserRepository
+	feed *UserChangeFeed
+}
+
+func (r *publishingUserRepository) Create(ctx context.Context, user *entities.User) error {
+	if err := r.UserRepository.Create(ctx, user); err != nil {
+		return err
+	}
+	r.publish(ctx, entities.UserCreated, user.ID, user)
+	return nil
+}
+
+func (r *publishingUserRepository) Update(ctx context.Context, user *entities.User) error {
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	r.publish(ctx, entities.UserUpdated, user.ID, user)
+	return nil
+}
+
+func (r *publishingUserRepository) Delete(ctx context.Context, id entities.UserID) error {
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.publish(ctx, entities.UserDeleted, id, nil)
+	return nil
+}
+
+func (r *publishingUserRepository) publish(ctx context.Context, eventType entities.UserEventType, id entities.UserID, user *entities.User) {
+	// The store succeeded, so ctx has a tenant
+	tenantID, _ := services.TenantFromContext(ctx)
+	event := &entities.UserEvent{Type: eventType, TenantID: tenantID, UserID: id}
+	if user != nil {
+		event.User = user.Clone()
+	}
+	r.feed.Publish(event)
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/persistencetest"
+)
+
+func TestUserChangeFeed(t *testing.T) {
+	feed := NewUserChangeFeed(100)
+	persistencetest.UserChangeFeed(t, feed.UserRepository(NewUserRepository(entities.EmailPolicy{})), feed)
+}
+
+func TestUserChangeFeed_ForgetsOldEvents(t *testing.T) {
+	feed := NewUserChangeFeed(2)
+	ctx := services.WithTenant(context.Background(), "acme")
+	for i := 0; i < 3; i++ {
+		feed.Publish(&entities.UserEvent{Type: entities.UserDeleted, TenantID: "acme", UserID: entities.NewUserID()})
+	}
+
+	// Events 2 and 3 are kept, so watching after event 1 still works
+	var got []string
+	err := feed.Watch(ctx, services.UserWatch{After: "1"}, func(event *entities.UserEvent) error {
+		if got = append(got, event.ID); len(got) == 2 {
+			return context.Canceled
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"2", "3"}, got)
+
+	err = feed.Watch(ctx, services.UserWatch{After: "0"}, func(*entities.UserEvent) error { return nil })
+	assert.ErrorIs(t, err, domainErrors.ErrInvalidUserEventID)
+	err = feed.Watch(ctx, services.UserWatch{After: "4"}, func(*entities.UserEvent) error { return nil })
+	assert.ErrorIs(t, err, domainErrors.ErrInvalidUserEventID)
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+type userInvitationRepository struct {
+	invitations map[entities.ID]*entities.UserInvitation
+	policy      entities.EmailPolicy
+	mutex       sync.RWMutex
+}
+
+// NewUserInvitationRepository finds invitations by the key of their email
+// under the policy, which must be the one of the user repository.
+func NewUserInvitationRepository(policy entities.EmailPolicy) repositories.UserInvitationRepository {
+	return &userInvitationRepository{
+		invitations: make(map[entities.ID]*entities.UserInvitation),
+		policy:      policy,
+	}
+}
+
+func (r *userInvitationRepository) Create(ctx context.Context, invitation *entities.UserInvitation) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	invitation.TenantID = tenantID
+	invitation.NormalizedEmail = r.policy.Key(invitation.Email)
+	r.invitations[invitation.ID] = invitation
+	return nil
+}
+
+func (r *userInvitationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.UserInvitation, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.get(tenantID, id)
+}
+
+// get returns the invitation with id if it belongs to the tenant. The caller
+// must hold the lock.
+func (r *userInvitationRepository) get(tenantID string, id entities.ID) (*entities.UserInvitation, error) {
+	invitation, exists := r.invitations[id]
+	if !exists || invitation.TenantID != tenantID {
+		return nil, domainErrors.ErrUserInvitationNotFound
+	}
+
+	return invitation, nil
+}
+
+func (r *userInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			return invitation, nil
+		}
+	}
+
+	return nil, domainErrors.ErrUserInvitationNotFound
+}
+
+func (r *userInvitationRepository) GetByEmail(ctx context.Context, email string) ([]*entities.UserInvitation, error) {
+	key := r.policy.Key(email)
+	return r.list(ctx, func(invitation *entities.UserInvitation) bool {
+		return invitation.NormalizedEmail == key
+	})
+}
+
+func (r *userInvitationRepository) GetAll(ctx context.Context) ([]*entities.UserInvitation, error) {
+	return r.list(ctx, func(*entities.UserInvitation) bool {
+		return true
+	})
+}
+
+// list returns the matching invitations of the tenant, newest first.
+func (r *userInvitationRepository) list(ctx context.Context, match func(*entities.UserInvitation) bool) ([]*entities.UserInvitation, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var invitations []*entities.UserInvitation
+	for _, invitation := range r.invitations {
+		if invitation.TenantID == tenantID && match(invitation) {
+			invitations = append(invitations, invitation)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+
+	return invitations, nil
+}
+
+func (r *userInvitationRepository) Update(ctx context.Context, invitation *entities.UserInvitation) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, err := r.get(tenantID, invitation.ID); err != nil {
+		return err
+	}
+
+	invitation.TenantID = tenantID
+	r.invitations[invitation.ID] = invitation
+	return nil
+}
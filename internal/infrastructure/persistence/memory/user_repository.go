@@ -2,47 +2,88 @@ package memory
 
 import (
 	"context"
+	"slices"
 	"sync"
 
 	"github.com/wonyus/backend-challenge/internal/domain/entities"
 	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
 	"github.com/wonyus/backend-challenge/internal/domain/repositories"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
 )
 
+// tenantEmail is unique, like the (tenant_id, email_normalized) index in
+// MongoDB. The email is its key under the email policy.
+type tenantEmail struct {
+	tenantID string
+	email    string
+}
+
+// userRepository keeps copies of the users it is given and hands out copies,
+// so changing a user only changes the store through Update, as with a
+// database. order keeps the IDs in the order the users were added, so
+// nothing depends on the order of the map.
 type userRepository struct {
-	users  map[primitive.ObjectID]*entities.User
-	emails map[string]primitive.ObjectID
+	users  map[entities.UserID]*entities.User
+	order  []entities.UserID
+	emails map[tenantEmail]entities.UserID
+	policy entities.EmailPolicy
 	mutex  sync.RWMutex
 }
 
-func NewUserRepository() repositories.UserRepository {
+// NewUserRepository tells emails apart by their key under the policy.
+func NewUserRepository(policy entities.EmailPolicy) repositories.UserRepository {
 	return &userRepository{
-		users:  make(map[primitive.ObjectID]*entities.User),
-		emails: make(map[string]primitive.ObjectID),
+		users:  make(map[entities.UserID]*entities.User),
+		emails: make(map[tenantEmail]entities.UserID),
+		policy: policy,
 	}
 }
 
 func (r *userRepository) Create(ctx context.Context, user *entities.User) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	// Check if email already exists
-	if _, exists := r.emails[user.Email]; exists {
+	user.NormalizedEmail = r.policy.Key(user.Email)
+	key := tenantEmail{tenantID, user.NormalizedEmail}
+	if _, exists := r.emails[key]; exists {
 		return domainErrors.ErrUserAlreadyExists
 	}
 
-	r.users[user.ID] = user
-	r.emails[user.Email] = user.ID
+	user.TenantID = tenantID
+	r.users[user.ID] = user.Clone()
+	r.order = append(r.order, user.ID)
+	r.emails[key] = user.ID
 	return nil
 }
 
-func (r *userRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*entities.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id entities.UserID) (*entities.User, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
+	user, err := r.get(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.Clone(), nil
+}
+
+// get returns the stored user with id if it belongs to the tenant. The
+// caller must hold the lock.
+func (r *userRepository) get(tenantID string, id entities.UserID) (*entities.User, error) {
 	user, exists := r.users[id]
-	if !exists {
+	if !exists || user.TenantID != tenantID {
 		return nil, domainErrors.ErrUserNotFound
 	}
 
@@ -50,66 +91,139 @@ func (r *userRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*e
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	userID, exists := r.emails[email]
+	userID, exists := r.emails[tenantEmail{tenantID, r.policy.Key(email)}]
 	if !exists {
 		return nil, domainErrors.ErrUserNotFound
 	}
 
-	user := r.users[userID]
-	return user, nil
+	return r.users[userID].Clone(), nil
 }
 
-func (r *userRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
+func (r *userRepository) GetByIdentity(ctx context.Context, provider, subject string) (*entities.User, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	users := make([]*entities.User, 0, len(r.users))
-	for _, user := range r.users {
-		users = append(users, user)
+	for _, id := range r.order {
+		if user := r.users[id]; user.TenantID == tenantID && user.HasIdentity(provider, subject) {
+			return user.Clone(), nil
+		}
 	}
 
-	return users, nil
+	return nil, domainErrors.ErrUserNotFound
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
+	users, _, err := r.Find(ctx, repositories.UserQuery{})
+	return users, err
+}
+
+func (r *userRepository) Find(ctx context.Context, query repositories.UserQuery) ([]*entities.User, int64, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, 0, domainErrors.ErrTenantRequired
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	users, total := query.Select(r.list(tenantID), r.policy.Key)
+	return users, total, nil
+}
+
+// list returns copies of the users of the tenant in the order they were
+// added. The caller must hold the lock.
+func (r *userRepository) list(tenantID string) []*entities.User {
+	users := make([]*entities.User, 0, len(r.order))
+	for _, id := range r.order {
+		if user := r.users[id]; user.TenantID == tenantID {
+			users = append(users, user.Clone())
+		}
+	}
+	return users
 }
 
 func (r *userRepository) Update(ctx context.Context, user *entities.User) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	existingUser, exists := r.users[user.ID]
-	if !exists {
-		return domainErrors.ErrUserNotFound
+	existingUser, err := r.get(tenantID, user.ID)
+	if err != nil {
+		return err
 	}
 
 	// If email changed, update email mapping
-	if existingUser.Email != user.Email {
-		delete(r.emails, existingUser.Email)
-		r.emails[user.Email] = user.ID
+	normalizedEmail := r.policy.Key(user.Email)
+	if existingUser.NormalizedEmail != normalizedEmail {
+		key := tenantEmail{tenantID, normalizedEmail}
+		if _, exists := r.emails[key]; exists {
+			return domainErrors.ErrUserAlreadyExists
+		}
+		delete(r.emails, tenantEmail{tenantID, existingUser.NormalizedEmail})
+		r.emails[key] = user.ID
 	}
+	user.NormalizedEmail = normalizedEmail
 
-	r.users[user.ID] = user
+	// Users never move between tenants
+	user.TenantID = tenantID
+	r.users[user.ID] = user.Clone()
 	return nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+func (r *userRepository) Delete(ctx context.Context, id entities.UserID) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	user, exists := r.users[id]
-	if !exists {
-		return domainErrors.ErrUserNotFound
+	user, err := r.get(tenantID, id)
+	if err != nil {
+		return err
 	}
 
 	delete(r.users, id)
-	delete(r.emails, user.Email)
+	r.order = slices.DeleteFunc(r.order, func(userID entities.UserID) bool {
+		return userID == id
+	})
+	delete(r.emails, tenantEmail{tenantID, user.NormalizedEmail})
 	return nil
 }
 
 func (r *userRepository) Count(ctx context.Context) (int64, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return 0, domainErrors.ErrTenantRequired
+	}
+
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	return int64(len(r.users)), nil
+	var count int64
+	for _, user := range r.users {
+		if user.TenantID == tenantID {
+			count++
+		}
+	}
+
+	return count, nil
 }
@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/persistencetest"
+)
+
+func TestUserRepository(t *testing.T) {
+	persistencetest.UserRepository(t, func(t *testing.T) repositories.UserRepository {
+		return NewUserRepository(entities.EmailPolicy{})
+	})
+}
+
+func TestAPIKeyRepository_MarkUsed(t *testing.T) {
+	persistencetest.APIKeyMarkUsed(t, NewAPIKeyRepository())
+}
@@ -0,0 +1,112 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type apiKeyRepository struct {
+	collection *collection
+}
+
+func NewAPIKeyRepository(db *Database) repositories.APIKeyRepository {
+	return &apiKeyRepository{
+		collection: db.collection("api_keys"),
+	}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domainErrors.ErrAPIKeyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id entities.ID) (*entities.APIKey, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	return r.findOne(ctx, bson.M{"prefix": prefix})
+}
+
+func (r *apiKeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.APIKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []*entities.APIKey
+	for cursor.Next(ctx) {
+		var key entities.APIKey
+		if err := cursor.Decode(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	return keys, cursor.Err()
+}
+
+func (r *apiKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	filter := bson.M{"_id": key.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":         key.Name,
+			"scopes":       key.Scopes,
+			"expires_at":   key.ExpiresAt,
+			"last_used_at": key.LastUsedAt,
+			"revoked_at":   key.RevokedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domainErrors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) MarkUsed(ctx context.Context, id entities.ID, t time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_used_at": t}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domainErrors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) findOne(ctx context.Context, filter bson.M) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := r.collection.FindOne(ctx, filter).Decode(&key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
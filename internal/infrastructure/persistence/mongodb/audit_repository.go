@@ -0,0 +1,23 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type auditRepository struct {
+	collection *collection
+}
+
+func NewAuditRepository(db *Database) repositories.AuditRepository {
+	return &auditRepository{
+		collection: db.collection("audit_events"),
+	}
+}
+
+func (r *auditRepository) Create(ctx context.Context, event *entities.AuditEvent) error {
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
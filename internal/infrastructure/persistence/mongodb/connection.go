@@ -2,25 +2,117 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
-func NewConnection(uri string) (*mongo.Client, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// ConnectionOptions tune the client. Zero values keep what the URI, or else
+// the driver, says.
+type ConnectionOptions struct {
+	AppName                string
+	MinPoolSize            uint64
+	MaxPoolSize            uint64
+	MaxConnIdleTime        time.Duration
+	ConnectTimeout         time.Duration
+	ServerSelectionTimeout time.Duration
+	// ReadPreference is a mode such as "primary" or "secondaryPreferred".
+	ReadPreference string
+	// ReadConcern is a level such as "local" or "majority".
+	ReadConcern string
+	// WriteConcern is "majority" or the number of members to acknowledge
+	// writes.
+	WriteConcern string
+	// Compressors are tried in order: "zstd", "zlib" or "snappy".
+	Compressors []string
+}
+
+func NewConnection(uri string, opts ConnectionOptions) (*mongo.Client, error) {
+	clientOptions, err := opts.apply(options.Client().ApplyURI(uri).SetRegistry(newRegistry()))
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := 10 * time.Second
+	if opts.ConnectTimeout > timeout {
+		timeout = opts.ConnectTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		return nil, err
 	}
 
 	// Ping the database
 	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(context.Background())
 		return nil, err
 	}
 
 	return client, nil
 }
+
+func (o ConnectionOptions) apply(client *options.ClientOptions) (*options.ClientOptions, error) {
+	if o.AppName != "" {
+		client.SetAppName(o.AppName)
+	}
+	if o.MinPoolSize > 0 {
+		client.SetMinPoolSize(o.MinPoolSize)
+	}
+	if o.MaxPoolSize > 0 {
+		client.SetMaxPoolSize(o.MaxPoolSize)
+	}
+	if o.MaxConnIdleTime > 0 {
+		client.SetMaxConnIdleTime(o.MaxConnIdleTime)
+	}
+	if o.ConnectTimeout > 0 {
+		client.SetConnectTimeout(o.ConnectTimeout)
+	}
+	if o.ServerSelectionTimeout > 0 {
+		client.SetServerSelectionTimeout(o.ServerSelectionTimeout)
+	}
+	if o.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(o.ReadPreference)
+		if err != nil {
+			return nil, err
+		}
+		pref, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		client.SetReadPreference(pref)
+	}
+	if o.ReadConcern != "" {
+		client.SetReadConcern(&readconcern.ReadConcern{Level: o.ReadConcern})
+	}
+	if o.WriteConcern != "" {
+		concern, err := parseWriteConcern(o.WriteConcern)
+		if err != nil {
+			return nil, err
+		}
+		client.SetWriteConcern(concern)
+	}
+	if len(o.Compressors) > 0 {
+		client.SetCompressors(o.Compressors)
+	}
+	return client, client.Validate()
+}
+
+func parseWriteConcern(value string) (*writeconcern.WriteConcern, error) {
+	if value == "majority" {
+		return writeconcern.Majority(), nil
+	}
+	w, err := strconv.Atoi(value)
+	if err != nil || w < 0 {
+		return nil, fmt.Errorf("invalid write concern %q", value)
+	}
+	return &writeconcern.WriteConcern{W: w}, nil
+}
@@ -0,0 +1,136 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Database is the MongoDB database the repositories keep their collections
+// in. Every operation they send goes through its Guard.
+type Database struct {
+	db    *mongo.Database
+	guard *Guard
+}
+
+// NewDatabase wraps db, guarding operations with guard, which may be nil.
+func NewDatabase(db *mongo.Database, guard *Guard) *Database {
+	return &Database{db: db, guard: guard}
+}
+
+func (d *Database) collection(name string) *collection {
+	return &collection{Collection: d.db.Collection(name), guard: d.guard}
+}
+
+// collection shadows the operations of a mongo.Collection the repositories
+// use with guarded ones.
+type collection struct {
+	*mongo.Collection
+	guard *Guard
+}
+
+func (c *collection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	var result *mongo.InsertOneResult
+	err := c.guard.run(ctx, true, func(ctx context.Context) error {
+		var err error
+		result, err = c.Collection.InsertOne(ctx, document, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *collection) UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	var result *mongo.UpdateResult
+	err := c.guard.run(ctx, true, func(ctx context.Context) error {
+		var err error
+		result, err = c.Collection.UpdateOne(ctx, filter, update, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	var result *mongo.DeleteResult
+	err := c.guard.run(ctx, true, func(ctx context.Context) error {
+		var err error
+		result, err = c.Collection.DeleteOne(ctx, filter, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	var result *mongo.DeleteResult
+	err := c.guard.run(ctx, true, func(ctx context.Context) error {
+		var err error
+		result, err = c.Collection.DeleteMany(ctx, filter, opts...)
+		return err
+	})
+	return result, err
+}
+
+func (c *collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	var count int64
+	err := c.guard.run(ctx, false, func(ctx context.Context) error {
+		var err error
+		count, err = c.Collection.CountDocuments(ctx, filter, opts...)
+		return err
+	})
+	return count, err
+}
+
+// Find guards running the query and fetching the first batch. Later batches
+// are fetched with the context given to the cursor.
+func (c *collection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	var cursor *mongo.Cursor
+	err := c.guard.run(ctx, false, func(ctx context.Context) error {
+		var err error
+		cursor, err = c.Collection.Find(ctx, filter, opts...)
+		return err
+	})
+	return cursor, err
+}
+
+// Watch guards opening the change stream. The stream resumes by itself once
+// after a transient failure, and fails after that.
+func (c *collection) Watch(ctx context.Context, pipeline interface{}, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	var stream *mongo.ChangeStream
+	err := c.guard.run(ctx, false, func(ctx context.Context) error {
+		var err error
+		stream, err = c.Collection.Watch(ctx, pipeline, opts...)
+		return err
+	})
+	return stream, err
+}
+
+func (c *collection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	return c.single(ctx, false, func(ctx context.Context) *mongo.SingleResult {
+		return c.Collection.FindOne(ctx, filter, opts...)
+	})
+}
+
+func (c *collection) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) *mongo.SingleResult {
+	return c.single(ctx, true, func(ctx context.Context) *mongo.SingleResult {
+		return c.Collection.FindOneAndDelete(ctx, filter, opts...)
+	})
+}
+
+// single guards an operation returning a single document. The document is
+// read before the operation context ends, and finding none is no failure.
+func (c *collection) single(ctx context.Context, write bool, op func(ctx context.Context) *mongo.SingleResult) *mongo.SingleResult {
+	var result *mongo.SingleResult
+	err := c.guard.run(ctx, write, func(ctx context.Context) error {
+		result = op(ctx)
+		if err := result.Err(); !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return result
+}
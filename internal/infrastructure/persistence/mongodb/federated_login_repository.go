@@ -0,0 +1,39 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type federatedLoginRepository struct {
+	collection *collection
+}
+
+func NewFederatedLoginRepository(db *Database) repositories.FederatedLoginRepository {
+	return &federatedLoginRepository{
+		collection: db.collection("federated_logins"),
+	}
+}
+
+func (r *federatedLoginRepository) Create(ctx context.Context, login *entities.FederatedLogin) error {
+	_, err := r.collection.InsertOne(ctx, login)
+	return err
+}
+
+func (r *federatedLoginRepository) Consume(ctx context.Context, stateHash string) (*entities.FederatedLogin, error) {
+	var login entities.FederatedLogin
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": stateHash}).Decode(&login)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrInvalidFederatedState
+		}
+		return nil, err
+	}
+	return &login, nil
+}
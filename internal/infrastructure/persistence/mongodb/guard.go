@@ -0,0 +1,282 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// BreakerState is the state of the circuit breaker of a Guard.
+type BreakerState int
+
+const (
+	// BreakerClosed lets operations through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails operations without trying them.
+	BreakerOpen
+	// BreakerHalfOpen lets a single operation through to probe whether
+	// MongoDB has recovered.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// GuardOptions configures a Guard. Zero values disable the matching
+// protection.
+type GuardOptions struct {
+	// OperationTimeout bounds every attempt of an operation, on top of the
+	// deadline of the caller.
+	OperationTimeout time.Duration
+	// MaxRetries is how many times an operation failing with a transient
+	// error is tried again. Writes are only tried again when MongoDB did not
+	// get to perform them.
+	MaxRetries int
+	// RetryBackoff is the base of the exponential backoff between retries.
+	// The actual wait is picked at random up to it, to spread retries out.
+	RetryBackoff time.Duration
+	// BreakerThreshold is how many transient failures in a row open the
+	// breaker, which then fails operations with ErrUnavailable for
+	// BreakerCooldown before letting a probe through.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// OnStateChange, when set, is called whenever the breaker changes state.
+	OnStateChange func(from, to BreakerState)
+}
+
+// Guard runs MongoDB operations with a timeout, retries transient failures
+// and stops sending operations to a MongoDB that keeps failing. A nil Guard
+// runs operations as they are.
+type Guard struct {
+	opts    GuardOptions
+	breaker *breaker
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewGuard creates a Guard.
+func NewGuard(opts GuardOptions) *Guard {
+	return &Guard{
+		opts: opts,
+		breaker: &breaker{
+			threshold: opts.BreakerThreshold,
+			cooldown:  opts.BreakerCooldown,
+			onChange:  opts.OnStateChange,
+			now:       time.Now,
+		},
+		sleep: sleep,
+	}
+}
+
+// State returns the current state of the breaker.
+func (g *Guard) State() BreakerState {
+	if g == nil {
+		return BreakerClosed
+	}
+	return g.breaker.current()
+}
+
+// run runs op, which is a write when write is set. Transient failures that
+// outlast the retries, and operations refused by an open breaker, fail with
+// ErrUnavailable.
+func (g *Guard) run(ctx context.Context, write bool, op func(ctx context.Context) error) error {
+	if g == nil {
+		return op(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if !g.breaker.allow() {
+			return domainErrors.ErrUnavailable
+		}
+
+		err := g.attempt(ctx, op)
+		switch {
+		case err == nil || !transient(err):
+			g.breaker.success()
+			return err
+		case ctx.Err() != nil:
+			// The caller gave up, which says nothing about MongoDB
+			g.breaker.abandon()
+			return err
+		}
+
+		g.breaker.failure()
+		if attempt >= g.opts.MaxRetries || (write && !unperformed(err)) {
+			return fmt.Errorf("%w: %w", domainErrors.ErrUnavailable, err)
+		}
+		if err := g.sleep(ctx, g.backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+func (g *Guard) attempt(ctx context.Context, op func(ctx context.Context) error) error {
+	if g.opts.OperationTimeout <= 0 {
+		return op(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, g.opts.OperationTimeout)
+	defer cancel()
+	return op(ctx)
+}
+
+// backoff picks the wait before retry attempt+1 at random between zero and
+// RetryBackoff doubled for every earlier attempt.
+func (g *Guard) backoff(attempt int) time.Duration {
+	limit := g.opts.RetryBackoff << min(attempt, 16)
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit + 1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// transient reports whether err may go away by itself: the network failed,
+// no server could be selected or the operation ran out of time.
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) || unperformed(err) {
+		return true
+	}
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorLabel("RetryableWriteError")
+}
+
+// unperformed reports whether err says the operation never reached a
+// server, so that a write can safely be tried again.
+func unperformed(err error) bool {
+	if errors.As(err, &topology.ServerSelectionError{}) || errors.As(err, &topology.WaitQueueTimeoutError{}) {
+		return true
+	}
+	var labeled mongo.LabeledError
+	return errors.As(err, &labeled) && labeled.HasErrorLabel("NoWritesPerformed")
+}
+
+// breaker opens after threshold failures in a row. After cooldown it lets a
+// single probe through, which closes it again when it succeeds.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	onChange  func(from, to BreakerState)
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *breaker) current() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *breaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	from := b.state
+	allowed := true
+	switch {
+	case b.state == BreakerOpen && b.now().Sub(b.openedAt) < b.cooldown:
+		allowed = false
+	case b.state == BreakerOpen:
+		b.state = BreakerHalfOpen
+		b.probing = true
+	case b.state == BreakerHalfOpen && b.probing:
+		allowed = false
+	case b.state == BreakerHalfOpen:
+		b.probing = true
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return allowed
+}
+
+func (b *breaker) success() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	from := b.state
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+	b.mu.Unlock()
+
+	b.notify(from, BreakerClosed)
+}
+
+func (b *breaker) failure() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	from := b.state
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.threshold) {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+// abandon frees the probe slot of an operation that ended without telling
+// whether MongoDB is healthy.
+func (b *breaker) abandon() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) notify(from, to BreakerState) {
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
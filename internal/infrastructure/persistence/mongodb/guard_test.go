@@ -0,0 +1,201 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+var (
+	errNetwork         = mongo.CommandError{Message: "connection reset", Labels: []string{"NetworkError"}}
+	errServerSelection = topology.ServerSelectionError{Wrapped: topology.ErrServerSelectionTimeout}
+	errDuplicate       = mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+)
+
+// newTestGuard returns a guard that does not wait between retries and whose
+// breaker reads the time from the returned clock.
+func newTestGuard(opts GuardOptions) (*Guard, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	guard := NewGuard(opts)
+	guard.breaker.now = func() time.Time { return now }
+	guard.sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	return guard, &now
+}
+
+// failing returns an operation failing with errs in turn and succeeding once
+// they run out, and the number of calls it got.
+func failing(errs ...error) (func(ctx context.Context) error, *int) {
+	calls := 0
+	return func(ctx context.Context) error {
+		calls++
+		if calls <= len(errs) {
+			return errs[calls-1]
+		}
+		return nil
+	}, &calls
+}
+
+func TestGuard_RetriesTransientReads(t *testing.T) {
+	guard, _ := newTestGuard(GuardOptions{MaxRetries: 2})
+	ctx := context.Background()
+
+	op, calls := failing(errNetwork, errNetwork)
+	assert.NoError(t, guard.run(ctx, false, op))
+	assert.Equal(t, 3, *calls)
+
+	op, calls = failing(errNetwork, errNetwork, errNetwork)
+	err := guard.run(ctx, false, op)
+	assert.ErrorIs(t, err, domainErrors.ErrUnavailable)
+	assert.ErrorAs(t, err, &mongo.CommandError{})
+	assert.Equal(t, 3, *calls)
+}
+
+func TestGuard_RetriesOnlyUnperformedWrites(t *testing.T) {
+	guard, _ := newTestGuard(GuardOptions{MaxRetries: 2})
+	ctx := context.Background()
+
+	// The write may have been applied before the connection broke
+	op, calls := failing(errNetwork)
+	assert.ErrorIs(t, guard.run(ctx, true, op), domainErrors.ErrUnavailable)
+	assert.Equal(t, 1, *calls)
+
+	op, calls = failing(errServerSelection)
+	assert.NoError(t, guard.run(ctx, true, op))
+	assert.Equal(t, 2, *calls)
+}
+
+func TestGuard_PassesOtherErrors(t *testing.T) {
+	guard, _ := newTestGuard(GuardOptions{MaxRetries: 2, BreakerThreshold: 1})
+	ctx := context.Background()
+
+	op, calls := failing(errDuplicate)
+	err := guard.run(ctx, true, op)
+	assert.Equal(t, errDuplicate, err)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, BreakerClosed, guard.State())
+}
+
+func TestGuard_OperationTimeout(t *testing.T) {
+	guard, _ := newTestGuard(GuardOptions{OperationTimeout: time.Minute})
+
+	err := guard.run(context.Background(), false, func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestGuard_Breaker(t *testing.T) {
+	var transitions []string
+	guard, now := newTestGuard(GuardOptions{
+		BreakerThreshold: 2,
+		BreakerCooldown:  10 * time.Second,
+		OnStateChange: func(from, to BreakerState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+	ctx := context.Background()
+
+	op, _ := failing(errNetwork, errNetwork)
+	assert.ErrorIs(t, guard.run(ctx, false, op), domainErrors.ErrUnavailable)
+	assert.Equal(t, BreakerClosed, guard.State())
+	assert.ErrorIs(t, guard.run(ctx, false, op), domainErrors.ErrUnavailable)
+	assert.Equal(t, BreakerOpen, guard.State())
+
+	// Open, operations fail without reaching MongoDB
+	op, calls := failing()
+	assert.ErrorIs(t, guard.run(ctx, false, op), domainErrors.ErrUnavailable)
+	assert.Equal(t, 0, *calls)
+
+	// After the cooldown a failing probe opens the breaker again
+	*now = now.Add(10 * time.Second)
+	op, calls = failing(errNetwork)
+	assert.ErrorIs(t, guard.run(ctx, false, op), domainErrors.ErrUnavailable)
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, BreakerOpen, guard.State())
+
+	// and a successful one closes it
+	*now = now.Add(10 * time.Second)
+	op, calls = failing()
+	assert.NoError(t, guard.run(ctx, false, op))
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, BreakerClosed, guard.State())
+
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}, transitions)
+}
+
+func TestGuard_BreakerProbesOnce(t *testing.T) {
+	guard, now := newTestGuard(GuardOptions{BreakerThreshold: 1, BreakerCooldown: time.Second})
+	ctx := context.Background()
+
+	op, _ := failing(errNetwork)
+	assert.ErrorIs(t, guard.run(ctx, false, op), domainErrors.ErrUnavailable)
+	*now = now.Add(time.Second)
+
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- guard.run(ctx, false, func(ctx context.Context) error {
+			close(probing)
+			<-release
+			return nil
+		})
+	}()
+	<-probing
+
+	// Only the probe gets through while it runs
+	op, calls := failing()
+	assert.ErrorIs(t, guard.run(ctx, false, op), domainErrors.ErrUnavailable)
+	assert.Equal(t, 0, *calls)
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, guard.run(ctx, false, op))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestGuard_CallerCancellationIsNoFailure(t *testing.T) {
+	guard, _ := newTestGuard(GuardOptions{MaxRetries: 2, BreakerThreshold: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := guard.run(ctx, false, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, domainErrors.ErrUnavailable)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, BreakerClosed, guard.State())
+}
+
+func TestGuard_Nil(t *testing.T) {
+	var guard *Guard
+
+	op, calls := failing(errNetwork)
+	assert.Equal(t, errNetwork, guard.run(context.Background(), false, op))
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, BreakerClosed, guard.State())
+}
+
+func TestCollection_FindOneUnavailable(t *testing.T) {
+	guard, _ := newTestGuard(GuardOptions{BreakerThreshold: 1, BreakerCooldown: time.Minute})
+	op, _ := failing(errNetwork)
+	assert.Error(t, guard.run(context.Background(), false, op))
+
+	// The breaker is open, so the collection is never used
+	users := &collection{guard: guard}
+	var document bson.M
+	err := users.FindOne(context.Background(), bson.M{}).Decode(&document)
+	assert.ErrorIs(t, err, domainErrors.ErrUnavailable)
+}
@@ -0,0 +1,86 @@
+package mongodb
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var idTypes = []reflect.Type{reflect.TypeOf(entities.UserID("")), reflect.TypeOf(entities.ID(""))}
+
+// newRegistry returns the default registry, storing IDs that are ObjectIDs as
+// ObjectIDs, as every record created before IDs were configurable or left the
+// driver's types behind has, and other IDs as strings. It applies to
+// filters as well, so an ID matches however it is stored.
+func newRegistry() *bsoncodec.Registry {
+	registry := bson.NewRegistry()
+	for _, idType := range idTypes {
+		registry.RegisterTypeEncoder(idType, bsoncodec.ValueEncoderFunc(encodeID))
+		registry.RegisterTypeDecoder(idType, bsoncodec.ValueDecoderFunc(decodeID))
+	}
+	return registry
+}
+
+func isIDType(t reflect.Type) bool {
+	for _, idType := range idTypes {
+		if t == idType {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeID(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || !isIDType(val.Type()) {
+		return bsoncodec.ValueEncoderError{Name: "encodeID", Types: idTypes, Received: val}
+	}
+
+	id := val.String()
+	if id == "" {
+		return vw.WriteNull()
+	}
+	if objectID, err := primitive.ObjectIDFromHex(id); err == nil {
+		return vw.WriteObjectID(objectID)
+	}
+	return vw.WriteString(id)
+}
+
+func decodeID(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || !isIDType(val.Type()) {
+		return bsoncodec.ValueDecoderError{Name: "decodeID", Types: idTypes, Received: val}
+	}
+
+	var id string
+	switch vr.Type() {
+	case bsontype.ObjectID:
+		objectID, err := vr.ReadObjectID()
+		if err != nil {
+			return err
+		}
+		// Unset references used to be stored as the zero ObjectID
+		if !objectID.IsZero() {
+			id = objectID.Hex()
+		}
+	case bsontype.String:
+		s, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		id = s
+	case bsontype.Null:
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot decode %v into %v", vr.Type(), val.Type())
+	}
+
+	val.SetString(id)
+	return nil
+}
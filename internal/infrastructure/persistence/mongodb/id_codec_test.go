@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUserIDCodec(t *testing.T) {
+	registry := newRegistry()
+	type document struct {
+		ID entities.UserID `bson:"id"`
+	}
+
+	tests := []struct {
+		name   string
+		id     entities.UserID
+		stored bsontype.Type
+	}{
+		{"ObjectID", entities.NewObjectIDUserID(), bsontype.ObjectID},
+		{"UUIDv7", entities.NewUUIDv7UserID(), bsontype.String},
+		{"ULID", entities.NewULIDUserID(), bsontype.String},
+		{"No user", "", bsontype.Null},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.MarshalWithRegistry(registry, document{ID: tt.id})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.stored, bson.Raw(data).Lookup("id").Type)
+
+			var decoded document
+			assert.NoError(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+			assert.Equal(t, tt.id, decoded.ID)
+		})
+	}
+
+	t.Run("Reads existing ObjectIDs", func(t *testing.T) {
+		objectID := primitive.NewObjectID()
+		for stored, want := range map[primitive.ObjectID]entities.UserID{
+			objectID:              entities.UserID(objectID.Hex()),
+			primitive.NilObjectID: "",
+		} {
+			data, err := bson.Marshal(bson.M{"id": stored})
+			assert.NoError(t, err)
+
+			var decoded document
+			assert.NoError(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+			assert.Equal(t, want, decoded.ID)
+		}
+	})
+}
+
+func TestIDCodec(t *testing.T) {
+	registry := newRegistry()
+	type document struct {
+		ID     entities.ID `bson:"_id"`
+		TeamID entities.ID `bson:"team_id,omitempty"`
+	}
+
+	id := entities.NewID()
+	data, err := bson.MarshalWithRegistry(registry, document{ID: id})
+	assert.NoError(t, err)
+	assert.Equal(t, bsontype.ObjectID, bson.Raw(data).Lookup("_id").Type)
+	_, err = bson.Raw(data).LookupErr("team_id")
+	assert.Error(t, err)
+
+	var decoded document
+	assert.NoError(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+	assert.Equal(t, document{ID: id}, decoded)
+
+	t.Run("Reads existing ObjectIDs", func(t *testing.T) {
+		objectID := primitive.NewObjectID()
+		data, err := bson.Marshal(bson.M{"_id": objectID, "team_id": primitive.NilObjectID})
+		assert.NoError(t, err)
+
+		var decoded document
+		assert.NoError(t, bson.UnmarshalWithRegistry(registry, data, &decoded))
+		assert.Equal(t, document{ID: entities.ID(objectID.Hex())}, decoded)
+	})
+}
@@ -0,0 +1,47 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type magicLinkRepository struct {
+	collection *collection
+}
+
+func NewMagicLinkRepository(db *Database) repositories.MagicLinkRepository {
+	return &magicLinkRepository{
+		collection: db.collection("magic_links"),
+	}
+}
+
+func (r *magicLinkRepository) Create(ctx context.Context, link *entities.MagicLink) error {
+	_, err := r.collection.InsertOne(ctx, link)
+	return err
+}
+
+func (r *magicLinkRepository) CountSince(ctx context.Context, email string, since time.Time) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"email":      email,
+		"created_at": bson.M{"$gte": since},
+	})
+}
+
+func (r *magicLinkRepository) Consume(ctx context.Context, tokenHash string) (*entities.MagicLink, error) {
+	var link entities.MagicLink
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": tokenHash}).Decode(&link)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+	return &link, nil
+}
@@ -0,0 +1,393 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrations is the schema of the database, oldest first, for the email
+// policy users are told apart by. Append new migrations with the next
+// version; never change one that has been released.
+func Migrations(emails entities.EmailPolicy) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "set default tenant",
+			Up:          setDefaultTenant,
+			Down:        unsetDefaultTenant,
+		},
+		{
+			Version:     2,
+			Description: "drop indexes from before tenants",
+			Up:          dropIndexes(preTenantIndexes),
+			Down:        createIndexes(preTenantIndexes),
+		},
+		{
+			Version:     3,
+			Description: "create indexes",
+			Up:          createIndexes(initialIndexes),
+			Down:        dropIndexes(initialIndexes),
+		},
+		{
+			Version:     4,
+			Description: "normalize emails",
+			Up:          normalizeEmails(emails),
+			Down:        denormalizeEmails,
+		},
+	}
+}
+
+// collectionIndexes are the indexes of one collection.
+type collectionIndexes struct {
+	collection string
+	indexes    []mongo.IndexModel
+}
+
+// preTenantIndexes are the indexes scripts/mongo-init.js created on users
+// before there were tenants. Their unique emails and identities span all
+// tenants, so they must go before users of several tenants can share them.
+var preTenantIndexes = []collectionIndexes{
+	{"users", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+	}},
+}
+
+// initialIndexes are the indexes of every collection, under the default
+// names MongoDB gives them.
+var initialIndexes = []collectionIndexes{
+	{"users", []mongo.IndexModel{
+		// Every query is scoped to a tenant, and emails are only unique
+		// within one
+		emailIndex,
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		// Accounts at external identity providers link to at most one user
+		// per tenant
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"identities.provider": bson.M{"$exists": true}}),
+		},
+	}},
+	{"api_keys", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "prefix", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	}},
+	{"oauth_clients", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "client_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+	}},
+	{"authorization_codes", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}},
+	{"federated_logins", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}},
+	{"passkeys", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	}},
+	{"passkey_ceremonies", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}},
+	{"sessions", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}},
+	{"audit_events", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subject_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	}},
+	{"organizations", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}}},
+	}},
+	{"teams", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "name", Value: 1}}},
+	}},
+	{"memberships", []mongo.IndexModel{
+		// A user is a member of an organization or team at most once
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "team_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "team_id", Value: 1}}},
+	}},
+	{"user_invitations", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		invitationEmailIndex,
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	}},
+	{"magic_links", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}},
+}
+
+var (
+	// emailIndex made emails unique per tenant as they were typed.
+	emailIndex = mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	// normalizedEmailIndex makes them unique per tenant under the email
+	// policy instead.
+	normalizedEmailIndex = mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "email_normalized", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	// invitationEmailIndex found invitations by their email as typed, and
+	// normalizedInvitationEmailIndex by its key under the email policy.
+	invitationEmailIndex = mongo.IndexModel{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "email", Value: 1}},
+	}
+	normalizedInvitationEmailIndex = mongo.IndexModel{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "email_normalized", Value: 1}},
+	}
+)
+
+// setDefaultTenant moves users stored before there were tenants into the
+// default one. Every query is scoped to a tenant, so without a tenant_id
+// they could never be found, and the unique indexes per tenant would treat
+// them as a tenant of their own.
+func setDefaultTenant(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("users").UpdateMany(ctx,
+		bson.M{"tenant_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"tenant_id": entities.DefaultTenant}})
+	return err
+}
+
+// unsetDefaultTenant takes the users of the default tenant out of it again,
+// as they were before there were tenants.
+func unsetDefaultTenant(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("users").UpdateMany(ctx,
+		bson.M{"tenant_id": entities.DefaultTenant},
+		bson.M{"$unset": bson.M{"tenant_id": ""}})
+	return err
+}
+
+// EmailCollisionError lists users of one tenant whose emails are distinct
+// but have the same key under the email policy. They must be merged or
+// renamed by hand before the emails can be normalized.
+type EmailCollisionError struct {
+	TenantID string
+	Key      string
+	Users    []EmailCollisionUser
+}
+
+// EmailCollisionUser is one of the colliding users.
+type EmailCollisionUser struct {
+	ID    entities.UserID
+	Email string
+}
+
+func (e *EmailCollisionError) Error() string {
+	users := make([]string, 0, len(e.Users))
+	for _, user := range e.Users {
+		users = append(users, fmt.Sprintf("%s (%s)", user.Email, user.ID.String()))
+	}
+	return fmt.Sprintf("tenant %q has several users for email %s: %s", e.TenantID, e.Key, strings.Join(users, ", "))
+}
+
+// EmailCollisionsError are all the collisions found in the users collection.
+type EmailCollisionsError []*EmailCollisionError
+
+func (e EmailCollisionsError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, collision := range e {
+		messages = append(messages, collision.Error())
+	}
+	return "emails collide under the email policy; merge or rename these users and migrate again:\n" + strings.Join(messages, "\n")
+}
+
+// normalizeEmails stores the key of every user's email under the policy in
+// email_normalized and makes that unique per tenant instead of the email as
+// typed. It changes nothing when users collide, failing with an
+// EmailCollisionsError listing them. Invitations get the keys of their
+// emails as well, to be found by them.
+func normalizeEmails(policy entities.EmailPolicy) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		users := db.Collection("users")
+		cursor, err := users.Find(ctx, bson.M{}, options.Find().
+			SetProjection(bson.M{"tenant_id": 1, "email": 1}).
+			SetSort(bson.D{{Key: "_id", Value: 1}}))
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		type tenantKey struct{ tenantID, key string }
+		byKey := make(map[tenantKey][]EmailCollisionUser)
+		var order []tenantKey
+		for cursor.Next(ctx) {
+			var user struct {
+				ID       entities.UserID `bson:"_id"`
+				TenantID string          `bson:"tenant_id"`
+				Email    string          `bson:"email"`
+			}
+			if err := cursor.Decode(&user); err != nil {
+				return err
+			}
+			k := tenantKey{user.TenantID, policy.Key(user.Email)}
+			if _, ok := byKey[k]; !ok {
+				order = append(order, k)
+			}
+			byKey[k] = append(byKey[k], EmailCollisionUser{ID: user.ID, Email: user.Email})
+		}
+		if err := cursor.Err(); err != nil {
+			return err
+		}
+
+		var collisions EmailCollisionsError
+		for _, k := range order {
+			if len(byKey[k]) > 1 {
+				collisions = append(collisions, &EmailCollisionError{TenantID: k.tenantID, Key: k.key, Users: byKey[k]})
+			}
+		}
+		if len(collisions) > 0 {
+			sort.SliceStable(collisions, func(i, j int) bool {
+				return collisions[i].TenantID < collisions[j].TenantID
+			})
+			return collisions
+		}
+
+		for _, k := range order {
+			for _, user := range byKey[k] {
+				_, err := users.UpdateByID(ctx, user.ID, bson.M{"$set": bson.M{
+					"email":            entities.NormalizeEmail(user.Email),
+					"email_normalized": k.key,
+				}})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if _, err := users.Indexes().CreateOne(ctx, normalizedEmailIndex); err != nil {
+			return fmt.Errorf("create email index: %w", err)
+		}
+		_, err = users.Indexes().DropOne(ctx, indexName(emailIndex.Keys.(bson.D)))
+		if err != nil && !isIndexNotFound(err) {
+			return fmt.Errorf("drop email index: %w", err)
+		}
+		return normalizeInvitationEmails(ctx, db, policy)
+	}
+}
+
+// normalizeInvitationEmails stores the key of every invitation's email under
+// the policy in email_normalized. Unlike users, invitations may share keys.
+func normalizeInvitationEmails(ctx context.Context, db *mongo.Database, policy entities.EmailPolicy) error {
+	invitations := db.Collection("user_invitations")
+	cursor, err := invitations.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"email": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var invitation struct {
+			ID    interface{} `bson:"_id"`
+			Email string      `bson:"email"`
+		}
+		if err := cursor.Decode(&invitation); err != nil {
+			return err
+		}
+		_, err := invitations.UpdateByID(ctx, invitation.ID, bson.M{"$set": bson.M{"email_normalized": policy.Key(invitation.Email)}})
+		if err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if _, err := invitations.Indexes().CreateOne(ctx, normalizedInvitationEmailIndex); err != nil {
+		return fmt.Errorf("create invitation email index: %w", err)
+	}
+	_, err = invitations.Indexes().DropOne(ctx, indexName(invitationEmailIndex.Keys.(bson.D)))
+	if err != nil && !isIndexNotFound(err) {
+		return fmt.Errorf("drop invitation email index: %w", err)
+	}
+	return nil
+}
+
+// denormalizeEmails makes emails unique as typed again. Emails keep their
+// lowercased domains.
+func denormalizeEmails(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+	if _, err := users.Indexes().CreateOne(ctx, emailIndex); err != nil {
+		return fmt.Errorf("create email index: %w", err)
+	}
+	_, err := users.Indexes().DropOne(ctx, indexName(normalizedEmailIndex.Keys.(bson.D)))
+	if err != nil && !isIndexNotFound(err) {
+		return fmt.Errorf("drop email index: %w", err)
+	}
+	if _, err = users.UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"email_normalized": ""}}); err != nil {
+		return err
+	}
+
+	invitations := db.Collection("user_invitations")
+	if _, err := invitations.Indexes().CreateOne(ctx, invitationEmailIndex); err != nil {
+		return fmt.Errorf("create invitation email index: %w", err)
+	}
+	_, err = invitations.Indexes().DropOne(ctx, indexName(normalizedInvitationEmailIndex.Keys.(bson.D)))
+	if err != nil && !isIndexNotFound(err) {
+		return fmt.Errorf("drop invitation email index: %w", err)
+	}
+	_, err = invitations.UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"email_normalized": ""}})
+	return err
+}
+
+// createIndexes creates the indexes, which is a no-op for those that exist.
+func createIndexes(all []collectionIndexes) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, c := range all {
+			if _, err := db.Collection(c.collection).Indexes().CreateMany(ctx, c.indexes); err != nil {
+				return fmt.Errorf("create indexes of %s: %w", c.collection, err)
+			}
+		}
+		return nil
+	}
+}
+
+// dropIndexes drops the indexes, skipping those that are already gone.
+func dropIndexes(all []collectionIndexes) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, c := range all {
+			for _, index := range c.indexes {
+				_, err := db.Collection(c.collection).Indexes().DropOne(ctx, indexName(index.Keys.(bson.D)))
+				if err != nil && !isIndexNotFound(err) {
+					return fmt.Errorf("drop indexes of %s: %w", c.collection, err)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// indexName returns the name MongoDB gives an index with these keys by
+// default, as in tenant_id_1_email_1.
+func indexName(keys bson.D) string {
+	parts := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// isIndexNotFound reports whether the index, or its whole collection, does
+// not exist.
+func isIndexNotFound(err error) bool {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.Code == 26 || commandErr.Code == 27 // NamespaceNotFound, IndexNotFound
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	migrationLockID = "schema_migrations"
+	// migrationLockTTL bounds how long a crashed instance keeps others from
+	// migrating. The lock is renewed after every migration, so a single
+	// migration must finish within it.
+	migrationLockTTL = 10 * time.Minute
+	// migrationLockRetry is how often an instance checks whether the lock
+	// holder is done.
+	migrationLockRetry = time.Second
+)
+
+// ErrMigrationLockLost is returned when the lock expired while migrating and
+// another instance may have taken over.
+var ErrMigrationLockLost = errors.New("lost the schema migration lock")
+
+// Migration changes the schema from the previous version to Version. MongoDB
+// cannot change the schema in a transaction, so Up and Down must be safe to
+// run again after failing halfway. Down may be nil for migrations that cannot
+// be undone.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// MigrationStatus is a known migration and, once applied, when it was.
+type MigrationStatus struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// appliedMigration is a migration recorded in schema_migrations.
+type appliedMigration struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Migrator applies migrations in order of their versions and records them in
+// the schema_migrations collection. Instances sharing a database take turns
+// through a lock in schema_migrations_lock, so migrations run once however
+// many instances start at the same time.
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+	applied    *mongo.Collection
+	locks      *mongo.Collection
+	owner      string
+}
+
+func NewMigrator(db *mongo.Database, migrations []Migration) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+		applied:    db.Collection("schema_migrations"),
+		locks:      db.Collection("schema_migrations_lock"),
+		owner:      primitive.NewObjectID().Hex(),
+	}
+}
+
+// Up applies every migration that has not been applied yet and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := migration.Up(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		_, err := m.applied.InsertOne(ctx, appliedMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+
+		if err := m.renew(ctx); err != nil {
+			return done, err
+		}
+	}
+
+	return done, nil
+}
+
+// Down undoes the last steps applied migrations, newest first, and returns
+// them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == nil {
+			return done, fmt.Errorf("migration %d (%s) cannot be undone", migration.Version, migration.Description)
+		}
+
+		if err := migration.Down(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		if _, err := m.applied.DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+
+		if err := m.renew(ctx); err != nil {
+			return done, err
+		}
+	}
+
+	return done, nil
+}
+
+// Status lists the known migrations in order and whether they are applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+		}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int]appliedMigration, error) {
+	cursor, err := m.applied.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	applied := make(map[int]appliedMigration)
+	for cursor.Next(ctx) {
+		var record appliedMigration
+		if err := cursor.Decode(&record); err != nil {
+			return nil, err
+		}
+		applied[record.Version] = record
+	}
+
+	return applied, cursor.Err()
+}
+
+// lock waits until no other instance holds the migration lock, or its hold
+// has expired, and takes it. The returned function releases it.
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	for {
+		now := time.Now()
+		// The filter only matches an expired lock. A lock that is still held
+		// makes the upsert insert a second document with the same _id, which
+		// fails.
+		_, err := m.locks.UpdateOne(ctx,
+			bson.M{"_id": migrationLockID, "expires_at": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"owner": m.owner, "expires_at": now.Add(migrationLockTTL)}},
+			options.Update().SetUpsert(true),
+		)
+		if err == nil {
+			return func() {
+				_, _ = m.locks.DeleteOne(context.Background(), bson.M{"_id": migrationLockID, "owner": m.owner})
+			}, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(migrationLockRetry):
+		}
+	}
+}
+
+// renew extends the hold on the lock after a migration.
+func (m *Migrator) renew(ctx context.Context) error {
+	result, err := m.locks.UpdateOne(ctx,
+		bson.M{"_id": migrationLockID, "owner": m.owner},
+		bson.M{"$set": bson.M{"expires_at": time.Now().Add(migrationLockTTL)}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrMigrationLockLost
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestMigrations_AreOrdered(t *testing.T) {
+	for i, migration := range Migrations(entities.EmailPolicy{}) {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Description)
+		assert.NotNil(t, migration.Up)
+	}
+}
+
+func TestIndexName(t *testing.T) {
+	assert.Equal(t, "tenant_id_1_email_1", indexName(bson.D{{Key: "tenant_id", Value: 1}, {Key: "email", Value: 1}}))
+	assert.Equal(t, "subject_id_1_created_at_-1", indexName(bson.D{{Key: "subject_id", Value: 1}, {Key: "created_at", Value: -1}}))
+}
+
+func TestMigrator_UpAndDown(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	migrations := Migrations(entities.EmailPolicy{})
+	migrator := NewMigrator(db, migrations)
+
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(migrations))
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.False(t, status.AppliedAt.IsZero())
+	}
+
+	// The unique email index is in place
+	users := db.Collection("users")
+	_, err = users.InsertOne(ctx, bson.M{"tenant_id": "default", "email": "test@example.com", "email_normalized": "test@example.com"})
+	assert.NoError(t, err)
+	_, err = users.InsertOne(ctx, bson.M{"tenant_id": "default", "email": "Test@example.com", "email_normalized": "test@example.com"})
+	assert.True(t, mongo.IsDuplicateKeyError(err))
+
+	applied, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	undone, err := migrator.Down(ctx, len(migrations))
+	assert.NoError(t, err)
+	assert.Len(t, undone, len(migrations))
+
+	statuses, err = migrator.Status(ctx)
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+	}
+
+	// Emails are unique across tenants again, as before there were tenants
+	_, err = users.InsertOne(ctx, bson.M{"tenant_id": "acme", "email": "other@example.com"})
+	assert.NoError(t, err)
+	_, err = users.InsertOne(ctx, bson.M{"tenant_id": "globex", "email": "other@example.com"})
+	assert.True(t, mongo.IsDuplicateKeyError(err))
+}
+
+func TestMigrations_NormalizeEmails(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	migrations := Migrations(entities.EmailPolicy{})
+	users := db.Collection("users")
+
+	_, err := NewMigrator(db, migrations[:3]).Up(ctx)
+	assert.NoError(t, err)
+	bob, err := users.InsertOne(ctx, bson.M{"tenant_id": "acme", "email": "Bob@Example.com"})
+	assert.NoError(t, err)
+	_, err = users.InsertOne(ctx, bson.M{"tenant_id": "globex", "email": "bob@example.com"})
+	assert.NoError(t, err)
+	twin, err := users.InsertOne(ctx, bson.M{"tenant_id": "acme", "email": "bob@example.com"})
+	assert.NoError(t, err)
+	invitation, err := db.Collection("user_invitations").InsertOne(ctx, bson.M{"tenant_id": "acme", "email": "Dana@example.com"})
+	assert.NoError(t, err)
+
+	// Colliding users are reported and nothing changes
+	_, err = NewMigrator(db, migrations).Up(ctx)
+	var collisions EmailCollisionsError
+	if assert.ErrorAs(t, err, &collisions) && assert.Len(t, collisions, 1) {
+		assert.Equal(t, "acme", collisions[0].TenantID)
+		assert.Equal(t, "bob@example.com", collisions[0].Key)
+		assert.Len(t, collisions[0].Users, 2)
+	}
+	count, err := users.CountDocuments(ctx, bson.M{"email_normalized": bson.M{"$exists": true}})
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	// Once they are resolved, the emails are normalized
+	_, err = users.DeleteOne(ctx, bson.M{"_id": twin.InsertedID})
+	assert.NoError(t, err)
+	_, err = NewMigrator(db, migrations).Up(ctx)
+	assert.NoError(t, err)
+
+	var user bson.M
+	assert.NoError(t, users.FindOne(ctx, bson.M{"_id": bob.InsertedID}).Decode(&user))
+	assert.Equal(t, "Bob@example.com", user["email"])
+	assert.Equal(t, "bob@example.com", user["email_normalized"])
+
+	// Invitations are found by the key of their email too
+	var invited bson.M
+	assert.NoError(t, db.Collection("user_invitations").FindOne(ctx, bson.M{"_id": invitation.InsertedID}).Decode(&invited))
+	assert.Equal(t, "dana@example.com", invited["email_normalized"])
+}
+
+func TestMigrations_FromBeforeTenants(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	migrations := Migrations(entities.EmailPolicy{})
+	users := db.Collection("users")
+
+	indexNames := func() []string {
+		cursor, err := users.Indexes().List(ctx)
+		assert.NoError(t, err)
+		var indexes []struct {
+			Name string `bson:"name"`
+		}
+		assert.NoError(t, cursor.All(ctx, &indexes))
+		names := make([]string, 0, len(indexes))
+		for _, index := range indexes {
+			names = append(names, index.Name)
+		}
+		return names
+	}
+
+	// As set up by the original scripts/mongo-init.js
+	_, err := users.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+	})
+	assert.NoError(t, err)
+	admin, err := users.InsertOne(ctx, bson.M{"name": "Admin User", "email": "admin@example.com", "password": "hash"})
+	assert.NoError(t, err)
+
+	_, err = NewMigrator(db, migrations).Up(ctx)
+	assert.NoError(t, err)
+	assert.NotContains(t, indexNames(), "email_1")
+	assert.NotContains(t, indexNames(), "created_at_1")
+	assert.Contains(t, indexNames(), "tenant_id_1_email_normalized_1")
+
+	var user bson.M
+	assert.NoError(t, users.FindOne(ctx, bson.M{"_id": admin.InsertedID}).Decode(&user))
+	assert.Equal(t, entities.DefaultTenant, user["tenant_id"])
+	assert.Equal(t, "admin@example.com", user["email_normalized"])
+
+	// The email is only unique within a tenant now
+	other, err := users.InsertOne(ctx, bson.M{"tenant_id": "acme", "email": "admin@example.com", "email_normalized": "admin@example.com"})
+	assert.NoError(t, err)
+	_, err = users.DeleteOne(ctx, bson.M{"_id": other.InsertedID})
+	assert.NoError(t, err)
+
+	// Undoing everything restores the original database
+	_, err = NewMigrator(db, migrations).Down(ctx, len(migrations))
+	assert.NoError(t, err)
+	assert.Contains(t, indexNames(), "email_1")
+	assert.Contains(t, indexNames(), "created_at_1")
+	assert.NotContains(t, indexNames(), "tenant_id_1_email_1")
+	user = nil
+	assert.NoError(t, users.FindOne(ctx, bson.M{"_id": admin.InsertedID}).Decode(&user))
+	assert.NotContains(t, user, "tenant_id")
+}
+
+func TestMigrator_RunsOnceAcrossInstances(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	var runs atomic.Int32
+	migrations := []Migration{
+		{Version: 2, Description: "second", Up: func(ctx context.Context, db *mongo.Database) error {
+			runs.Add(1)
+			return nil
+		}},
+		{Version: 1, Description: "first", Up: func(ctx context.Context, db *mongo.Database) error {
+			assert.Equal(t, int32(0), runs.Load())
+			runs.Add(1)
+			return nil
+		}},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := NewMigrator(db, migrations).Up(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), runs.Load())
+
+	count, err := db.Collection("schema_migrations_lock").CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = NewMigrator(db, migrations).Down(ctx, 1)
+	assert.ErrorContains(t, err, "cannot be undone")
+}
@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type oauthClientRepository struct {
+	collection *collection
+}
+
+func NewOAuthClientRepository(db *Database) repositories.OAuthClientRepository {
+	return &oauthClientRepository{
+		collection: db.collection("oauth_clients"),
+	}
+}
+
+func (r *oauthClientRepository) Create(ctx context.Context, client *entities.OAuthClient) error {
+	_, err := r.collection.InsertOne(ctx, client)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domainErrors.ErrOAuthClientExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *oauthClientRepository) GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	var client entities.OAuthClient
+	err := r.collection.FindOne(ctx, bson.M{"client_id": clientID}).Decode(&client)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+type authorizationCodeRepository struct {
+	collection *collection
+}
+
+func NewAuthorizationCodeRepository(db *Database) repositories.AuthorizationCodeRepository {
+	return &authorizationCodeRepository{
+		collection: db.collection("authorization_codes"),
+	}
+}
+
+func (r *authorizationCodeRepository) Create(ctx context.Context, code *entities.AuthorizationCode) error {
+	_, err := r.collection.InsertOne(ctx, code)
+	return err
+}
+
+func (r *authorizationCodeRepository) Consume(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error) {
+	var code entities.AuthorizationCode
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": codeHash}).Decode(&code)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrAuthorizationCodeNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
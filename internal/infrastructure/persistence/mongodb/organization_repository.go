@@ -0,0 +1,292 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type organizationRepository struct {
+	collection *collection
+}
+
+func NewOrganizationRepository(db *Database) repositories.OrganizationRepository {
+	return &organizationRepository{
+		collection: db.collection("organizations"),
+	}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, organization *entities.Organization) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	organization.TenantID = tenantID
+
+	_, err := r.collection.InsertOne(ctx, organization)
+	return err
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Organization, error) {
+	filter, err := tenantFilter(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	var organization entities.Organization
+	err = r.collection.FindOne(ctx, filter).Decode(&organization)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (r *organizationRepository) Update(ctx context.Context, organization *entities.Organization) error {
+	filter, err := tenantFilter(ctx, bson.M{"_id": organization.ID})
+	if err != nil {
+		return err
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"name":       organization.Name,
+			"updated_at": organization.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domainErrors.ErrOrganizationNotFound
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) Delete(ctx context.Context, id entities.ID) error {
+	filter, err := tenantFilter(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domainErrors.ErrOrganizationNotFound
+	}
+
+	return nil
+}
+
+type teamRepository struct {
+	collection *collection
+}
+
+func NewTeamRepository(db *Database) repositories.TeamRepository {
+	return &teamRepository{
+		collection: db.collection("teams"),
+	}
+}
+
+func (r *teamRepository) Create(ctx context.Context, team *entities.Team) error {
+	_, err := r.collection.InsertOne(ctx, team)
+	return err
+}
+
+func (r *teamRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Team, error) {
+	var team entities.Team
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&team)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrTeamNotFound
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Team, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"organization_id": organizationID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var teams []*entities.Team
+	for cursor.Next(ctx) {
+		var team entities.Team
+		if err := cursor.Decode(&team); err != nil {
+			return nil, err
+		}
+		teams = append(teams, &team)
+	}
+
+	return teams, cursor.Err()
+}
+
+func (r *teamRepository) Update(ctx context.Context, team *entities.Team) error {
+	filter := bson.M{"_id": team.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":       team.Name,
+			"updated_at": team.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domainErrors.ErrTeamNotFound
+	}
+
+	return nil
+}
+
+func (r *teamRepository) Delete(ctx context.Context, id entities.ID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domainErrors.ErrTeamNotFound
+	}
+
+	return nil
+}
+
+func (r *teamRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"organization_id": organizationID})
+	return err
+}
+
+type membershipRepository struct {
+	collection *collection
+}
+
+func NewMembershipRepository(db *Database) repositories.MembershipRepository {
+	return &membershipRepository{
+		collection: db.collection("memberships"),
+	}
+}
+
+func (r *membershipRepository) Create(ctx context.Context, membership *entities.Membership) error {
+	_, err := r.collection.InsertOne(ctx, membership)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domainErrors.ErrMembershipExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *membershipRepository) Get(ctx context.Context, organizationID, teamID entities.ID, userID entities.UserID) (*entities.Membership, error) {
+	// Organization memberships have no team_id, which null matches
+	filter := bson.M{"organization_id": organizationID, "team_id": nil, "user_id": userID}
+	if !teamID.IsZero() {
+		filter["team_id"] = teamID
+	}
+
+	var membership entities.Membership
+	err := r.collection.FindOne(ctx, filter).Decode(&membership)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrMembershipNotFound
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (r *membershipRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Membership, error) {
+	return r.find(ctx, bson.M{"organization_id": organizationID})
+}
+
+func (r *membershipRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Membership, error) {
+	return r.find(ctx, bson.M{"user_id": userID})
+}
+
+func (r *membershipRepository) find(ctx context.Context, filter bson.M) ([]*entities.Membership, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var memberships []*entities.Membership
+	for cursor.Next(ctx) {
+		var membership entities.Membership
+		if err := cursor.Decode(&membership); err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, &membership)
+	}
+
+	return memberships, cursor.Err()
+}
+
+func (r *membershipRepository) Update(ctx context.Context, membership *entities.Membership) error {
+	filter := bson.M{"_id": membership.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"role":       membership.Role,
+			"status":     membership.Status,
+			"updated_at": membership.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domainErrors.ErrMembershipNotFound
+	}
+
+	return nil
+}
+
+func (r *membershipRepository) Delete(ctx context.Context, id entities.ID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domainErrors.ErrMembershipNotFound
+	}
+
+	return nil
+}
+
+func (r *membershipRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"organization_id": organizationID})
+	return err
+}
+
+func (r *membershipRepository) DeleteByTeamID(ctx context.Context, teamID entities.ID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"team_id": teamID})
+	return err
+}
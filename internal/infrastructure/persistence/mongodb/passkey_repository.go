@@ -0,0 +1,129 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type passkeyRepository struct {
+	collection *collection
+}
+
+func NewPasskeyRepository(db *Database) repositories.PasskeyRepository {
+	return &passkeyRepository{
+		collection: db.collection("passkeys"),
+	}
+}
+
+func (r *passkeyRepository) Create(ctx context.Context, passkey *entities.Passkey) error {
+	_, err := r.collection.InsertOne(ctx, passkey)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domainErrors.ErrPasskeyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *passkeyRepository) GetByID(ctx context.Context, id string) (*entities.Passkey, error) {
+	var passkey entities.Passkey
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&passkey)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrPasskeyNotFound
+		}
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+func (r *passkeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Passkey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var passkeys []*entities.Passkey
+	for cursor.Next(ctx) {
+		var passkey entities.Passkey
+		if err := cursor.Decode(&passkey); err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, &passkey)
+	}
+
+	return passkeys, cursor.Err()
+}
+
+func (r *passkeyRepository) Update(ctx context.Context, passkey *entities.Passkey) error {
+	filter := bson.M{"_id": passkey.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"name":         passkey.Name,
+			"sign_count":   passkey.SignCount,
+			"backup_state": passkey.BackupState,
+			"last_used_at": passkey.LastUsedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domainErrors.ErrPasskeyNotFound
+	}
+
+	return nil
+}
+
+func (r *passkeyRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return domainErrors.ErrPasskeyNotFound
+	}
+
+	return nil
+}
+
+type passkeyCeremonyRepository struct {
+	collection *collection
+}
+
+func NewPasskeyCeremonyRepository(db *Database) repositories.PasskeyCeremonyRepository {
+	return &passkeyCeremonyRepository{
+		collection: db.collection("passkey_ceremonies"),
+	}
+}
+
+func (r *passkeyCeremonyRepository) Create(ctx context.Context, ceremony *entities.PasskeyCeremony) error {
+	_, err := r.collection.InsertOne(ctx, ceremony)
+	return err
+}
+
+func (r *passkeyCeremonyRepository) Consume(ctx context.Context, challengeHash string) (*entities.PasskeyCeremony, error) {
+	var ceremony entities.PasskeyCeremony
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": challengeHash}).Decode(&ceremony)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrInvalidPasskeyChallenge
+		}
+		return nil, err
+	}
+	return &ceremony, nil
+}
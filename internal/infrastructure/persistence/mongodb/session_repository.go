@@ -0,0 +1,81 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type sessionRepository struct {
+	collection *collection
+}
+
+func NewSessionRepository(db *Database) repositories.SessionRepository {
+	return &sessionRepository{
+		collection: db.collection("sessions"),
+	}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Session, error) {
+	var session entities.Session
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Session, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*entities.Session
+	for cursor.Next(ctx) {
+		var session entities.Session
+		if err := cursor.Decode(&session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, cursor.Err()
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *entities.Session) error {
+	filter := bson.M{"_id": session.ID}
+	update := bson.M{
+		"$set": bson.M{
+			"last_seen_at": session.LastSeenAt,
+			"revoked_at":   session.RevokedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domainErrors.ErrSessionNotFound
+	}
+
+	return nil
+}
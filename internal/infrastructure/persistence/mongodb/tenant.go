@@ -0,0 +1,24 @@
+package mongodb
+
+import (
+	"context"
+
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// tenantFilter adds the tenant of ctx to filter, so that no query can reach
+// documents of another tenant.
+func tenantFilter(ctx context.Context, filter bson.M) (bson.M, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	scoped := bson.M{"tenant_id": tenantID}
+	for key, value := range filter {
+		scoped[key] = value
+	}
+	return scoped, nil
+}
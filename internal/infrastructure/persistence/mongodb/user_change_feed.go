@@ -0,0 +1,193 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Server error codes saying a change stream cannot resume from a token
+const (
+	codeInvalidResumeToken      = 260
+	codeChangeStreamFatalError  = 280
+	codeChangeStreamHistoryLost = 286
+)
+
+// UserChangeFeed watches the users collection with a change stream, so it
+// sees the changes of every process sharing the database. Change streams
+// need a replica set or a sharded cluster. Deletions only say which user was
+// deleted, so they are only reported when the collection records pre-images
+// (MongoDB 6.0 and later), which EnablePreImages turns on.
+//
+// Event IDs are resume tokens, valid as long as the oplog holds the event.
+// The last token a consumer handled is kept in user_feed_positions.
+type UserChangeFeed struct {
+	db        *Database
+	users     *collection
+	positions *collection
+}
+
+func NewUserChangeFeed(db *Database) *UserChangeFeed {
+	return &UserChangeFeed{
+		db:        db,
+		users:     db.collection("users"),
+		positions: db.collection("user_feed_positions"),
+	}
+}
+
+// EnablePreImages makes the users collection record the documents as they
+// were before each change, which tells whose user a deletion removed.
+func (f *UserChangeFeed) EnablePreImages(ctx context.Context) error {
+	return f.db.db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: "users"},
+		{Key: "changeStreamPreAndPostImages", Value: bson.M{"enabled": true}},
+	}).Err()
+}
+
+// userChange is the part of a change event of the users collection the feed
+// reads.
+type userChange struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID entities.UserID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *userDocument       `bson:"fullDocument"`
+	ClusterTime  primitive.Timestamp `bson:"clusterTime"`
+	WallTime     time.Time           `bson:"wallTime"`
+}
+
+// operationTypes are the change stream operations of each event type.
+var operationTypes = map[entities.UserEventType][]string{
+	entities.UserCreated: {"insert"},
+	entities.UserUpdated: {"update", "replace"},
+	entities.UserDeleted: {"delete"},
+}
+
+func (f *UserChangeFeed) Watch(ctx context.Context, watch services.UserWatch, handle func(*entities.UserEvent) error) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	positionID := tenantID + ":" + watch.Consumer
+
+	after := watch.After
+	if after == "" && watch.Consumer != "" {
+		var position struct {
+			EventID string `bson:"event_id"`
+		}
+		err := f.positions.FindOne(ctx, bson.M{"_id": positionID}).Decode(&position)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		after = position.EventID
+	}
+
+	types := watch.Types
+	if len(types) == 0 {
+		types = entities.UserEventTypes
+	}
+	var operations []string
+	for _, t := range types {
+		operations = append(operations, operationTypes[t]...)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": operations},
+		"$or": bson.A{
+			bson.M{"fullDocument.tenant_id": tenantID},
+			bson.M{"fullDocumentBeforeChange.tenant_id": tenantID},
+		},
+	}}}}
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+	if after != "" {
+		if _, err := hex.DecodeString(after); err != nil {
+			return domainErrors.ErrInvalidUserEventID
+		}
+		opts.SetStartAfter(bson.D{{Key: "_data", Value: after}})
+	}
+
+	stream, err := f.users.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return f.streamError(ctx, err)
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change userChange
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+		id, _ := stream.ResumeToken().Lookup("_data").StringValueOK()
+
+		event := change.event(id, tenantID)
+		// An update looks the user up when it reports the change, and finds
+		// none when it was deleted since. The deletion follows.
+		if event.Type == entities.UserUpdated && event.User == nil {
+			continue
+		}
+		if err := handle(event); err != nil {
+			return err
+		}
+
+		if watch.Consumer != "" {
+			_, err := f.positions.UpdateOne(ctx,
+				bson.M{"_id": positionID},
+				bson.M{"$set": bson.M{"event_id": id, "updated_at": time.Now()}},
+				options.Update().SetUpsert(true))
+			if err != nil && ctx.Err() == nil {
+				return err
+			}
+		}
+	}
+	return f.streamError(ctx, stream.Err())
+}
+
+func (f *UserChangeFeed) streamError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && (serverErr.HasErrorCode(codeInvalidResumeToken) ||
+		serverErr.HasErrorCode(codeChangeStreamFatalError) ||
+		serverErr.HasErrorCode(codeChangeStreamHistoryLost)) {
+		return domainErrors.ErrInvalidUserEventID
+	}
+	return err
+}
+
+func (c *userChange) event(id, tenantID string) *entities.UserEvent {
+	event := &entities.UserEvent{
+		ID:         id,
+		TenantID:   tenantID,
+		UserID:     c.DocumentKey.ID,
+		OccurredAt: c.WallTime,
+	}
+	if event.OccurredAt.IsZero() {
+		// wallTime is only reported from MongoDB 6.0
+		event.OccurredAt = time.Unix(int64(c.ClusterTime.T), 0)
+	}
+
+	switch c.OperationType {
+	case "insert":
+		event.Type = entities.UserCreated
+	case "delete":
+		event.Type = entities.UserDeleted
+	default:
+		event.Type = entities.UserUpdated
+	}
+	if event.Type != entities.UserDeleted && c.FullDocument != nil {
+		event.User = c.FullDocument.toEntity()
+	}
+	return event
+}
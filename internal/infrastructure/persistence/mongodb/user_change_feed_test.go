@@ -0,0 +1,25 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/persistencetest"
+)
+
+func TestUserChangeFeed(t *testing.T) {
+	db := newTestDatabase(t)
+	_, err := NewMigrator(db, Migrations(entities.EmailPolicy{})).Up(context.Background())
+	assert.NoError(t, err)
+
+	guarded := NewDatabase(db, NewGuard(GuardOptions{OperationTimeout: 5 * time.Second}))
+	feed := NewUserChangeFeed(guarded)
+	if err := feed.EnablePreImages(context.Background()); err != nil {
+		t.Skipf("change streams with pre-images need a MongoDB 6.0 replica set: %v", err)
+	}
+
+	persistencetest.UserChangeFeed(t, NewUserRepository(guarded, entities.EmailPolicy{}), feed)
+}
@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+)
+
+// userDocument is how a user is stored in the users collection. It is mapped
+// to and from entities.User field by field, so the stored shape does not
+// follow changes to the entity by accident.
+type userDocument struct {
+	ID        entities.UserID `bson:"_id"`
+	TenantID  string          `bson:"tenant_id"`
+	CreatedAt time.Time       `bson:"created_at"`
+	// Fields is named because the driver skips unexported embedded structs
+	Fields userFields `bson:",inline"`
+}
+
+// userFields are the fields of a user that can change after it is created.
+// Update sets all of them, so none may be omitted when empty: an omitted
+// field would keep its old value.
+type userFields struct {
+	Name            string             `bson:"name"`
+	Email           string             `bson:"email"`
+	NormalizedEmail string             `bson:"email_normalized"`
+	Password        string             `bson:"password"`
+	UpdatedAt       time.Time          `bson:"updated_at"`
+	Scopes          []string           `bson:"scopes"`
+	Identities      []identityDocument `bson:"identities"`
+	ExternalID      string             `bson:"external_id"`
+	Disabled        bool               `bson:"disabled"`
+	MFA             mfaDocument        `bson:"mfa"`
+}
+
+type identityDocument struct {
+	Provider string    `bson:"provider"`
+	Subject  string    `bson:"subject"`
+	Email    string    `bson:"email,omitempty"`
+	LinkedAt time.Time `bson:"linked_at"`
+}
+
+type mfaDocument struct {
+	Enabled        bool      `bson:"enabled"`
+	Secret         string    `bson:"secret,omitempty"`
+	LastUsedStep   int64     `bson:"last_used_step,omitempty"`
+	RecoveryCodes  []string  `bson:"recovery_codes,omitempty"`
+	FailedAttempts int       `bson:"failed_attempts,omitempty"`
+	LockedUntil    time.Time `bson:"locked_until,omitempty"`
+}
+
+func newUserDocument(user *entities.User) *userDocument {
+	identities := make([]identityDocument, 0, len(user.Identities))
+	for _, identity := range user.Identities {
+		identities = append(identities, identityDocument{
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+
+	return &userDocument{
+		ID:        user.ID,
+		TenantID:  user.TenantID,
+		CreatedAt: user.CreatedAt,
+		Fields: userFields{
+			Name:            user.Name,
+			Email:           user.Email,
+			NormalizedEmail: user.NormalizedEmail,
+			Password:        user.Password,
+			UpdatedAt:       user.UpdatedAt,
+			Scopes:          user.Scopes,
+			Identities:      identities,
+			ExternalID:      user.ExternalID,
+			Disabled:        user.Disabled,
+			MFA: mfaDocument{
+				Enabled:        user.MFA.Enabled,
+				Secret:         user.MFA.Secret,
+				LastUsedStep:   user.MFA.LastUsedStep,
+				RecoveryCodes:  user.MFA.RecoveryCodes,
+				FailedAttempts: user.MFA.FailedAttempts,
+				LockedUntil:    user.MFA.LockedUntil,
+			},
+		},
+	}
+}
+
+func (d *userDocument) toEntity() *entities.User {
+	var identities []entities.ExternalIdentity
+	for _, identity := range d.Fields.Identities {
+		identities = append(identities, entities.ExternalIdentity{
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+
+	return &entities.User{
+		ID:              d.ID,
+		TenantID:        d.TenantID,
+		Name:            d.Fields.Name,
+		Email:           d.Fields.Email,
+		NormalizedEmail: d.Fields.NormalizedEmail,
+		Password:        d.Fields.Password,
+		CreatedAt:       d.CreatedAt,
+		UpdatedAt:       d.Fields.UpdatedAt,
+		Scopes:          d.Fields.Scopes,
+		Identities:      identities,
+		ExternalID:      d.Fields.ExternalID,
+		Disabled:        d.Fields.Disabled,
+		MFA: entities.MFA{
+			Enabled:        d.Fields.MFA.Enabled,
+			Secret:         d.Fields.MFA.Secret,
+			LastUsedStep:   d.Fields.MFA.LastUsedStep,
+			RecoveryCodes:  d.Fields.MFA.RecoveryCodes,
+			FailedAttempts: d.Fields.MFA.FailedAttempts,
+			LockedUntil:    d.Fields.MFA.LockedUntil,
+		},
+	}
+}
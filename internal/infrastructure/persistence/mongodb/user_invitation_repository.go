@@ -0,0 +1,123 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type userInvitationRepository struct {
+	collection *collection
+	policy     entities.EmailPolicy
+}
+
+// NewUserInvitationRepository finds invitations by the key of their email
+// under the policy, which must be the one of the user repository.
+func NewUserInvitationRepository(db *Database, policy entities.EmailPolicy) repositories.UserInvitationRepository {
+	return &userInvitationRepository{
+		collection: db.collection("user_invitations"),
+		policy:     policy,
+	}
+}
+
+func (r *userInvitationRepository) Create(ctx context.Context, invitation *entities.UserInvitation) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	invitation.TenantID = tenantID
+	invitation.NormalizedEmail = r.policy.Key(invitation.Email)
+
+	_, err := r.collection.InsertOne(ctx, invitation)
+	return err
+}
+
+func (r *userInvitationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.UserInvitation, error) {
+	filter, err := tenantFilter(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+	return r.findOne(ctx, filter)
+}
+
+func (r *userInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error) {
+	return r.findOne(ctx, bson.M{"token_hash": tokenHash})
+}
+
+func (r *userInvitationRepository) findOne(ctx context.Context, filter bson.M) (*entities.UserInvitation, error) {
+	var invitation entities.UserInvitation
+	err := r.collection.FindOne(ctx, filter).Decode(&invitation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domainErrors.ErrUserInvitationNotFound
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *userInvitationRepository) GetByEmail(ctx context.Context, email string) ([]*entities.UserInvitation, error) {
+	return r.find(ctx, bson.M{"email_normalized": r.policy.Key(email)})
+}
+
+func (r *userInvitationRepository) GetAll(ctx context.Context) ([]*entities.UserInvitation, error) {
+	return r.find(ctx, bson.M{})
+}
+
+// find returns the matching invitations of the tenant, newest first.
+func (r *userInvitationRepository) find(ctx context.Context, filter bson.M) ([]*entities.UserInvitation, error) {
+	filter, err := tenantFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var invitations []*entities.UserInvitation
+	for cursor.Next(ctx) {
+		var invitation entities.UserInvitation
+		if err := cursor.Decode(&invitation); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, &invitation)
+	}
+
+	return invitations, cursor.Err()
+}
+
+func (r *userInvitationRepository) Update(ctx context.Context, invitation *entities.UserInvitation) error {
+	filter, err := tenantFilter(ctx, bson.M{"_id": invitation.ID})
+	if err != nil {
+		return err
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"accepted_at": invitation.AcceptedAt,
+			"user_id":     invitation.UserID,
+			"revoked_at":  invitation.RevokedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domainErrors.ErrUserInvitationNotFound
+	}
+
+	return nil
+}
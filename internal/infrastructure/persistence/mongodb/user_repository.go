@@ -7,23 +7,36 @@ import (
 	"github.com/wonyus/backend-challenge/internal/domain/entities"
 	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
 	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type userRepository struct {
-	collection *mongo.Collection
+	collection *collection
+	policy     entities.EmailPolicy
 }
 
-func NewUserRepository(db *mongo.Database) repositories.UserRepository {
+// NewUserRepository tells emails apart by their key under the policy, stored
+// in email_normalized. The policy must match the one the emails were
+// migrated with.
+func NewUserRepository(db *Database, policy entities.EmailPolicy) repositories.UserRepository {
 	return &userRepository{
-		collection: db.Collection("users"),
+		collection: db.collection("users"),
+		policy:     policy,
 	}
 }
 
 func (r *userRepository) Create(ctx context.Context, user *entities.User) error {
-	_, err := r.collection.InsertOne(ctx, user)
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	user.TenantID = tenantID
+	user.NormalizedEmail = r.policy.Key(user.Email)
+
+	_, err := r.collection.InsertOne(ctx, newUserDocument(user))
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
 			return domainErrors.ErrUserAlreadyExists
@@ -33,32 +46,99 @@ func (r *userRepository) Create(ctx context.Context, user *entities.User) error
 	return nil
 }
 
-func (r *userRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*entities.User, error) {
-	var user entities.User
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+func (r *userRepository) GetByID(ctx context.Context, id entities.UserID) (*entities.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	return r.findOne(ctx, bson.M{"email_normalized": r.policy.Key(email)})
+}
+
+func (r *userRepository) GetByIdentity(ctx context.Context, provider, subject string) (*entities.User, error) {
+	return r.findOne(ctx, bson.M{"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}}})
+}
+
+func (r *userRepository) findOne(ctx context.Context, filter bson.M) (*entities.User, error) {
+	filter, err := tenantFilter(ctx, filter)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, domainErrors.ErrUserNotFound
-		}
 		return nil, err
 	}
-	return &user, nil
-}
 
-func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
-	var user entities.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	var document userDocument
+	err = r.collection.FindOne(ctx, filter).Decode(&document)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, domainErrors.ErrUserNotFound
 		}
 		return nil, err
 	}
-	return &user, nil
+	return document.toEntity(), nil
 }
 
 func (r *userRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	filter, err := tenantFilter(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+func (r *userRepository) Find(ctx context.Context, query repositories.UserQuery) ([]*entities.User, int64, error) {
+	conditions := bson.M{}
+	if query.Email != "" {
+		conditions["email_normalized"] = r.policy.Key(query.Email)
+	}
+	if query.ExternalID != "" {
+		conditions["external_id"] = query.ExternalID
+	}
+	if query.Disabled != nil {
+		// Enabled users are stored without the field
+		if *query.Disabled {
+			conditions["disabled"] = true
+		} else {
+			conditions["disabled"] = bson.M{"$ne": true}
+		}
+	}
+	filter, err := tenantFilter(ctx, conditions)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	field := "created_at"
+	switch query.Sort {
+	case repositories.UserSortName:
+		field = "name"
+	case repositories.UserSortEmail:
+		field = "email"
+	}
+	direction := 1
+	if query.Descending {
+		direction = -1
+	}
+	opts := options.Find().SetSort(bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}})
+	if query.Offset > 0 {
+		opts.SetSkip(int64(query.Offset))
+	}
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	users, err := r.find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// find returns the users matching filter, which must be scoped to a tenant.
+func (r *userRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*entities.User, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -66,27 +146,30 @@ func (r *userRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
 
 	var users []*entities.User
 	for cursor.Next(ctx) {
-		var user entities.User
-		if err := cursor.Decode(&user); err != nil {
+		var document userDocument
+		if err := cursor.Decode(&document); err != nil {
 			return nil, err
 		}
-		users = append(users, &user)
+		users = append(users, document.toEntity())
 	}
 
 	return users, cursor.Err()
 }
 
 func (r *userRepository) Update(ctx context.Context, user *entities.User) error {
-	filter := bson.M{"_id": user.ID}
-	update := bson.M{
-		"$set": bson.M{
-			"name":  user.Name,
-			"email": user.Email,
-		},
+	filter, err := tenantFilter(ctx, bson.M{"_id": user.ID})
+	if err != nil {
+		return err
 	}
+	user.NormalizedEmail = r.policy.Key(user.Email)
 
-	result, err := r.collection.UpdateOne(ctx, filter, update)
+	// Every mutable field is set; the ID, tenant and creation time never
+	// change
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": newUserDocument(user).Fields})
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domainErrors.ErrUserAlreadyExists
+		}
 		return err
 	}
 
@@ -97,8 +180,13 @@ func (r *userRepository) Update(ctx context.Context, user *entities.User) error
 	return nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+func (r *userRepository) Delete(ctx context.Context, id entities.UserID) error {
+	filter, err := tenantFilter(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return err
 	}
@@ -111,5 +199,10 @@ func (r *userRepository) Delete(ctx context.Context, id primitive.ObjectID) erro
 }
 
 func (r *userRepository) Count(ctx context.Context) (int64, error) {
-	return r.collection.CountDocuments(ctx, bson.M{})
+	filter, err := tenantFilter(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+
+	return r.collection.CountDocuments(ctx, filter)
 }
@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/persistencetest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// newTestDatabase connects to the MongoDB at MONGODB_TEST_URI and returns a
+// database of its own, dropped after the test.
+func newTestDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI is not set")
+	}
+
+	client, err := NewConnection(uri, ConnectionOptions{})
+	if err != nil {
+		t.Fatalf("connect to MongoDB: %v", err)
+	}
+	db := client.Database("test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		_ = db.Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+	return db
+}
+
+func TestUserRepository(t *testing.T) {
+	persistencetest.UserRepository(t, func(t *testing.T) repositories.UserRepository {
+		db := newTestDatabase(t)
+
+		// The unique indexes come with the migrations
+		_, err := NewMigrator(db, Migrations(entities.EmailPolicy{})).Up(context.Background())
+		assert.NoError(t, err)
+
+		guard := NewGuard(GuardOptions{OperationTimeout: 5 * time.Second, MaxRetries: 2, RetryBackoff: 50 * time.Millisecond})
+		return NewUserRepository(NewDatabase(db, guard), entities.EmailPolicy{})
+	})
+}
+
+func TestUserDocument_RoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	user := &entities.User{
+		ID:              entities.NewUserID(),
+		TenantID:        "acme",
+		Name:            "Dana",
+		Email:           "Dana@example.com",
+		NormalizedEmail: "dana@example.com",
+		Password:        "hash",
+		CreatedAt:       now.Add(-time.Hour),
+		UpdatedAt:       now,
+		Scopes:          []string{entities.ScopeAdmin},
+		Identities:      []entities.ExternalIdentity{{Provider: "corp", Subject: "7", Email: "dana@corp.example", LinkedAt: now}},
+		ExternalID:      "hr-7",
+		Disabled:        true,
+		MFA:             entities.MFA{Enabled: true, Secret: "sealed", LastUsedStep: 42, RecoveryCodes: []string{"code-hash"}, FailedAttempts: 2, LockedUntil: now},
+	}
+
+	data, err := bson.Marshal(newUserDocument(user))
+	assert.NoError(t, err)
+	var document userDocument
+	assert.NoError(t, bson.Unmarshal(data, &document))
+	assert.Equal(t, user, document.toEntity())
+}
+
+func TestAPIKeyRepository_MarkUsed(t *testing.T) {
+	guard := NewGuard(GuardOptions{OperationTimeout: 5 * time.Second, MaxRetries: 2, RetryBackoff: 50 * time.Millisecond})
+	persistencetest.APIKeyMarkUsed(t, NewAPIKeyRepository(NewDatabase(newTestDatabase(t), guard)))
+}
+
+func TestUserRepository_UsersFromBeforeTenants(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	// Users were stored without a tenant before there were tenants
+	_, err := db.Collection("users").InsertOne(ctx, bson.M{
+		"_id":        primitive.NewObjectID(),
+		"name":       "Admin User",
+		"email":      "admin@example.com",
+		"password":   "hash",
+		"created_at": time.Now(),
+		"updated_at": time.Now(),
+	})
+	assert.NoError(t, err)
+
+	_, err = NewMigrator(db, Migrations(entities.EmailPolicy{})).Up(ctx)
+	assert.NoError(t, err)
+
+	guard := NewGuard(GuardOptions{OperationTimeout: 5 * time.Second, MaxRetries: 2, RetryBackoff: 50 * time.Millisecond})
+	repo := NewUserRepository(NewDatabase(db, guard), entities.EmailPolicy{})
+	ctx = services.WithTenant(ctx, entities.DefaultTenant)
+
+	user, err := repo.GetByEmail(ctx, "Admin@example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, entities.DefaultTenant, user.TenantID)
+		assert.Equal(t, "Admin User", user.Name)
+	}
+	count, err := repo.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// Another tenant does not see them
+	_, err = repo.GetByEmail(services.WithTenant(ctx, "acme"), "admin@example.com")
+	assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+}
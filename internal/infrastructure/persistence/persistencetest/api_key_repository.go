@@ -0,0 +1,45 @@
+package persistencetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+// APIKeyMarkUsed checks that marking a key used records when, and nothing
+// else: a revocation made since the key was read stays. repo must be empty.
+func APIKeyMarkUsed(t *testing.T, repo repositories.APIKeyRepository) {
+	ctx := context.Background()
+	key, err := entities.NewAPIKey(entities.NewUserID(), "CI", "prefix", "hash", []string{entities.ScopeUsersRead}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Create(ctx, key))
+
+	// The key was read before it was revoked
+	read, err := repo.GetByID(ctx, key.ID)
+	assert.NoError(t, err)
+	revoked, err := repo.GetByID(ctx, key.ID)
+	assert.NoError(t, err)
+	revoked.Revoke()
+	assert.NoError(t, repo.Update(ctx, revoked))
+
+	usedAt := time.Now().UTC().Truncate(time.Millisecond)
+	assert.NoError(t, repo.MarkUsed(ctx, read.ID, usedAt))
+
+	stored, err := repo.GetByID(ctx, key.ID)
+	if assert.NoError(t, err) {
+		assert.NotNil(t, stored.RevokedAt, "the revocation was undone")
+		assert.Equal(t, []string{entities.ScopeUsersRead}, stored.Scopes)
+		if assert.NotNil(t, stored.LastUsedAt) {
+			assert.True(t, usedAt.Equal(*stored.LastUsedAt))
+		}
+	}
+	assert.Nil(t, read.LastUsedAt, "the key read before was changed")
+
+	err = repo.MarkUsed(ctx, entities.NewID(), usedAt)
+	assert.ErrorIs(t, err, domainErrors.ErrAPIKeyNotFound)
+}
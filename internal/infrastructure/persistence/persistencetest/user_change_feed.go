@@ -0,0 +1,173 @@
+package persistencetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+var errStopWatching = errors.New("stop watching")
+
+// UserChangeFeed runs every change feed scenario against feed, which must
+// report the changes made through repo. repo must be empty.
+func UserChangeFeed(t *testing.T, repo repositories.UserRepository, feed services.UserChangeFeed) {
+	ctx := services.WithTenant(context.Background(), "acme")
+	other := services.WithTenant(context.Background(), "globex")
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan *entities.UserEvent, 16)
+	go func() {
+		_ = feed.Watch(watchCtx, services.UserWatch{}, func(event *entities.UserEvent) error {
+			select {
+			case events <- event:
+			case <-watchCtx.Done():
+			}
+			return nil
+		})
+	}()
+	probed := probeUserChangeFeed(t, ctx, repo, events)
+
+	dana, _ := entities.NewUser("Dana", "dana@example.com", "hash")
+	assert.NoError(t, repo.Create(ctx, dana))
+	eve, _ := entities.NewUser("Eve", "eve@example.com", "hash")
+	assert.NoError(t, repo.Create(other, eve))
+	dana.UpdateName("Dana Scully")
+	assert.NoError(t, repo.Update(ctx, dana))
+	assert.NoError(t, repo.Delete(ctx, dana.ID))
+
+	var created, updated, deleted *entities.UserEvent
+	t.Run("Reports the changes of the tenant", func(t *testing.T) {
+		created = nextUserEvent(t, events, dana.ID)
+		updated = nextUserEvent(t, events, dana.ID)
+		deleted = nextUserEvent(t, events, dana.ID)
+		if created == nil || updated == nil || deleted == nil {
+			t.FailNow()
+		}
+
+		assert.Equal(t, entities.UserCreated, created.Type)
+		assert.Equal(t, "acme", created.TenantID)
+		if assert.NotNil(t, created.User) {
+			assert.Equal(t, "dana@example.com", created.User.Email)
+		}
+		assert.Equal(t, entities.UserUpdated, updated.Type)
+		if assert.NotNil(t, updated.User) {
+			assert.Equal(t, "Dana Scully", updated.User.Name)
+		}
+		assert.Equal(t, entities.UserDeleted, deleted.Type)
+		assert.Nil(t, deleted.User)
+		assert.False(t, deleted.OccurredAt.IsZero())
+	})
+	if created == nil {
+		return
+	}
+
+	t.Run("Resumes after an event, with the selected types", func(t *testing.T) {
+		got := collectUserEvents(t, ctx, feed, services.UserWatch{After: probed, Types: []entities.UserEventType{entities.UserDeleted}}, 1)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, deleted.ID, got[0].ID)
+			assert.Equal(t, dana.ID, got[0].UserID)
+		}
+	})
+
+	t.Run("Remembers where a consumer left off", func(t *testing.T) {
+		watch := services.UserWatch{
+			After:    created.ID,
+			Types:    []entities.UserEventType{entities.UserUpdated, entities.UserDeleted},
+			Consumer: "mailer",
+		}
+		// The consumer handles the update and fails on the deletion
+		handled := 0
+		err := feed.Watch(ctx, watch, func(event *entities.UserEvent) error {
+			if handled++; handled > 1 {
+				return errStopWatching
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, errStopWatching)
+
+		watch.After = ""
+		got := collectUserEvents(t, ctx, feed, watch, 1)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, deleted.ID, got[0].ID)
+		}
+	})
+
+	t.Run("Rejects unknown event IDs", func(t *testing.T) {
+		err := feed.Watch(ctx, services.UserWatch{After: "not-an-event"}, func(*entities.UserEvent) error { return nil })
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidUserEventID)
+	})
+
+	t.Run("Requires a tenant", func(t *testing.T) {
+		err := feed.Watch(context.Background(), services.UserWatch{}, func(*entities.UserEvent) error { return nil })
+		assert.ErrorIs(t, err, domainErrors.ErrTenantRequired)
+	})
+}
+
+// probeUserChangeFeed creates users until the watcher sending to events gets
+// one, as it may not watch yet when it starts. It returns the ID of that
+// event.
+func probeUserChangeFeed(t *testing.T, ctx context.Context, repo repositories.UserRepository, events <-chan *entities.UserEvent) string {
+	t.Helper()
+	for i := 0; i < 50; i++ {
+		probe, _ := entities.NewUser("Probe", fmt.Sprintf("probe-%d@example.com", i), "hash")
+		if !assert.NoError(t, repo.Create(ctx, probe)) {
+			t.FailNow()
+		}
+		select {
+		case event := <-events:
+			return event.ID
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	t.Fatal("the feed reported no change")
+	return ""
+}
+
+// nextUserEvent returns the next event, which must be about the user,
+// skipping the events of probes.
+func nextUserEvent(t *testing.T, events <-chan *entities.UserEvent, id entities.UserID) *entities.UserEvent {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.User != nil && event.User.Name == "Probe" {
+				continue
+			}
+			assert.Equal(t, id, event.UserID, "event about another user")
+			return event
+		case <-timeout:
+			t.Errorf("no event about user %s", id)
+			return nil
+		}
+	}
+}
+
+// collectUserEvents watches feed until it gets count events, or gives up
+// after a while.
+func collectUserEvents(t *testing.T, ctx context.Context, feed services.UserChangeFeed, watch services.UserWatch, count int) []*entities.UserEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var events []*entities.UserEvent
+	err := feed.Watch(ctx, watch, func(event *entities.UserEvent) error {
+		if events = append(events, event); len(events) == count {
+			return errStopWatching
+		}
+		return nil
+	})
+	if !errors.Is(err, errStopWatching) {
+		assert.NoError(t, err)
+	}
+	return events
+}
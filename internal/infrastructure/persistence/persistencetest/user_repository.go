@@ -0,0 +1,446 @@
+// Package persistencetest holds the behavior every persistence adapter must
+// share, run from the tests of each adapter.
+package persistencetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+// UserRepository runs every user repository scenario, each against a fresh
+// empty repository from newRepo.
+func UserRepository(t *testing.T, newRepo func(t *testing.T) repositories.UserRepository) {
+	t.Run("Stores every field", func(t *testing.T) {
+		UserFields(t, newRepo(t))
+	})
+	t.Run("Creates, counts and deletes", func(t *testing.T) {
+		UserLifecycle(t, newRepo(t))
+	})
+	t.Run("Tenant isolation", func(t *testing.T) {
+		UserTenantIsolation(t, newRepo(t))
+	})
+	t.Run("Email identity", func(t *testing.T) {
+		UserEmailIdentity(t, newRepo(t))
+	})
+	t.Run("Queries", func(t *testing.T) {
+		UserQueries(t, newRepo(t))
+	})
+	t.Run("Returns copies", func(t *testing.T) {
+		UserCopies(t, newRepo(t))
+	})
+}
+
+// UserFields checks that repo stores every field of a user on Create and
+// every mutable one on Update. repo must be empty.
+func UserFields(t *testing.T, repo repositories.UserRepository) {
+	ctx := services.WithTenant(context.Background(), "acme")
+
+	// Stores keep times to the millisecond
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	user, err := entities.NewUser("Dana", "dana@example.com", "hash")
+	assert.NoError(t, err)
+	user.CreatedAt = now.Add(-time.Hour)
+	user.UpdatedAt = now.Add(-time.Hour)
+	user.Scopes = []string{entities.ScopeAdmin}
+	user.ExternalID = "hr-7"
+	user.Identities = []entities.ExternalIdentity{{Provider: "corp", Subject: "7", Email: "dana@corp.example", LinkedAt: now.Add(-time.Hour)}}
+	assert.NoError(t, repo.Create(ctx, user))
+
+	assertStored := func(t *testing.T, want *entities.User) {
+		t.Helper()
+		found, err := repo.GetByID(ctx, want.ID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, want.ID, found.ID)
+		assert.Equal(t, "acme", found.TenantID)
+		assert.Equal(t, want.Name, found.Name)
+		assert.Equal(t, want.Email, found.Email)
+		assert.Equal(t, want.Password, found.Password)
+		assert.True(t, want.CreatedAt.Equal(found.CreatedAt), "created at %v, want %v", found.CreatedAt, want.CreatedAt)
+		assert.True(t, want.UpdatedAt.Equal(found.UpdatedAt), "updated at %v, want %v", found.UpdatedAt, want.UpdatedAt)
+		assert.ElementsMatch(t, want.Scopes, found.Scopes)
+		assert.Equal(t, want.ExternalID, found.ExternalID)
+		assert.Equal(t, want.Disabled, found.Disabled)
+		if assert.Len(t, found.Identities, len(want.Identities)) {
+			for i, identity := range want.Identities {
+				assert.Equal(t, identity.Provider, found.Identities[i].Provider)
+				assert.Equal(t, identity.Subject, found.Identities[i].Subject)
+				assert.Equal(t, identity.Email, found.Identities[i].Email)
+				assert.True(t, identity.LinkedAt.Equal(found.Identities[i].LinkedAt))
+			}
+		}
+		assert.Equal(t, want.MFA.Enabled, found.MFA.Enabled)
+		assert.Equal(t, want.MFA.Secret, found.MFA.Secret)
+		assert.Equal(t, want.MFA.LastUsedStep, found.MFA.LastUsedStep)
+		assert.ElementsMatch(t, want.MFA.RecoveryCodes, found.MFA.RecoveryCodes)
+		assert.Equal(t, want.MFA.FailedAttempts, found.MFA.FailedAttempts)
+		assert.True(t, want.MFA.LockedUntil.Equal(found.MFA.LockedUntil))
+	}
+
+	t.Run("On create", func(t *testing.T) {
+		assertStored(t, user)
+	})
+
+	t.Run("On update", func(t *testing.T) {
+		changed := *user
+		changed.UpdateName("Dana Scully")
+		changed.UpdateEmail("dana.scully@example.com")
+		changed.UpdatedAt = now
+		changed.Password = "new-hash"
+		changed.Scopes = []string{entities.ScopeUsersRead}
+		changed.ExternalID = "hr-8"
+		changed.Disabled = true
+		changed.Identities = []entities.ExternalIdentity{{Provider: "corp", Subject: "8", LinkedAt: now}}
+		changed.MFA = entities.MFA{
+			Enabled:        true,
+			Secret:         "sealed",
+			LastUsedStep:   42,
+			RecoveryCodes:  []string{"code-hash"},
+			FailedAttempts: 2,
+			LockedUntil:    now.Add(time.Minute),
+		}
+		assert.NoError(t, repo.Update(ctx, &changed))
+		assertStored(t, &changed)
+	})
+
+	t.Run("Clears fields on update", func(t *testing.T) {
+		cleared, err := repo.GetByID(ctx, user.ID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		cleared.Scopes = nil
+		cleared.ExternalID = ""
+		cleared.Disabled = false
+		cleared.Identities = nil
+		cleared.MFA = entities.MFA{}
+		assert.NoError(t, repo.Update(ctx, cleared))
+		assertStored(t, cleared)
+	})
+}
+
+// UserLifecycle checks creating, listing, counting and deleting users. repo
+// must be empty.
+func UserLifecycle(t *testing.T, repo repositories.UserRepository) {
+	ctx := services.WithTenant(context.Background(), "acme")
+
+	var ids []entities.UserID
+	for _, email := range []string{"one@example.com", "two@example.com"} {
+		user, err := entities.NewUser("User", email, "hash")
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(ctx, user))
+		ids = append(ids, user.ID)
+	}
+
+	users, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	var found []entities.UserID
+	for _, user := range users {
+		found = append(found, user.ID)
+	}
+	assert.ElementsMatch(t, ids, found)
+
+	count, err := repo.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, repo.Delete(ctx, ids[0]))
+	assert.ErrorIs(t, repo.Delete(ctx, ids[0]), domainErrors.ErrUserNotFound)
+	_, err = repo.GetByID(ctx, ids[0])
+	assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+	_, err = repo.GetByEmail(ctx, "one@example.com")
+	assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+
+	// The email is free again
+	user, err := entities.NewUser("User", "one@example.com", "hash")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Create(ctx, user))
+
+	missing, err := entities.NewUser("Missing", "missing@example.com", "hash")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, repo.Update(ctx, missing), domainErrors.ErrUserNotFound)
+}
+
+// UserTenantIsolation checks that repo keeps the users of each tenant apart.
+// repo must be empty.
+func UserTenantIsolation(t *testing.T, repo repositories.UserRepository) {
+	acme := services.WithTenant(context.Background(), "acme")
+	globex := services.WithTenant(context.Background(), "globex")
+
+	newUser := func(t *testing.T, name string) *entities.User {
+		t.Helper()
+		user, err := entities.NewPasswordlessUser(name, "same@example.com", entities.ExternalIdentity{Provider: "corp", Subject: "42"})
+		assert.NoError(t, err)
+		return user
+	}
+
+	acmeUser := newUser(t, "Acme User")
+	globexUser := newUser(t, "Globex User")
+
+	t.Run("Requires a tenant", func(t *testing.T) {
+		ctx := context.Background()
+		assert.ErrorIs(t, repo.Create(ctx, newUser(t, "Nobody")), domainErrors.ErrTenantRequired)
+		_, err := repo.GetByEmail(ctx, "same@example.com")
+		assert.ErrorIs(t, err, domainErrors.ErrTenantRequired)
+		_, err = repo.GetAll(ctx)
+		assert.ErrorIs(t, err, domainErrors.ErrTenantRequired)
+		_, err = repo.Count(ctx)
+		assert.ErrorIs(t, err, domainErrors.ErrTenantRequired)
+	})
+
+	t.Run("Allows the same email and identity in each tenant", func(t *testing.T) {
+		assert.NoError(t, repo.Create(acme, acmeUser))
+		assert.NoError(t, repo.Create(globex, globexUser))
+		assert.Equal(t, "acme", acmeUser.TenantID)
+		assert.Equal(t, "globex", globexUser.TenantID)
+
+		assert.ErrorIs(t, repo.Create(acme, newUser(t, "Acme Twin")), domainErrors.ErrUserAlreadyExists)
+	})
+
+	t.Run("Finds users of the tenant only", func(t *testing.T) {
+		found, err := repo.GetByEmail(acme, "same@example.com")
+		if assert.NoError(t, err) {
+			assert.Equal(t, acmeUser.ID, found.ID)
+		}
+		found, err = repo.GetByIdentity(globex, "corp", "42")
+		if assert.NoError(t, err) {
+			assert.Equal(t, globexUser.ID, found.ID)
+		}
+
+		_, err = repo.GetByID(acme, globexUser.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+		_, err = repo.GetByID(services.WithTenant(context.Background(), "initech"), acmeUser.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+
+		users, err := repo.GetAll(acme)
+		assert.NoError(t, err)
+		if assert.Len(t, users, 1) {
+			assert.Equal(t, acmeUser.ID, users[0].ID)
+		}
+		count, err := repo.Count(globex)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Does not change users of other tenants", func(t *testing.T) {
+		intruder := *globexUser
+		intruder.Name = "Changed"
+		assert.ErrorIs(t, repo.Update(acme, &intruder), domainErrors.ErrUserNotFound)
+		assert.ErrorIs(t, repo.Delete(acme, globexUser.ID), domainErrors.ErrUserNotFound)
+
+		found, err := repo.GetByID(globex, globexUser.ID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Globex User", found.Name)
+			assert.Equal(t, "globex", found.TenantID)
+		}
+	})
+
+	t.Run("Keeps the tenant of updated users", func(t *testing.T) {
+		renamed := *acmeUser
+		renamed.Name = "Renamed"
+		renamed.TenantID = "globex"
+		assert.NoError(t, repo.Update(acme, &renamed))
+
+		found, err := repo.GetByID(acme, acmeUser.ID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Renamed", found.Name)
+			assert.Equal(t, "acme", found.TenantID)
+		}
+		count, err := repo.Count(globex)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
+
+// UserEmailIdentity checks that repo tells emails apart by their key under
+// the default email policy: case-insensitively, keeping subaddresses. repo
+// must be empty.
+func UserEmailIdentity(t *testing.T, repo repositories.UserRepository) {
+	ctx := services.WithTenant(context.Background(), "acme")
+
+	newUser := func(t *testing.T, email string) *entities.User {
+		t.Helper()
+		user, err := entities.NewUser("Bob", email, "hash")
+		assert.NoError(t, err)
+		return user
+	}
+
+	bob := newUser(t, "  Bob@Example.COM ")
+	assert.NoError(t, repo.Create(ctx, bob))
+	assert.Equal(t, "Bob@example.com", bob.Email)
+
+	t.Run("Finds users by any case of their email", func(t *testing.T) {
+		for _, email := range []string{"bob@example.com", "BOB@EXAMPLE.COM", " Bob@example.com"} {
+			found, err := repo.GetByEmail(ctx, email)
+			if assert.NoError(t, err, email) {
+				assert.Equal(t, bob.ID, found.ID)
+				assert.Equal(t, "Bob@example.com", found.Email)
+			}
+		}
+		_, err := repo.GetByEmail(ctx, "bob+news@example.com")
+		assert.ErrorIs(t, err, domainErrors.ErrUserNotFound)
+	})
+
+	t.Run("Rejects emails differing in case only", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Create(ctx, newUser(t, "bob@example.com")), domainErrors.ErrUserAlreadyExists)
+
+		alice := newUser(t, "alice@example.com")
+		assert.NoError(t, repo.Create(ctx, alice))
+		alice.UpdateEmail("BOB@example.com")
+		assert.ErrorIs(t, repo.Update(ctx, alice), domainErrors.ErrUserAlreadyExists)
+	})
+
+	t.Run("Lets users change the case of their email", func(t *testing.T) {
+		bob.UpdateEmail("bob@example.com")
+		assert.NoError(t, repo.Update(ctx, bob))
+
+		found, err := repo.GetByEmail(ctx, "BOB@example.com")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "bob@example.com", found.Email)
+		}
+	})
+}
+
+// UserQueries checks filtering, sorting and paging users with Find, and that
+// GetAll lists them oldest first. repo must be empty.
+func UserQueries(t *testing.T, repo repositories.UserRepository) {
+	ctx := services.WithTenant(context.Background(), "acme")
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	newUser := func(t *testing.T, name, email string, age time.Duration) *entities.User {
+		t.Helper()
+		user, err := entities.NewUser(name, email, "hash")
+		assert.NoError(t, err)
+		user.CreatedAt = now.Add(-age)
+		user.UpdatedAt = user.CreatedAt
+		return user
+	}
+	// Created out of order, to tell creation time from insertion order
+	carol := newUser(t, "Carol", "carol@example.com", 3*time.Hour)
+	alice := newUser(t, "Alice", "alice@example.com", 4*time.Hour)
+	dave := newUser(t, "Dave", "dave@example.com", time.Hour)
+	dave.Disabled = true
+	bob := newUser(t, "Bob", "bob@example.com", 2*time.Hour)
+	bob.ExternalID = "hr-2"
+	for _, user := range []*entities.User{carol, alice, dave, bob} {
+		assert.NoError(t, repo.Create(ctx, user))
+	}
+	other := newUser(t, "Alice", "alice@example.com", 5*time.Hour)
+	assert.NoError(t, repo.Create(services.WithTenant(context.Background(), "globex"), other))
+
+	ids := func(users []*entities.User) []entities.UserID {
+		var ids []entities.UserID
+		for _, user := range users {
+			ids = append(ids, user.ID)
+		}
+		return ids
+	}
+	assertFound := func(t *testing.T, query repositories.UserQuery, total int64, want ...*entities.User) {
+		t.Helper()
+		users, found, err := repo.Find(ctx, query)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, ids(want), ids(users))
+		assert.Equal(t, total, found)
+	}
+
+	users, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ids([]*entities.User{alice, carol, bob, dave}), ids(users))
+
+	t.Run("Sorts", func(t *testing.T) {
+		assertFound(t, repositories.UserQuery{}, 4, alice, carol, bob, dave)
+		assertFound(t, repositories.UserQuery{Descending: true}, 4, dave, bob, carol, alice)
+		assertFound(t, repositories.UserQuery{Sort: repositories.UserSortName, Descending: true}, 4, dave, carol, bob, alice)
+		assertFound(t, repositories.UserQuery{Sort: repositories.UserSortEmail}, 4, alice, bob, carol, dave)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		enabled, disabled := false, true
+		assertFound(t, repositories.UserQuery{Email: "Carol@Example.com"}, 1, carol)
+		assertFound(t, repositories.UserQuery{ExternalID: "hr-2"}, 1, bob)
+		assertFound(t, repositories.UserQuery{Disabled: &disabled}, 1, dave)
+		assertFound(t, repositories.UserQuery{Disabled: &enabled}, 3, alice, carol, bob)
+		assertFound(t, repositories.UserQuery{Email: "nobody@example.com"}, 0)
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		assertFound(t, repositories.UserQuery{Limit: 2}, 4, alice, carol)
+		assertFound(t, repositories.UserQuery{Offset: 2, Limit: 2}, 4, bob, dave)
+		assertFound(t, repositories.UserQuery{Offset: 3}, 4, dave)
+		assertFound(t, repositories.UserQuery{Offset: 10, Limit: 2}, 4)
+		enabled := false
+		assertFound(t, repositories.UserQuery{Disabled: &enabled, Sort: repositories.UserSortName, Descending: true, Limit: 1}, 3, carol)
+	})
+
+	t.Run("Ties are ordered by ID", func(t *testing.T) {
+		twin := newUser(t, "Alice", "alice.twin@example.com", 6*time.Hour)
+		assert.NoError(t, repo.Create(ctx, twin))
+		first, second := alice, twin
+		if twin.ID.String() < alice.ID.String() {
+			first, second = twin, alice
+		}
+
+		assertFound(t, repositories.UserQuery{Sort: repositories.UserSortName, Limit: 2}, 5, first, second)
+		assertFound(t, repositories.UserQuery{Sort: repositories.UserSortName, Offset: 1, Limit: 1}, 5, second)
+		assertFound(t, repositories.UserQuery{Sort: repositories.UserSortName, Descending: true, Offset: 3}, 5, second, first)
+	})
+}
+
+// UserCopies checks that changing a user repo was given or returned changes
+// nothing stored until it is updated, even when the update fails. repo must
+// be empty.
+func UserCopies(t *testing.T, repo repositories.UserRepository) {
+	ctx := services.WithTenant(context.Background(), "acme")
+
+	user, err := entities.NewUser("Dana", "dana@example.com", "hash")
+	assert.NoError(t, err)
+	user.Scopes = []string{entities.ScopeAdmin}
+	assert.NoError(t, repo.Create(ctx, user))
+	other, err := entities.NewUser("Eli", "eli@example.com", "hash")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Create(ctx, other))
+
+	assertUnchanged := func(t *testing.T) {
+		t.Helper()
+		found, err := repo.GetByID(ctx, user.ID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "Dana", found.Name)
+		assert.Equal(t, "dana@example.com", found.Email)
+		assert.Equal(t, []string{entities.ScopeAdmin}, found.Scopes)
+	}
+
+	user.Name = "Created"
+	user.Scopes[0] = "changed"
+	assertUnchanged(t)
+
+	found, err := repo.GetByEmail(ctx, "dana@example.com")
+	assert.NoError(t, err)
+	found.Name = "Read"
+	found.Scopes[0] = "changed"
+	assertUnchanged(t)
+
+	users, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	for _, user := range users {
+		user.Name = "Listed"
+	}
+	assertUnchanged(t)
+
+	found, err = repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	found.Name = "Failed"
+	found.Email = "eli@example.com"
+	assert.ErrorIs(t, repo.Update(ctx, found), domainErrors.ErrUserAlreadyExists)
+	assertUnchanged(t)
+}
@@ -0,0 +1,90 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+// apiKeyColumns are the columns of the api_keys table, in the order
+// scanAPIKey reads them.
+const apiKeyColumns = "id, user_id, name, prefix, hash, scopes, expires_at, last_used_at, revoked_at, created_at"
+
+type apiKeyRepository struct {
+	db *DB
+}
+
+func NewAPIKeyRepository(db *DB) repositories.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	scopes, err := encodeStrings(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	return insert(ctx, r.db, domainErrors.ErrAPIKeyExists,
+		"INSERT INTO api_keys ("+apiKeyColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		key.ID.String(), key.UserID.String(), key.Name, key.Prefix, key.Hash, scopes,
+		nullTimeOf(key.ExpiresAt), nullTimeOf(key.LastUsedAt), nullTimeOf(key.RevokedAt), key.CreatedAt.UTC(),
+	)
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id entities.ID) (*entities.APIKey, error) {
+	return queryOne(ctx, r.db, scanAPIKey, domainErrors.ErrAPIKeyNotFound,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE id = ?", id.String())
+}
+
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	return queryOne(ctx, r.db, scanAPIKey, domainErrors.ErrAPIKeyNotFound,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE prefix = ?", prefix)
+}
+
+func (r *apiKeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.APIKey, error) {
+	return queryAll(ctx, r.db, scanAPIKey,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = ? ORDER BY created_at, id", userID.String())
+}
+
+func (r *apiKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	scopes, err := encodeStrings(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	// The user, prefix and hash of a key never change
+	return execOne(ctx, r.db, domainErrors.ErrAPIKeyNotFound,
+		"UPDATE api_keys SET name = ?, scopes = ?, expires_at = ?, last_used_at = ?, revoked_at = ? WHERE id = ?",
+		key.Name, scopes, nullTimeOf(key.ExpiresAt), nullTimeOf(key.LastUsedAt), nullTimeOf(key.RevokedAt), key.ID.String(),
+	)
+}
+
+func (r *apiKeyRepository) MarkUsed(ctx context.Context, id entities.ID, t time.Time) error {
+	return execOne(ctx, r.db, domainErrors.ErrAPIKeyNotFound,
+		"UPDATE api_keys SET last_used_at = ? WHERE id = ?", t.UTC(), id.String())
+}
+
+// scanAPIKey reads a key from a row of apiKeyColumns.
+func scanAPIKey(row row) (*entities.APIKey, error) {
+	var key entities.APIKey
+	var id, userID, scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&id, &userID, &key.Name, &key.Prefix, &key.Hash, &scopes, &expiresAt, &lastUsedAt, &revokedAt, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	key.ID = entities.ID(id)
+	key.UserID = entities.UserID(userID)
+	if key.Scopes, err = decodeStrings(scopes); err != nil {
+		return nil, err
+	}
+	key.ExpiresAt = timeOf(expiresAt)
+	key.LastUsedAt = timeOf(lastUsedAt)
+	key.RevokedAt = timeOf(revokedAt)
+	return &key, nil
+}
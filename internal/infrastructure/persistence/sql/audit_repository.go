@@ -0,0 +1,24 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type auditRepository struct {
+	db *DB
+}
+
+func NewAuditRepository(db *DB) repositories.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, event *entities.AuditEvent) error {
+	return insert(ctx, r.db, nil, `INSERT INTO audit_events
+		(id, action, actor_id, subject_id, method, path, ip_address, user_agent, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.ID.String(), event.Action, event.ActorID.String(), event.SubjectID.String(), event.Method, event.Path,
+		event.IPAddress, event.UserAgent, event.CreatedAt.UTC(),
+	)
+}
@@ -0,0 +1,97 @@
+// Package sql keeps the data in a SQL database through database/sql: SQLite,
+// which needs no server, or PostgreSQL.
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// The supported databases, as named by the storage.driver option.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// DB is a connection pool and the database it connects to. Queries are
+// written with ? placeholders and rewritten for PostgreSQL.
+type DB struct {
+	*sql.DB
+	driver string
+}
+
+// Open connects to the database at dsn: a file name or SQLite URI for
+// DriverSQLite, a connection string for DriverPostgres.
+func Open(driver, dsn string) (*DB, error) {
+	var db *sql.DB
+	var err error
+	switch driver {
+	case DriverSQLite:
+		db, err = sql.Open("sqlite3", dsn)
+		if err != nil {
+			return nil, err
+		}
+		// SQLite takes one writer at a time, and every connection to
+		// :memory: opens a database of its own
+		db.SetMaxOpenConns(1)
+	case DriverPostgres:
+		db, err = sql.Open("pgx", dsn)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown SQL driver %q", driver)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{DB: db, driver: driver}, nil
+}
+
+// rebind rewrites the ? placeholders of query for the database.
+func (db *DB) rebind(query string) string {
+	if db.driver != DriverPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// orderBy is column for ORDER BY, compared byte by byte like the other
+// stores do rather than by the collation of the PostgreSQL database.
+func (db *DB) orderBy(column string) string {
+	if db.driver == DriverPostgres {
+		return column + ` COLLATE "C"`
+	}
+	return column
+}
+
+// isUniqueViolation reports whether err is a unique or primary key
+// constraint failing.
+func isUniqueViolation(err error) bool {
+	if isSQLiteUniqueViolation(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,39 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type federatedLoginRepository struct {
+	db *DB
+}
+
+func NewFederatedLoginRepository(db *DB) repositories.FederatedLoginRepository {
+	return &federatedLoginRepository{db: db}
+}
+
+func (r *federatedLoginRepository) Create(ctx context.Context, login *entities.FederatedLogin) error {
+	return insert(ctx, r.db, nil,
+		"INSERT INTO federated_logins (state_hash, provider, nonce, code_verifier, expires_at) VALUES (?, ?, ?, ?, ?)",
+		login.StateHash, login.Provider, login.Nonce, login.CodeVerifier, login.ExpiresAt.UTC(),
+	)
+}
+
+// Consume deletes the login and returns it in one statement, so that it is
+// consumed at most once.
+func (r *federatedLoginRepository) Consume(ctx context.Context, stateHash string) (*entities.FederatedLogin, error) {
+	return queryOne(ctx, r.db, scanFederatedLogin, domainErrors.ErrInvalidFederatedState,
+		"DELETE FROM federated_logins WHERE state_hash = ? RETURNING state_hash, provider, nonce, code_verifier, expires_at", stateHash)
+}
+
+func scanFederatedLogin(row row) (*entities.FederatedLogin, error) {
+	var login entities.FederatedLogin
+	if err := row.Scan(&login.StateHash, &login.Provider, &login.Nonce, &login.CodeVerifier, &login.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type magicLinkRepository struct {
+	db *DB
+}
+
+func NewMagicLinkRepository(db *DB) repositories.MagicLinkRepository {
+	return &magicLinkRepository{db: db}
+}
+
+func (r *magicLinkRepository) Create(ctx context.Context, link *entities.MagicLink) error {
+	return insert(ctx, r.db, nil,
+		"INSERT INTO magic_links (token_hash, tenant_id, email, user_id, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)",
+		link.TokenHash, link.TenantID, link.Email, link.UserID.String(), link.CreatedAt.UTC(), link.ExpiresAt.UTC(),
+	)
+}
+
+func (r *magicLinkRepository) CountSince(ctx context.Context, email string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx,
+		r.db.rebind("SELECT COUNT(*) FROM magic_links WHERE email = ? AND created_at >= ?"), email, since.UTC(),
+	).Scan(&count)
+	return count, err
+}
+
+// Consume deletes the link and returns it in one statement, so that it is
+// consumed at most once.
+func (r *magicLinkRepository) Consume(ctx context.Context, tokenHash string) (*entities.MagicLink, error) {
+	return queryOne(ctx, r.db, scanMagicLink, domainErrors.ErrInvalidMagicLink,
+		"DELETE FROM magic_links WHERE token_hash = ? RETURNING token_hash, tenant_id, email, user_id, created_at, expires_at", tokenHash)
+}
+
+func scanMagicLink(row row) (*entities.MagicLink, error) {
+	var link entities.MagicLink
+	var userID string
+	if err := row.Scan(&link.TokenHash, &link.TenantID, &link.Email, &userID, &link.CreatedAt, &link.ExpiresAt); err != nil {
+		return nil, err
+	}
+	link.UserID = entities.UserID(userID)
+	return &link, nil
+}
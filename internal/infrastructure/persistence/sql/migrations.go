@@ -0,0 +1,146 @@
+package sql
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the schema of each database as numbered scripts,
+// migrations/<driver>/<version>_<description>.sql. Add new scripts with the
+// next version for every driver; never change one that has been released.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLockID is the PostgreSQL advisory lock migrating instances take
+// turns through.
+const migrationLockID = 4_045_001
+
+// Migration changes the schema from the previous version to Version.
+type Migration struct {
+	Version     int
+	Description string
+	script      string
+}
+
+// Migrations is the schema of a database of driver, oldest first.
+func Migrations(driver string) ([]Migration, error) {
+	dir := path.Join("migrations", driver)
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for SQL driver %q", driver)
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		number, description, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %s is not named <version>_<description>.sql", entry.Name())
+		}
+		script, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version:     version,
+			Description: strings.ReplaceAll(description, "_", " "),
+			script:      string(script),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrate applies every migration that has not been applied yet, recording
+// them in schema_migrations, and returns them. The migrations run in one
+// transaction, so they are applied all or not at all, and instances
+// migrating a PostgreSQL database at the same time wait for each other.
+func Migrate(ctx context.Context, db *DB) ([]Migration, error) {
+	migrations, err := Migrations(db.driver)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if db.driver == DriverPostgres {
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version     INTEGER PRIMARY KEY,
+    description TEXT NOT NULL,
+    applied_at  `+db.timestampType()+` NOT NULL
+)`)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool)
+	rows, err := tx.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, migration.script); err != nil {
+			return nil, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+		_, err := tx.ExecContext(ctx,
+			db.rebind("INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)"),
+			migration.Version, migration.Description, time.Now().UTC(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		done = append(done, migration)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return done, nil
+}
+
+// timestampType is the column type of points in time.
+func (db *DB) timestampType() string {
+	if db.driver == DriverPostgres {
+		return "TIMESTAMPTZ"
+	}
+	return "TIMESTAMP"
+}
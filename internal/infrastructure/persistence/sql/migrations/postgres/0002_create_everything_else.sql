@@ -0,0 +1,168 @@
+CREATE TABLE api_keys (
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT NOT NULL,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL,
+    hash         TEXT NOT NULL,
+    scopes       TEXT NOT NULL,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX api_keys_prefix ON api_keys (prefix);
+CREATE INDEX api_keys_user_id_created_at ON api_keys (user_id, created_at);
+
+CREATE TABLE audit_events (
+    id         TEXT PRIMARY KEY,
+    action     TEXT NOT NULL,
+    actor_id   TEXT NOT NULL,
+    subject_id TEXT NOT NULL,
+    method     TEXT NOT NULL,
+    path       TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX audit_events_subject_id_created_at ON audit_events (subject_id, created_at);
+CREATE INDEX audit_events_actor_id_created_at ON audit_events (actor_id, created_at);
+
+CREATE TABLE federated_logins (
+    state_hash    TEXT PRIMARY KEY,
+    provider      TEXT NOT NULL,
+    nonce         TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE magic_links (
+    token_hash TEXT PRIMARY KEY,
+    tenant_id  TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    user_id    TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX magic_links_email_created_at ON magic_links (email, created_at);
+
+CREATE TABLE oauth_clients (
+    id            TEXT PRIMARY KEY,
+    client_id     TEXT NOT NULL,
+    secret_hash   TEXT NOT NULL,
+    name          TEXT NOT NULL,
+    redirect_uris TEXT NOT NULL,
+    scopes        TEXT NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX oauth_clients_client_id ON oauth_clients (client_id);
+
+CREATE TABLE authorization_codes (
+    code_hash      TEXT PRIMARY KEY,
+    tenant_id      TEXT NOT NULL,
+    client_id      TEXT NOT NULL,
+    user_id        TEXT NOT NULL,
+    redirect_uri   TEXT NOT NULL,
+    scopes         TEXT NOT NULL,
+    nonce          TEXT NOT NULL,
+    code_challenge TEXT NOT NULL,
+    auth_time      TIMESTAMPTZ NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE passkeys (
+    id              TEXT PRIMARY KEY,
+    user_id         TEXT NOT NULL,
+    name            TEXT NOT NULL,
+    public_key      BYTEA NOT NULL,
+    sign_count      BIGINT NOT NULL,
+    aaguid          BYTEA,
+    transports      TEXT NOT NULL,
+    backup_eligible BOOLEAN NOT NULL,
+    backup_state    BOOLEAN NOT NULL,
+    last_used_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX passkeys_user_id ON passkeys (user_id);
+
+CREATE TABLE passkey_ceremonies (
+    challenge_hash TEXT PRIMARY KEY,
+    type           TEXT NOT NULL,
+    user_id        TEXT NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE sessions (
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT NOT NULL,
+    auth_method  TEXT NOT NULL,
+    ip_address   TEXT NOT NULL,
+    user_agent   TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX sessions_user_id ON sessions (user_id);
+
+CREATE TABLE organizations (
+    id         TEXT PRIMARY KEY,
+    tenant_id  TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX organizations_tenant_id ON organizations (tenant_id);
+
+CREATE TABLE teams (
+    id              TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    name            TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX teams_organization_id_name ON teams (organization_id, name);
+
+-- The membership of a user in an organization itself has the zero team ID
+CREATE TABLE memberships (
+    id              TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL,
+    team_id         TEXT NOT NULL,
+    user_id         TEXT NOT NULL,
+    role            TEXT NOT NULL,
+    status          TEXT NOT NULL,
+    invited_by      TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL
+);
+
+-- A user is a member of an organization or team at most once
+CREATE UNIQUE INDEX memberships_organization_id_team_id_user_id ON memberships (organization_id, team_id, user_id);
+CREATE INDEX memberships_user_id ON memberships (user_id);
+CREATE INDEX memberships_team_id ON memberships (team_id);
+
+CREATE TABLE user_invitations (
+    id               TEXT PRIMARY KEY,
+    tenant_id        TEXT NOT NULL,
+    email            TEXT NOT NULL,
+    email_normalized TEXT NOT NULL,
+    token_hash       TEXT NOT NULL,
+    scopes           TEXT NOT NULL,
+    invited_by       TEXT NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL,
+    expires_at       TIMESTAMPTZ NOT NULL,
+    accepted_at      TIMESTAMPTZ,
+    user_id          TEXT NOT NULL,
+    revoked_at       TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX user_invitations_token_hash ON user_invitations (token_hash);
+CREATE INDEX user_invitations_tenant_id_email_normalized ON user_invitations (tenant_id, email_normalized);
+CREATE INDEX user_invitations_tenant_id_created_at ON user_invitations (tenant_id, created_at);
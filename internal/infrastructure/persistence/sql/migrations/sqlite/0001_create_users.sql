@@ -0,0 +1,38 @@
+CREATE TABLE users (
+    id                  TEXT PRIMARY KEY,
+    tenant_id           TEXT NOT NULL,
+    name                TEXT NOT NULL,
+    email               TEXT NOT NULL,
+    email_normalized    TEXT NOT NULL,
+    password            TEXT NOT NULL,
+    created_at          TIMESTAMP NOT NULL,
+    updated_at          TIMESTAMP NOT NULL,
+    scopes              TEXT NOT NULL,
+    external_id         TEXT NOT NULL,
+    disabled            BOOLEAN NOT NULL,
+    mfa_enabled         BOOLEAN NOT NULL,
+    mfa_secret          TEXT NOT NULL,
+    mfa_last_used_step  INTEGER NOT NULL,
+    mfa_recovery_codes  TEXT NOT NULL,
+    mfa_failed_attempts INTEGER NOT NULL,
+    mfa_locked_until    TIMESTAMP
+);
+
+-- Every query is scoped to a tenant, and emails are only unique within one
+CREATE UNIQUE INDEX users_tenant_id_email_normalized ON users (tenant_id, email_normalized);
+CREATE INDEX users_tenant_id_created_at ON users (tenant_id, created_at);
+
+CREATE TABLE user_identities (
+    user_id   TEXT NOT NULL REFERENCES users (id),
+    position  INTEGER NOT NULL,
+    tenant_id TEXT NOT NULL,
+    provider  TEXT NOT NULL,
+    subject   TEXT NOT NULL,
+    email     TEXT NOT NULL,
+    linked_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, position)
+);
+
+-- Accounts at external identity providers link to at most one user per
+-- tenant
+CREATE UNIQUE INDEX user_identities_tenant_id_provider_subject ON user_identities (tenant_id, provider, subject);
@@ -0,0 +1,102 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+type oauthClientRepository struct {
+	db *DB
+}
+
+func NewOAuthClientRepository(db *DB) repositories.OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+func (r *oauthClientRepository) Create(ctx context.Context, client *entities.OAuthClient) error {
+	redirectURIs, err := encodeStrings(client.RedirectURIs)
+	if err != nil {
+		return err
+	}
+	scopes, err := encodeStrings(client.Scopes)
+	if err != nil {
+		return err
+	}
+
+	return insert(ctx, r.db, domainErrors.ErrOAuthClientExists, `INSERT INTO oauth_clients
+		(id, client_id, secret_hash, name, redirect_uris, scopes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		client.ID.String(), client.ClientID, client.SecretHash, client.Name, redirectURIs, scopes, client.CreatedAt.UTC(),
+	)
+}
+
+func (r *oauthClientRepository) GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	return queryOne(ctx, r.db, scanOAuthClient, domainErrors.ErrOAuthClientNotFound,
+		"SELECT id, client_id, secret_hash, name, redirect_uris, scopes, created_at FROM oauth_clients WHERE client_id = ?", clientID)
+}
+
+func scanOAuthClient(row row) (*entities.OAuthClient, error) {
+	var client entities.OAuthClient
+	var id, redirectURIs, scopes string
+	err := row.Scan(&id, &client.ClientID, &client.SecretHash, &client.Name, &redirectURIs, &scopes, &client.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	client.ID = entities.ID(id)
+	if client.RedirectURIs, err = decodeStrings(redirectURIs); err != nil {
+		return nil, err
+	}
+	if client.Scopes, err = decodeStrings(scopes); err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+type authorizationCodeRepository struct {
+	db *DB
+}
+
+func NewAuthorizationCodeRepository(db *DB) repositories.AuthorizationCodeRepository {
+	return &authorizationCodeRepository{db: db}
+}
+
+func (r *authorizationCodeRepository) Create(ctx context.Context, code *entities.AuthorizationCode) error {
+	scopes, err := encodeStrings(code.Scopes)
+	if err != nil {
+		return err
+	}
+
+	return insert(ctx, r.db, nil, `INSERT INTO authorization_codes
+		(code_hash, tenant_id, client_id, user_id, redirect_uri, scopes, nonce, code_challenge, auth_time, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		code.CodeHash, code.TenantID, code.ClientID, code.UserID.String(), code.RedirectURI, scopes, code.Nonce, code.CodeChallenge,
+		code.AuthTime.UTC(), code.ExpiresAt.UTC(),
+	)
+}
+
+// Consume deletes the code and returns it in one statement, so that it is
+// redeemed at most once.
+func (r *authorizationCodeRepository) Consume(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error) {
+	return queryOne(ctx, r.db, scanAuthorizationCode, domainErrors.ErrAuthorizationCodeNotFound,
+		`DELETE FROM authorization_codes WHERE code_hash = ?
+		RETURNING code_hash, tenant_id, client_id, user_id, redirect_uri, scopes, nonce, code_challenge, auth_time, expires_at`, codeHash)
+}
+
+func scanAuthorizationCode(row row) (*entities.AuthorizationCode, error) {
+	var code entities.AuthorizationCode
+	var userID, scopes string
+	err := row.Scan(&code.CodeHash, &code.TenantID, &code.ClientID, &userID, &code.RedirectURI, &scopes, &code.Nonce, &code.CodeChallenge,
+		&code.AuthTime, &code.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	code.UserID = entities.UserID(userID)
+	if code.Scopes, err = decodeStrings(scopes); err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
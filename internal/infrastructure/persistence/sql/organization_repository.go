@@ -0,0 +1,211 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+// Organizations are scoped to the tenant, so one of another tenant is never
+// found.
+type organizationRepository struct {
+	db *DB
+}
+
+func NewOrganizationRepository(db *DB) repositories.OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, organization *entities.Organization) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	organization.TenantID = tenantID
+	return insert(ctx, r.db, nil,
+		"INSERT INTO organizations (id, tenant_id, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		organization.ID.String(), organization.TenantID, organization.Name, organization.CreatedAt.UTC(), organization.UpdatedAt.UTC(),
+	)
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Organization, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	return queryOne(ctx, r.db, scanOrganization, domainErrors.ErrOrganizationNotFound,
+		"SELECT id, tenant_id, name, created_at, updated_at FROM organizations WHERE tenant_id = ? AND id = ?", tenantID, id.String())
+}
+
+func (r *organizationRepository) Update(ctx context.Context, organization *entities.Organization) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	err := execOne(ctx, r.db, domainErrors.ErrOrganizationNotFound,
+		"UPDATE organizations SET name = ?, updated_at = ? WHERE tenant_id = ? AND id = ?",
+		organization.Name, organization.UpdatedAt.UTC(), tenantID, organization.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	organization.TenantID = tenantID
+	return nil
+}
+
+func (r *organizationRepository) Delete(ctx context.Context, id entities.ID) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	return execOne(ctx, r.db, domainErrors.ErrOrganizationNotFound,
+		"DELETE FROM organizations WHERE tenant_id = ? AND id = ?", tenantID, id.String())
+}
+
+func scanOrganization(row row) (*entities.Organization, error) {
+	var organization entities.Organization
+	var id string
+	err := row.Scan(&id, &organization.TenantID, &organization.Name, &organization.CreatedAt, &organization.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	organization.ID = entities.ID(id)
+	return &organization, nil
+}
+
+type teamRepository struct {
+	db *DB
+}
+
+func NewTeamRepository(db *DB) repositories.TeamRepository {
+	return &teamRepository{db: db}
+}
+
+func (r *teamRepository) Create(ctx context.Context, team *entities.Team) error {
+	return insert(ctx, r.db, nil,
+		"INSERT INTO teams (id, organization_id, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		team.ID.String(), team.OrganizationID.String(), team.Name, team.CreatedAt.UTC(), team.UpdatedAt.UTC(),
+	)
+}
+
+func (r *teamRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Team, error) {
+	return queryOne(ctx, r.db, scanTeam, domainErrors.ErrTeamNotFound,
+		"SELECT id, organization_id, name, created_at, updated_at FROM teams WHERE id = ?", id.String())
+}
+
+func (r *teamRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Team, error) {
+	return queryAll(ctx, r.db, scanTeam,
+		"SELECT id, organization_id, name, created_at, updated_at FROM teams WHERE organization_id = ? ORDER BY "+r.db.orderBy("name")+", id",
+		organizationID.String())
+}
+
+func (r *teamRepository) Update(ctx context.Context, team *entities.Team) error {
+	return execOne(ctx, r.db, domainErrors.ErrTeamNotFound,
+		"UPDATE teams SET name = ?, updated_at = ? WHERE id = ?", team.Name, team.UpdatedAt.UTC(), team.ID.String())
+}
+
+func (r *teamRepository) Delete(ctx context.Context, id entities.ID) error {
+	return execOne(ctx, r.db, domainErrors.ErrTeamNotFound, "DELETE FROM teams WHERE id = ?", id.String())
+}
+
+func (r *teamRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	_, err := r.db.ExecContext(ctx, r.db.rebind("DELETE FROM teams WHERE organization_id = ?"), organizationID.String())
+	return err
+}
+
+func scanTeam(row row) (*entities.Team, error) {
+	var team entities.Team
+	var id, organizationID string
+	err := row.Scan(&id, &organizationID, &team.Name, &team.CreatedAt, &team.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	team.ID = entities.ID(id)
+	team.OrganizationID = entities.ID(organizationID)
+	return &team, nil
+}
+
+// membershipColumns are the columns of the memberships table, in the order
+// scanMembership reads them.
+const membershipColumns = "id, organization_id, team_id, user_id, role, status, invited_by, created_at, updated_at"
+
+type membershipRepository struct {
+	db *DB
+}
+
+func NewMembershipRepository(db *DB) repositories.MembershipRepository {
+	return &membershipRepository{db: db}
+}
+
+func (r *membershipRepository) Create(ctx context.Context, membership *entities.Membership) error {
+	return insert(ctx, r.db, domainErrors.ErrMembershipExists,
+		"INSERT INTO memberships ("+membershipColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		membership.ID.String(), membership.OrganizationID.String(), membership.TeamID.String(), membership.UserID.String(),
+		membership.Role, membership.Status, membership.InvitedBy.String(), membership.CreatedAt.UTC(), membership.UpdatedAt.UTC(),
+	)
+}
+
+func (r *membershipRepository) Get(ctx context.Context, organizationID, teamID entities.ID, userID entities.UserID) (*entities.Membership, error) {
+	return queryOne(ctx, r.db, scanMembership, domainErrors.ErrMembershipNotFound,
+		"SELECT "+membershipColumns+" FROM memberships WHERE organization_id = ? AND team_id = ? AND user_id = ?",
+		organizationID.String(), teamID.String(), userID.String())
+}
+
+func (r *membershipRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Membership, error) {
+	return queryAll(ctx, r.db, scanMembership,
+		"SELECT "+membershipColumns+" FROM memberships WHERE organization_id = ? ORDER BY created_at, id", organizationID.String())
+}
+
+func (r *membershipRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Membership, error) {
+	return queryAll(ctx, r.db, scanMembership,
+		"SELECT "+membershipColumns+" FROM memberships WHERE user_id = ? ORDER BY created_at, id", userID.String())
+}
+
+func (r *membershipRepository) Update(ctx context.Context, membership *entities.Membership) error {
+	// Who is a member of what never changes
+	return execOne(ctx, r.db, domainErrors.ErrMembershipNotFound,
+		"UPDATE memberships SET role = ?, status = ?, invited_by = ?, updated_at = ? WHERE id = ?",
+		membership.Role, membership.Status, membership.InvitedBy.String(), membership.UpdatedAt.UTC(), membership.ID.String(),
+	)
+}
+
+func (r *membershipRepository) Delete(ctx context.Context, id entities.ID) error {
+	return execOne(ctx, r.db, domainErrors.ErrMembershipNotFound, "DELETE FROM memberships WHERE id = ?", id.String())
+}
+
+func (r *membershipRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	_, err := r.db.ExecContext(ctx, r.db.rebind("DELETE FROM memberships WHERE organization_id = ?"), organizationID.String())
+	return err
+}
+
+func (r *membershipRepository) DeleteByTeamID(ctx context.Context, teamID entities.ID) error {
+	_, err := r.db.ExecContext(ctx, r.db.rebind("DELETE FROM memberships WHERE team_id = ?"), teamID.String())
+	return err
+}
+
+// scanMembership reads a membership from a row of membershipColumns.
+func scanMembership(row row) (*entities.Membership, error) {
+	var membership entities.Membership
+	var id, organizationID, teamID, userID, invitedBy string
+	err := row.Scan(&id, &organizationID, &teamID, &userID, &membership.Role, &membership.Status, &invitedBy,
+		&membership.CreatedAt, &membership.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	membership.ID = entities.ID(id)
+	membership.OrganizationID = entities.ID(organizationID)
+	membership.TeamID = entities.ID(teamID)
+	membership.UserID = entities.UserID(userID)
+	membership.InvitedBy = entities.UserID(invitedBy)
+	return &membership, nil
+}
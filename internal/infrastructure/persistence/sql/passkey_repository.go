@@ -0,0 +1,118 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+// passkeyColumns are the columns of the passkeys table, in the order
+// scanPasskey reads them.
+const passkeyColumns = `id, user_id, name, public_key, sign_count, aaguid, transports, backup_eligible, backup_state,
+	last_used_at, created_at`
+
+type passkeyRepository struct {
+	db *DB
+}
+
+func NewPasskeyRepository(db *DB) repositories.PasskeyRepository {
+	return &passkeyRepository{db: db}
+}
+
+func (r *passkeyRepository) Create(ctx context.Context, passkey *entities.Passkey) error {
+	transports, err := encodeStrings(passkey.Transports)
+	if err != nil {
+		return err
+	}
+
+	return insert(ctx, r.db, domainErrors.ErrPasskeyExists,
+		"INSERT INTO passkeys ("+passkeyColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		passkey.ID, passkey.UserID.String(), passkey.Name, passkey.PublicKey, int64(passkey.SignCount), passkey.AAGUID,
+		transports, passkey.BackupEligible, passkey.BackupState, nullTimeOf(passkey.LastUsedAt), passkey.CreatedAt.UTC(),
+	)
+}
+
+func (r *passkeyRepository) GetByID(ctx context.Context, id string) (*entities.Passkey, error) {
+	return queryOne(ctx, r.db, scanPasskey, domainErrors.ErrPasskeyNotFound,
+		"SELECT "+passkeyColumns+" FROM passkeys WHERE id = ?", id)
+}
+
+func (r *passkeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Passkey, error) {
+	return queryAll(ctx, r.db, scanPasskey,
+		"SELECT "+passkeyColumns+" FROM passkeys WHERE user_id = ? ORDER BY created_at, id", userID.String())
+}
+
+func (r *passkeyRepository) Update(ctx context.Context, passkey *entities.Passkey) error {
+	transports, err := encodeStrings(passkey.Transports)
+	if err != nil {
+		return err
+	}
+
+	// The user and public key of a passkey never change
+	return execOne(ctx, r.db, domainErrors.ErrPasskeyNotFound, `UPDATE passkeys SET
+		name = ?, sign_count = ?, aaguid = ?, transports = ?, backup_eligible = ?, backup_state = ?, last_used_at = ?
+		WHERE id = ?`,
+		passkey.Name, int64(passkey.SignCount), passkey.AAGUID, transports, passkey.BackupEligible, passkey.BackupState,
+		nullTimeOf(passkey.LastUsedAt), passkey.ID,
+	)
+}
+
+func (r *passkeyRepository) Delete(ctx context.Context, id string) error {
+	return execOne(ctx, r.db, domainErrors.ErrPasskeyNotFound, "DELETE FROM passkeys WHERE id = ?", id)
+}
+
+// scanPasskey reads a passkey from a row of passkeyColumns.
+func scanPasskey(row row) (*entities.Passkey, error) {
+	var passkey entities.Passkey
+	var userID, transports string
+	var signCount int64
+	var lastUsedAt sql.NullTime
+	err := row.Scan(&passkey.ID, &userID, &passkey.Name, &passkey.PublicKey, &signCount, &passkey.AAGUID, &transports,
+		&passkey.BackupEligible, &passkey.BackupState, &lastUsedAt, &passkey.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	passkey.UserID = entities.UserID(userID)
+	passkey.SignCount = uint32(signCount)
+	if passkey.Transports, err = decodeStrings(transports); err != nil {
+		return nil, err
+	}
+	passkey.LastUsedAt = timeOf(lastUsedAt)
+	return &passkey, nil
+}
+
+type passkeyCeremonyRepository struct {
+	db *DB
+}
+
+func NewPasskeyCeremonyRepository(db *DB) repositories.PasskeyCeremonyRepository {
+	return &passkeyCeremonyRepository{db: db}
+}
+
+func (r *passkeyCeremonyRepository) Create(ctx context.Context, ceremony *entities.PasskeyCeremony) error {
+	return insert(ctx, r.db, nil,
+		"INSERT INTO passkey_ceremonies (challenge_hash, type, user_id, expires_at) VALUES (?, ?, ?, ?)",
+		ceremony.ChallengeHash, ceremony.Type, ceremony.UserID.String(), ceremony.ExpiresAt.UTC(),
+	)
+}
+
+// Consume deletes the ceremony and returns it in one statement, so that a
+// challenge is answered at most once.
+func (r *passkeyCeremonyRepository) Consume(ctx context.Context, challengeHash string) (*entities.PasskeyCeremony, error) {
+	return queryOne(ctx, r.db, scanPasskeyCeremony, domainErrors.ErrInvalidPasskeyChallenge,
+		"DELETE FROM passkey_ceremonies WHERE challenge_hash = ? RETURNING challenge_hash, type, user_id, expires_at", challengeHash)
+}
+
+func scanPasskeyCeremony(row row) (*entities.PasskeyCeremony, error) {
+	var ceremony entities.PasskeyCeremony
+	var userID string
+	if err := row.Scan(&ceremony.ChallengeHash, &ceremony.Type, &userID, &ceremony.ExpiresAt); err != nil {
+		return nil, err
+	}
+	ceremony.UserID = entities.UserID(userID)
+	return &ceremony, nil
+}
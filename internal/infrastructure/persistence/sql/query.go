@@ -0,0 +1,104 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// row is a row a query selected, the only one or one of several.
+type row interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryOne returns the record scan reads from the row query selects, or
+// notFound if it selects none.
+func queryOne[T any](ctx context.Context, db *DB, scan func(row) (*T, error), notFound error, query string, args ...interface{}) (*T, error) {
+	record, err := scan(db.QueryRowContext(ctx, db.rebind(query), args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, notFound
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+// queryAll returns the records scan reads from the rows query selects, in
+// the order it selects them.
+func queryAll[T any](ctx context.Context, db *DB, scan func(row) (*T, error), query string, args ...interface{}) ([]*T, error) {
+	rows, err := db.QueryContext(ctx, db.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*T
+	for rows.Next() {
+		record, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// insert runs the INSERT statement, returning exists, unless nil, if a
+// unique constraint fails.
+func insert(ctx context.Context, db *DB, exists error, statement string, args ...interface{}) error {
+	_, err := db.ExecContext(ctx, db.rebind(statement), args...)
+	if err != nil && exists != nil && isUniqueViolation(err) {
+		return exists
+	}
+	return err
+}
+
+// execOne runs the statement, which changes one row, returning notFound if
+// it changes none.
+func execOne(ctx context.Context, db *DB, notFound error, statement string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, db.rebind(statement), args...)
+	if err != nil {
+		return err
+	}
+	if changed, err := result.RowsAffected(); err != nil {
+		return err
+	} else if changed == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// encodeStrings returns list as a JSON array, the way lists are stored. A nil
+// list is stored as null, so that it reads back as nil rather than empty.
+func encodeStrings(list []string) (string, error) {
+	encoded, err := json.Marshal(list)
+	return string(encoded), err
+}
+
+// decodeStrings reads a list stored by encodeStrings.
+func decodeStrings(encoded string) ([]string, error) {
+	var list []string
+	if err := json.Unmarshal([]byte(encoded), &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// nullTimeOf stores a nil time as NULL.
+func nullTimeOf(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// timeOf is the time a nullable column holds, nil for NULL.
+func timeOf(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,257 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+func TestOrganizationRepositories(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, newDatabase func(t *testing.T) *DB) {
+		db := newDatabase(t)
+		organizations := NewOrganizationRepository(db)
+		teams := NewTeamRepository(db)
+		memberships := NewMembershipRepository(db)
+		ctx := services.WithTenant(context.Background(), "acme")
+
+		organization, err := entities.NewOrganization("Acme")
+		assert.NoError(t, err)
+		assert.NoError(t, organizations.Create(ctx, organization))
+		assert.Equal(t, "acme", organization.TenantID)
+
+		// Organizations of another tenant are never found
+		other := services.WithTenant(context.Background(), "globex")
+		_, err = organizations.GetByID(other, organization.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrOrganizationNotFound)
+		assert.ErrorIs(t, organizations.Delete(other, organization.ID), domainErrors.ErrOrganizationNotFound)
+		_, err = organizations.GetByID(context.Background(), organization.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrTenantRequired)
+
+		organization.Name = "Acme Corp"
+		assert.NoError(t, organizations.Update(ctx, organization))
+		stored, err := organizations.GetByID(ctx, organization.ID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Acme Corp", stored.Name)
+		}
+
+		// Teams are listed by name
+		var created []*entities.Team
+		for _, name := range []string{"Support", "Engineering"} {
+			team, err := entities.NewTeam(organization.ID, name)
+			assert.NoError(t, err)
+			assert.NoError(t, teams.Create(ctx, team))
+			created = append(created, team)
+		}
+		listed, err := teams.GetByOrganizationID(ctx, organization.ID)
+		if assert.NoError(t, err) && assert.Len(t, listed, 2) {
+			assert.Equal(t, "Engineering", listed[0].Name)
+			assert.Equal(t, organization.ID, listed[0].OrganizationID)
+		}
+
+		// A user is a member of the organization, with the zero team ID, and
+		// of each team at most once
+		userID := entities.NewUserID()
+		member := entities.NewMembership(organization.ID, "", userID, entities.OrganizationRoleMember)
+		assert.NoError(t, memberships.Create(ctx, member))
+		duplicate := entities.NewMembership(organization.ID, "", userID, entities.OrganizationRoleMember)
+		assert.ErrorIs(t, memberships.Create(ctx, duplicate), domainErrors.ErrMembershipExists)
+		assert.NoError(t, memberships.Create(ctx, entities.NewMembership(organization.ID, created[0].ID, userID, entities.TeamRoleMember)))
+
+		found, err := memberships.Get(ctx, organization.ID, "", userID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, member.ID, found.ID)
+			assert.True(t, found.TeamID.IsZero())
+		}
+		byUser, err := memberships.GetByUserID(ctx, userID)
+		assert.NoError(t, err)
+		assert.Len(t, byUser, 2)
+
+		assert.NoError(t, memberships.DeleteByTeamID(ctx, created[0].ID))
+		_, err = memberships.Get(ctx, organization.ID, created[0].ID, userID)
+		assert.ErrorIs(t, err, domainErrors.ErrMembershipNotFound)
+
+		assert.NoError(t, teams.DeleteByOrganizationID(ctx, organization.ID))
+		assert.NoError(t, memberships.DeleteByOrganizationID(ctx, organization.ID))
+		assert.NoError(t, organizations.Delete(ctx, organization.ID))
+		listed, err = teams.GetByOrganizationID(ctx, organization.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, listed)
+		_, err = memberships.Get(ctx, organization.ID, "", userID)
+		assert.ErrorIs(t, err, domainErrors.ErrMembershipNotFound)
+		_, err = organizations.GetByID(ctx, organization.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrOrganizationNotFound)
+	})
+}
+
+func TestUserInvitationRepository(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, newDatabase func(t *testing.T) *DB) {
+		repo := NewUserInvitationRepository(newDatabase(t), entities.EmailPolicy{})
+		ctx := services.WithTenant(context.Background(), "acme")
+		expiresAt := time.Now().Add(time.Hour)
+
+		older := entities.NewUserInvitation("dana@example.com", "hash-1", nil, entities.NewUserID(), expiresAt)
+		older.CreatedAt = older.CreatedAt.Add(-time.Minute)
+		newer := entities.NewUserInvitation("dana@example.com", "hash-2", []string{entities.ScopeUsersRead}, entities.NewUserID(), expiresAt)
+		assert.NoError(t, repo.Create(ctx, older))
+		assert.NoError(t, repo.Create(ctx, newer))
+
+		// Newest first
+		all, err := repo.GetAll(ctx)
+		if assert.NoError(t, err) && assert.Len(t, all, 2) {
+			assert.Equal(t, newer.ID, all[0].ID)
+			assert.Nil(t, all[1].Scopes)
+			assert.Equal(t, []string{entities.ScopeUsersRead}, all[0].Scopes)
+		}
+		// Emails match under the email policy
+		byEmail, err := repo.GetByEmail(ctx, "Dana@Example.com")
+		assert.NoError(t, err)
+		assert.Len(t, byEmail, 2)
+		byEmail, err = repo.GetByEmail(services.WithTenant(context.Background(), "globex"), "dana@example.com")
+		assert.NoError(t, err)
+		assert.Empty(t, byEmail)
+
+		userID := entities.NewUserID()
+		newer.Accept(userID)
+		assert.NoError(t, repo.Update(ctx, newer))
+
+		// Tokens are looked up before the tenant is known
+		stored, err := repo.GetByTokenHash(context.Background(), "hash-2")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "acme", stored.TenantID)
+			assert.Equal(t, userID, stored.UserID)
+			assert.False(t, stored.AcceptedAt.IsZero())
+			assert.True(t, stored.RevokedAt.IsZero())
+		}
+		_, err = repo.GetByID(services.WithTenant(context.Background(), "globex"), newer.ID)
+		assert.ErrorIs(t, err, domainErrors.ErrUserInvitationNotFound)
+	})
+}
+
+func TestPasskeyRepository(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, newDatabase func(t *testing.T) *DB) {
+		repo := NewPasskeyRepository(newDatabase(t))
+		ctx := context.Background()
+
+		passkey, err := entities.NewPasskey(entities.NewUserID(), "Laptop", "credential", []byte{1, 2, 3}, 7)
+		assert.NoError(t, err)
+		passkey.Transports = []string{"internal", "hybrid"}
+		assert.NoError(t, repo.Create(ctx, passkey))
+		assert.ErrorIs(t, repo.Create(ctx, passkey), domainErrors.ErrPasskeyExists)
+
+		usedAt := time.Now().UTC().Truncate(time.Millisecond)
+		passkey.SignCount = 8
+		passkey.LastUsedAt = &usedAt
+		assert.NoError(t, repo.Update(ctx, passkey))
+
+		stored, err := repo.GetByUserID(ctx, passkey.UserID)
+		if assert.NoError(t, err) && assert.Len(t, stored, 1) {
+			assert.Equal(t, []byte{1, 2, 3}, stored[0].PublicKey)
+			assert.Equal(t, uint32(8), stored[0].SignCount)
+			assert.Nil(t, stored[0].AAGUID)
+			assert.Equal(t, []string{"internal", "hybrid"}, stored[0].Transports)
+			if assert.NotNil(t, stored[0].LastUsedAt) {
+				assert.True(t, usedAt.Equal(*stored[0].LastUsedAt))
+			}
+		}
+
+		assert.NoError(t, repo.Delete(ctx, passkey.ID))
+		assert.ErrorIs(t, repo.Delete(ctx, passkey.ID), domainErrors.ErrPasskeyNotFound)
+	})
+}
+
+func TestSessionRepository(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, newDatabase func(t *testing.T) *DB) {
+		repo := NewSessionRepository(newDatabase(t))
+		ctx := context.Background()
+
+		session := entities.NewSession(entities.NewUserID(), entities.AuthMethodPassword, "192.0.2.1", "curl", time.Now().Add(time.Hour))
+		assert.NoError(t, repo.Create(ctx, session))
+		session.Revoke(time.Now())
+		assert.NoError(t, repo.Update(ctx, session))
+
+		stored, err := repo.GetByID(ctx, session.ID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, session.UserID, stored.UserID)
+			assert.NotNil(t, stored.RevokedAt)
+		}
+		_, err = repo.GetByID(ctx, entities.NewID())
+		assert.ErrorIs(t, err, domainErrors.ErrSessionNotFound)
+	})
+}
+
+// Links, codes, logins and ceremonies are used once: consuming one returns
+// it the first time only.
+func TestConsume(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, newDatabase func(t *testing.T) *DB) {
+		db := newDatabase(t)
+		ctx := context.Background()
+		expiresAt := time.Now().Add(time.Minute)
+
+		magicLinks := NewMagicLinkRepository(db)
+		link := &entities.MagicLink{TokenHash: "link", TenantID: "acme", Email: "dana@example.com", CreatedAt: time.Now(), ExpiresAt: expiresAt}
+		assert.NoError(t, magicLinks.Create(ctx, link))
+		count, err := magicLinks.CountSince(ctx, "dana@example.com", time.Now().Add(-time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		consumed, err := magicLinks.Consume(ctx, "link")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "dana@example.com", consumed.Email)
+			assert.Equal(t, "acme", consumed.TenantID)
+		}
+		_, err = magicLinks.Consume(ctx, "link")
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidMagicLink)
+
+		codes := NewAuthorizationCodeRepository(db)
+		code := &entities.AuthorizationCode{CodeHash: "code", TenantID: "acme", ClientID: "client", UserID: entities.NewUserID(), Scopes: []string{"openid"}, AuthTime: time.Now(), ExpiresAt: expiresAt}
+		assert.NoError(t, codes.Create(ctx, code))
+		redeemed, err := codes.Consume(ctx, "code")
+		if assert.NoError(t, err) {
+			assert.Equal(t, code.UserID, redeemed.UserID)
+			assert.Equal(t, "acme", redeemed.TenantID)
+			assert.Equal(t, []string{"openid"}, redeemed.Scopes)
+		}
+		_, err = codes.Consume(ctx, "code")
+		assert.ErrorIs(t, err, domainErrors.ErrAuthorizationCodeNotFound)
+
+		logins := NewFederatedLoginRepository(db)
+		assert.NoError(t, logins.Create(ctx, &entities.FederatedLogin{StateHash: "state", Provider: "google", ExpiresAt: expiresAt}))
+		_, err = logins.Consume(ctx, "state")
+		assert.NoError(t, err)
+		_, err = logins.Consume(ctx, "state")
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidFederatedState)
+
+		ceremonies := NewPasskeyCeremonyRepository(db)
+		assert.NoError(t, ceremonies.Create(ctx, &entities.PasskeyCeremony{ChallengeHash: "challenge", Type: "login", ExpiresAt: expiresAt}))
+		_, err = ceremonies.Consume(ctx, "challenge")
+		assert.NoError(t, err)
+		_, err = ceremonies.Consume(ctx, "challenge")
+		assert.ErrorIs(t, err, domainErrors.ErrInvalidPasskeyChallenge)
+	})
+}
+
+func TestOAuthClientRepository(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, newDatabase func(t *testing.T) *DB) {
+		repo := NewOAuthClientRepository(newDatabase(t))
+		ctx := context.Background()
+
+		client, err := entities.NewOAuthClient("client", "secret", "App", []string{"https://app.example.com/callback"}, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(ctx, client))
+		again, err := entities.NewOAuthClient("client", "secret", "App", []string{"https://app.example.com/callback"}, nil)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, repo.Create(ctx, again), domainErrors.ErrOAuthClientExists)
+
+		stored, err := repo.GetByClientID(ctx, "client")
+		if assert.NoError(t, err) {
+			assert.Equal(t, client.ID, stored.ID)
+			assert.Equal(t, []string{"https://app.example.com/callback"}, stored.RedirectURIs)
+		}
+		_, err = repo.GetByClientID(ctx, "unknown")
+		assert.ErrorIs(t, err, domainErrors.ErrOAuthClientNotFound)
+	})
+}
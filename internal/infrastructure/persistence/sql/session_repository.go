@@ -0,0 +1,66 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+)
+
+// sessionColumns are the columns of the sessions table, in the order
+// scanSession reads them.
+const sessionColumns = "id, user_id, auth_method, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at"
+
+type sessionRepository struct {
+	db *DB
+}
+
+func NewSessionRepository(db *DB) repositories.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	return insert(ctx, r.db, nil,
+		"INSERT INTO sessions ("+sessionColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		session.ID.String(), session.UserID.String(), session.AuthMethod, session.IPAddress, session.UserAgent,
+		session.CreatedAt.UTC(), session.LastSeenAt.UTC(), session.ExpiresAt.UTC(), nullTimeOf(session.RevokedAt),
+	)
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Session, error) {
+	return queryOne(ctx, r.db, scanSession, domainErrors.ErrSessionNotFound,
+		"SELECT "+sessionColumns+" FROM sessions WHERE id = ?", id.String())
+}
+
+func (r *sessionRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Session, error) {
+	return queryAll(ctx, r.db, scanSession,
+		"SELECT "+sessionColumns+" FROM sessions WHERE user_id = ? ORDER BY created_at, id", userID.String())
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *entities.Session) error {
+	// The user and how they signed in never change
+	return execOne(ctx, r.db, domainErrors.ErrSessionNotFound, `UPDATE sessions SET
+		ip_address = ?, user_agent = ?, last_seen_at = ?, expires_at = ?, revoked_at = ? WHERE id = ?`,
+		session.IPAddress, session.UserAgent, session.LastSeenAt.UTC(), session.ExpiresAt.UTC(),
+		nullTimeOf(session.RevokedAt), session.ID.String(),
+	)
+}
+
+// scanSession reads a session from a row of sessionColumns.
+func scanSession(row row) (*entities.Session, error) {
+	var session entities.Session
+	var id, userID string
+	var revokedAt sql.NullTime
+	err := row.Scan(&id, &userID, &session.AuthMethod, &session.IPAddress, &session.UserAgent,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	session.ID = entities.ID(id)
+	session.UserID = entities.UserID(userID)
+	session.RevokedAt = timeOf(revokedAt)
+	return &session, nil
+}
@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package sql
+
+import (
+	// Without cgo the driver only fails to connect, saying it needs cgo
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSupported reports whether the binary can open SQLite databases.
+const sqliteSupported = false
+
+func isSQLiteUniqueViolation(err error) bool {
+	return false
+}
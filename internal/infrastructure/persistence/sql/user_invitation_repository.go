@@ -0,0 +1,134 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+// userInvitationColumns are the columns of the user_invitations table, in
+// the order scanUserInvitation reads them.
+const userInvitationColumns = `id, tenant_id, email, email_normalized, token_hash, scopes, invited_by, created_at, expires_at,
+	accepted_at, user_id, revoked_at`
+
+// Invitations are scoped to the tenant, so one of another tenant is never
+// found, except by its token.
+type userInvitationRepository struct {
+	db     *DB
+	policy entities.EmailPolicy
+}
+
+// NewUserInvitationRepository finds invitations by the key of their email
+// under the policy, which must be the one of the user repository.
+func NewUserInvitationRepository(db *DB, policy entities.EmailPolicy) repositories.UserInvitationRepository {
+	return &userInvitationRepository{db: db, policy: policy}
+}
+
+func (r *userInvitationRepository) Create(ctx context.Context, invitation *entities.UserInvitation) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	scopes, err := encodeStrings(invitation.Scopes)
+	if err != nil {
+		return err
+	}
+
+	invitation.TenantID = tenantID
+	invitation.NormalizedEmail = r.policy.Key(invitation.Email)
+	return insert(ctx, r.db, nil,
+		"INSERT INTO user_invitations ("+userInvitationColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		invitation.ID.String(), invitation.TenantID, invitation.Email, invitation.NormalizedEmail, invitation.TokenHash, scopes,
+		invitation.InvitedBy.String(), invitation.CreatedAt.UTC(), invitation.ExpiresAt.UTC(),
+		nullTime(invitation.AcceptedAt), invitation.UserID.String(), nullTime(invitation.RevokedAt),
+	)
+}
+
+func (r *userInvitationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.UserInvitation, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	return queryOne(ctx, r.db, scanUserInvitation, domainErrors.ErrUserInvitationNotFound,
+		"SELECT "+userInvitationColumns+" FROM user_invitations WHERE tenant_id = ? AND id = ?", tenantID, id.String())
+}
+
+func (r *userInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error) {
+	return queryOne(ctx, r.db, scanUserInvitation, domainErrors.ErrUserInvitationNotFound,
+		"SELECT "+userInvitationColumns+" FROM user_invitations WHERE token_hash = ?", tokenHash)
+}
+
+func (r *userInvitationRepository) GetByEmail(ctx context.Context, email string) ([]*entities.UserInvitation, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	return queryAll(ctx, r.db, scanUserInvitation,
+		"SELECT "+userInvitationColumns+" FROM user_invitations WHERE tenant_id = ? AND email_normalized = ? ORDER BY created_at DESC, id DESC",
+		tenantID, r.policy.Key(email))
+}
+
+func (r *userInvitationRepository) GetAll(ctx context.Context) ([]*entities.UserInvitation, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	return queryAll(ctx, r.db, scanUserInvitation,
+		"SELECT "+userInvitationColumns+" FROM user_invitations WHERE tenant_id = ? ORDER BY created_at DESC, id DESC", tenantID)
+}
+
+func (r *userInvitationRepository) Update(ctx context.Context, invitation *entities.UserInvitation) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	scopes, err := encodeStrings(invitation.Scopes)
+	if err != nil {
+		return err
+	}
+
+	// The token and who invited whom never change
+	err = execOne(ctx, r.db, domainErrors.ErrUserInvitationNotFound, `UPDATE user_invitations SET
+		scopes = ?, expires_at = ?, accepted_at = ?, user_id = ?, revoked_at = ? WHERE tenant_id = ? AND id = ?`,
+		scopes, invitation.ExpiresAt.UTC(), nullTime(invitation.AcceptedAt), invitation.UserID.String(),
+		nullTime(invitation.RevokedAt), tenantID, invitation.ID.String(),
+	)
+	if err != nil {
+		return err
+	}
+	invitation.TenantID = tenantID
+	return nil
+}
+
+// scanUserInvitation reads an invitation from a row of userInvitationColumns.
+func scanUserInvitation(row row) (*entities.UserInvitation, error) {
+	var invitation entities.UserInvitation
+	var id, scopes, invitedBy, userID string
+	var acceptedAt, revokedAt sql.NullTime
+	err := row.Scan(&id, &invitation.TenantID, &invitation.Email, &invitation.NormalizedEmail, &invitation.TokenHash, &scopes, &invitedBy,
+		&invitation.CreatedAt, &invitation.ExpiresAt, &acceptedAt, &userID, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.ID = entities.ID(id)
+	if invitation.Scopes, err = decodeStrings(scopes); err != nil {
+		return nil, err
+	}
+	invitation.InvitedBy = entities.UserID(invitedBy)
+	invitation.UserID = entities.UserID(userID)
+	if acceptedAt.Valid {
+		invitation.AcceptedAt = acceptedAt.Time
+	}
+	if revokedAt.Valid {
+		invitation.RevokedAt = revokedAt.Time
+	}
+	return &invitation, nil
+}
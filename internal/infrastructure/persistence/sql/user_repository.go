@@ -0,0 +1,395 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	domainErrors "github.com/wonyus/backend-challenge/internal/domain/errors"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/domain/services"
+)
+
+// userColumns are the columns of the users table, in the order scanUser
+// reads them.
+const userColumns = `id, tenant_id, name, email, email_normalized, password, created_at, updated_at,
+	scopes, external_id, disabled, mfa_enabled, mfa_secret, mfa_last_used_step, mfa_recovery_codes,
+	mfa_failed_attempts, mfa_locked_until`
+
+type userRepository struct {
+	db     *DB
+	policy entities.EmailPolicy
+}
+
+// NewUserRepository tells emails apart by their key under the policy, stored
+// in email_normalized. The schema must have been created with Migrate.
+func NewUserRepository(db *DB, policy entities.EmailPolicy) repositories.UserRepository {
+	return &userRepository{
+		db:     db,
+		policy: policy,
+	}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *entities.User) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	user.TenantID = tenantID
+	user.NormalizedEmail = r.policy.Key(user.Email)
+
+	scopes, recoveryCodes, err := encodeUserLists(user)
+	if err != nil {
+		return err
+	}
+
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, r.db.rebind(`INSERT INTO users (`+userColumns+`)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+			user.ID.String(), user.TenantID, user.Name, user.Email, user.NormalizedEmail, user.Password,
+			user.CreatedAt.UTC(), user.UpdatedAt.UTC(), scopes, user.ExternalID, user.Disabled,
+			user.MFA.Enabled, user.MFA.Secret, user.MFA.LastUsedStep, recoveryCodes,
+			user.MFA.FailedAttempts, nullTime(user.MFA.LockedUntil),
+		)
+		if err != nil {
+			return err
+		}
+		return r.insertIdentities(ctx, tx, user)
+	})
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id entities.UserID) (*entities.User, error) {
+	return r.findOne(ctx, "id = ?", id.String())
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	return r.findOne(ctx, "email_normalized = ?", r.policy.Key(email))
+}
+
+func (r *userRepository) GetByIdentity(ctx context.Context, provider, subject string) (*entities.User, error) {
+	return r.findOne(ctx, "id IN (SELECT user_id FROM user_identities WHERE tenant_id = users.tenant_id AND provider = ? AND subject = ?)", provider, subject)
+}
+
+// findOne returns the user of the tenant of ctx matching the condition where.
+func (r *userRepository) findOne(ctx context.Context, where string, args ...interface{}) (*entities.User, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	row := r.db.QueryRowContext(ctx,
+		r.db.rebind("SELECT "+userColumns+" FROM users WHERE tenant_id = ? AND "+where),
+		append([]interface{}{tenantID}, args...)...,
+	)
+	user, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domainErrors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	identities, err := r.identities(ctx, "user_id = ?", user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	user.Identities = identities[user.ID]
+	return user, nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, domainErrors.ErrTenantRequired
+	}
+
+	return r.list(ctx, "tenant_id = ? ORDER BY created_at, "+r.db.orderBy("id"), tenantID)
+}
+
+func (r *userRepository) Find(ctx context.Context, query repositories.UserQuery) ([]*entities.User, int64, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return nil, 0, domainErrors.ErrTenantRequired
+	}
+
+	where := "tenant_id = ?"
+	args := []interface{}{tenantID}
+	if query.Email != "" {
+		where += " AND email_normalized = ?"
+		args = append(args, r.policy.Key(query.Email))
+	}
+	if query.ExternalID != "" {
+		where += " AND external_id = ?"
+		args = append(args, query.ExternalID)
+	}
+	if query.Disabled != nil {
+		where += " AND disabled = ?"
+		args = append(args, *query.Disabled)
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, r.db.rebind("SELECT COUNT(*) FROM users WHERE "+where), args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	column := "created_at"
+	switch query.Sort {
+	case repositories.UserSortName:
+		column = r.db.orderBy("name")
+	case repositories.UserSortEmail:
+		column = r.db.orderBy("email")
+	}
+	direction := " ASC"
+	if query.Descending {
+		direction = " DESC"
+	}
+	page := where + " ORDER BY " + column + direction + ", " + r.db.orderBy("id") + direction
+	if query.Limit > 0 {
+		page += " LIMIT ?"
+		args = append(args, query.Limit)
+	} else if query.Offset > 0 && r.db.driver == DriverSQLite {
+		// SQLite only takes an offset after a limit, which -1 lifts
+		page += " LIMIT -1"
+	}
+	if query.Offset > 0 {
+		page += " OFFSET ?"
+		args = append(args, query.Offset)
+	}
+
+	users, err := r.list(ctx, page, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// list returns the users the condition where selects, which must be scoped
+// to a tenant and may order and limit them, with their identities.
+func (r *userRepository) list(ctx context.Context, where string, args ...interface{}) ([]*entities.User, error) {
+	rows, err := r.db.QueryContext(ctx, r.db.rebind("SELECT "+userColumns+" FROM users WHERE "+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*entities.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// SQLite has a single connection, which the rows hold until closed
+	rows.Close()
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	identities, err := r.identities(ctx, "user_id IN (SELECT id FROM users WHERE "+where+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		user.Identities = identities[user.ID]
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *entities.User) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+	user.NormalizedEmail = r.policy.Key(user.Email)
+
+	scopes, recoveryCodes, err := encodeUserLists(user)
+	if err != nil {
+		return err
+	}
+
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		// Every mutable field is set; the ID, tenant and creation time never
+		// change
+		result, err := tx.ExecContext(ctx, r.db.rebind(`UPDATE users SET
+			name = ?, email = ?, email_normalized = ?, password = ?, updated_at = ?, scopes = ?,
+			external_id = ?, disabled = ?, mfa_enabled = ?, mfa_secret = ?, mfa_last_used_step = ?,
+			mfa_recovery_codes = ?, mfa_failed_attempts = ?, mfa_locked_until = ?
+			WHERE tenant_id = ? AND id = ?`),
+			user.Name, user.Email, user.NormalizedEmail, user.Password, user.UpdatedAt.UTC(), scopes,
+			user.ExternalID, user.Disabled, user.MFA.Enabled, user.MFA.Secret, user.MFA.LastUsedStep,
+			recoveryCodes, user.MFA.FailedAttempts, nullTime(user.MFA.LockedUntil),
+			tenantID, user.ID.String(),
+		)
+		if err != nil {
+			return err
+		}
+		if updated, err := result.RowsAffected(); err != nil {
+			return err
+		} else if updated == 0 {
+			return domainErrors.ErrUserNotFound
+		}
+
+		// Users never move between tenants
+		user.TenantID = tenantID
+		if _, err := tx.ExecContext(ctx, r.db.rebind("DELETE FROM user_identities WHERE user_id = ?"), user.ID.String()); err != nil {
+			return err
+		}
+		return r.insertIdentities(ctx, tx, user)
+	})
+}
+
+func (r *userRepository) Delete(ctx context.Context, id entities.UserID) error {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return domainErrors.ErrTenantRequired
+	}
+
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, r.db.rebind("DELETE FROM user_identities WHERE tenant_id = ? AND user_id = ?"), tenantID, id.String())
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, r.db.rebind("DELETE FROM users WHERE tenant_id = ? AND id = ?"), tenantID, id.String())
+		if err != nil {
+			return err
+		}
+		if deleted, err := result.RowsAffected(); err != nil {
+			return err
+		} else if deleted == 0 {
+			return domainErrors.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
+	tenantID, ok := services.TenantFromContext(ctx)
+	if !ok {
+		return 0, domainErrors.ErrTenantRequired
+	}
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, r.db.rebind("SELECT COUNT(*) FROM users WHERE tenant_id = ?"), tenantID).Scan(&count)
+	return count, err
+}
+
+// inTx runs fn in a transaction, committed if fn succeeds. A unique
+// constraint failing, on the email or a linked identity, means the user
+// already exists.
+func (r *userRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		if isUniqueViolation(err) {
+			return domainErrors.ErrUserAlreadyExists
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		if isUniqueViolation(err) {
+			return domainErrors.ErrUserAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *userRepository) insertIdentities(ctx context.Context, tx *sql.Tx, user *entities.User) error {
+	for i, identity := range user.Identities {
+		_, err := tx.ExecContext(ctx, r.db.rebind(`INSERT INTO user_identities
+			(user_id, position, tenant_id, provider, subject, email, linked_at) VALUES (?, ?, ?, ?, ?, ?, ?)`),
+			user.ID.String(), i, user.TenantID, identity.Provider, identity.Subject, identity.Email, identity.LinkedAt.UTC(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// identities returns the linked identities matching the condition where, in
+// the order they were linked, by user.
+func (r *userRepository) identities(ctx context.Context, where string, args ...interface{}) (map[entities.UserID][]entities.ExternalIdentity, error) {
+	rows, err := r.db.QueryContext(ctx,
+		r.db.rebind("SELECT user_id, provider, subject, email, linked_at FROM user_identities WHERE "+where+" ORDER BY user_id, position"),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := make(map[entities.UserID][]entities.ExternalIdentity)
+	for rows.Next() {
+		var userID string
+		var identity entities.ExternalIdentity
+		if err := rows.Scan(&userID, &identity.Provider, &identity.Subject, &identity.Email, &identity.LinkedAt); err != nil {
+			return nil, err
+		}
+		identities[entities.UserID(userID)] = append(identities[entities.UserID(userID)], identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// scanUser reads a user from a row of userColumns, without its identities.
+func scanUser(row interface {
+	Scan(dest ...interface{}) error
+}) (*entities.User, error) {
+	var user entities.User
+	var id, scopes, recoveryCodes string
+	var lockedUntil sql.NullTime
+	err := row.Scan(
+		&id, &user.TenantID, &user.Name, &user.Email, &user.NormalizedEmail, &user.Password,
+		&user.CreatedAt, &user.UpdatedAt, &scopes, &user.ExternalID, &user.Disabled,
+		&user.MFA.Enabled, &user.MFA.Secret, &user.MFA.LastUsedStep, &recoveryCodes,
+		&user.MFA.FailedAttempts, &lockedUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.ID = entities.UserID(id)
+	if err := json.Unmarshal([]byte(scopes), &user.Scopes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(recoveryCodes), &user.MFA.RecoveryCodes); err != nil {
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		user.MFA.LockedUntil = lockedUntil.Time
+	}
+	return &user, nil
+}
+
+// encodeUserLists returns the scopes and recovery codes of user as JSON
+// arrays, the way they are stored.
+func encodeUserLists(user *entities.User) (string, string, error) {
+	scopes, err := json.Marshal(user.Scopes)
+	if err != nil {
+		return "", "", err
+	}
+	recoveryCodes, err := json.Marshal(user.MFA.RecoveryCodes)
+	if err != nil {
+		return "", "", err
+	}
+	return string(scopes), string(recoveryCodes), nil
+}
+
+// nullTime stores the zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
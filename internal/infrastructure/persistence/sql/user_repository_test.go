@@ -0,0 +1,131 @@
+package sql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wonyus/backend-challenge/internal/domain/entities"
+	"github.com/wonyus/backend-challenge/internal/domain/repositories"
+	"github.com/wonyus/backend-challenge/internal/infrastructure/persistence/persistencetest"
+)
+
+// newSQLiteDatabase returns a migrated SQLite database in a file of its own.
+func newSQLiteDatabase(t *testing.T) *DB {
+	t.Helper()
+
+	if !sqliteSupported {
+		t.Fatal("SQLite needs cgo: run the tests with CGO_ENABLED=1")
+	}
+	db, err := Open(DriverSQLite, filepath.Join(t.TempDir(), "users.db"))
+	if err != nil {
+		t.Fatalf("open SQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = Migrate(context.Background(), db)
+	assert.NoError(t, err)
+	return db
+}
+
+// newPostgresDatabase connects to the PostgreSQL at POSTGRES_TEST_DSN, a
+// connection URL, and returns a migrated schema of its own, dropped after
+// the test.
+func newPostgresDatabase(t *testing.T) *DB {
+	t.Helper()
+
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	admin, err := Open(DriverPostgres, dsn)
+	if err != nil {
+		t.Fatalf("connect to PostgreSQL: %v", err)
+	}
+	schema := "test_" + strings.ToLower(entities.NewULIDUserID().String())
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	db, err := Open(DriverPostgres, dsn+separator+"search_path="+schema)
+	if err != nil {
+		t.Fatalf("connect to PostgreSQL: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = Migrate(context.Background(), db)
+	assert.NoError(t, err)
+	return db
+}
+
+// eachDatabase runs test against a migrated database of each driver.
+func eachDatabase(t *testing.T, test func(t *testing.T, newDatabase func(t *testing.T) *DB)) {
+	for driver, newDatabase := range map[string]func(t *testing.T) *DB{
+		DriverSQLite:   newSQLiteDatabase,
+		DriverPostgres: newPostgresDatabase,
+	} {
+		t.Run(driver, func(t *testing.T) {
+			test(t, newDatabase)
+		})
+	}
+}
+
+func TestUserRepository(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, newDatabase func(t *testing.T) *DB) {
+		persistencetest.UserRepository(t, func(t *testing.T) repositories.UserRepository {
+			return NewUserRepository(newDatabase(t), entities.EmailPolicy{})
+		})
+	})
+}
+
+func TestAPIKeyRepository_MarkUsed(t *testing.T) {
+	eachDatabase(t, func(t *testing.T, newDatabase func(t *testing.T) *DB) {
+		persistencetest.APIKeyMarkUsed(t, NewAPIKeyRepository(newDatabase(t)))
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	db := newSQLiteDatabase(t)
+
+	// Applied migrations are not applied again
+	applied, err := Migrate(context.Background(), db)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	var versions int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&versions))
+	migrations, err := Migrations(DriverSQLite)
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), versions)
+}
+
+func TestMigrations_MatchAcrossDrivers(t *testing.T) {
+	sqlite, err := Migrations(DriverSQLite)
+	assert.NoError(t, err)
+	postgres, err := Migrations(DriverPostgres)
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(sqlite), len(postgres))
+	for i := range sqlite {
+		assert.Equal(t, i+1, sqlite[i].Version)
+		assert.Equal(t, sqlite[i].Version, postgres[i].Version)
+		assert.Equal(t, sqlite[i].Description, postgres[i].Description)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := "SELECT id FROM users WHERE tenant_id = ? AND email_normalized = ?"
+	assert.Equal(t, query, (&DB{driver: DriverSQLite}).rebind(query))
+	assert.Equal(t, "SELECT id FROM users WHERE tenant_id = $1 AND email_normalized = $2", (&DB{driver: DriverPostgres}).rebind(query))
+}
@@ -0,0 +1,71 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\domain\repositories\federated_login_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\domain\repositories\federated_login_repository.go -destination .\mock\mongodb\federated_login_repository.go
+//
+
+// Package mock_repositories is a generated GoMock package.
+package mock_repositories
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFederatedLoginRepository is a mock of FederatedLoginRepository interface.
+type MockFederatedLoginRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFederatedLoginRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockFederatedLoginRepositoryMockRecorder is the mock recorder for MockFederatedLoginRepository.
+type MockFederatedLoginRepositoryMockRecorder struct {
+	mock *MockFederatedLoginRepository
+}
+
+// NewMockFederatedLoginRepository creates a new mock instance.
+func NewMockFederatedLoginRepository(ctrl *gomock.Controller) *MockFederatedLoginRepository {
+	mock := &MockFederatedLoginRepository{ctrl: ctrl}
+	mock.recorder = &MockFederatedLoginRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFederatedLoginRepository) EXPECT() *MockFederatedLoginRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockFederatedLoginRepository) Consume(ctx context.Context, stateHash string) (*entities.FederatedLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, stateHash)
+	ret0, _ := ret[0].(*entities.FederatedLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockFederatedLoginRepositoryMockRecorder) Consume(ctx, stateHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockFederatedLoginRepository)(nil).Consume), ctx, stateHash)
+}
+
+// Create mocks base method.
+func (m *MockFederatedLoginRepository) Create(ctx context.Context, login *entities.FederatedLogin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFederatedLoginRepositoryMockRecorder) Create(ctx, login any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFederatedLoginRepository)(nil).Create), ctx, login)
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\domain\repositories\magic_link_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\domain\repositories\magic_link_repository.go -destination .\mock\mongodb\magic_link_repository.go
+//
+
+// Package mock_repositories is a generated GoMock package.
+package mock_repositories
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMagicLinkRepository is a mock of MagicLinkRepository interface.
+type MockMagicLinkRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMagicLinkRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMagicLinkRepositoryMockRecorder is the mock recorder for MockMagicLinkRepository.
+type MockMagicLinkRepositoryMockRecorder struct {
+	mock *MockMagicLinkRepository
+}
+
+// NewMockMagicLinkRepository creates a new mock instance.
+func NewMockMagicLinkRepository(ctrl *gomock.Controller) *MockMagicLinkRepository {
+	mock := &MockMagicLinkRepository{ctrl: ctrl}
+	mock.recorder = &MockMagicLinkRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMagicLinkRepository) EXPECT() *MockMagicLinkRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockMagicLinkRepository) Consume(ctx context.Context, tokenHash string) (*entities.MagicLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, tokenHash)
+	ret0, _ := ret[0].(*entities.MagicLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockMagicLinkRepositoryMockRecorder) Consume(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockMagicLinkRepository)(nil).Consume), ctx, tokenHash)
+}
+
+// CountSince mocks base method.
+func (m *MockMagicLinkRepository) CountSince(ctx context.Context, email string, since time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSince", ctx, email, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSince indicates an expected call of CountSince.
+func (mr *MockMagicLinkRepositoryMockRecorder) CountSince(ctx, email, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSince", reflect.TypeOf((*MockMagicLinkRepository)(nil).CountSince), ctx, email, since)
+}
+
+// Create mocks base method.
+func (m *MockMagicLinkRepository) Create(ctx context.Context, link *entities.MagicLink) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMagicLinkRepositoryMockRecorder) Create(ctx, link any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMagicLinkRepository)(nil).Create), ctx, link)
+}
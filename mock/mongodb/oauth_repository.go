@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\domain\repositories\oauth_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\domain\repositories\oauth_repository.go -destination .\mock\mongodb\oauth_repository.go
+//
+
+// Package mock_repositories is a generated GoMock package.
+package mock_repositories
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuthClientRepository is a mock of OAuthClientRepository interface.
+type MockOAuthClientRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthClientRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOAuthClientRepositoryMockRecorder is the mock recorder for MockOAuthClientRepository.
+type MockOAuthClientRepositoryMockRecorder struct {
+	mock *MockOAuthClientRepository
+}
+
+// NewMockOAuthClientRepository creates a new mock instance.
+func NewMockOAuthClientRepository(ctrl *gomock.Controller) *MockOAuthClientRepository {
+	mock := &MockOAuthClientRepository{ctrl: ctrl}
+	mock.recorder = &MockOAuthClientRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthClientRepository) EXPECT() *MockOAuthClientRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOAuthClientRepository) Create(ctx context.Context, client *entities.OAuthClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOAuthClientRepositoryMockRecorder) Create(ctx, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOAuthClientRepository)(nil).Create), ctx, client)
+}
+
+// GetByClientID mocks base method.
+func (m *MockOAuthClientRepository) GetByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByClientID", ctx, clientID)
+	ret0, _ := ret[0].(*entities.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByClientID indicates an expected call of GetByClientID.
+func (mr *MockOAuthClientRepositoryMockRecorder) GetByClientID(ctx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByClientID", reflect.TypeOf((*MockOAuthClientRepository)(nil).GetByClientID), ctx, clientID)
+}
+
+// MockAuthorizationCodeRepository is a mock of AuthorizationCodeRepository interface.
+type MockAuthorizationCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizationCodeRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAuthorizationCodeRepositoryMockRecorder is the mock recorder for MockAuthorizationCodeRepository.
+type MockAuthorizationCodeRepositoryMockRecorder struct {
+	mock *MockAuthorizationCodeRepository
+}
+
+// NewMockAuthorizationCodeRepository creates a new mock instance.
+func NewMockAuthorizationCodeRepository(ctrl *gomock.Controller) *MockAuthorizationCodeRepository {
+	mock := &MockAuthorizationCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockAuthorizationCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizationCodeRepository) EXPECT() *MockAuthorizationCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockAuthorizationCodeRepository) Consume(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, codeHash)
+	ret0, _ := ret[0].(*entities.AuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockAuthorizationCodeRepositoryMockRecorder) Consume(ctx, codeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockAuthorizationCodeRepository)(nil).Consume), ctx, codeHash)
+}
+
+// Create mocks base method.
+func (m *MockAuthorizationCodeRepository) Create(ctx context.Context, code *entities.AuthorizationCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuthorizationCodeRepositoryMockRecorder) Create(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAuthorizationCodeRepository)(nil).Create), ctx, code)
+}
@@ -0,0 +1,348 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\domain\repositories\organization_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\domain\repositories\organization_repository.go -destination .\mock\mongodb\organization_repository.go
+//
+
+// Package mock_repositories is a generated GoMock package.
+package mock_repositories
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrganizationRepository is a mock of OrganizationRepository interface.
+type MockOrganizationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockOrganizationRepositoryMockRecorder is the mock recorder for MockOrganizationRepository.
+type MockOrganizationRepositoryMockRecorder struct {
+	mock *MockOrganizationRepository
+}
+
+// NewMockOrganizationRepository creates a new mock instance.
+func NewMockOrganizationRepository(ctrl *gomock.Controller) *MockOrganizationRepository {
+	mock := &MockOrganizationRepository{ctrl: ctrl}
+	mock.recorder = &MockOrganizationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationRepository) EXPECT() *MockOrganizationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOrganizationRepository) Create(ctx context.Context, organization *entities.Organization) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, organization)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOrganizationRepositoryMockRecorder) Create(ctx, organization any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrganizationRepository)(nil).Create), ctx, organization)
+}
+
+// Delete mocks base method.
+func (m *MockOrganizationRepository) Delete(ctx context.Context, id entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockOrganizationRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrganizationRepository)(nil).Delete), ctx, id)
+}
+
+// GetByID mocks base method.
+func (m *MockOrganizationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entities.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockOrganizationRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockOrganizationRepository)(nil).GetByID), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockOrganizationRepository) Update(ctx context.Context, organization *entities.Organization) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, organization)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOrganizationRepositoryMockRecorder) Update(ctx, organization any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOrganizationRepository)(nil).Update), ctx, organization)
+}
+
+// MockTeamRepository is a mock of TeamRepository interface.
+type MockTeamRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTeamRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTeamRepositoryMockRecorder is the mock recorder for MockTeamRepository.
+type MockTeamRepositoryMockRecorder struct {
+	mock *MockTeamRepository
+}
+
+// NewMockTeamRepository creates a new mock instance.
+func NewMockTeamRepository(ctrl *gomock.Controller) *MockTeamRepository {
+	mock := &MockTeamRepository{ctrl: ctrl}
+	mock.recorder = &MockTeamRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTeamRepository) EXPECT() *MockTeamRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTeamRepository) Create(ctx context.Context, team *entities.Team) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, team)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTeamRepositoryMockRecorder) Create(ctx, team any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTeamRepository)(nil).Create), ctx, team)
+}
+
+// Delete mocks base method.
+func (m *MockTeamRepository) Delete(ctx context.Context, id entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTeamRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTeamRepository)(nil).Delete), ctx, id)
+}
+
+// DeleteByOrganizationID mocks base method.
+func (m *MockTeamRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByOrganizationID", ctx, organizationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByOrganizationID indicates an expected call of DeleteByOrganizationID.
+func (mr *MockTeamRepositoryMockRecorder) DeleteByOrganizationID(ctx, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByOrganizationID", reflect.TypeOf((*MockTeamRepository)(nil).DeleteByOrganizationID), ctx, organizationID)
+}
+
+// GetByID mocks base method.
+func (m *MockTeamRepository) GetByID(ctx context.Context, id entities.ID) (*entities.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entities.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockTeamRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockTeamRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByOrganizationID mocks base method.
+func (m *MockTeamRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrganizationID", ctx, organizationID)
+	ret0, _ := ret[0].([]*entities.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrganizationID indicates an expected call of GetByOrganizationID.
+func (mr *MockTeamRepositoryMockRecorder) GetByOrganizationID(ctx, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrganizationID", reflect.TypeOf((*MockTeamRepository)(nil).GetByOrganizationID), ctx, organizationID)
+}
+
+// Update mocks base method.
+func (m *MockTeamRepository) Update(ctx context.Context, team *entities.Team) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, team)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTeamRepositoryMockRecorder) Update(ctx, team any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTeamRepository)(nil).Update), ctx, team)
+}
+
+// MockMembershipRepository is a mock of MembershipRepository interface.
+type MockMembershipRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMembershipRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMembershipRepositoryMockRecorder is the mock recorder for MockMembershipRepository.
+type MockMembershipRepositoryMockRecorder struct {
+	mock *MockMembershipRepository
+}
+
+// NewMockMembershipRepository creates a new mock instance.
+func NewMockMembershipRepository(ctrl *gomock.Controller) *MockMembershipRepository {
+	mock := &MockMembershipRepository{ctrl: ctrl}
+	mock.recorder = &MockMembershipRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMembershipRepository) EXPECT() *MockMembershipRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockMembershipRepository) Create(ctx context.Context, membership *entities.Membership) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, membership)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMembershipRepositoryMockRecorder) Create(ctx, membership any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMembershipRepository)(nil).Create), ctx, membership)
+}
+
+// Delete mocks base method.
+func (m *MockMembershipRepository) Delete(ctx context.Context, id entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockMembershipRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMembershipRepository)(nil).Delete), ctx, id)
+}
+
+// DeleteByOrganizationID mocks base method.
+func (m *MockMembershipRepository) DeleteByOrganizationID(ctx context.Context, organizationID entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByOrganizationID", ctx, organizationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByOrganizationID indicates an expected call of DeleteByOrganizationID.
+func (mr *MockMembershipRepositoryMockRecorder) DeleteByOrganizationID(ctx, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByOrganizationID", reflect.TypeOf((*MockMembershipRepository)(nil).DeleteByOrganizationID), ctx, organizationID)
+}
+
+// DeleteByTeamID mocks base method.
+func (m *MockMembershipRepository) DeleteByTeamID(ctx context.Context, teamID entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByTeamID", ctx, teamID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByTeamID indicates an expected call of DeleteByTeamID.
+func (mr *MockMembershipRepositoryMockRecorder) DeleteByTeamID(ctx, teamID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByTeamID", reflect.TypeOf((*MockMembershipRepository)(nil).DeleteByTeamID), ctx, teamID)
+}
+
+// Get mocks base method.
+func (m *MockMembershipRepository) Get(ctx context.Context, organizationID, teamID entities.ID, userID entities.UserID) (*entities.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, organizationID, teamID, userID)
+	ret0, _ := ret[0].(*entities.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockMembershipRepositoryMockRecorder) Get(ctx, organizationID, teamID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMembershipRepository)(nil).Get), ctx, organizationID, teamID, userID)
+}
+
+// GetByOrganizationID mocks base method.
+func (m *MockMembershipRepository) GetByOrganizationID(ctx context.Context, organizationID entities.ID) ([]*entities.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByOrganizationID", ctx, organizationID)
+	ret0, _ := ret[0].([]*entities.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByOrganizationID indicates an expected call of GetByOrganizationID.
+func (mr *MockMembershipRepositoryMockRecorder) GetByOrganizationID(ctx, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOrganizationID", reflect.TypeOf((*MockMembershipRepository)(nil).GetByOrganizationID), ctx, organizationID)
+}
+
+// GetByUserID mocks base method.
+func (m *MockMembershipRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*entities.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockMembershipRepositoryMockRecorder) GetByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockMembershipRepository)(nil).GetByUserID), ctx, userID)
+}
+
+// Update mocks base method.
+func (m *MockMembershipRepository) Update(ctx context.Context, membership *entities.Membership) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, membership)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMembershipRepositoryMockRecorder) Update(ctx, membership any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMembershipRepository)(nil).Update), ctx, membership)
+}
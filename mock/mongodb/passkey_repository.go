@@ -0,0 +1,167 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\domain\repositories\passkey_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\domain\repositories\passkey_repository.go -destination .\mock\mongodb\passkey_repository.go
+//
+
+// Package mock_repositories is a generated GoMock package.
+package mock_repositories
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPasskeyRepository is a mock of PasskeyRepository interface.
+type MockPasskeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPasskeyRepositoryMockRecorder is the mock recorder for MockPasskeyRepository.
+type MockPasskeyRepositoryMockRecorder struct {
+	mock *MockPasskeyRepository
+}
+
+// NewMockPasskeyRepository creates a new mock instance.
+func NewMockPasskeyRepository(ctrl *gomock.Controller) *MockPasskeyRepository {
+	mock := &MockPasskeyRepository{ctrl: ctrl}
+	mock.recorder = &MockPasskeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasskeyRepository) EXPECT() *MockPasskeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPasskeyRepository) Create(ctx context.Context, passkey *entities.Passkey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, passkey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPasskeyRepositoryMockRecorder) Create(ctx, passkey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPasskeyRepository)(nil).Create), ctx, passkey)
+}
+
+// Delete mocks base method.
+func (m *MockPasskeyRepository) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockPasskeyRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPasskeyRepository)(nil).Delete), ctx, id)
+}
+
+// GetByID mocks base method.
+func (m *MockPasskeyRepository) GetByID(ctx context.Context, id string) (*entities.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entities.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockPasskeyRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPasskeyRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByUserID mocks base method.
+func (m *MockPasskeyRepository) GetByUserID(ctx context.Context, userID entities.UserID) ([]*entities.Passkey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*entities.Passkey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockPasskeyRepositoryMockRecorder) GetByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockPasskeyRepository)(nil).GetByUserID), ctx, userID)
+}
+
+// Update mocks base method.
+func (m *MockPasskeyRepository) Update(ctx context.Context, passkey *entities.Passkey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, passkey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPasskeyRepositoryMockRecorder) Update(ctx, passkey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPasskeyRepository)(nil).Update), ctx, passkey)
+}
+
+// MockPasskeyCeremonyRepository is a mock of PasskeyCeremonyRepository interface.
+type MockPasskeyCeremonyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyCeremonyRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPasskeyCeremonyRepositoryMockRecorder is the mock recorder for MockPasskeyCeremonyRepository.
+type MockPasskeyCeremonyRepositoryMockRecorder struct {
+	mock *MockPasskeyCeremonyRepository
+}
+
+// NewMockPasskeyCeremonyRepository creates a new mock instance.
+func NewMockPasskeyCeremonyRepository(ctrl *gomock.Controller) *MockPasskeyCeremonyRepository {
+	mock := &MockPasskeyCeremonyRepository{ctrl: ctrl}
+	mock.recorder = &MockPasskeyCeremonyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasskeyCeremonyRepository) EXPECT() *MockPasskeyCeremonyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockPasskeyCeremonyRepository) Consume(ctx context.Context, challengeHash string) (*entities.PasskeyCeremony, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, challengeHash)
+	ret0, _ := ret[0].(*entities.PasskeyCeremony)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockPasskeyCeremonyRepositoryMockRecorder) Consume(ctx, challengeHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockPasskeyCeremonyRepository)(nil).Consume), ctx, challengeHash)
+}
+
+// Create mocks base method.
+func (m *MockPasskeyCeremonyRepository) Create(ctx context.Context, ceremony *entities.PasskeyCeremony) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, ceremony)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPasskeyCeremonyRepositoryMockRecorder) Create(ctx, ceremony any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPasskeyCeremonyRepository)(nil).Create), ctx, ceremony)
+}
@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\domain\repositories\user_invitation_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\domain\repositories\user_invitation_repository.go -destination .\mock\mongodb\user_invitation_repository.go
+//
+
+// Package mock_repositories is a generated GoMock package.
+package mock_repositories
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserInvitationRepository is a mock of UserInvitationRepository interface.
+type MockUserInvitationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserInvitationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserInvitationRepositoryMockRecorder is the mock recorder for MockUserInvitationRepository.
+type MockUserInvitationRepositoryMockRecorder struct {
+	mock *MockUserInvitationRepository
+}
+
+// NewMockUserInvitationRepository creates a new mock instance.
+func NewMockUserInvitationRepository(ctrl *gomock.Controller) *MockUserInvitationRepository {
+	mock := &MockUserInvitationRepository{ctrl: ctrl}
+	mock.recorder = &MockUserInvitationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserInvitationRepository) EXPECT() *MockUserInvitationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUserInvitationRepository) Create(ctx context.Context, invitation *entities.UserInvitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, invitation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserInvitationRepositoryMockRecorder) Create(ctx, invitation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserInvitationRepository)(nil).Create), ctx, invitation)
+}
+
+// GetAll mocks base method.
+func (m *MockUserInvitationRepository) GetAll(ctx context.Context) ([]*entities.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*entities.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockUserInvitationRepositoryMockRecorder) GetAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockUserInvitationRepository)(nil).GetAll), ctx)
+}
+
+// GetByEmail mocks base method.
+func (m *MockUserInvitationRepository) GetByEmail(ctx context.Context, email string) ([]*entities.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].([]*entities.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockUserInvitationRepositoryMockRecorder) GetByEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUserInvitationRepository)(nil).GetByEmail), ctx, email)
+}
+
+// GetByID mocks base method.
+func (m *MockUserInvitationRepository) GetByID(ctx context.Context, id entities.ID) (*entities.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*entities.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockUserInvitationRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserInvitationRepository)(nil).GetByID), ctx, id)
+}
+
+// GetByTokenHash mocks base method.
+func (m *MockUserInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTokenHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*entities.UserInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByTokenHash indicates an expected call of GetByTokenHash.
+func (mr *MockUserInvitationRepositoryMockRecorder) GetByTokenHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHash", reflect.TypeOf((*MockUserInvitationRepository)(nil).GetByTokenHash), ctx, tokenHash)
+}
+
+// Update mocks base method.
+func (m *MockUserInvitationRepository) Update(ctx context.Context, invitation *entities.UserInvitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, invitation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUserInvitationRepositoryMockRecorder) Update(ctx, invitation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserInvitationRepository)(nil).Update), ctx, invitation)
+}
@@ -14,7 +14,7 @@ import (
 	reflect "reflect"
 
 	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
-	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+	repositories "github.com/wonyus/backend-challenge/internal/domain/repositories"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -72,7 +72,7 @@ func (mr *MockUserRepositoryMockRecorder) Create(ctx, user any) *gomock.Call {
 }
 
 // Delete mocks base method.
-func (m *MockUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+func (m *MockUserRepository) Delete(ctx context.Context, id entities.UserID) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", ctx, id)
 	ret0, _ := ret[0].(error)
@@ -85,6 +85,22 @@ func (mr *MockUserRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserRepository)(nil).Delete), ctx, id)
 }
 
+// Find mocks base method.
+func (m *MockUserRepository) Find(ctx context.Context, query repositories.UserQuery) ([]*entities.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, query)
+	ret0, _ := ret[0].([]*entities.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockUserRepositoryMockRecorder) Find(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockUserRepository)(nil).Find), ctx, query)
+}
+
 // GetAll mocks base method.
 func (m *MockUserRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
 	m.ctrl.T.Helper()
@@ -116,7 +132,7 @@ func (mr *MockUserRepositoryMockRecorder) GetByEmail(ctx, email any) *gomock.Cal
 }
 
 // GetByID mocks base method.
-func (m *MockUserRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*entities.User, error) {
+func (m *MockUserRepository) GetByID(ctx context.Context, id entities.UserID) (*entities.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetByID", ctx, id)
 	ret0, _ := ret[0].(*entities.User)
@@ -130,6 +146,21 @@ func (mr *MockUserRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByIdentity mocks base method.
+func (m *MockUserRepository) GetByIdentity(ctx context.Context, provider, subject string) (*entities.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIdentity", ctx, provider, subject)
+	ret0, _ := ret[0].(*entities.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIdentity indicates an expected call of GetByIdentity.
+func (mr *MockUserRepositoryMockRecorder) GetByIdentity(ctx, provider, subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdentity", reflect.TypeOf((*MockUserRepository)(nil).GetByIdentity), ctx, provider, subject)
+}
+
 // Update mocks base method.
 func (m *MockUserRepository) Update(ctx context.Context, user *entities.User) error {
 	m.ctrl.T.Helper()
@@ -72,10 +72,10 @@ func (mr *MockAuthServiceMockRecorder) Register(ctx, req any) *gomock.Call {
 }
 
 // ValidateToken mocks base method.
-func (m *MockAuthService) ValidateToken(ctx context.Context, token string) (*dto.UserResponse, error) {
+func (m *MockAuthService) ValidateToken(ctx context.Context, token string) (*dto.Principal, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ValidateToken", ctx, token)
-	ret0, _ := ret[0].(*dto.UserResponse)
+	ret0, _ := ret[0].(*dto.Principal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\application\ports\federation_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\application\ports\federation_service.go -destination .\mock\port\federation_service.go
+//
+
+// Package mock_ports is a generated GoMock package.
+package mock_ports
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/wonyus/backend-challenge/internal/application/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFederationService is a mock of FederationService interface.
+type MockFederationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockFederationServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockFederationServiceMockRecorder is the mock recorder for MockFederationService.
+type MockFederationServiceMockRecorder struct {
+	mock *MockFederationService
+}
+
+// NewMockFederationService creates a new mock instance.
+func NewMockFederationService(ctrl *gomock.Controller) *MockFederationService {
+	mock := &MockFederationService{ctrl: ctrl}
+	mock.recorder = &MockFederationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFederationService) EXPECT() *MockFederationServiceMockRecorder {
+	return m.recorder
+}
+
+// CompleteLogin mocks base method.
+func (m *MockFederationService) CompleteLogin(ctx context.Context, provider, state, code string) (*dto.LoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteLogin", ctx, provider, state, code)
+	ret0, _ := ret[0].(*dto.LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteLogin indicates an expected call of CompleteLogin.
+func (mr *MockFederationServiceMockRecorder) CompleteLogin(ctx, provider, state, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteLogin", reflect.TypeOf((*MockFederationService)(nil).CompleteLogin), ctx, provider, state, code)
+}
+
+// Providers mocks base method.
+func (m *MockFederationService) Providers() *dto.IdentityProvidersResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Providers")
+	ret0, _ := ret[0].(*dto.IdentityProvidersResponse)
+	return ret0
+}
+
+// Providers indicates an expected call of Providers.
+func (mr *MockFederationServiceMockRecorder) Providers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Providers", reflect.TypeOf((*MockFederationService)(nil).Providers))
+}
+
+// StartLogin mocks base method.
+func (m *MockFederationService) StartLogin(ctx context.Context, provider string) (*dto.FederatedLoginStart, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartLogin", ctx, provider)
+	ret0, _ := ret[0].(*dto.FederatedLoginStart)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartLogin indicates an expected call of StartLogin.
+func (mr *MockFederationServiceMockRecorder) StartLogin(ctx, provider any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartLogin", reflect.TypeOf((*MockFederationService)(nil).StartLogin), ctx, provider)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\application\ports\impersonation_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\application\ports\impersonation_service.go -destination .\mock\port\impersonation_service.go
+//
+
+// Package mock_ports is a generated GoMock package.
+package mock_ports
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/wonyus/backend-challenge/internal/application/dto"
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockImpersonationService is a mock of ImpersonationService interface.
+type MockImpersonationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockImpersonationServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockImpersonationServiceMockRecorder is the mock recorder for MockImpersonationService.
+type MockImpersonationServiceMockRecorder struct {
+	mock *MockImpersonationService
+}
+
+// NewMockImpersonationService creates a new mock instance.
+func NewMockImpersonationService(ctrl *gomock.Controller) *MockImpersonationService {
+	mock := &MockImpersonationService{ctrl: ctrl}
+	mock.recorder = &MockImpersonationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImpersonationService) EXPECT() *MockImpersonationServiceMockRecorder {
+	return m.recorder
+}
+
+// Impersonate mocks base method.
+func (m *MockImpersonationService) Impersonate(ctx context.Context, actorID, userID entities.UserID) (*dto.ImpersonationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonate", ctx, actorID, userID)
+	ret0, _ := ret[0].(*dto.ImpersonationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Impersonate indicates an expected call of Impersonate.
+func (mr *MockImpersonationServiceMockRecorder) Impersonate(ctx, actorID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonate", reflect.TypeOf((*MockImpersonationService)(nil).Impersonate), ctx, actorID, userID)
+}
+
+// RecordRequest mocks base method.
+func (m *MockImpersonationService) RecordRequest(ctx context.Context, actorID, userID entities.UserID, method, path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordRequest", ctx, actorID, userID, method, path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordRequest indicates an expected call of RecordRequest.
+func (mr *MockImpersonationServiceMockRecorder) RecordRequest(ctx, actorID, userID, method, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRequest", reflect.TypeOf((*MockImpersonationService)(nil).RecordRequest), ctx, actorID, userID, method, path)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\application\ports\magic_link_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\application\ports\magic_link_service.go -destination .\mock\port\magic_link_service.go
+//
+
+// Package mock_ports is a generated GoMock package.
+package mock_ports
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/wonyus/backend-challenge/internal/application/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMagicLinkService is a mock of MagicLinkService interface.
+type MockMagicLinkService struct {
+	ctrl     *gomock.Controller
+	recorder *MockMagicLinkServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockMagicLinkServiceMockRecorder is the mock recorder for MockMagicLinkService.
+type MockMagicLinkServiceMockRecorder struct {
+	mock *MockMagicLinkService
+}
+
+// NewMockMagicLinkService creates a new mock instance.
+func NewMockMagicLinkService(ctrl *gomock.Controller) *MockMagicLinkService {
+	mock := &MockMagicLinkService{ctrl: ctrl}
+	mock.recorder = &MockMagicLinkServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMagicLinkService) EXPECT() *MockMagicLinkServiceMockRecorder {
+	return m.recorder
+}
+
+// Login mocks base method.
+func (m *MockMagicLinkService) Login(ctx context.Context, token, email string) (*dto.LoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, token, email)
+	ret0, _ := ret[0].(*dto.LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockMagicLinkServiceMockRecorder) Login(ctx, token, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockMagicLinkService)(nil).Login), ctx, token, email)
+}
+
+// RequestLink mocks base method.
+func (m *MockMagicLinkService) RequestLink(ctx context.Context, req *dto.MagicLinkRequest) (*dto.MagicLinkResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestLink", ctx, req)
+	ret0, _ := ret[0].(*dto.MagicLinkResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestLink indicates an expected call of RequestLink.
+func (mr *MockMagicLinkServiceMockRecorder) RequestLink(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestLink", reflect.TypeOf((*MockMagicLinkService)(nil).RequestLink), ctx, req)
+}
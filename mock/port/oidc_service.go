@@ -0,0 +1,144 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\application\ports\oidc_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\application\ports\oidc_service.go -destination .\mock\port\oidc_service.go
+//
+
+// Package mock_ports is a generated GoMock package.
+package mock_ports
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/wonyus/backend-challenge/internal/application/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOIDCService is a mock of OIDCService interface.
+type MockOIDCService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockOIDCServiceMockRecorder is the mock recorder for MockOIDCService.
+type MockOIDCServiceMockRecorder struct {
+	mock *MockOIDCService
+}
+
+// NewMockOIDCService creates a new mock instance.
+func NewMockOIDCService(ctrl *gomock.Controller) *MockOIDCService {
+	mock := &MockOIDCService{ctrl: ctrl}
+	mock.recorder = &MockOIDCServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCService) EXPECT() *MockOIDCServiceMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockOIDCService) Authorize(ctx context.Context, accessToken string, req *dto.AuthorizeRequest) (*dto.AuthorizeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, accessToken, req)
+	ret0, _ := ret[0].(*dto.AuthorizeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockOIDCServiceMockRecorder) Authorize(ctx, accessToken, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockOIDCService)(nil).Authorize), ctx, accessToken, req)
+}
+
+// Discovery mocks base method.
+func (m *MockOIDCService) Discovery() *dto.OpenIDConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Discovery")
+	ret0, _ := ret[0].(*dto.OpenIDConfiguration)
+	return ret0
+}
+
+// Discovery indicates an expected call of Discovery.
+func (mr *MockOIDCServiceMockRecorder) Discovery() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discovery", reflect.TypeOf((*MockOIDCService)(nil).Discovery))
+}
+
+// JWKS mocks base method.
+func (m *MockOIDCService) JWKS() *dto.JSONWebKeySet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(*dto.JSONWebKeySet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockOIDCServiceMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockOIDCService)(nil).JWKS))
+}
+
+// RegisterClient mocks base method.
+func (m *MockOIDCService) RegisterClient(ctx context.Context, req *dto.CreateOAuthClientRequest) (*dto.OAuthClientResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterClient", ctx, req)
+	ret0, _ := ret[0].(*dto.OAuthClientResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterClient indicates an expected call of RegisterClient.
+func (mr *MockOIDCServiceMockRecorder) RegisterClient(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterClient", reflect.TypeOf((*MockOIDCService)(nil).RegisterClient), ctx, req)
+}
+
+// Token mocks base method.
+func (m *MockOIDCService) Token(ctx context.Context, req *dto.TokenRequest) (*dto.TokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token", ctx, req)
+	ret0, _ := ret[0].(*dto.TokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockOIDCServiceMockRecorder) Token(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockOIDCService)(nil).Token), ctx, req)
+}
+
+// UserInfo mocks base method.
+func (m *MockOIDCService) UserInfo(ctx context.Context, principal *dto.Principal) (*dto.UserInfoResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserInfo", ctx, principal)
+	ret0, _ := ret[0].(*dto.UserInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserInfo indicates an expected call of UserInfo.
+func (mr *MockOIDCServiceMockRecorder) UserInfo(ctx, principal any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserInfo", reflect.TypeOf((*MockOIDCService)(nil).UserInfo), ctx, principal)
+}
+
+// ValidateAuthorizeRequest mocks base method.
+func (m *MockOIDCService) ValidateAuthorizeRequest(ctx context.Context, req *dto.AuthorizeRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAuthorizeRequest", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateAuthorizeRequest indicates an expected call of ValidateAuthorizeRequest.
+func (mr *MockOIDCServiceMockRecorder) ValidateAuthorizeRequest(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAuthorizeRequest", reflect.TypeOf((*MockOIDCService)(nil).ValidateAuthorizeRequest), ctx, req)
+}
@@ -0,0 +1,353 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\application\ports\organization_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\application\ports\organization_service.go -destination .\mock\port\organization_service.go
+//
+
+// Package mock_ports is a generated GoMock package.
+package mock_ports
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/wonyus/backend-challenge/internal/application/dto"
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOrganizationService is a mock of OrganizationService interface.
+type MockOrganizationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockOrganizationServiceMockRecorder is the mock recorder for MockOrganizationService.
+type MockOrganizationServiceMockRecorder struct {
+	mock *MockOrganizationService
+}
+
+// NewMockOrganizationService creates a new mock instance.
+func NewMockOrganizationService(ctrl *gomock.Controller) *MockOrganizationService {
+	mock := &MockOrganizationService{ctrl: ctrl}
+	mock.recorder = &MockOrganizationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationService) EXPECT() *MockOrganizationServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockOrganizationService) AcceptInvitation(ctx context.Context, userID entities.UserID, organizationID entities.ID) (*dto.MembershipResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, userID, organizationID)
+	ret0, _ := ret[0].(*dto.MembershipResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockOrganizationServiceMockRecorder) AcceptInvitation(ctx, userID, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockOrganizationService)(nil).AcceptInvitation), ctx, userID, organizationID)
+}
+
+// CreateOrganization mocks base method.
+func (m *MockOrganizationService) CreateOrganization(ctx context.Context, caller *dto.Principal, req *dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, caller, req)
+	ret0, _ := ret[0].(*dto.OrganizationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockOrganizationServiceMockRecorder) CreateOrganization(ctx, caller, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockOrganizationService)(nil).CreateOrganization), ctx, caller, req)
+}
+
+// CreateTeam mocks base method.
+func (m *MockOrganizationService) CreateTeam(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.CreateTeamRequest) (*dto.TeamResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTeam", ctx, caller, organizationID, req)
+	ret0, _ := ret[0].(*dto.TeamResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTeam indicates an expected call of CreateTeam.
+func (mr *MockOrganizationServiceMockRecorder) CreateTeam(ctx, caller, organizationID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTeam", reflect.TypeOf((*MockOrganizationService)(nil).CreateTeam), ctx, caller, organizationID, req)
+}
+
+// DeclineInvitation mocks base method.
+func (m *MockOrganizationService) DeclineInvitation(ctx context.Context, userID entities.UserID, organizationID entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineInvitation", ctx, userID, organizationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeclineInvitation indicates an expected call of DeclineInvitation.
+func (mr *MockOrganizationServiceMockRecorder) DeclineInvitation(ctx, userID, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineInvitation", reflect.TypeOf((*MockOrganizationService)(nil).DeclineInvitation), ctx, userID, organizationID)
+}
+
+// DeleteOrganization mocks base method.
+func (m *MockOrganizationService) DeleteOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganization", ctx, caller, organizationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganization indicates an expected call of DeleteOrganization.
+func (mr *MockOrganizationServiceMockRecorder) DeleteOrganization(ctx, caller, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganization", reflect.TypeOf((*MockOrganizationService)(nil).DeleteOrganization), ctx, caller, organizationID)
+}
+
+// DeleteTeam mocks base method.
+func (m *MockOrganizationService) DeleteTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTeam", ctx, caller, organizationID, teamID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTeam indicates an expected call of DeleteTeam.
+func (mr *MockOrganizationServiceMockRecorder) DeleteTeam(ctx, caller, organizationID, teamID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTeam", reflect.TypeOf((*MockOrganizationService)(nil).DeleteTeam), ctx, caller, organizationID, teamID)
+}
+
+// GetOrganization mocks base method.
+func (m *MockOrganizationService) GetOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.OrganizationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", ctx, caller, organizationID)
+	ret0, _ := ret[0].(*dto.OrganizationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockOrganizationServiceMockRecorder) GetOrganization(ctx, caller, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockOrganizationService)(nil).GetOrganization), ctx, caller, organizationID)
+}
+
+// GetTeam mocks base method.
+func (m *MockOrganizationService) GetTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) (*dto.TeamResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeam", ctx, caller, organizationID, teamID)
+	ret0, _ := ret[0].(*dto.TeamResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeam indicates an expected call of GetTeam.
+func (mr *MockOrganizationServiceMockRecorder) GetTeam(ctx, caller, organizationID, teamID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeam", reflect.TypeOf((*MockOrganizationService)(nil).GetTeam), ctx, caller, organizationID, teamID)
+}
+
+// GetUserMemberships mocks base method.
+func (m *MockOrganizationService) GetUserMemberships(ctx context.Context, userID entities.UserID) ([]dto.MembershipResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserMemberships", ctx, userID)
+	ret0, _ := ret[0].([]dto.MembershipResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserMemberships indicates an expected call of GetUserMemberships.
+func (mr *MockOrganizationServiceMockRecorder) GetUserMemberships(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserMemberships", reflect.TypeOf((*MockOrganizationService)(nil).GetUserMemberships), ctx, userID)
+}
+
+// InviteMember mocks base method.
+func (m *MockOrganizationService) InviteMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.InviteMemberRequest) (*dto.MemberResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteMember", ctx, caller, organizationID, req)
+	ret0, _ := ret[0].(*dto.MemberResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteMember indicates an expected call of InviteMember.
+func (mr *MockOrganizationServiceMockRecorder) InviteMember(ctx, caller, organizationID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteMember", reflect.TypeOf((*MockOrganizationService)(nil).InviteMember), ctx, caller, organizationID, req)
+}
+
+// ListInvitations mocks base method.
+func (m *MockOrganizationService) ListInvitations(ctx context.Context, userID entities.UserID) (*dto.MembershipsListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvitations", ctx, userID)
+	ret0, _ := ret[0].(*dto.MembershipsListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvitations indicates an expected call of ListInvitations.
+func (mr *MockOrganizationServiceMockRecorder) ListInvitations(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvitations", reflect.TypeOf((*MockOrganizationService)(nil).ListInvitations), ctx, userID)
+}
+
+// ListMembers mocks base method.
+func (m *MockOrganizationService) ListMembers(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.MembersListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, caller, organizationID)
+	ret0, _ := ret[0].(*dto.MembersListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockOrganizationServiceMockRecorder) ListMembers(ctx, caller, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockOrganizationService)(nil).ListMembers), ctx, caller, organizationID)
+}
+
+// ListOrganizations mocks base method.
+func (m *MockOrganizationService) ListOrganizations(ctx context.Context, userID entities.UserID) (*dto.OrganizationsListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizations", ctx, userID)
+	ret0, _ := ret[0].(*dto.OrganizationsListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizations indicates an expected call of ListOrganizations.
+func (mr *MockOrganizationServiceMockRecorder) ListOrganizations(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizations", reflect.TypeOf((*MockOrganizationService)(nil).ListOrganizations), ctx, userID)
+}
+
+// ListTeamMembers mocks base method.
+func (m *MockOrganizationService) ListTeamMembers(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID) (*dto.MembersListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeamMembers", ctx, caller, organizationID, teamID)
+	ret0, _ := ret[0].(*dto.MembersListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeamMembers indicates an expected call of ListTeamMembers.
+func (mr *MockOrganizationServiceMockRecorder) ListTeamMembers(ctx, caller, organizationID, teamID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeamMembers", reflect.TypeOf((*MockOrganizationService)(nil).ListTeamMembers), ctx, caller, organizationID, teamID)
+}
+
+// ListTeams mocks base method.
+func (m *MockOrganizationService) ListTeams(ctx context.Context, caller *dto.Principal, organizationID entities.ID) (*dto.TeamsListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeams", ctx, caller, organizationID)
+	ret0, _ := ret[0].(*dto.TeamsListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeams indicates an expected call of ListTeams.
+func (mr *MockOrganizationServiceMockRecorder) ListTeams(ctx, caller, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeams", reflect.TypeOf((*MockOrganizationService)(nil).ListTeams), ctx, caller, organizationID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockOrganizationService) RemoveMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, userID entities.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, caller, organizationID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockOrganizationServiceMockRecorder) RemoveMember(ctx, caller, organizationID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockOrganizationService)(nil).RemoveMember), ctx, caller, organizationID, userID)
+}
+
+// RemoveTeamMember mocks base method.
+func (m *MockOrganizationService) RemoveTeamMember(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, userID entities.UserID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTeamMember", ctx, caller, organizationID, teamID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTeamMember indicates an expected call of RemoveTeamMember.
+func (mr *MockOrganizationServiceMockRecorder) RemoveTeamMember(ctx, caller, organizationID, teamID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTeamMember", reflect.TypeOf((*MockOrganizationService)(nil).RemoveTeamMember), ctx, caller, organizationID, teamID, userID)
+}
+
+// SetTeamMember mocks base method.
+func (m *MockOrganizationService) SetTeamMember(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, userID entities.UserID, req *dto.MemberRoleRequest) (*dto.MemberResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTeamMember", ctx, caller, organizationID, teamID, userID, req)
+	ret0, _ := ret[0].(*dto.MemberResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTeamMember indicates an expected call of SetTeamMember.
+func (mr *MockOrganizationServiceMockRecorder) SetTeamMember(ctx, caller, organizationID, teamID, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTeamMember", reflect.TypeOf((*MockOrganizationService)(nil).SetTeamMember), ctx, caller, organizationID, teamID, userID, req)
+}
+
+// UpdateMember mocks base method.
+func (m *MockOrganizationService) UpdateMember(ctx context.Context, caller *dto.Principal, organizationID entities.ID, userID entities.UserID, req *dto.MemberRoleRequest) (*dto.MemberResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMember", ctx, caller, organizationID, userID, req)
+	ret0, _ := ret[0].(*dto.MemberResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMember indicates an expected call of UpdateMember.
+func (mr *MockOrganizationServiceMockRecorder) UpdateMember(ctx, caller, organizationID, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMember", reflect.TypeOf((*MockOrganizationService)(nil).UpdateMember), ctx, caller, organizationID, userID, req)
+}
+
+// UpdateOrganization mocks base method.
+func (m *MockOrganizationService) UpdateOrganization(ctx context.Context, caller *dto.Principal, organizationID entities.ID, req *dto.UpdateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrganization", ctx, caller, organizationID, req)
+	ret0, _ := ret[0].(*dto.OrganizationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrganization indicates an expected call of UpdateOrganization.
+func (mr *MockOrganizationServiceMockRecorder) UpdateOrganization(ctx, caller, organizationID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganization", reflect.TypeOf((*MockOrganizationService)(nil).UpdateOrganization), ctx, caller, organizationID, req)
+}
+
+// UpdateTeam mocks base method.
+func (m *MockOrganizationService) UpdateTeam(ctx context.Context, caller *dto.Principal, organizationID, teamID entities.ID, req *dto.UpdateTeamRequest) (*dto.TeamResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTeam", ctx, caller, organizationID, teamID, req)
+	ret0, _ := ret[0].(*dto.TeamResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTeam indicates an expected call of UpdateTeam.
+func (mr *MockOrganizationServiceMockRecorder) UpdateTeam(ctx, caller, organizationID, teamID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTeam", reflect.TypeOf((*MockOrganizationService)(nil).UpdateTeam), ctx, caller, organizationID, teamID, req)
+}
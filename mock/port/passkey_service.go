@@ -0,0 +1,147 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\application\ports\passkey_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\application\ports\passkey_service.go -destination .\mock\port\passkey_service.go
+//
+
+// Package mock_ports is a generated GoMock package.
+package mock_ports
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/wonyus/backend-challenge/internal/application/dto"
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPasskeyService is a mock of PasskeyService interface.
+type MockPasskeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasskeyServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockPasskeyServiceMockRecorder is the mock recorder for MockPasskeyService.
+type MockPasskeyServiceMockRecorder struct {
+	mock *MockPasskeyService
+}
+
+// NewMockPasskeyService creates a new mock instance.
+func NewMockPasskeyService(ctrl *gomock.Controller) *MockPasskeyService {
+	mock := &MockPasskeyService{ctrl: ctrl}
+	mock.recorder = &MockPasskeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasskeyService) EXPECT() *MockPasskeyServiceMockRecorder {
+	return m.recorder
+}
+
+// BeginLogin mocks base method.
+func (m *MockPasskeyService) BeginLogin(ctx context.Context) (*dto.PasskeyLoginOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginLogin", ctx)
+	ret0, _ := ret[0].(*dto.PasskeyLoginOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginLogin indicates an expected call of BeginLogin.
+func (mr *MockPasskeyServiceMockRecorder) BeginLogin(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginLogin", reflect.TypeOf((*MockPasskeyService)(nil).BeginLogin), ctx)
+}
+
+// BeginRegistration mocks base method.
+func (m *MockPasskeyService) BeginRegistration(ctx context.Context, userID entities.UserID) (*dto.PasskeyRegistrationOptions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginRegistration", ctx, userID)
+	ret0, _ := ret[0].(*dto.PasskeyRegistrationOptions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginRegistration indicates an expected call of BeginRegistration.
+func (mr *MockPasskeyServiceMockRecorder) BeginRegistration(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginRegistration", reflect.TypeOf((*MockPasskeyService)(nil).BeginRegistration), ctx, userID)
+}
+
+// DeletePasskey mocks base method.
+func (m *MockPasskeyService) DeletePasskey(ctx context.Context, userID entities.UserID, passkeyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasskey", ctx, userID, passkeyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasskey indicates an expected call of DeletePasskey.
+func (mr *MockPasskeyServiceMockRecorder) DeletePasskey(ctx, userID, passkeyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasskey", reflect.TypeOf((*MockPasskeyService)(nil).DeletePasskey), ctx, userID, passkeyID)
+}
+
+// FinishLogin mocks base method.
+func (m *MockPasskeyService) FinishLogin(ctx context.Context, req *dto.PasskeyLoginRequest) (*dto.LoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishLogin", ctx, req)
+	ret0, _ := ret[0].(*dto.LoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishLogin indicates an expected call of FinishLogin.
+func (mr *MockPasskeyServiceMockRecorder) FinishLogin(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishLogin", reflect.TypeOf((*MockPasskeyService)(nil).FinishLogin), ctx, req)
+}
+
+// FinishRegistration mocks base method.
+func (m *MockPasskeyService) FinishRegistration(ctx context.Context, userID entities.UserID, req *dto.RegisterPasskeyRequest) (*dto.PasskeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRegistration", ctx, userID, req)
+	ret0, _ := ret[0].(*dto.PasskeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishRegistration indicates an expected call of FinishRegistration.
+func (mr *MockPasskeyServiceMockRecorder) FinishRegistration(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRegistration", reflect.TypeOf((*MockPasskeyService)(nil).FinishRegistration), ctx, userID, req)
+}
+
+// ListPasskeys mocks base method.
+func (m *MockPasskeyService) ListPasskeys(ctx context.Context, userID entities.UserID) (*dto.PasskeysListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasskeys", ctx, userID)
+	ret0, _ := ret[0].(*dto.PasskeysListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasskeys indicates an expected call of ListPasskeys.
+func (mr *MockPasskeyServiceMockRecorder) ListPasskeys(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasskeys", reflect.TypeOf((*MockPasskeyService)(nil).ListPasskeys), ctx, userID)
+}
+
+// RenamePasskey mocks base method.
+func (m *MockPasskeyService) RenamePasskey(ctx context.Context, userID entities.UserID, passkeyID string, req *dto.RenamePasskeyRequest) (*dto.PasskeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenamePasskey", ctx, userID, passkeyID, req)
+	ret0, _ := ret[0].(*dto.PasskeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenamePasskey indicates an expected call of RenamePasskey.
+func (mr *MockPasskeyServiceMockRecorder) RenamePasskey(ctx, userID, passkeyID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenamePasskey", reflect.TypeOf((*MockPasskeyService)(nil).RenamePasskey), ctx, userID, passkeyID, req)
+}
@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\application\ports\user_event_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\application\ports\user_event_service.go -destination .\mock\port\user_event_service.go
+//
+
+// Package mock_ports is a generated GoMock package.
+package mock_ports
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/wonyus/backend-challenge/internal/application/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserEventService is a mock of UserEventService interface.
+type MockUserEventService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserEventServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockUserEventServiceMockRecorder is the mock recorder for MockUserEventService.
+type MockUserEventServiceMockRecorder struct {
+	mock *MockUserEventService
+}
+
+// NewMockUserEventService creates a new mock instance.
+func NewMockUserEventService(ctrl *gomock.Controller) *MockUserEventService {
+	mock := &MockUserEventService{ctrl: ctrl}
+	mock.recorder = &MockUserEventServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserEventService) EXPECT() *MockUserEventServiceMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockUserEventService) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockUserEventServiceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockUserEventService)(nil).Close))
+}
+
+// WatchUsers mocks base method.
+func (m *MockUserEventService) WatchUsers(ctx context.Context, req *dto.WatchUsersRequest, send func(*dto.UserEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchUsers", ctx, req, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchUsers indicates an expected call of WatchUsers.
+func (mr *MockUserEventServiceMockRecorder) WatchUsers(ctx, req, send any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchUsers", reflect.TypeOf((*MockUserEventService)(nil).WatchUsers), ctx, req, send)
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: .\internal\application\ports\user_invitation_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source .\internal\application\ports\user_invitation_service.go -destination .\mock\port\user_invitation_service.go
+//
+
+// Package mock_ports is a generated GoMock package.
+package mock_ports
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/wonyus/backend-challenge/internal/application/dto"
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserInvitationService is a mock of UserInvitationService interface.
+type MockUserInvitationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserInvitationServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockUserInvitationServiceMockRecorder is the mock recorder for MockUserInvitationService.
+type MockUserInvitationServiceMockRecorder struct {
+	mock *MockUserInvitationService
+}
+
+// NewMockUserInvitationService creates a new mock instance.
+func NewMockUserInvitationService(ctrl *gomock.Controller) *MockUserInvitationService {
+	mock := &MockUserInvitationService{ctrl: ctrl}
+	mock.recorder = &MockUserInvitationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserInvitationService) EXPECT() *MockUserInvitationServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockUserInvitationService) AcceptInvitation(ctx context.Context, req *dto.AcceptUserInvitationRequest) (*dto.UserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, req)
+	ret0, _ := ret[0].(*dto.UserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockUserInvitationServiceMockRecorder) AcceptInvitation(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockUserInvitationService)(nil).AcceptInvitation), ctx, req)
+}
+
+// CreateInvitation mocks base method.
+func (m *MockUserInvitationService) CreateInvitation(ctx context.Context, inviterID entities.UserID, req *dto.CreateUserInvitationRequest) (*dto.UserInvitationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, inviterID, req)
+	ret0, _ := ret[0].(*dto.UserInvitationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockUserInvitationServiceMockRecorder) CreateInvitation(ctx, inviterID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockUserInvitationService)(nil).CreateInvitation), ctx, inviterID, req)
+}
+
+// ListInvitations mocks base method.
+func (m *MockUserInvitationService) ListInvitations(ctx context.Context) (*dto.UserInvitationsListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvitations", ctx)
+	ret0, _ := ret[0].(*dto.UserInvitationsListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvitations indicates an expected call of ListInvitations.
+func (mr *MockUserInvitationServiceMockRecorder) ListInvitations(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvitations", reflect.TypeOf((*MockUserInvitationService)(nil).ListInvitations), ctx)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockUserInvitationService) RevokeInvitation(ctx context.Context, id entities.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockUserInvitationServiceMockRecorder) RevokeInvitation(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockUserInvitationService)(nil).RevokeInvitation), ctx, id)
+}
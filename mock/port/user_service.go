@@ -14,7 +14,7 @@ import (
 	reflect "reflect"
 
 	dto "github.com/wonyus/backend-challenge/internal/application/dto"
-	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+	entities "github.com/wonyus/backend-challenge/internal/domain/entities"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -58,7 +58,7 @@ func (mr *MockUserServiceMockRecorder) CreateUser(ctx, req any) *gomock.Call {
 }
 
 // DeleteUser mocks base method.
-func (m *MockUserService) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+func (m *MockUserService) DeleteUser(ctx context.Context, id entities.UserID) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
 	ret0, _ := ret[0].(error)
@@ -87,7 +87,7 @@ func (mr *MockUserServiceMockRecorder) GetAllUsers(ctx any) *gomock.Call {
 }
 
 // GetUserByID mocks base method.
-func (m *MockUserService) GetUserByID(ctx context.Context, id primitive.ObjectID) (*dto.UserResponse, error) {
+func (m *MockUserService) GetUserByID(ctx context.Context, id entities.UserID) (*dto.UserResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUserByID", ctx, id)
 	ret0, _ := ret[0].(*dto.UserResponse)
@@ -116,8 +116,23 @@ func (mr *MockUserServiceMockRecorder) GetUserCount(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCount", reflect.TypeOf((*MockUserService)(nil).GetUserCount), ctx)
 }
 
+// ProvisionUser mocks base method.
+func (m *MockUserService) ProvisionUser(ctx context.Context, req *dto.ProvisionUserRequest) (*dto.UserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvisionUser", ctx, req)
+	ret0, _ := ret[0].(*dto.UserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProvisionUser indicates an expected call of ProvisionUser.
+func (mr *MockUserServiceMockRecorder) ProvisionUser(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvisionUser", reflect.TypeOf((*MockUserService)(nil).ProvisionUser), ctx, req)
+}
+
 // UpdateUser mocks base method.
-func (m *MockUserService) UpdateUser(ctx context.Context, id primitive.ObjectID, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+func (m *MockUserService) UpdateUser(ctx context.Context, id entities.UserID, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUser", ctx, id, req)
 	ret0, _ := ret[0].(*dto.UserResponse)
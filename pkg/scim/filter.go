@@ -0,0 +1,345 @@
+// Package scim implements the parts of SCIM 2.0 (RFC 7644) that do not depend
+// on a particular resource: filter expressions.
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Attribute holds the values of an attribute of a resource, as strings.
+// Booleans are "true" and "false", dates are RFC 3339 timestamps.
+type Attribute struct {
+	Values    []string
+	CaseExact bool
+}
+
+// Attributes maps attribute paths such as "userName" or "emails.value" to
+// their values. Paths are matched case-insensitively.
+type Attributes map[string]Attribute
+
+func (a Attributes) get(path string) (Attribute, bool) {
+	for name, attribute := range a {
+		if strings.EqualFold(name, path) {
+			return attribute, true
+		}
+	}
+	return Attribute{}, false
+}
+
+// Filter is a parsed filter expression.
+type Filter interface {
+	Matches(attributes Attributes) bool
+}
+
+type logicalFilter struct {
+	and         bool
+	left, right Filter
+}
+
+func (f logicalFilter) Matches(attributes Attributes) bool {
+	if f.and {
+		return f.left.Matches(attributes) && f.right.Matches(attributes)
+	}
+	return f.left.Matches(attributes) || f.right.Matches(attributes)
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (f notFilter) Matches(attributes Attributes) bool {
+	return !f.filter.Matches(attributes)
+}
+
+type attributeFilter struct {
+	path     string
+	operator string
+	value    string
+}
+
+// Comparison compares the attribute at Path with Value, such as
+// `userName eq "bjensen"`. Operator is lower case.
+type Comparison struct {
+	Path     string
+	Operator string
+	Value    string
+}
+
+// Comparisons returns the comparisons of a filter that is only comparisons
+// joined by "and", so that stores can evaluate it themselves. ok is false
+// for filters using "or", "not" or "pr".
+func Comparisons(filter Filter) (comparisons []Comparison, ok bool) {
+	switch f := filter.(type) {
+	case logicalFilter:
+		if !f.and {
+			return nil, false
+		}
+		left, ok := Comparisons(f.left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := Comparisons(f.right)
+		if !ok {
+			return nil, false
+		}
+		return append(left, right...), true
+	case attributeFilter:
+		if f.operator == "pr" {
+			return nil, false
+		}
+		return []Comparison{{Path: f.path, Operator: f.operator, Value: f.value}}, true
+	}
+	return nil, false
+}
+
+func (f attributeFilter) Matches(attributes Attributes) bool {
+	attribute, ok := attributes.get(f.path)
+	if f.operator == "pr" {
+		return ok && len(attribute.Values) > 0
+	}
+
+	// A multi-valued attribute matches when any of its values does
+	for _, value := range attribute.Values {
+		if compare(f.operator, value, f.value, attribute.CaseExact) {
+			return true
+		}
+	}
+	return f.operator == "ne" && len(attribute.Values) == 0
+}
+
+func compare(operator, actual, expected string, caseExact bool) bool {
+	if !caseExact {
+		actual = strings.ToLower(actual)
+		expected = strings.ToLower(expected)
+	}
+
+	switch operator {
+	case "eq":
+		return actual == expected
+	case "ne":
+		return actual != expected
+	case "co":
+		return strings.Contains(actual, expected)
+	case "sw":
+		return strings.HasPrefix(actual, expected)
+	case "ew":
+		return strings.HasSuffix(actual, expected)
+	case "gt":
+		return actual > expected
+	case "ge":
+		return actual >= expected
+	case "lt":
+		return actual < expected
+	case "le":
+		return actual <= expected
+	}
+	return false
+}
+
+// ParseFilter parses a filter such as `userName eq "bjensen"`. Attribute
+// comparisons can be combined with "and", "or", "not" and parentheses;
+// complex attribute filters like `emails[type eq "work"]` are not supported.
+func ParseFilter(filter string) (Filter, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty filter", ErrInvalidFilter)
+	}
+
+	p := &parser{tokens: tokens}
+	parsed, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilter, p.tokens[p.pos].text)
+	}
+	return parsed, nil
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(filter string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(filter); {
+		switch c := filter[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{text: string(c)})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(filter) && filter[end] != '"'; end++ {
+				if filter[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(filter) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+			}
+			var value string
+			if err := json.Unmarshal([]byte(filter[i:end+1]), &value); err != nil {
+				return nil, fmt.Errorf("%w: invalid string %s", ErrInvalidFilter, filter[i:end+1])
+			}
+			tokens = append(tokens, token{text: value, quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for end < len(filter) && !strings.ContainsRune(" \t()\"", rune(filter[end])) {
+				end++
+			}
+			tokens = append(tokens, token{text: filter[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("%w: unexpected end of filter", ErrInvalidFilter)
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTerm() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if !p.peekKeyword("(") {
+			return nil, fmt.Errorf("%w: expected ( after not", ErrInvalidFilter)
+		}
+		inner, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return notFilter{filter: inner}, nil
+	}
+
+	if p.peekKeyword("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekKeyword(")") {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidFilter)
+		}
+		p.pos++
+		return inner, nil
+	}
+
+	path, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if path.quoted || strings.ContainsAny(path.text, "[]") {
+		return nil, fmt.Errorf("%w: unsupported attribute %q", ErrInvalidFilter, path.text)
+	}
+
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(operator.text)
+	if operator.quoted {
+		return nil, fmt.Errorf("%w: expected operator, got %q", ErrInvalidFilter, operator.text)
+	}
+	if op == "pr" {
+		return attributeFilter{path: AttributePath(path.text), operator: op}, nil
+	}
+	switch op {
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, operator.text)
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if !value.quoted {
+		switch strings.ToLower(value.text) {
+		case "true", "false":
+			value.text = strings.ToLower(value.text)
+		case "null":
+			// "attr eq null" is the same as "not (attr pr)"
+			present := attributeFilter{path: AttributePath(path.text), operator: "pr"}
+			if op == "eq" {
+				return notFilter{filter: present}, nil
+			}
+			if op == "ne" {
+				return present, nil
+			}
+			return nil, fmt.Errorf("%w: null can only be compared with eq or ne", ErrInvalidFilter)
+		default:
+			if strings.Trim(value.text, "0123456789.-+eE") != "" {
+				return nil, fmt.Errorf("%w: invalid value %q", ErrInvalidFilter, value.text)
+			}
+		}
+	}
+
+	return attributeFilter{path: AttributePath(path.text), operator: op, value: value.text}, nil
+}
+
+// AttributePath strips a schema URN, so that
+// "urn:ietf:params:scim:schemas:core:2.0:User:userName" becomes "userName".
+func AttributePath(path string) string {
+	if strings.HasPrefix(strings.ToLower(path), "urn:") {
+		return path[strings.LastIndex(path, ":")+1:]
+	}
+	return path
+}
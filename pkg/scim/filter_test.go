@@ -0,0 +1,114 @@
+package scim
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var testAttributes = Attributes{
+	"userName":           {Values: []string{"Bjensen@example.com"}},
+	"externalId":         {Values: []string{"E-100"}, CaseExact: true},
+	"emails.value":       {Values: []string{"bjensen@example.com", "babs@example.org"}},
+	"active":             {Values: []string{"true"}},
+	"meta.lastModified":  {Values: []string{"2025-07-16T09:19:41Z"}},
+	"name.formatted":     {Values: []string{"Barbara Jensen"}},
+	"name.honorificName": {},
+}
+
+func TestParseFilter_Matches(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "bjensen@example.com"`, true},
+		{`USERNAME Eq "BJENSEN@EXAMPLE.COM"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen@example.com"`, true},
+		{`userName eq "other@example.com"`, false},
+		{`userName ne "other@example.com"`, true},
+		{`externalId eq "E-100"`, true},
+		{`externalId eq "e-100"`, false},
+		{`emails.value co "example.org"`, true},
+		{`name.formatted sw "barbara"`, true},
+		{`name.formatted ew "Jensen"`, true},
+		{`active eq true`, true},
+		{`active eq false`, false},
+		{`meta.lastModified gt "2025-01-01T00:00:00Z"`, true},
+		{`meta.lastModified lt "2025-01-01T00:00:00Z"`, false},
+		{`externalId pr`, true},
+		{`name.honorificName pr`, false},
+		{`title pr`, false},
+		{`title eq null`, true},
+		{`userName eq "x" or externalId eq "E-100"`, true},
+		{`userName eq "x" or externalId eq "E-100" and active eq false`, false},
+		{`(userName eq "x" or externalId eq "E-100") and active eq true`, true},
+		{`not (active eq true)`, false},
+		{`userName eq "say \"hi\""`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			if got := filter.Matches(testAttributes); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	filters := []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName equals "x"`,
+		`userName eq "x`,
+		`userName eq bjensen`,
+		`emails[type eq "work"].value eq "x"`,
+		`(userName eq "x"`,
+		`userName eq "x" and`,
+		`userName eq "x" userName eq "y"`,
+		`not userName eq "x"`,
+		`userName gt null`,
+	}
+
+	for _, filter := range filters {
+		t.Run(filter, func(t *testing.T) {
+			if _, err := ParseFilter(filter); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("ParseFilter() error = %v, want ErrInvalidFilter", err)
+			}
+		})
+	}
+}
+
+func TestComparisons(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []Comparison
+		ok     bool
+	}{
+		{`userName eq "bjensen"`, []Comparison{{"userName", "eq", "bjensen"}}, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName EQ "bjensen"`, []Comparison{{"userName", "eq", "bjensen"}}, true},
+		{`(userName eq "bjensen") and externalId sw "E-"`, []Comparison{{"userName", "eq", "bjensen"}, {"externalId", "sw", "E-"}}, true},
+		{`userName eq "bjensen" or externalId eq "E-100"`, nil, false},
+		{`userName eq "bjensen" and not (active eq true)`, nil, false},
+		{`externalId pr`, nil, false},
+		{`title eq null`, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			got, ok := Comparisons(filter)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Comparisons() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B uses the ASCII secret "12345678901234567890" for SHA1.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8 digit codes; the last 6 digits are the 6 digit code.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := GenerateCode(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("GenerateCode() error = %v", err)
+		}
+		if code != tt.code {
+			t.Errorf("GenerateCode(T=%d) = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := GenerateCode(rfcSecret, Step(now))
+
+	if step, ok := Validate(rfcSecret, code, now, 1); !ok || step != Step(now) {
+		t.Errorf("Validate() = %d, %v; want %d, true", step, ok, Step(now))
+	}
+
+	if _, ok := Validate(rfcSecret, code, now.Add(Period), 1); !ok {
+		t.Error("Validate() should accept the previous step within skew")
+	}
+
+	if _, ok := Validate(rfcSecret, code, now.Add(3*Period), 1); ok {
+		t.Error("Validate() should reject codes outside the skew window")
+	}
+
+	if _, ok := Validate(rfcSecret, "12345", now, 1); ok {
+		t.Error("Validate() should reject codes of the wrong length")
+	}
+
+	if _, ok := Validate("not base32!", code, now, 1); ok {
+		t.Error("Validate() should reject an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("GenerateSecret() length = %d, want 32", len(secret))
+	}
+
+	other, _ := GenerateSecret()
+	if secret == other {
+		t.Error("GenerateSecret() returned the same secret twice")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Backend Challenge", "admin@example.com", "JBSWY3DPEHPK3PXP")
+
+	if !strings.HasPrefix(uri, "otpauth://totp/Backend%20Challenge:admin@example.com?") {
+		t.Errorf("URI() has unexpected label: %s", uri)
+	}
+	for _, part := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=Backend+Challenge", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("URI() = %s, missing %s", uri, part)
+		}
+	}
+}
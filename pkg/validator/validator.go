@@ -62,7 +62,7 @@ func (v *Validator) validateField(field reflect.Value, fieldName, tag string) er
 			}
 		case rule == "email":
 			if field.Kind() == reflect.String && field.String() != "" {
-				if !v.emailRegex.MatchString(strings.ToLower(field.String())) {
+				if !v.emailRegex.MatchString(strings.ToLower(strings.TrimSpace(field.String()))) {
 					return errors.New(fieldName + " must be a valid email")
 				}
 			}
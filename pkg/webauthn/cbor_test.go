@@ -0,0 +1,56 @@
+package webauthn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		value interface{}
+	}{
+		{"small int", []byte{0x17}, int64(23)},
+		{"uint8", []byte{0x18, 0x64}, int64(100)},
+		{"negative", []byte{0x38, 0x63}, int64(-100)},
+		{"bytes", []byte{0x42, 0x01, 0x02}, []byte{1, 2}},
+		{"text", []byte{0x63, 'f', 'm', 't'}, "fmt"},
+		{"array", []byte{0x82, 0x01, 0xf5}, []interface{}{int64(1), true}},
+		{"map", []byte{0xa2, 0x01, 0x02, 0x61, 'a', 0xf6}, map[interface{}]interface{}{int64(1): int64(2), "a": nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, rest, err := decodeCBOR(append(tt.data, 0xff))
+			if err != nil {
+				t.Fatalf("decodeCBOR() error = %v", err)
+			}
+			if !reflect.DeepEqual(value, tt.value) || !reflect.DeepEqual(rest, []byte{0xff}) {
+				t.Errorf("decodeCBOR() = %#v, %x, want %#v", value, rest, tt.value)
+			}
+		})
+	}
+}
+
+func TestDecodeCBOR_Malformed(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":               {},
+		"truncated bytes":     {0x45, 0x01},
+		"truncated argument":  {0x19, 0x01},
+		"indefinite length":   {0x5f, 0x41, 0x01, 0xff},
+		"duplicate map key":   {0xa2, 0x01, 0x01, 0x01, 0x02},
+		"byte string map key": {0xa1, 0x41, 0x01, 0x01},
+		"float":               {0xf9, 0x3c, 0x00},
+		"huge array":          {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"deep nesting":        {0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x00},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := decodeCBOR(data); err == nil {
+				t.Error("decodeCBOR() error = nil, want error")
+			}
+		})
+	}
+}
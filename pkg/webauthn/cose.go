@@ -0,0 +1,120 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers of the supported credential key types.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms lists the supported algorithms in order of preference.
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key labels (RFC 9053).
+const (
+	coseKeyType   = 1
+	coseAlgorithm = 3
+	coseCurve     = -1
+	coseX         = -2
+	coseY         = -3
+	coseRSAN      = -1
+	coseRSAE      = -2
+
+	coseKeyTypeOKP   = 1
+	coseKeyTypeEC2   = 2
+	coseKeyTypeRSA   = 3
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+
+	minRSAKeyBits = 2048
+)
+
+// PublicKey is a credential public key parsed from its COSE encoding.
+type PublicKey struct {
+	Algorithm int64
+	key       crypto.PublicKey
+}
+
+// ParsePublicKey parses a COSE_Key with one of the SupportedAlgorithms.
+func ParsePublicKey(coseKey []byte) (*PublicKey, error) {
+	decoded, rest, err := decodeCBOR(coseKey)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: malformed public key", ErrInvalidResponse)
+	}
+	return parseCOSEKey(decoded)
+}
+
+func parseCOSEKey(decoded interface{}) (*PublicKey, error) {
+	fields, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed public key", ErrInvalidResponse)
+	}
+	keyType, _ := fields[int64(coseKeyType)].(int64)
+	algorithm, _ := fields[int64(coseAlgorithm)].(int64)
+
+	switch {
+	case keyType == coseKeyTypeEC2 && algorithm == AlgES256:
+		curve, _ := fields[int64(coseCurve)].(int64)
+		x, _ := fields[int64(coseX)].([]byte)
+		y, _ := fields[int64(coseY)].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			break
+		}
+		// ecdh rejects points that are not on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			break
+		}
+		return &PublicKey{Algorithm: algorithm, key: &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}}, nil
+	case keyType == coseKeyTypeOKP && algorithm == AlgEdDSA:
+		curve, _ := fields[int64(coseCurve)].(int64)
+		x, _ := fields[int64(coseX)].([]byte)
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			break
+		}
+		return &PublicKey{Algorithm: algorithm, key: ed25519.PublicKey(x)}, nil
+	case keyType == coseKeyTypeRSA && algorithm == AlgRS256:
+		n, _ := fields[int64(coseRSAN)].([]byte)
+		e, _ := fields[int64(coseRSAE)].([]byte)
+		modulus := new(big.Int).SetBytes(n)
+		exponent := new(big.Int).SetBytes(e)
+		if modulus.BitLen() < minRSAKeyBits || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			break
+		}
+		return &PublicKey{Algorithm: algorithm, key: &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}}, nil
+	default:
+		return nil, fmt.Errorf("%w: algorithm %d", ErrUnsupportedAlgorithm, algorithm)
+	}
+	return nil, fmt.Errorf("%w: invalid public key parameters", ErrInvalidResponse)
+}
+
+// Verify reports whether signature is a valid signature of data.
+func (k *PublicKey) Verify(data, signature []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
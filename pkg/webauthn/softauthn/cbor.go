@@ -0,0 +1,47 @@
+package softauthn
+
+import "encoding/binary"
+
+// pair is a map entry; maps are encoded from slices of pairs to keep the
+// key order the caller chose.
+type pair struct {
+	key, value interface{}
+}
+
+// encodeCBOR encodes the values softauthn produces: int64, []byte, string
+// and []pair as a map.
+func encodeCBOR(value interface{}) []byte {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return cborHeader(1, uint64(-1-v))
+		}
+		return cborHeader(0, uint64(v))
+	case []byte:
+		return append(cborHeader(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHeader(3, uint64(len(v))), v...)
+	case []pair:
+		data := cborHeader(5, uint64(len(v)))
+		for _, p := range v {
+			data = append(data, encodeCBOR(p.key)...)
+			data = append(data, encodeCBOR(p.value)...)
+		}
+		return data
+	}
+	panic("softauthn: cannot encode value")
+}
+
+func cborHeader(major byte, argument uint64) []byte {
+	switch {
+	case argument < 24:
+		return []byte{major<<5 | byte(argument)}
+	case argument <= 0xff:
+		return []byte{major<<5 | 24, byte(argument)}
+	case argument <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(argument))
+	case argument <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(argument))
+	}
+	return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, argument)
+}
@@ -0,0 +1,199 @@
+// Package softauthn is a software WebAuthn authenticator for tests. It plays
+// both the browser and a platform authenticator: it answers ceremonies with
+// ES256 passkeys as if the user had approved them, without any interaction.
+package softauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/wonyus/backend-challenge/pkg/webauthn"
+)
+
+var (
+	ErrNoCredential       = errors.New("softauthn: no matching credential")
+	ErrCredentialExcluded = errors.New("softauthn: credential already registered")
+	ErrUnsupported        = errors.New("softauthn: ES256 not offered")
+)
+
+// Authenticator holds passkeys for any relying party and signs in from Origin.
+type Authenticator struct {
+	Origin string
+
+	mutex       sync.Mutex
+	credentials []*credential
+}
+
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+func New(origin string) *Authenticator {
+	return &Authenticator{Origin: origin}
+}
+
+// Clone returns an authenticator with copies of the same keys and counters,
+// as an attacker who extracted them would have.
+func (a *Authenticator) Clone() *Authenticator {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	clone := &Authenticator{Origin: a.Origin}
+	for _, c := range a.credentials {
+		copied := *c
+		clone.credentials = append(clone.credentials, &copied)
+	}
+	return clone
+}
+
+// Create answers navigator.credentials.create with a new passkey.
+func (a *Authenticator) Create(options *webauthn.CreationOptions) (*webauthn.RegistrationResponse, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	supported := false
+	for _, parameter := range options.Parameters {
+		supported = supported || parameter.Algorithm == webauthn.AlgES256
+	}
+	if !supported {
+		return nil, ErrUnsupported
+	}
+	for _, excluded := range options.ExcludeCredentials {
+		if a.find(options.RelyingParty.ID, excluded.ID) != nil {
+			return nil, ErrCredentialExcluded
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	c := &credential{id: id, rpID: options.RelyingParty.ID, userHandle: options.User.ID, key: key}
+	a.credentials = append(a.credentials, c)
+
+	var attested bytes.Buffer
+	attested.Write(make([]byte, 16)) // AAGUID
+	binary.Write(&attested, binary.BigEndian, uint16(len(id)))
+	attested.Write(id)
+	attested.Write(encodeCBOR(coseKey(&key.PublicKey)))
+
+	authenticatorData := c.authenticatorData(0x01|0x04|0x40, attested.Bytes())
+	attestationObject := encodeCBOR([]pair{
+		{"fmt", "none"},
+		{"attStmt", []pair{}},
+		{"authData", authenticatorData},
+	})
+
+	return &webauthn.RegistrationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(id),
+		RawID: id,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAttestationData{
+			ClientDataJSON:    a.clientData("webauthn.create", options.Challenge),
+			AttestationObject: attestationObject,
+			Transports:        []string{"internal"},
+		},
+	}, nil
+}
+
+// Get answers navigator.credentials.get with the most recently created
+// matching passkey.
+func (a *Authenticator) Get(options *webauthn.RequestOptions) (*webauthn.AssertionResponse, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	var c *credential
+	if len(options.AllowCredentials) == 0 {
+		for _, candidate := range a.credentials {
+			if candidate.rpID == options.RelyingPartyID {
+				c = candidate
+			}
+		}
+	}
+	for _, allowed := range options.AllowCredentials {
+		if found := a.find(options.RelyingPartyID, allowed.ID); found != nil {
+			c = found
+		}
+	}
+	if c == nil {
+		return nil, ErrNoCredential
+	}
+
+	c.signCount++
+	authenticatorData := c.authenticatorData(0x01|0x04, nil)
+	clientData := a.clientData("webauthn.get", options.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authenticatorData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, c.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.AssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(c.id),
+		RawID: c.id,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAssertionData{
+			ClientDataJSON:    clientData,
+			AuthenticatorData: authenticatorData,
+			Signature:         signature,
+			UserHandle:        c.userHandle,
+		},
+	}, nil
+}
+
+func (a *Authenticator) find(rpID string, id []byte) *credential {
+	for _, c := range a.credentials {
+		if c.rpID == rpID && bytes.Equal(c.id, id) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) clientData(ceremony string, challenge []byte) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":        ceremony,
+		"challenge":   base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+	return data
+}
+
+func (c *credential) authenticatorData(flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(c.rpID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, c.signCount)
+	return append(data, attested...)
+}
+
+func coseKey(key *ecdsa.PublicKey) []pair {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	key.X.FillBytes(x)
+	key.Y.FillBytes(y)
+	return []pair{
+		{int64(1), int64(2)},
+		{int64(3), webauthn.AlgES256},
+		{int64(-1), int64(1)},
+		{int64(-2), x},
+		{int64(-3), y},
+	}
+}
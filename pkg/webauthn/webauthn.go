@@ -0,0 +1,434 @@
+// Package webauthn implements the relying party side of the WebAuthn
+// registration and authentication ceremonies (W3C Web Authentication Level 2)
+// for passkeys. Credentials are discoverable and user verification is always
+// required, so a passkey alone is a complete multi-factor sign in. Only the
+// "none" attestation format is accepted, since authenticators are not vetted.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ChallengeSize = 32
+	// Timeout is how long the browser waits for the user.
+	Timeout = 5 * time.Minute
+
+	maxCredentialIDLength = 1023
+
+	typeCreate = "webauthn.create"
+	typeGet    = "webauthn.get"
+
+	// Authenticator data flags
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagBackupEligible   = 0x08
+	flagBackupState      = 0x10
+	flagAttestedData     = 0x40
+	flagExtensionData    = 0x80
+	authenticatorDataMin = 37
+)
+
+var (
+	ErrInvalidResponse        = errors.New("invalid webauthn response")
+	ErrUnsupportedAlgorithm   = errors.New("unsupported credential algorithm")
+	ErrUnsupportedAttestation = errors.New("unsupported attestation format")
+)
+
+// URLEncodedBase64 is binary data that is base64url encoded in JSON, the
+// encoding browsers use for WebAuthn buffers. Padding is accepted on input.
+type URLEncodedBase64 []byte
+
+func (b URLEncodedBase64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *URLEncodedBase64) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// RelyingParty verifies ceremonies for the relying party ID, which is the
+// registrable domain of the site, and the origins the site is served from.
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity describes the account a credential is created for. ID is an
+// opaque handle without personal data; the authenticator returns it on login.
+type UserEntity struct {
+	ID          URLEncodedBase64 `json:"id"`
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type      string `json:"type"`
+	Algorithm int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string           `json:"type"`
+	ID         URLEncodedBase64 `json:"id"`
+	Transports []string         `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions are the PublicKeyCredentialCreationOptions to pass to
+// navigator.credentials.create after decoding the binary fields.
+type CreationOptions struct {
+	Challenge              URLEncodedBase64       `json:"challenge"`
+	RelyingParty           RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Parameters             []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are the PublicKeyCredentialRequestOptions to pass to
+// navigator.credentials.get. Without AllowCredentials the authenticator
+// offers any passkey it holds for the relying party.
+type RequestOptions struct {
+	Challenge        URLEncodedBase64       `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RelyingPartyID   string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse is the JSON form of the PublicKeyCredential returned
+// by navigator.credentials.create.
+type RegistrationResponse struct {
+	ID       string                       `json:"id"`
+	RawID    URLEncodedBase64             `json:"rawId"`
+	Type     string                       `json:"type"`
+	Response AuthenticatorAttestationData `json:"response"`
+}
+
+type AuthenticatorAttestationData struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AttestationObject URLEncodedBase64 `json:"attestationObject"`
+	Transports        []string         `json:"transports,omitempty"`
+}
+
+// AssertionResponse is the JSON form of the PublicKeyCredential returned by
+// navigator.credentials.get.
+type AssertionResponse struct {
+	ID       string                     `json:"id"`
+	RawID    URLEncodedBase64           `json:"rawId"`
+	Type     string                     `json:"type"`
+	Response AuthenticatorAssertionData `json:"response"`
+}
+
+type AuthenticatorAssertionData struct {
+	ClientDataJSON    URLEncodedBase64 `json:"clientDataJSON"`
+	AuthenticatorData URLEncodedBase64 `json:"authenticatorData"`
+	Signature         URLEncodedBase64 `json:"signature"`
+	UserHandle        URLEncodedBase64 `json:"userHandle,omitempty"`
+}
+
+// ClientData is the collected client data the browser signs over.
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin,omitempty"`
+}
+
+// AuthenticatorData is the parsed authenticator data of a ceremony.
+// CredentialID and PublicKey are only set during registration.
+type AuthenticatorData struct {
+	RelyingPartyIDHash []byte
+	UserPresent        bool
+	UserVerified       bool
+	BackupEligible     bool
+	BackupState        bool
+	SignCount          uint32
+	AAGUID             []byte
+	CredentialID       []byte
+	PublicKey          []byte
+}
+
+// Credential is a newly registered credential. PublicKey is COSE encoded.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	BackupEligible bool
+	BackupState    bool
+}
+
+// NewChallenge returns a random challenge for a ceremony.
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, ChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// CreationOptions starts a registration ceremony. Credentials in exclude are
+// already registered, so the authenticator holding one will not create another.
+func (rp *RelyingParty) CreationOptions(challenge []byte, user UserEntity, exclude []CredentialDescriptor) *CreationOptions {
+	parameters := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, algorithm := range SupportedAlgorithms {
+		parameters = append(parameters, CredentialParameter{Type: "public-key", Algorithm: algorithm})
+	}
+
+	return &CreationOptions{
+		Challenge:          challenge,
+		RelyingParty:       RelyingPartyEntity{ID: rp.ID, Name: rp.Name},
+		User:               user,
+		Parameters:         parameters,
+		Timeout:            Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions starts an authentication ceremony.
+func (rp *RelyingParty) RequestOptions(challenge []byte, allow []CredentialDescriptor) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          Timeout.Milliseconds(),
+		RelyingPartyID:   rp.ID,
+		AllowCredentials: allow,
+		UserVerification: "required",
+	}
+}
+
+// ParseClientData decodes collected client data, for example to find the
+// challenge of a ceremony before verifying it.
+func ParseClientData(clientDataJSON []byte) (*ClientData, error) {
+	var clientData ClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return nil, fmt.Errorf("%w: malformed client data", ErrInvalidResponse)
+	}
+	return &clientData, nil
+}
+
+// DecodedChallenge returns the challenge as bytes.
+func (c *ClientData) DecodedChallenge() ([]byte, error) {
+	challenge, err := base64.RawURLEncoding.DecodeString(c.Challenge)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed challenge", ErrInvalidResponse)
+	}
+	return challenge, nil
+}
+
+// VerifyRegistration checks the response to CreationOptions issued with
+// challenge and returns the new credential.
+func (rp *RelyingParty) VerifyRegistration(response *RegistrationResponse, challenge []byte) (*Credential, error) {
+	if response.Type != "public-key" {
+		return nil, fmt.Errorf("%w: credential type %q", ErrInvalidResponse, response.Type)
+	}
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, typeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, rest, err := decodeCBOR(response.Response.AttestationObject)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: malformed attestation object", ErrInvalidResponse)
+	}
+	attestation, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed attestation object", ErrInvalidResponse)
+	}
+	format, _ := attestation["fmt"].(string)
+	statement, _ := attestation["attStmt"].(map[interface{}]interface{})
+	rawAuthenticatorData, _ := attestation["authData"].([]byte)
+	if format != "none" || len(statement) != 0 {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAttestation, format)
+	}
+
+	authenticatorData, err := rp.verifyAuthenticatorData(rawAuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if authenticatorData.CredentialID == nil {
+		return nil, fmt.Errorf("%w: no attested credential data", ErrInvalidResponse)
+	}
+	if response.RawID != nil && !bytes.Equal(response.RawID, authenticatorData.CredentialID) {
+		return nil, fmt.Errorf("%w: credential id mismatch", ErrInvalidResponse)
+	}
+
+	return &Credential{
+		ID:             authenticatorData.CredentialID,
+		PublicKey:      authenticatorData.PublicKey,
+		SignCount:      authenticatorData.SignCount,
+		AAGUID:         authenticatorData.AAGUID,
+		Transports:     response.Response.Transports,
+		BackupEligible: authenticatorData.BackupEligible,
+		BackupState:    authenticatorData.BackupState,
+	}, nil
+}
+
+// VerifyAssertion checks the response to RequestOptions issued with challenge
+// against the stored COSE public key of the credential. Checking the sign
+// count against the stored one is left to the caller.
+func (rp *RelyingParty) VerifyAssertion(response *AssertionResponse, challenge, publicKey []byte) (*AuthenticatorData, error) {
+	if response.Type != "public-key" {
+		return nil, fmt.Errorf("%w: credential type %q", ErrInvalidResponse, response.Type)
+	}
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, typeGet, challenge); err != nil {
+		return nil, err
+	}
+
+	authenticatorData, err := rp.verifyAuthenticatorData(response.Response.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(response.Response.ClientDataJSON)
+	signed := append(append([]byte(nil), response.Response.AuthenticatorData...), clientDataHash[:]...)
+	if !key.Verify(signed, response.Response.Signature) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidResponse)
+	}
+
+	return authenticatorData, nil
+}
+
+func (rp *RelyingParty) verifyClientData(clientDataJSON []byte, ceremony string, challenge []byte) error {
+	clientData, err := ParseClientData(clientDataJSON)
+	if err != nil {
+		return err
+	}
+	if clientData.Type != ceremony {
+		return fmt.Errorf("%w: client data type %q", ErrInvalidResponse, clientData.Type)
+	}
+
+	expected := base64.RawURLEncoding.EncodeToString(challenge)
+	if subtle.ConstantTimeCompare([]byte(clientData.Challenge), []byte(expected)) != 1 {
+		return fmt.Errorf("%w: challenge mismatch", ErrInvalidResponse)
+	}
+
+	// Embedding in a frame on another origin is not allowed
+	if clientData.CrossOrigin || !rp.isAllowedOrigin(clientData.Origin) {
+		return fmt.Errorf("%w: origin %q not allowed", ErrInvalidResponse, clientData.Origin)
+	}
+	return nil
+}
+
+func (rp *RelyingParty) isAllowedOrigin(origin string) bool {
+	for _, allowed := range rp.Origins {
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	authenticatorData, err := parseAuthenticatorData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(authenticatorData.RelyingPartyIDHash, rpIDHash[:]) != 1 {
+		return nil, fmt.Errorf("%w: relying party id mismatch", ErrInvalidResponse)
+	}
+	if !authenticatorData.UserPresent || !authenticatorData.UserVerified {
+		return nil, fmt.Errorf("%w: user not verified", ErrInvalidResponse)
+	}
+	if authenticatorData.BackupState && !authenticatorData.BackupEligible {
+		return nil, fmt.Errorf("%w: invalid backup flags", ErrInvalidResponse)
+	}
+	return authenticatorData, nil
+}
+
+func parseAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	if len(data) < authenticatorDataMin {
+		return nil, fmt.Errorf("%w: authenticator data too short", ErrInvalidResponse)
+	}
+
+	flags := data[32]
+	authenticatorData := &AuthenticatorData{
+		RelyingPartyIDHash: data[:32],
+		UserPresent:        flags&flagUserPresent != 0,
+		UserVerified:       flags&flagUserVerified != 0,
+		BackupEligible:     flags&flagBackupEligible != 0,
+		BackupState:        flags&flagBackupState != 0,
+		SignCount:          binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[authenticatorDataMin:]
+
+	if flags&flagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("%w: attested credential data too short", ErrInvalidResponse)
+		}
+		authenticatorData.AAGUID = rest[:16]
+		length := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if length == 0 || length > maxCredentialIDLength || len(rest) < length {
+			return nil, fmt.Errorf("%w: invalid credential id", ErrInvalidResponse)
+		}
+		authenticatorData.CredentialID = rest[:length]
+		rest = rest[length:]
+
+		key, remaining, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed public key", ErrInvalidResponse)
+		}
+		if _, err := parseCOSEKey(key); err != nil {
+			return nil, err
+		}
+		authenticatorData.PublicKey = rest[:len(rest)-len(remaining)]
+		rest = remaining
+	}
+
+	// Extension outputs are not used, but must be well-formed
+	if flags&flagExtensionData != 0 {
+		var err error
+		if _, rest, err = decodeCBOR(rest); err != nil {
+			return nil, fmt.Errorf("%w: malformed extensions", ErrInvalidResponse)
+		}
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: trailing authenticator data", ErrInvalidResponse)
+	}
+	return authenticatorData, nil
+}
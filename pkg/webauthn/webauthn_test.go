@@ -0,0 +1,182 @@
+package webauthn_test
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/wonyus/backend-challenge/pkg/webauthn"
+	"github.com/wonyus/backend-challenge/pkg/webauthn/softauthn"
+)
+
+var rp = &webauthn.RelyingParty{ID: "example.com", Name: "Example", Origins: []string{"https://example.com"}}
+
+func register(t *testing.T, authenticator *softauthn.Authenticator) (*webauthn.Credential, []byte) {
+	t.Helper()
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatalf("NewChallenge() error = %v", err)
+	}
+	options := rp.CreationOptions(challenge, webauthn.UserEntity{ID: []byte("user-1"), Name: "test@example.com"}, nil)
+	response, err := authenticator.Create(options)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	credential, err := rp.VerifyRegistration(response, challenge)
+	if err != nil {
+		t.Fatalf("VerifyRegistration() error = %v", err)
+	}
+	return credential, credential.PublicKey
+}
+
+func TestCeremonies(t *testing.T) {
+	authenticator := softauthn.New("https://example.com")
+	credential, publicKey := register(t, authenticator)
+	if len(credential.ID) == 0 || credential.SignCount != 0 {
+		t.Fatalf("VerifyRegistration() = %+v", credential)
+	}
+
+	for i := uint32(1); i <= 2; i++ {
+		challenge, _ := webauthn.NewChallenge()
+		response, err := authenticator.Get(rp.RequestOptions(challenge, nil))
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if string(response.Response.UserHandle) != "user-1" {
+			t.Errorf("UserHandle = %q, want user-1", response.Response.UserHandle)
+		}
+
+		data, err := rp.VerifyAssertion(response, challenge, publicKey)
+		if err != nil {
+			t.Fatalf("VerifyAssertion() error = %v", err)
+		}
+		if data.SignCount != i || !data.UserVerified {
+			t.Errorf("VerifyAssertion() = %+v, want sign count %d", data, i)
+		}
+	}
+}
+
+func TestVerifyAssertion_Rejects(t *testing.T) {
+	authenticator := softauthn.New("https://example.com")
+	_, publicKey := register(t, authenticator)
+	_, otherKey := register(t, softauthn.New("https://example.com"))
+
+	tests := []struct {
+		name      string
+		rp        *webauthn.RelyingParty
+		origin    string
+		tamper    func(*webauthn.AssertionResponse)
+		publicKey []byte
+		challenge []byte
+	}{
+		{name: "other origin", origin: "https://evil.example"},
+		{name: "other relying party", rp: &webauthn.RelyingParty{ID: "other.com", Origins: rp.Origins}},
+		{name: "other challenge", challenge: []byte("other challenge")},
+		{name: "other key", publicKey: otherKey},
+		{name: "tampered signature", tamper: func(r *webauthn.AssertionResponse) { r.Response.Signature[len(r.Response.Signature)-1] ^= 1 }},
+		{name: "user not verified", tamper: func(r *webauthn.AssertionResponse) { r.Response.AuthenticatorData[32] &^= 0x04 }},
+		{name: "registration client data", tamper: func(r *webauthn.AssertionResponse) {
+			var clientData map[string]interface{}
+			json.Unmarshal(r.Response.ClientDataJSON, &clientData)
+			clientData["type"] = "webauthn.create"
+			r.Response.ClientDataJSON, _ = json.Marshal(clientData)
+		}},
+		{name: "trailing data", tamper: func(r *webauthn.AssertionResponse) {
+			r.Response.AuthenticatorData = append(r.Response.AuthenticatorData, 0)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge, _ := webauthn.NewChallenge()
+			authenticator.Origin = "https://example.com"
+			if tt.origin != "" {
+				authenticator.Origin = tt.origin
+			}
+			response, err := authenticator.Get(rp.RequestOptions(challenge, nil))
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(response)
+			}
+
+			verifier, key := rp, publicKey
+			if tt.rp != nil {
+				verifier = tt.rp
+			}
+			if tt.publicKey != nil {
+				key = tt.publicKey
+			}
+			if tt.challenge != nil {
+				challenge = tt.challenge
+			}
+
+			if _, err := verifier.VerifyAssertion(response, challenge, key); !errors.Is(err, webauthn.ErrInvalidResponse) {
+				t.Errorf("VerifyAssertion() error = %v, want ErrInvalidResponse", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistration_Rejects(t *testing.T) {
+	challenge, _ := webauthn.NewChallenge()
+	options := rp.CreationOptions(challenge, webauthn.UserEntity{ID: []byte("user-1")}, nil)
+	response, err := softauthn.New("https://example.com").Create(options)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	other, _ := webauthn.NewChallenge()
+	if _, err := rp.VerifyRegistration(response, other); !errors.Is(err, webauthn.ErrInvalidResponse) {
+		t.Errorf("VerifyRegistration(other challenge) error = %v, want ErrInvalidResponse", err)
+	}
+
+	// Swap the "none" format for "packed" without touching anything else
+	attestation := response.Response.AttestationObject
+	for i := 0; i+4 <= len(attestation); i++ {
+		if string(attestation[i:i+4]) == "none" {
+			packed := append(append(append([]byte(nil), attestation[:i-1]...), 0x66), "packed"...)
+			response.Response.AttestationObject = append(packed, attestation[i+4:]...)
+			break
+		}
+	}
+	if _, err := rp.VerifyRegistration(response, challenge); !errors.Is(err, webauthn.ErrUnsupportedAttestation) {
+		t.Errorf("VerifyRegistration(packed) error = %v, want ErrUnsupportedAttestation", err)
+	}
+}
+
+func TestCreationOptions_ExcludeCredentials(t *testing.T) {
+	authenticator := softauthn.New("https://example.com")
+	credential, _ := register(t, authenticator)
+
+	challenge, _ := webauthn.NewChallenge()
+	options := rp.CreationOptions(challenge, webauthn.UserEntity{ID: []byte("user-1")}, []webauthn.CredentialDescriptor{{Type: "public-key", ID: credential.ID}})
+	if _, err := authenticator.Create(options); !errors.Is(err, softauthn.ErrCredentialExcluded) {
+		t.Errorf("Create() error = %v, want ErrCredentialExcluded", err)
+	}
+}
+
+func TestURLEncodedBase64(t *testing.T) {
+	var decoded struct{ Value webauthn.URLEncodedBase64 }
+	if err := json.Unmarshal([]byte(`{"Value":"aGk="}`), &decoded); err != nil || string(decoded.Value) != "hi" {
+		t.Fatalf("Unmarshal() = %q, %v", decoded.Value, err)
+	}
+	encoded, _ := json.Marshal(decoded)
+	if string(encoded) != `{"Value":"aGk"}` {
+		t.Errorf("Marshal() = %s", encoded)
+	}
+}
+
+func TestParsePublicKey_Unsupported(t *testing.T) {
+	// {1: 2, 3: -35}: an ES384 key
+	if _, err := webauthn.ParsePublicKey([]byte{0xa2, 0x01, 0x02, 0x03, 0x38, 0x22}); !errors.Is(err, webauthn.ErrUnsupportedAlgorithm) {
+		t.Errorf("ParsePublicKey() error = %v, want ErrUnsupportedAlgorithm", err)
+	}
+	sum := sha256.Sum256(nil)
+	if _, err := webauthn.ParsePublicKey(sum[:]); !errors.Is(err, webauthn.ErrInvalidResponse) {
+		t.Errorf("ParsePublicKey(garbage) error = %v, want ErrInvalidResponse", err)
+	}
+}
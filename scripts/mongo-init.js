@@ -1,19 +1,20 @@
 // Create application database
 db = db.getSiblingDB('backend_challenge');
 
-
-// Create users collection with indexes
-db.createCollection('users');
-
-// Create indexes
-db.users.createIndex({ "email": 1 }, { unique: true });
-db.users.createIndex({ "created_at": 1 });
+// Collections and indexes are created by the schema migrations. The servers
+// apply them on startup when runMigrations is set; otherwise run
+// `go run ./cmd/migrate up`.
 
 // Insert sample data (optional)
+// The seed password uses a low bcrypt cost; the service re-hashes it with the
+// configured algorithm and parameters on the first successful login.
 db.users.insertOne({
+  tenant_id: "default",
   name: "Admin User",
   email: "admin@example.com",
+  email_normalized: "admin@example.com",
   password: "$2a$06$R.ga34oljt5UqXmSgNR6ze4QpEbq8u9i0Fui/eG2WpZs/nCgjbT1e",
+  scopes: ["admin:*"],
   created_at: new Date(),
   updated_at: new Date()
 });